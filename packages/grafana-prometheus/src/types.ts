@@ -49,6 +49,9 @@ export interface PromOptions extends DataSourceJsonData {
   defaultEditor?: QueryEditorMode;
   incrementalQuerying?: boolean;
   incrementalQueryOverlapWindow?: string;
+  rangeSplitInterval?: string;
+  rangeSplitConcurrency?: number;
+  rangeSplitCacheTTL?: string;
   disableRecordingRules?: boolean;
   sigV4Auth?: boolean;
   oauthPassThru?: boolean;
@@ -361,6 +361,10 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 // Creates a new dashboard or updates an existing dashboard.
 // Note: This endpoint is not intended for creating folders, use `POST /api/folders` for that.
 //
+// When `merge` is set and the dashboard was saved by someone else in between, the changes are merged into the
+// stored dashboard, using the version they were made on as the common ancestor. Changes of different panels
+// and variables are merged automatically; otherwise the response lists the conflicts.
+//
 // Responses:
 // 200: postDashboardResponse
 // 400: badRequestError
@@ -442,6 +446,23 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 
 	dashboard, err := hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
 
+	merged := false
+	if errors.Is(err, dashboards.ErrDashboardVersionMismatch) && cmd.Merge && !cmd.Overwrite {
+		conflicts, mergeErr := hs.mergeDashboard(ctx, dashItem)
+		if mergeErr != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to merge dashboard", mergeErr)
+		}
+		if len(conflicts) > 0 {
+			return response.JSON(http.StatusPreconditionFailed, util.DynMap{
+				"status":    dashboards.ErrDashboardVersionMismatch.Status,
+				"message":   dashboards.ErrDashboardVersionMismatch.Reason,
+				"conflicts": conflicts,
+			})
+		}
+		dashboard, err = hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
+		merged = err == nil
+	}
+
 	if hs.Live != nil {
 		// Tell everyone listening that the dashboard changed
 		if dashboard == nil {
@@ -475,6 +496,16 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, err)
 	}
 
+	// Provisioned dashboards saved from the UI are written back to providers that support it, such as git.
+	// The dashboard is saved even if that fails, and the error is returned with the response.
+	var provisioningErr string
+	if provisioningData != nil && allowUiUpdate {
+		if _, err := hs.ProvisioningService.SaveDashboardToSource(ctx, provisioningData, dashboard, c.SignedInUser); err != nil {
+			hs.log.Error("Failed to write dashboard back to its provisioning source", "uid", dashboard.UID, "provisioner", provisioningData.Name, "error", err)
+			provisioningErr = err.Error()
+		}
+	}
+
 	// Clear permission cache for the user who's created the dashboard, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	if newDashboard {
@@ -488,7 +519,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":    "success",
 		"slug":      dashboard.Slug,
 		"version":   dashboard.Version,
@@ -496,7 +527,46 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
+		"merged":    merged,
+	}
+	if provisioningErr != "" {
+		result["provisioningError"] = provisioningErr
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// mergeDashboard merges the changes of the dashboard being saved into the stored dashboard, using the
+// version the changes were made on as the common ancestor. On success, the dashboard being saved is
+// replaced by the merged dashboard, with the version of the stored dashboard.
+func (hs *HTTPServer) mergeDashboard(ctx context.Context, dashItem *dashboards.SaveDashboardDTO) ([]dashdiffs.MergeConflict, error) {
+	dash := dashItem.Dashboard
+	current, err := hs.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: dashItem.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	base, err := hs.dashboardVersionService.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID:  current.ID,
+		DashboardUID: current.UID,
+		OrgID:        dashItem.OrgID,
+		Version:      dash.Version,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version %d of the dashboard: %w", dash.Version, err)
+	}
+
+	mergedData, conflicts, err := dashdiffs.Merge(base.Data, dash.Data, current.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	dash.Data = mergedData
+	dash.SetVersion(current.Version)
+	dash.Title = mergedData.Get("title").MustString()
+	dash.UpdateSlug()
+	return nil, nil
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -804,6 +874,10 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 //
 // Perform diff on two dashboards.
 //
+// Each side of the diff is either a stored version of a dashboard, or the unsaved dashboard JSON
+// given in `unsavedDashboard`. The `semantic` diff type returns the added, removed and moved panels,
+// and the changes of queries, thresholds, overrides and variables as JSON.
+//
 // Produces:
 // - application/json
 // - text/html
@@ -853,27 +927,17 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 		},
 	}
 
-	baseVersionQuery := dashver.GetDashboardVersionQuery{
-		DashboardID: options.Base.DashboardId,
-		Version:     options.Base.Version,
-		OrgID:       options.OrgId,
+	baseData, rsp := hs.getDiffTargetData(c.Req.Context(), options.OrgId, options.Base)
+	if rsp != nil {
+		return rsp
 	}
-
-	baseVersionRes, err := hs.dashboardVersionService.Get(c.Req.Context(), &baseVersionQuery)
-	if err != nil {
-		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
-			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
-		}
-		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	newData, rsp := hs.getDiffTargetData(c.Req.Context(), options.OrgId, options.New)
+	if rsp != nil {
+		return rsp
 	}
 
-	newVersionQuery := dashver.GetDashboardVersionQuery{
-		DashboardID: options.New.DashboardId,
-		Version:     options.New.Version,
-		OrgID:       options.OrgId,
-	}
+	result, err := dashdiffs.CalculateDiff(c.Req.Context(), &options, baseData, newData)
 
-	newVersionRes, err := hs.dashboardVersionService.Get(c.Req.Context(), &newVersionQuery)
 	if err != nil {
 		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
 			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
@@ -881,23 +945,33 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
 	}
 
-	baseData := baseVersionRes.Data
-	newData := newVersionRes.Data
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffSemantic {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
 
-	result, err := dashdiffs.CalculateDiff(c.Req.Context(), &options, baseData, newData)
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
 
+// getDiffTargetData returns the unsaved dashboard of the diff target if there is one,
+// otherwise the stored version of the dashboard.
+func (hs *HTTPServer) getDiffTargetData(ctx context.Context, orgID int64, target dashdiffs.DiffTarget) (*simplejson.Json, response.Response) {
+	if target.UnsavedDashboard != nil {
+		return target.UnsavedDashboard, nil
+	}
+
+	versionQuery := dashver.GetDashboardVersionQuery{
+		DashboardID: target.DashboardId,
+		Version:     target.Version,
+		OrgID:       orgID,
+	}
+	versionRes, err := hs.dashboardVersionService.Get(ctx, &versionQuery)
 	if err != nil {
 		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
-			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+			return nil, response.Error(http.StatusNotFound, "Dashboard version not found", err)
 		}
-		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
-	}
-
-	if options.DiffType == dashdiffs.DiffDelta {
-		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+		return nil, response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
 	}
-
-	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+	return versionRes.Data, nil
 }
 
 // swagger:route POST /dashboards/id/{DashboardID}/restore dashboard_versions restoreDashboardVersionByID
@@ -1196,6 +1270,15 @@ type PostDashboardResponse struct {
 		// FolderUID The unique identifier (uid) of the folder the dashboard belongs to.
 		// required: false
 		FolderUID string `json:"folderUid"`
+
+		// Merged Whether the dashboard was merged with changes saved by someone else in between.
+		// required: false
+		Merged bool `json:"merged"`
+
+		// ProvisioningError The error of writing a provisioned dashboard back to its provisioning source, such as git.
+		// The dashboard is saved in Grafana regardless.
+		// required: false
+		ProvisioningError string `json:"provisioningError,omitempty"`
 	} `json:"body"`
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -124,6 +125,75 @@ func TestHTTPServer_DeleteDashboardSnapshot(t *testing.T) {
 	})
 }
 
+func TestHTTPServer_CreateServerSideDashboardSnapshot(t *testing.T) {
+	newRequest := func(server *webtest.Server, body string) *http.Request {
+		req := server.NewPostRequest("/api/snapshots/server-side", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	setup := func(t *testing.T, snapshots dashboardsnapshots.Service) *webtest.Server {
+		t.Helper()
+
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{
+			UID:   "1",
+			Title: "Dash",
+			Data:  simplejson.NewFromAny(map[string]any{"uid": "1", "panels": []any{}}),
+		}, nil)
+
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			cfg := setting.NewCfg()
+			cfg.SnapshotEnabled = true
+			hs.Cfg = cfg
+			hs.dashboardsnapshotsService = snapshots
+			hs.DashboardService = dashSvc
+			hs.queryDataService = query.NewFakeQueryService(t)
+
+			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
+		})
+	}
+
+	t.Run("User should not be able to snapshot a dashboard without permissions", func(t *testing.T) {
+		server := setup(t, dashboardsnapshots.NewMockService(t))
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(
+			newRequest(server, `{"dashboardUid":"1"}`),
+			&user.SignedInUser{UserID: 1, OrgID: 1},
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("User should be able to snapshot a dashboard they can view", func(t *testing.T) {
+		snapshots := dashboardsnapshots.NewMockService(t)
+		var stored *dashboardsnapshots.CreateDashboardSnapshotCommand
+		snapshots.On("CreateDashboardSnapshot", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*dashboardsnapshots.CreateDashboardSnapshotCommand)
+		}).Return(&dashboardsnapshots.DashboardSnapshot{Key: "key", DeleteKey: "deleteKey"}, nil)
+		server := setup(t, snapshots)
+
+		res, err := server.Send(webtest.RequestWithSignedInUser(
+			newRequest(server, `{"dashboardUid":"1","from":"now-1h","to":"now","expires":3600}`),
+			userWithPermissions(1, []accesscontrol.Permission{
+				{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"},
+			}),
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+		require.NotNil(t, stored)
+		assert.Equal(t, "Dash", stored.Name)
+		assert.EqualValues(t, 3600, stored.Expires)
+		assert.Equal(t, "now-1h", stored.Dashboard.GetNestedString("time", "raw", "from"))
+		assert.Equal(t, "/d/1", stored.Dashboard.GetNestedString("snapshot", "originalUrl"))
+	})
+}
+
 func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 	setupRemoteServer := func(fn func(http.ResponseWriter, *http.Request)) *httptest.Server {
 		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
@@ -562,6 +563,44 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 		})
 	})
 
+	t.Run("Given an unsaved dashboard being compared semantically with a stored version", func(t *testing.T) {
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersion = &dashver.DashboardVersionDTO{
+			DashboardID: 1,
+			Version:     1,
+			Data: simplejson.NewFromAny(map[string]any{
+				"panels": []any{map[string]any{"id": 1, "type": "stat", "title": "Up"}},
+			}),
+		}
+		cmd := dtos.CalculateDiffOptions{
+			Base: dtos.CalculateDiffTarget{
+				DashboardId: 1,
+				Version:     1,
+			},
+			New: dtos.CalculateDiffTarget{
+				DashboardId: 1,
+				UnsavedDashboard: simplejson.NewFromAny(map[string]any{
+					"panels": []any{map[string]any{"id": 2, "type": "stat", "title": "Down"}},
+				}),
+			},
+			DiffType: "semantic",
+		}
+
+		postDiffScenario(t, "When calling POST on", "/api/dashboards/calculate-diff", "/api/dashboards/calculate-diff", cmd, org.RoleAdmin, func(sc *scenarioContext) {
+			guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+			callPostDashboard(sc)
+			require.Equal(t, http.StatusOK, sc.resp.Code)
+			assert.Equal(t, "application/json", sc.resp.Header().Get("Content-Type"))
+
+			diff := dashdiffs.SemanticDiff{}
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &diff))
+			require.Len(t, diff.Panels, 2)
+			assert.Equal(t, dashdiffs.SemanticRemoved, diff.Panels[0].Change)
+			assert.Equal(t, int64(2), diff.Panels[1].ID)
+			assert.Equal(t, dashdiffs.SemanticAdded, diff.Panels[1].Change)
+		}, dbtest.NewFakeDB(), fakeDashboardVersionService)
+	})
+
 	t.Run("Given dashboard in folder being restored should restore to folder", func(t *testing.T) {
 		fakeDash := dashboards.NewDashboard("Child dash")
 		fakeDash.ID = 2
@@ -694,6 +733,91 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 	})
 }
 
+func TestPostDashboardMerge(t *testing.T) {
+	panel := func(id int, title string) map[string]any {
+		return map[string]any{"id": id, "type": "stat", "title": title}
+	}
+	base := simplejson.NewFromAny(map[string]any{"id": 1, "uid": "dash", "version": 1, "title": "Dash",
+		"panels": []any{panel(1, "A"), panel(2, "B")}})
+	current := &dashboards.Dashboard{ID: 1, UID: "dash", OrgID: 1, Version: 2, Title: "Dash", Data: simplejson.NewFromAny(map[string]any{
+		"id": 1, "uid": "dash", "version": 2, "title": "Dash", "panels": []any{panel(1, "A changed"), panel(2, "B")}})}
+
+	scenario := func(t *testing.T, ours []any, fn func(sc *scenarioContext, dashboardService *dashboards.FakeDashboardService)) {
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(current, nil).Maybe()
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersion = &dashver.DashboardVersionDTO{DashboardID: 1, Version: 1, Data: base}
+
+		hs := &HTTPServer{
+			Cfg:                          setting.NewCfg(),
+			ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+			QuotaService:                 quotatest.New(false, nil),
+			pluginStore:                  &pluginstore.FakePluginStore{},
+			LibraryPanelService:          &mockLibraryPanelService{},
+			LibraryElementService:        &mockLibraryElementService{},
+			dashboardProvisioningService: mockDashboardProvisioningService{},
+			dashboardVersionService:      fakeDashboardVersionService,
+			DashboardService:             dashboardService,
+			Features:                     featuremgmt.WithFeatures(),
+			accesscontrolService:         actest.FakeService{},
+			log:                          log.New("test-logger"),
+		}
+		cmd := dashboards.SaveDashboardCommand{
+			OrgID: 1,
+			Dashboard: simplejson.NewFromAny(map[string]any{"id": 1, "uid": "dash", "version": 1, "title": "Dash",
+				"panels": ours}),
+			Merge: true,
+		}
+
+		sc := setupScenarioContext(t, "/api/dashboards/db")
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{OrgID: 1, UserID: 1}
+			return hs.PostDashboard(c)
+		})
+		sc.m.Post("/api/dashboards/db", sc.defaultHandler)
+		fn(sc, dashboardService)
+	}
+
+	t.Run("changes of different panels are merged", func(t *testing.T) {
+		scenario(t, []any{panel(1, "A"), panel(2, "B changed")}, func(sc *scenarioContext, dashboardService *dashboards.FakeDashboardService) {
+			dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardVersionMismatch).Once()
+			var saved *dashboards.Dashboard
+			dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(*dashboards.SaveDashboardDTO).Dashboard
+			}).Return(&dashboards.Dashboard{ID: 1, UID: "dash", Slug: "dash", Version: 3}, nil).Once()
+
+			callPostDashboardShouldReturnSuccess(sc)
+			assert.True(t, sc.ToJSON().Get("merged").MustBool())
+			require.NotNil(t, saved)
+			assert.Equal(t, 2, saved.Version)
+			titles := []string{}
+			for _, p := range saved.Data.Get("panels").MustArray() {
+				titles = append(titles, simplejson.NewFromAny(p).Get("title").MustString())
+			}
+			assert.Equal(t, []string{"A changed", "B changed"}, titles)
+		})
+	})
+
+	t.Run("conflicting changes are returned", func(t *testing.T) {
+		scenario(t, []any{panel(1, "A mine"), panel(2, "B")}, func(sc *scenarioContext, dashboardService *dashboards.FakeDashboardService) {
+			dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardVersionMismatch).Once()
+
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			require.Equal(t, http.StatusPreconditionFailed, sc.resp.Code)
+			result := sc.ToJSON()
+			assert.Equal(t, "version-mismatch", result.Get("status").MustString())
+			conflicts := result.Get("conflicts").MustArray()
+			require.Len(t, conflicts, 1)
+			conflict := simplejson.NewFromAny(conflicts[0])
+			assert.Equal(t, "panel", conflict.Get("kind").MustString())
+			assert.Equal(t, "1", conflict.Get("key").MustString())
+		})
+	})
+}
+
 func TestDashboardVersionsAPIEndpoint(t *testing.T) {
 	fakeDash := dashboards.NewDashboard("Child dash")
 
@@ -0,0 +1,255 @@
+package dashdiffs
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// ConflictKind is the kind of element of a dashboard that could not be merged.
+type ConflictKind string
+
+const (
+	ConflictPanel     ConflictKind = "panel"
+	ConflictVariable  ConflictKind = "variable"
+	ConflictDashboard ConflictKind = "dashboard"
+)
+
+// MergeConflict is an element of a dashboard that was changed differently on both sides of a merge.
+// A missing value means the element was removed, or did not exist.
+type MergeConflict struct {
+	Kind ConflictKind `json:"kind"`
+	// Key is the ID of the panel, the name of the variable or the key of the dashboard property.
+	Key    string `json:"key"`
+	Base   any    `json:"base,omitempty"`
+	Ours   any    `json:"ours,omitempty"`
+	Theirs any    `json:"theirs,omitempty"`
+}
+
+// mergeKeysIgnored are the top-level keys of a dashboard that are not merged, and are kept from theirs.
+var mergeKeysIgnored = map[string]bool{"id": true, "uid": true, "version": true}
+
+// Merge merges the changes from base to ours into theirs, where base is the common ancestor
+// of ours and theirs. Panels, by ID, and template variables, by name, are merged independently
+// of each other. Other properties of the dashboard are merged as a whole.
+//
+// The merged dashboard keeps the id, uid and version of theirs. Conflicting changes are returned
+// and, if there are any, the merged dashboard holds the values of theirs for them.
+func Merge(base, ours, theirs *simplejson.Json) (*simplejson.Json, []MergeConflict, error) {
+	baseMap, err := copyDashboard(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	oursMap, err := copyDashboard(ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	theirsMap, err := copyDashboard(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conflicts := make([]MergeConflict, 0)
+	merged := make(map[string]any, len(theirsMap))
+	for k, v := range theirsMap {
+		merged[k] = v
+	}
+
+	keys := make(map[string]bool)
+	for _, m := range []map[string]any{baseMap, oursMap, theirsMap} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	for key := range keys {
+		if mergeKeysIgnored[key] || key == "panels" || key == "templating" {
+			continue
+		}
+		b, o, t := sideOf(baseMap, key), sideOf(oursMap, key), sideOf(theirsMap, key)
+		value, ok := merge3(b, o, t)
+		if !ok {
+			conflicts = append(conflicts, MergeConflict{Kind: ConflictDashboard, Key: key, Base: b.value, Ours: o.value, Theirs: t.value})
+			continue
+		}
+		setSide(merged, key, value)
+	}
+
+	panels, panelConflicts := mergeList(
+		listOf(baseMap["panels"]), listOf(oursMap["panels"]), listOf(theirsMap["panels"]),
+		ConflictPanel, panelKey,
+	)
+	conflicts = append(conflicts, panelConflicts...)
+	if panels != nil || merged["panels"] != nil {
+		merged["panels"] = panels
+	}
+
+	templating := simplejson.NewFromAny(merged["templating"])
+	variables, variableConflicts := mergeList(
+		simplejson.NewFromAny(baseMap["templating"]).Get("list").MustArray(),
+		simplejson.NewFromAny(oursMap["templating"]).Get("list").MustArray(),
+		templating.Get("list").MustArray(),
+		ConflictVariable, variableKey,
+	)
+	conflicts = append(conflicts, variableConflicts...)
+	if variables != nil {
+		templating.Set("list", variables)
+		merged["templating"] = templating.Interface()
+	}
+
+	return simplejson.NewFromAny(merged), conflicts, nil
+}
+
+// copyDashboard returns a deep copy of the dashboard, so that merging does not modify its inputs.
+func copyDashboard(data *simplejson.Json) (map[string]any, error) {
+	if data == nil {
+		return map[string]any{}, nil
+	}
+	encoded, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return decoded.MustMap(map[string]any{}), nil
+}
+
+// side is a value on one side of a merge. A missing value is not the same as a null value.
+type side struct {
+	value   any
+	present bool
+}
+
+func sideOf(m map[string]any, key string) side {
+	v, ok := m[key]
+	return side{value: v, present: ok}
+}
+
+func setSide(m map[string]any, key string, s side) {
+	if s.present {
+		m[key] = s.value
+	} else {
+		delete(m, key)
+	}
+}
+
+func (s side) equal(other side) bool {
+	return s.present == other.present && reflect.DeepEqual(s.value, other.value)
+}
+
+// merge3 merges a single value. It returns false if the value was changed differently on both sides.
+func merge3(base, ours, theirs side) (side, bool) {
+	switch {
+	case ours.equal(theirs):
+		return ours, true
+	case ours.equal(base):
+		return theirs, true
+	case theirs.equal(base):
+		return ours, true
+	default:
+		return theirs, false
+	}
+}
+
+func listOf(v any) []any {
+	list, _ := v.([]any)
+	return list
+}
+
+func panelKey(v any) (string, bool) {
+	id, err := simplejson.NewFromAny(v).Get("id").Int64()
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(id, 10), true
+}
+
+func variableKey(v any) (string, bool) {
+	name, err := simplejson.NewFromAny(v).Get("name").String()
+	if err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// mergeList merges lists of elements identified by key. The merged list follows the order of theirs,
+// and elements added by ours are inserted after the element that precedes them in ours.
+// Elements without a key are kept from theirs.
+func mergeList(base, ours, theirs []any, kind ConflictKind, key func(any) (string, bool)) ([]any, []MergeConflict) {
+	if base == nil && ours == nil && theirs == nil {
+		return nil, nil
+	}
+	byKey := func(list []any) map[string]any {
+		m := make(map[string]any, len(list))
+		for _, v := range list {
+			if k, ok := key(v); ok {
+				m[k] = v
+			}
+		}
+		return m
+	}
+	baseByKey, oursByKey, theirsByKey := byKey(base), byKey(ours), byKey(theirs)
+
+	conflicts := make([]MergeConflict, 0)
+	merged := make([]any, 0, len(theirs))
+	mergedKeys := make(map[string]bool)
+	for _, v := range theirs {
+		k, ok := key(v)
+		if !ok {
+			merged = append(merged, v)
+			continue
+		}
+		value, ok := mergeElement(k, baseByKey, oursByKey, theirsByKey, kind, &conflicts)
+		if ok {
+			merged = append(merged, value)
+			mergedKeys[k] = true
+		}
+	}
+
+	// Elements that are only in ours were either added by ours, or removed by theirs.
+	for i, v := range ours {
+		k, ok := key(v)
+		if !ok || mergedKeys[k] {
+			continue
+		}
+		if _, inTheirs := theirsByKey[k]; inTheirs {
+			// Removed by ours, or conflicting, and already handled.
+			continue
+		}
+		value, ok := mergeElement(k, baseByKey, oursByKey, theirsByKey, kind, &conflicts)
+		if !ok {
+			continue
+		}
+		position := 0
+		for j := i - 1; j >= 0; j-- {
+			if prev, ok := key(ours[j]); ok && mergedKeys[prev] {
+				position = indexOfKey(merged, prev, key) + 1
+				break
+			}
+		}
+		merged = append(merged[:position], append([]any{value}, merged[position:]...)...)
+		mergedKeys[k] = true
+	}
+	return merged, conflicts
+}
+
+// mergeElement merges the element with the key, and returns false if the merged list should not contain it.
+func mergeElement(k string, base, ours, theirs map[string]any, kind ConflictKind, conflicts *[]MergeConflict) (any, bool) {
+	b, o, t := sideOf(base, k), sideOf(ours, k), sideOf(theirs, k)
+	value, ok := merge3(b, o, t)
+	if !ok {
+		*conflicts = append(*conflicts, MergeConflict{Kind: kind, Key: k, Base: b.value, Ours: o.value, Theirs: t.value})
+	}
+	return value.value, value.present
+}
+
+func indexOfKey(list []any, k string, key func(any) (string, bool)) int {
+	for i, v := range list {
+		if vk, ok := key(v); ok && vk == k {
+			return i
+		}
+	}
+	return len(list) - 1
+}
@@ -0,0 +1,111 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestMerge(t *testing.T) {
+	const baseJSON = `{
+		"id": 1, "uid": "dash", "version": 1, "title": "Dash", "refresh": "1m",
+		"panels": [
+			{"id": 1, "type": "stat", "title": "A"},
+			{"id": 2, "type": "stat", "title": "B"},
+			{"id": 3, "type": "stat", "title": "C"}
+		],
+		"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "host", "query": "*"}]}
+	}`
+	parse := func(t *testing.T, s string) *simplejson.Json {
+		t.Helper()
+		j, err := simplejson.NewJson([]byte(s))
+		require.NoError(t, err)
+		return j
+	}
+	titles := func(j *simplejson.Json) []string {
+		var titles []string
+		for _, p := range j.Get("panels").MustArray() {
+			titles = append(titles, simplejson.NewFromAny(p).Get("title").MustString())
+		}
+		return titles
+	}
+
+	t.Run("non-conflicting changes are merged", func(t *testing.T) {
+		ours := parse(t, `{
+			"id": 1, "uid": "dash", "version": 1, "title": "Dash renamed", "refresh": "1m",
+			"panels": [
+				{"id": 1, "type": "stat", "title": "A"},
+				{"id": 4, "type": "stat", "title": "D"},
+				{"id": 2, "type": "stat", "title": "B mine"}
+			],
+			"templating": {"list": [{"name": "env", "query": "prod,dev"}, {"name": "host", "query": "*"}]}
+		}`)
+		theirs := parse(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Dash", "refresh": "5m",
+			"panels": [
+				{"id": 1, "type": "stat", "title": "A theirs"},
+				{"id": 2, "type": "stat", "title": "B"},
+				{"id": 3, "type": "stat", "title": "C"}
+			],
+			"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "region", "query": "eu"}]}
+		}`)
+
+		merged, conflicts, err := Merge(parse(t, baseJSON), ours, theirs)
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+
+		assert.Equal(t, "Dash renamed", merged.Get("title").MustString())
+		assert.Equal(t, "5m", merged.Get("refresh").MustString())
+		assert.Equal(t, 2, merged.Get("version").MustInt())
+		// Panel 3 was removed by ours, and panel 4 added after panel 1.
+		assert.Equal(t, []string{"A theirs", "D", "B mine"}, titles(merged))
+
+		var variables []string
+		for _, v := range merged.GetPath("templating", "list").MustArray() {
+			variable := simplejson.NewFromAny(v)
+			variables = append(variables, variable.Get("name").MustString()+"="+variable.Get("query").MustString())
+		}
+		assert.Equal(t, []string{"env=prod,dev", "region=eu"}, variables)
+	})
+
+	t.Run("conflicting changes are returned", func(t *testing.T) {
+		ours := parse(t, `{
+			"id": 1, "uid": "dash", "version": 1, "title": "Dash", "refresh": "10s",
+			"panels": [
+				{"id": 1, "type": "stat", "title": "A mine"},
+				{"id": 3, "type": "stat", "title": "C"}
+			],
+			"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "host", "query": "*"}]}
+		}`)
+		theirs := parse(t, `{
+			"id": 1, "uid": "dash", "version": 2, "title": "Dash", "refresh": "5m",
+			"panels": [
+				{"id": 1, "type": "stat", "title": "A theirs"},
+				{"id": 2, "type": "stat", "title": "B theirs"},
+				{"id": 3, "type": "stat", "title": "C"}
+			],
+			"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "host", "query": "*"}]}
+		}`)
+
+		merged, conflicts, err := Merge(parse(t, baseJSON), ours, theirs)
+		require.NoError(t, err)
+		require.Len(t, conflicts, 3)
+
+		byKey := map[string]MergeConflict{}
+		for _, c := range conflicts {
+			byKey[string(c.Kind)+":"+c.Key] = c
+		}
+		assert.Contains(t, byKey, "dashboard:refresh")
+		assert.Contains(t, byKey, "panel:1")
+		// Panel 2 was removed by ours and changed by theirs.
+		require.Contains(t, byKey, "panel:2")
+		assert.Nil(t, byKey["panel:2"].Ours)
+		assert.NotNil(t, byKey["panel:2"].Theirs)
+
+		// Conflicting elements keep the values of theirs.
+		assert.Equal(t, []string{"A theirs", "B theirs", "C"}, titles(merged))
+	})
+}
@@ -0,0 +1,333 @@
+package dashdiffs
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// SemanticChange is the kind of change of an element of a dashboard in a semantic diff.
+type SemanticChange string
+
+const (
+	SemanticAdded    SemanticChange = "added"
+	SemanticRemoved  SemanticChange = "removed"
+	SemanticModified SemanticChange = "modified"
+)
+
+// SemanticDiff is a summary of the changes between two versions of a dashboard, in terms
+// of panels, queries and variables rather than JSON paths.
+type SemanticDiff struct {
+	Panels    []PanelChange    `json:"panels"`
+	Variables []VariableChange `json:"variables"`
+}
+
+// PanelChange describes the changes of a panel, identified by its ID.
+type PanelChange struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	Type  string `json:"type,omitempty"`
+	// Change is added, removed or modified. A panel that is only moved is modified.
+	Change SemanticChange `json:"change"`
+	// Moved is set when the position or the parent row of the panel changed.
+	Moved *PanelMove `json:"moved,omitempty"`
+	// TitleChange is set when the title of the panel changed.
+	TitleChange *ValueChange `json:"titleChange,omitempty"`
+	// Datasource is set when the datasource of the panel changed.
+	Datasource *ValueChange  `json:"datasource,omitempty"`
+	Queries    []QueryChange `json:"queries,omitempty"`
+	Thresholds *ValueChange  `json:"thresholds,omitempty"`
+	Overrides  *ValueChange  `json:"overrides,omitempty"`
+	// Options is set when anything else in the panel changed.
+	Options *ValueChange `json:"options,omitempty"`
+}
+
+// PanelMove is the position of a panel before and after a change.
+type PanelMove struct {
+	FromRow     *int64 `json:"fromRow,omitempty"`
+	ToRow       *int64 `json:"toRow,omitempty"`
+	FromGridPos any    `json:"fromGridPos,omitempty"`
+	ToGridPos   any    `json:"toGridPos,omitempty"`
+}
+
+// QueryChange describes the change of a query of a panel, identified by its ref ID.
+type QueryChange struct {
+	RefID  string         `json:"refId"`
+	Change SemanticChange `json:"change"`
+	// Datasource is the datasource of the query after the change, or before it if the query was removed.
+	Datasource any `json:"datasource,omitempty"`
+	Before     any `json:"before,omitempty"`
+	After      any `json:"after,omitempty"`
+}
+
+// VariableChange describes the change of a template variable, identified by its name.
+type VariableChange struct {
+	Name   string         `json:"name"`
+	Change SemanticChange `json:"change"`
+	Before any            `json:"before,omitempty"`
+	After  any            `json:"after,omitempty"`
+}
+
+// ValueChange is a value before and after a change.
+type ValueChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// CalculateSemanticDiff compares the panels and variables of two versions of a dashboard.
+// Panels of collapsed rows are compared as well.
+func CalculateSemanticDiff(baseData, newData *simplejson.Json) *SemanticDiff {
+	return &SemanticDiff{
+		Panels:    diffPanels(dashboardPanels(baseData), dashboardPanels(newData)),
+		Variables: diffVariables(baseData, newData),
+	}
+}
+
+// dashboardPanel is a panel of a dashboard, with the ID of the row it belongs to.
+type dashboardPanel struct {
+	row   *int64
+	panel map[string]any
+}
+
+func (p dashboardPanel) title() string {
+	title, _ := p.panel["title"].(string)
+	return title
+}
+
+func (p dashboardPanel) panelType() string {
+	panelType, _ := p.panel["type"].(string)
+	return panelType
+}
+
+// dashboardPanels returns the panels of the dashboard by ID, including the panels of rows.
+// Rows themselves are returned as well.
+func dashboardPanels(data *simplejson.Json) map[int64]dashboardPanel {
+	panels := make(map[int64]dashboardPanel)
+	var currentRow *int64
+	for _, p := range data.Get("panels").MustArray() {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, err := simplejson.NewFromAny(panel).Get("id").Int64()
+		if err != nil {
+			continue
+		}
+		if panel["type"] == "row" {
+			rowID := id
+			panels[id] = dashboardPanel{panel: panel}
+			// Panels of expanded rows follow the row at the top level, while
+			// panels of collapsed rows are nested in the row.
+			currentRow = &rowID
+			for _, child := range simplejson.NewFromAny(panel).Get("panels").MustArray() {
+				childPanel, ok := child.(map[string]any)
+				if !ok {
+					continue
+				}
+				if childID, err := simplejson.NewFromAny(childPanel).Get("id").Int64(); err == nil {
+					panels[childID] = dashboardPanel{row: &rowID, panel: childPanel}
+				}
+			}
+			continue
+		}
+		panels[id] = dashboardPanel{row: currentRow, panel: panel}
+	}
+	return panels
+}
+
+func diffPanels(base, new map[int64]dashboardPanel) []PanelChange {
+	changes := make([]PanelChange, 0)
+	for id, before := range base {
+		after, ok := new[id]
+		if !ok {
+			changes = append(changes, PanelChange{ID: id, Title: before.title(), Type: before.panelType(), Change: SemanticRemoved})
+			continue
+		}
+		if change, changed := diffPanel(id, before, after); changed {
+			changes = append(changes, change)
+		}
+	}
+	for id, after := range new {
+		if _, ok := base[id]; !ok {
+			changes = append(changes, PanelChange{ID: id, Title: after.title(), Type: after.panelType(), Change: SemanticAdded})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// panelKeysCompared are the keys of panels that are reported on their own, rather than as options.
+var panelKeysCompared = map[string]bool{
+	"id": true, "title": true, "gridPos": true, "datasource": true, "targets": true, "fieldConfig": true,
+	// The panels of a row are compared as panels.
+	"panels": true,
+}
+
+func diffPanel(id int64, before, after dashboardPanel) (PanelChange, bool) {
+	change := PanelChange{ID: id, Title: after.title(), Type: after.panelType(), Change: SemanticModified}
+	changed := false
+
+	beforeGridPos, afterGridPos := before.panel["gridPos"], after.panel["gridPos"]
+	if !equalRow(before.row, after.row) || !reflect.DeepEqual(beforeGridPos, afterGridPos) {
+		change.Moved = &PanelMove{FromRow: before.row, ToRow: after.row, FromGridPos: beforeGridPos, ToGridPos: afterGridPos}
+		changed = true
+	}
+	if before.title() != after.title() {
+		change.TitleChange = &ValueChange{Before: before.title(), After: after.title()}
+		changed = true
+	}
+	if vc := diffValue(before.panel["datasource"], after.panel["datasource"]); vc != nil {
+		change.Datasource = vc
+		changed = true
+	}
+	if queries := diffQueries(before.panel, after.panel); len(queries) > 0 {
+		change.Queries = queries
+		changed = true
+	}
+
+	beforeConfig := simplejson.NewFromAny(before.panel["fieldConfig"])
+	afterConfig := simplejson.NewFromAny(after.panel["fieldConfig"])
+	if vc := diffValue(beforeConfig.GetPath("defaults", "thresholds").Interface(), afterConfig.GetPath("defaults", "thresholds").Interface()); vc != nil {
+		change.Thresholds = vc
+		changed = true
+	}
+	if vc := diffValue(beforeConfig.Get("overrides").Interface(), afterConfig.Get("overrides").Interface()); vc != nil {
+		change.Overrides = vc
+		changed = true
+	}
+
+	if vc := diffValue(panelOptions(before.panel), panelOptions(after.panel)); vc != nil {
+		change.Options = vc
+		changed = true
+	}
+	return change, changed
+}
+
+// panelOptions returns a copy of the panel without the keys that are compared on their own.
+// The field config is kept without its thresholds and overrides.
+func panelOptions(panel map[string]any) map[string]any {
+	options := make(map[string]any, len(panel))
+	for k, v := range panel {
+		if !panelKeysCompared[k] {
+			options[k] = v
+		}
+	}
+	if fieldConfig, ok := panel["fieldConfig"].(map[string]any); ok {
+		config := make(map[string]any, len(fieldConfig))
+		for k, v := range fieldConfig {
+			if k == "overrides" {
+				continue
+			}
+			if k == "defaults" {
+				if defaults, ok := v.(map[string]any); ok {
+					copied := make(map[string]any, len(defaults))
+					for dk, dv := range defaults {
+						if dk != "thresholds" {
+							copied[dk] = dv
+						}
+					}
+					v = copied
+				}
+			}
+			config[k] = v
+		}
+		options["fieldConfig"] = config
+	}
+	return options
+}
+
+func diffQueries(before, after map[string]any) []QueryChange {
+	beforeQueries, afterQueries := panelQueries(before), panelQueries(after)
+	changes := make([]QueryChange, 0)
+	for refID, b := range beforeQueries {
+		a, ok := afterQueries[refID]
+		if !ok {
+			changes = append(changes, QueryChange{RefID: refID, Change: SemanticRemoved, Datasource: queryDatasource(b, before), Before: b})
+			continue
+		}
+		beforeDatasource, afterDatasource := queryDatasource(b, before), queryDatasource(a, after)
+		if !reflect.DeepEqual(b, a) || !reflect.DeepEqual(beforeDatasource, afterDatasource) {
+			changes = append(changes, QueryChange{RefID: refID, Change: SemanticModified, Datasource: afterDatasource, Before: b, After: a})
+		}
+	}
+	for refID, a := range afterQueries {
+		if _, ok := beforeQueries[refID]; !ok {
+			changes = append(changes, QueryChange{RefID: refID, Change: SemanticAdded, Datasource: queryDatasource(a, after), After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].RefID < changes[j].RefID })
+	return changes
+}
+
+// panelQueries returns the queries of the panel by ref ID.
+func panelQueries(panel map[string]any) map[string]map[string]any {
+	queries := make(map[string]map[string]any)
+	targets, _ := panel["targets"].([]any)
+	for _, t := range targets {
+		target, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		refID, _ := target["refId"].(string)
+		queries[refID] = target
+	}
+	return queries
+}
+
+// queryDatasource returns the datasource of the query, which defaults to the datasource of the panel.
+func queryDatasource(query map[string]any, panel map[string]any) any {
+	if ds, ok := query["datasource"]; ok && ds != nil {
+		return ds
+	}
+	return panel["datasource"]
+}
+
+func diffVariables(baseData, newData *simplejson.Json) []VariableChange {
+	before, after := dashboardVariables(baseData), dashboardVariables(newData)
+	changes := make([]VariableChange, 0)
+	for name, b := range before {
+		a, ok := after[name]
+		if !ok {
+			changes = append(changes, VariableChange{Name: name, Change: SemanticRemoved, Before: b})
+			continue
+		}
+		if !reflect.DeepEqual(b, a) {
+			changes = append(changes, VariableChange{Name: name, Change: SemanticModified, Before: b, After: a})
+		}
+	}
+	for name, a := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, VariableChange{Name: name, Change: SemanticAdded, After: a})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func dashboardVariables(data *simplejson.Json) map[string]map[string]any {
+	variables := make(map[string]map[string]any)
+	for _, v := range data.GetPath("templating", "list").MustArray() {
+		variable, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := variable["name"].(string)
+		variables[name] = variable
+	}
+	return variables
+}
+
+func diffValue(before, after any) *ValueChange {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return &ValueChange{Before: before, After: after}
+}
+
+func equalRow(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,111 @@
+package dashdiffs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestCalculateSemanticDiff(t *testing.T) {
+	const (
+		baseJSON = `{
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "CPU", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+				 "datasource": {"uid": "prom"},
+				 "targets": [{"refId": "A", "expr": "cpu"}, {"refId": "B", "expr": "load"}],
+				 "fieldConfig": {"defaults": {"unit": "percent", "thresholds": {"steps": [{"value": null, "color": "green"}]}}, "overrides": []}},
+				{"id": 2, "type": "stat", "title": "Memory", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "type": "row", "title": "Details", "collapsed": true, "panels": [
+					{"id": 4, "type": "table", "title": "Hosts", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}}
+				]}
+			],
+			"templating": {"list": [
+				{"name": "env", "type": "custom", "query": "prod,dev"},
+				{"name": "old", "type": "textbox"}
+			]}
+		}`
+		newJSON = `{
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "CPU usage", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+				 "datasource": {"uid": "prom"},
+				 "targets": [{"refId": "A", "expr": "cpu", "datasource": {"uid": "other"}}, {"refId": "C", "expr": "steal"}],
+				 "fieldConfig": {"defaults": {"unit": "percent", "thresholds": {"steps": [{"value": null, "color": "red"}]}},
+				   "overrides": [{"matcher": {"id": "byName", "options": "A"}, "properties": []}]}},
+				{"id": 4, "type": "table", "title": "Hosts", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 5, "type": "gauge", "title": "Disk", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}},
+				{"id": 3, "type": "row", "title": "Details", "collapsed": true, "panels": []}
+			],
+			"templating": {"list": [
+				{"name": "env", "type": "custom", "query": "prod,dev,staging"},
+				{"name": "region", "type": "query"}
+			]}
+		}`
+	)
+	base, err := simplejson.NewJson([]byte(baseJSON))
+	require.NoError(t, err)
+	next, err := simplejson.NewJson([]byte(newJSON))
+	require.NoError(t, err)
+
+	diff := CalculateSemanticDiff(base, next)
+
+	require.Len(t, diff.Panels, 4)
+
+	cpu := diff.Panels[0]
+	assert.Equal(t, int64(1), cpu.ID)
+	assert.Equal(t, SemanticModified, cpu.Change)
+	assert.Nil(t, cpu.Moved)
+	assert.Nil(t, cpu.Datasource)
+	assert.Nil(t, cpu.Options)
+	assert.Equal(t, &ValueChange{Before: "CPU", After: "CPU usage"}, cpu.TitleChange)
+	require.NotNil(t, cpu.Thresholds)
+	require.NotNil(t, cpu.Overrides)
+	require.Len(t, cpu.Queries, 3)
+	assert.Equal(t, "A", cpu.Queries[0].RefID)
+	assert.Equal(t, SemanticModified, cpu.Queries[0].Change)
+	assert.Equal(t, map[string]any{"uid": "other"}, cpu.Queries[0].Datasource)
+	assert.Equal(t, "B", cpu.Queries[1].RefID)
+	assert.Equal(t, SemanticRemoved, cpu.Queries[1].Change)
+	assert.Equal(t, "C", cpu.Queries[2].RefID)
+	assert.Equal(t, SemanticAdded, cpu.Queries[2].Change)
+	assert.Equal(t, map[string]any{"uid": "prom"}, cpu.Queries[2].Datasource)
+
+	assert.Equal(t, PanelChange{ID: 2, Title: "Memory", Type: "stat", Change: SemanticRemoved}, diff.Panels[1])
+
+	hosts := diff.Panels[2]
+	assert.Equal(t, int64(4), hosts.ID)
+	assert.Equal(t, SemanticModified, hosts.Change)
+	require.NotNil(t, hosts.Moved)
+	require.NotNil(t, hosts.Moved.FromRow)
+	assert.Equal(t, int64(3), *hosts.Moved.FromRow)
+	assert.Nil(t, hosts.Moved.ToRow)
+	assert.Equal(t, map[string]any{"x": json.Number("12"), "y": json.Number("0"), "w": json.Number("12"), "h": json.Number("8")}, hosts.Moved.ToGridPos)
+
+	assert.Equal(t, PanelChange{ID: 5, Title: "Disk", Type: "gauge", Change: SemanticAdded}, diff.Panels[3])
+
+	require.Len(t, diff.Variables, 3)
+	assert.Equal(t, "env", diff.Variables[0].Name)
+	assert.Equal(t, SemanticModified, diff.Variables[0].Change)
+	assert.Equal(t, "old", diff.Variables[1].Name)
+	assert.Equal(t, SemanticRemoved, diff.Variables[1].Change)
+	assert.Equal(t, "region", diff.Variables[2].Name)
+	assert.Equal(t, SemanticAdded, diff.Variables[2].Change)
+}
+
+func TestCalculateDiffSemantic(t *testing.T) {
+	dash := simplejson.NewFromAny(map[string]any{
+		"panels": []any{map[string]any{"id": 1, "type": "stat", "title": "Up"}},
+	})
+
+	result, err := CalculateDiff(context.Background(), &Options{DiffType: ParseDiffType("semantic")}, dash, dash)
+	require.NoError(t, err)
+
+	diff := SemanticDiff{}
+	require.NoError(t, json.Unmarshal(result.Delta, &diff))
+	assert.Empty(t, diff.Panels)
+	assert.Empty(t, diff.Variables)
+}
@@ -108,6 +108,9 @@ var (
 	// MPublicDashboardDatasourceQuerySuccess is a metric counter for successful queries labelled by datasource
 	MPublicDashboardDatasourceQuerySuccess *prometheus.CounterVec
 
+	// MPublicDashboardQueryRateLimited is a metric counter for public dashboards queries rejected by rate limits, labelled by limit
+	MPublicDashboardQueryRateLimited *prometheus.CounterVec
+
 	// MFolderIDsAPICount is a metric counter for folder ids count in the api package
 	MFolderIDsAPICount *prometheus.CounterVec
 
@@ -468,6 +471,12 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"datasource", "status"}, map[string][]string{"status": pubdash.QueryResultStatuses})
 
+	MPublicDashboardQueryRateLimited = metricutil.NewCounterVecStartingAtZero(prometheus.CounterOpts{
+		Name:      "public_dashboard_query_rate_limited_total",
+		Help:      "counter for public dashboards queries rejected by rate limits, labelled by the limit per dashboard or per ip",
+		Namespace: ExporterName,
+	}, []string{"limit"}, map[string][]string{"limit": pubdash.QueryRateLimits})
+
 	MFolderIDsAPICount = metricutil.NewCounterVecStartingAtZero(prometheus.CounterOpts{
 		Name:      "folder_id_api_count",
 		Help:      "counter for folder id usage in api package",
@@ -774,6 +783,7 @@ func initMetricVars(reg prometheus.Registerer) {
 		MStatTotalPublicDashboards,
 		MPublicDashboardRequestCount,
 		MPublicDashboardDatasourceQuerySuccess,
+		MPublicDashboardQueryRateLimited,
 		MStatTotalCorrelations,
 		MFolderIDsAPICount,
 		MFolderIDsServiceCount,
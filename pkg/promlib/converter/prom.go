@@ -21,6 +21,45 @@ func logf(format string, a ...any) {
 
 type Options struct {
 	Dataplane bool
+	// MaxSeries and MaxPoints stop reading results with more series or data points, zero means no limit.
+	// Log lines are counted as one data point.
+	MaxSeries int64
+	MaxPoints int64
+}
+
+// LimitExceededError is the error of results which have more series or data points than the limits of
+// the options.
+type LimitExceededError struct {
+	// Limit is "series" or "points"
+	Limit string
+	Max   int64
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("query result exceeds the limit of %d %s", e.Max, e.Limit)
+}
+
+// QueryLimit returns the exceeded limit, like the errors of the query limits of Grafana.
+func (e *LimitExceededError) QueryLimit() (string, int64) {
+	return e.Limit, e.Max
+}
+
+// limiter counts the series and data points read from a result.
+type limiter struct {
+	maxSeries, maxPoints int64
+	series, points       int64
+}
+
+func (l *limiter) add(series, points int64) error {
+	l.series += series
+	l.points += points
+	if l.maxSeries > 0 && l.series > l.maxSeries {
+		return &LimitExceededError{Limit: "series", Max: l.maxSeries}
+	}
+	if l.maxPoints > 0 && l.points > l.maxPoints {
+		return &LimitExceededError{Limit: "points", Max: l.maxPoints}
+	}
+	return nil
 }
 
 func rspErr(e error) backend.DataResponse {
@@ -192,6 +231,9 @@ l1Fields:
 			if len(resultBytes) > 0 {
 				ji := sdkjsoniter.NewIterator(jsoniter.ParseBytes(sdkjsoniter.ConfigDefault, resultBytes))
 				rsp = readResult(resultType, rsp, ji, opt, encodingFlags)
+				if rsp.Error != nil {
+					return rsp
+				}
 			}
 		case "result":
 			// for some rare cases resultType is coming after the result.
@@ -199,6 +241,9 @@ l1Fields:
 			// see: https://github.com/grafana/grafana/issues/64693
 			if resultTypeFound {
 				rsp = readResult(resultType, rsp, iter, opt, encodingFlags)
+				if rsp.Error != nil {
+					return rsp
+				}
 			} else {
 				resultBytes, _ = iter.SkipAndReturnBytes()
 			}
@@ -242,17 +287,18 @@ l1Fields:
 
 // will read the result object based on the resultType and return a DataResponse
 func readResult(resultType string, rsp backend.DataResponse, iter *sdkjsoniter.Iterator, opt Options, encodingFlags []string) backend.DataResponse {
+	l := &limiter{maxSeries: opt.MaxSeries, maxPoints: opt.MaxPoints}
 	switch resultType {
 	case "matrix", "vector":
-		rsp = readMatrixOrVectorMulti(iter, resultType, opt)
+		rsp = readMatrixOrVectorMulti(iter, resultType, opt, l)
 		if rsp.Error != nil {
 			return rsp
 		}
 	case "streams":
 		if slices.Contains(encodingFlags, "categorize-labels") {
-			rsp = readCategorizedStream(iter)
+			rsp = readCategorizedStream(iter, l)
 		} else {
-			rsp = readStream(iter)
+			rsp = readStream(iter, l)
 		}
 		if rsp.Error != nil {
 			return rsp
@@ -573,13 +619,16 @@ func readScalar(iter *sdkjsoniter.Iterator, dataPlane bool) backend.DataResponse
 	}
 }
 
-func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt Options) backend.DataResponse {
+func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt Options, l *limiter) backend.DataResponse {
 	rsp := backend.DataResponse{}
 
 	for more, err := iter.ReadArray(); more; more, err = iter.ReadArray() {
 		if err != nil {
 			return rspErr(err)
 		}
+		if err := l.add(1, 0); err != nil {
+			return rspErr(err)
+		}
 		timeField := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
 		timeField.Name = data.TimeSeriesTimeFieldName
 		valueField := data.NewFieldFromFieldType(data.FieldTypeFloat64, 0)
@@ -603,6 +652,9 @@ func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt
 				if err != nil {
 					return rspErr(err)
 				}
+				if err := l.add(0, 1); err != nil {
+					return rspErr(err)
+				}
 				timeField.Append(t)
 				valueField.Append(v)
 
@@ -616,6 +668,9 @@ func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt
 					if err != nil {
 						return rspErr(err)
 					}
+					if err := l.add(0, 1); err != nil {
+						return rspErr(err)
+					}
 					timeField.Append(t)
 					valueField.Append(v)
 				}
@@ -624,10 +679,14 @@ func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt
 				if histogram == nil {
 					histogram = newHistogramInfo()
 				}
+				buckets := histogram.time.Len()
 				err = readHistogram(iter, histogram)
 				if err != nil {
 					return rspErr(err)
 				}
+				if err := l.add(0, int64(histogram.time.Len()-buckets)); err != nil {
+					return rspErr(err)
+				}
 
 			case "histograms":
 				if histogram == nil {
@@ -637,9 +696,13 @@ func readMatrixOrVectorMulti(iter *sdkjsoniter.Iterator, resultType string, opt
 					if err != nil {
 						return rspErr(err)
 					}
+					buckets := histogram.time.Len()
 					if err = readHistogram(iter, histogram); err != nil {
 						return rspErr(err)
 					}
+					if err := l.add(0, int64(histogram.time.Len()-buckets)); err != nil {
+						return rspErr(err)
+					}
 				}
 
 			default:
@@ -850,8 +913,11 @@ func appendValueFromString(iter *sdkjsoniter.Iterator, field *data.Field) error
 	return nil
 }
 
-func readStream(iter *sdkjsoniter.Iterator) backend.DataResponse {
+func readStream(iter *sdkjsoniter.Iterator, l *limiter) backend.DataResponse {
 	rsp := backend.DataResponse{}
+	if err := l.add(1, 0); err != nil {
+		return rspErr(err)
+	}
 
 	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
 	labelsField.Name = "__labels" // avoid automatically spreading this by labels
@@ -928,6 +994,9 @@ func readStream(iter *sdkjsoniter.Iterator) backend.DataResponse {
 					if err != nil {
 						return rspErr(err)
 					}
+					if err := l.add(0, 1); err != nil {
+						return rspErr(err)
+					}
 
 					labelsField.Append(labelJson)
 					timeField.Append(t)
@@ -950,8 +1019,11 @@ func readStream(iter *sdkjsoniter.Iterator) backend.DataResponse {
 	return rsp
 }
 
-func readCategorizedStream(iter *sdkjsoniter.Iterator) backend.DataResponse {
+func readCategorizedStream(iter *sdkjsoniter.Iterator, l *limiter) backend.DataResponse {
 	rsp := backend.DataResponse{}
+	if err := l.add(1, 0); err != nil {
+		return rspErr(err)
+	}
 
 	labelsField := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
 	labelsField.Name = "__labels" // avoid automatically spreading this by labels
@@ -1032,6 +1104,9 @@ func readCategorizedStream(iter *sdkjsoniter.Iterator) backend.DataResponse {
 					if err != nil {
 						return rspErr(err)
 					}
+					if err := l.add(0, 1); err != nil {
+						return rspErr(err)
+					}
 
 					typeMap := data.Labels{}
 					clonedLabels := data.Labels{}
@@ -64,6 +64,43 @@ func runScenario(name string, opts Options) func(t *testing.T) {
 	}
 }
 
+func TestReadPromFramesWithLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		frames int
+		err    string
+	}{
+		{name: "prom-matrix", opts: Options{MaxSeries: 2, MaxPoints: 6}, frames: 2},
+		{name: "prom-matrix", opts: Options{MaxSeries: 1}, err: "query result exceeds the limit of 1 series"},
+		{name: "prom-matrix", opts: Options{MaxPoints: 5}, err: "query result exceeds the limit of 5 points"},
+		{name: "prom-matrix-histogram-no-labels", opts: Options{MaxPoints: 932}, frames: 1},
+		{name: "prom-matrix-histogram-no-labels", opts: Options{MaxPoints: 931}, err: "query result exceeds the limit of 931 points"},
+		{name: "loki-streams-a", opts: Options{MaxSeries: 1, MaxPoints: 6}, frames: 1},
+		{name: "loki-streams-a", opts: Options{MaxPoints: 5}, err: "query result exceeds the limit of 5 points"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Safe to disable, this is a test.
+			// nolint:gosec
+			f, err := os.Open(path.Join("testdata", test.name+".json"))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = f.Close() })
+
+			rsp := ReadPrometheusStyleResult(jsoniter.Parse(sdkjsoniter.ConfigDefault, f, 1024), test.opts)
+			if test.err == "" {
+				require.NoError(t, rsp.Error)
+				require.Len(t, rsp.Frames, test.frames)
+				return
+			}
+			require.EqualError(t, rsp.Error, test.err)
+			var limitErr *LimitExceededError
+			require.ErrorAs(t, rsp.Error, &limitErr)
+		})
+	}
+}
+
 func TestTimeConversions(t *testing.T) {
 	// include millisecond precision
 	assert.Equal(t,
@@ -0,0 +1,417 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	defaultRangeSplitConcurrency   = 4
+	defaultIncrementalQueryOverlap = 10 * time.Minute
+	maxRangeSplitCacheEntries      = 10000
+)
+
+// rangeSplitOptions configures splitting long range queries into chunks. Splitting is
+// disabled when the interval is zero, caching of chunks is disabled when the TTL is zero.
+type rangeSplitOptions struct {
+	// Interval is the maximum duration of a chunk, it is rounded down to a multiple of the query step.
+	Interval time.Duration
+	// Concurrency is the maximum number of chunks queried at the same time.
+	Concurrency int
+	// CacheTTL is how long chunks are cached.
+	CacheTTL time.Duration
+	// OverlapWindow is the duration before now in which chunks are always queried, because
+	// the data may still change.
+	OverlapWindow time.Duration
+}
+
+func parseRangeSplitOptions(jsonData map[string]any) (rangeSplitOptions, error) {
+	opts := rangeSplitOptions{
+		Concurrency:   defaultRangeSplitConcurrency,
+		OverlapWindow: defaultIncrementalQueryOverlap,
+	}
+
+	durations := map[string]*time.Duration{
+		"rangeSplitInterval":            &opts.Interval,
+		"rangeSplitCacheTTL":            &opts.CacheTTL,
+		"incrementalQueryOverlapWindow": &opts.OverlapWindow,
+	}
+	for key, d := range durations {
+		s, err := maputil.GetStringOptional(jsonData, key)
+		if err != nil {
+			return opts, err
+		}
+		if s == "" {
+			continue
+		}
+		if *d, err = gtime.ParseDuration(s); err != nil {
+			return opts, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	if v, ok := jsonData["rangeSplitConcurrency"]; ok && v != nil {
+		concurrency, ok := v.(float64)
+		if !ok || concurrency < 1 {
+			return opts, fmt.Errorf("invalid rangeSplitConcurrency: %v", v)
+		}
+		opts.Concurrency = int(concurrency)
+	}
+
+	return opts, nil
+}
+
+// rangeChunk is a step aligned part of the time range of a query. Start and end are inclusive.
+type rangeChunk struct {
+	Start time.Time
+	End   time.Time
+}
+
+// splitTimeRange splits the time range of the query into chunks of at most the given
+// interval. Chunk boundaries are aligned to multiples of the chunk size, so that the
+// chunks of older data stay the same when the time range moves. Nil is returned if the
+// time range doesn't need to be split.
+func splitTimeRange(q *models.Query, interval time.Duration) []rangeChunk {
+	tr := q.TimeRange()
+	if tr.Step <= 0 || interval <= 0 {
+		return nil
+	}
+
+	size := interval - interval%tr.Step
+	if size < tr.Step {
+		size = tr.Step
+	}
+	if tr.End.Sub(tr.Start) < size {
+		return nil
+	}
+
+	var chunks []rangeChunk
+	for start := tr.Start; !start.After(tr.End); {
+		next := models.AlignTimeRange(start, size, q.UtcOffsetSec).Add(size)
+		end := next.Add(-tr.Step)
+		if end.After(tr.End) {
+			end = tr.End
+		}
+		chunks = append(chunks, rangeChunk{Start: start, End: end})
+		start = next
+	}
+	return chunks
+}
+
+// splitRangeQuery runs the range query in chunks with bounded concurrency and merges the
+// frames of the chunks. Chunks which can't change anymore are cached, so that refreshing
+// a dashboard only queries the newest chunks.
+func (s *QueryData) splitRangeQuery(ctx context.Context, c *client.Client, q *models.Query, chunks []rangeChunk, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	logger := s.log.FromContext(ctx)
+	logger.Debug("Splitting range query", "query", q.Expr, "chunks", len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cacheable := time.Now().Add(-s.rangeSplit.OverlapWindow)
+	responses := make([]backend.DataResponse, len(chunks))
+	sem := make(chan struct{}, s.rangeSplit.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		key := chunkCacheKey(chunkCacheIdentityFromContext(ctx), q, chunk, enablePrometheusDataplaneFlag)
+		if frames, ok := s.chunkCache.get(key); ok {
+			responses[i] = backend.DataResponse{Frames: frames}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, chunk rangeChunk, key string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				responses[i] = backend.DataResponse{Error: ctx.Err()}
+				return
+			}
+
+			chunkQuery := *q
+			chunkQuery.Start = chunk.Start
+			chunkQuery.End = chunk.End
+			res := s.queryRange(ctx, c, &chunkQuery, enablePrometheusDataplaneFlag)
+			if res.Error != nil {
+				cancel()
+			} else if chunk.End.Before(cacheable) {
+				s.chunkCache.set(key, res.Frames)
+			}
+			responses[i] = res
+		}(i, chunk, key)
+	}
+	wg.Wait()
+
+	if res := firstChunkError(responses); res != nil {
+		return *res
+	}
+
+	frames := make([]data.Frames, 0, len(responses))
+	for _, res := range responses {
+		frames = append(frames, res.Frames)
+	}
+
+	merged := mergeChunkFrames(frames)
+	if len(merged) > 0 {
+		merged[0].Meta.ExecutedQueryString = executedQueryString(q)
+	}
+	return backend.DataResponse{Frames: merged}
+}
+
+// firstChunkError returns the response of the first failed chunk. Errors of chunks
+// cancelled because of that failure are only returned if there is no other error.
+func firstChunkError(responses []backend.DataResponse) *backend.DataResponse {
+	var cancelled *backend.DataResponse
+	for i, res := range responses {
+		if res.Error == nil {
+			continue
+		}
+		if !errors.Is(res.Error, context.Canceled) {
+			return &responses[i]
+		}
+		if cancelled == nil {
+			cancelled = &responses[i]
+		}
+	}
+	return cancelled
+}
+
+// mergeChunkFrames merges the frames of consecutive chunks. Frames of the same series
+// are concatenated, series are kept in the order they first appear in.
+func mergeChunkFrames(chunks []data.Frames) data.Frames {
+	var merged data.Frames
+	index := map[string]*data.Frame{}
+	var empty *data.Frame
+
+	for _, frames := range chunks {
+		for _, frame := range frames {
+			if len(frame.Fields) == 0 {
+				if empty == nil {
+					empty = frame
+				}
+				continue
+			}
+
+			key := frameSeriesKey(frame)
+			target, ok := index[key]
+			if !ok {
+				target = emptyFrameCopy(frame)
+				target.Meta.ExecutedQueryString = ""
+				index[key] = target
+				merged = append(merged, target)
+			} else if target.Meta != nil && frame.Meta != nil {
+				target.Meta.Notices = appendNewNotices(target.Meta.Notices, frame.Meta.Notices)
+			}
+
+			for rowIdx := 0; rowIdx < frame.Rows(); rowIdx++ {
+				for fieldIdx, field := range frame.Fields {
+					target.Fields[fieldIdx].Append(field.CopyAt(rowIdx))
+				}
+			}
+		}
+	}
+
+	if len(merged) == 0 && empty != nil {
+		return data.Frames{emptyFrameCopy(empty)}
+	}
+	return merged
+}
+
+// frameSeriesKey identifies the series of a frame by its name and the names, types and
+// labels of its fields.
+func frameSeriesKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("\x00")
+		sb.WriteString(field.Name)
+		sb.WriteString("\x00")
+		sb.WriteString(field.Type().String())
+		sb.WriteString("\x00")
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
+
+// emptyFrameCopy returns a frame with the same name, metadata and fields as the given
+// frame, but without rows.
+func emptyFrameCopy(frame *data.Frame) *data.Frame {
+	fields := make([]*data.Field, len(frame.Fields))
+	for i, field := range frame.Fields {
+		fields[i] = data.NewFieldFromFieldType(field.Type(), 0)
+		fields[i].Name = field.Name
+		fields[i].Labels = field.Labels
+		fields[i].Config = field.Config
+	}
+
+	copied := data.NewFrame(frame.Name, fields...)
+	copied.RefID = frame.RefID
+	meta := data.FrameMeta{}
+	if frame.Meta != nil {
+		meta = *frame.Meta
+		meta.Notices = append([]data.Notice(nil), frame.Meta.Notices...)
+	}
+	copied.Meta = &meta
+	return copied
+}
+
+func appendNewNotices(notices []data.Notice, more []data.Notice) []data.Notice {
+	for _, n := range more {
+		found := false
+		for _, existing := range notices {
+			if existing.Severity == n.Severity && existing.Text == n.Text {
+				found = true
+				break
+			}
+		}
+		if !found {
+			notices = append(notices, n)
+		}
+	}
+	return notices
+}
+
+func chunkCacheKey(identity string, q *models.Query, chunk rangeChunk, enablePrometheusDataplaneFlag bool) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%d\x00%t\x00%s",
+		identity, q.Expr, q.Step, chunk.Start.UnixNano(), chunk.End.UnixNano(), enablePrometheusDataplaneFlag, q.LegendFormat)
+}
+
+// requestMetadataHeaders are forwarded headers which describe the request rather than the caller,
+// they are left out of the identity of cached chunks. All other forwarded headers, such as OAuth
+// tokens, cookies and tenant headers, can change the result of the query.
+var requestMetadataHeaders = map[string]bool{
+	"X-Datasource-Uid":            true,
+	"X-Dashboard-Uid":             true,
+	"X-Panel-Id":                  true,
+	"X-Panel-Plugin-Id":           true,
+	"X-Query-Group-Id":            true,
+	"X-Grafana-From-Expr":         true,
+	"X-Grafana-Org-Id":            true,
+	"X-Grafana-Request-Id":        true,
+	"X-Grafana-Signed-Request-Id": true,
+	"X-Grafana-Internal-Request":  true,
+	"X-Real-Ip":                   true,
+}
+
+type chunkCacheIdentityKey struct{}
+
+// withChunkCacheIdentity adds the identity of the caller of the request to the context, so that
+// chunks cached for one user are never returned to another user.
+func withChunkCacheIdentity(ctx context.Context, req *backend.QueryDataRequest) context.Context {
+	return context.WithValue(ctx, chunkCacheIdentityKey{}, chunkCacheIdentity(req))
+}
+
+func chunkCacheIdentityFromContext(ctx context.Context) string {
+	identity, _ := ctx.Value(chunkCacheIdentityKey{}).(string)
+	return identity
+}
+
+// chunkCacheIdentity hashes the user and the forwarded headers of the request.
+func chunkCacheIdentity(req *backend.QueryDataRequest) string {
+	h := sha256.New()
+	if user := req.PluginContext.User; user != nil {
+		_, _ = fmt.Fprintf(h, "%s\x00", user.Login)
+	}
+	headers := req.GetHTTPHeaders()
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		if !requestMetadataHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", http.CanonicalHeaderKey(name), strings.Join(headers[name], "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// chunkCache is a short-lived cache of the frames of range query chunks.
+type chunkCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]chunkCacheEntry
+}
+
+type chunkCacheEntry struct {
+	frames  data.Frames
+	expires time.Time
+}
+
+func newChunkCache(ttl time.Duration, maxEntries int) *chunkCache {
+	return &chunkCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]chunkCacheEntry{},
+	}
+}
+
+func (c *chunkCache) get(key string) (data.Frames, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.frames, true
+}
+
+func (c *chunkCache) set(key string, frames data.Frames) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	// still full, evict the entry expiring first
+	for len(c.entries) >= c.maxEntries {
+		var oldest string
+		var oldestExpires time.Time
+		for k, entry := range c.entries {
+			if oldest == "" || entry.expires.Before(oldestExpires) {
+				oldest, oldestExpires = k, entry.expires
+			}
+		}
+		delete(c.entries, oldest)
+	}
+
+	c.entries[key] = chunkCacheEntry{frames: frames, expires: now.Add(c.ttl)}
+}
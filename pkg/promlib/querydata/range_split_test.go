@@ -0,0 +1,300 @@
+package querydata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+// fakePrometheus answers range queries with one sample per step for two series, the
+// second series only has samples after seriesBStart.
+type fakePrometheus struct {
+	mu           sync.Mutex
+	requests     []rangeChunk
+	seriesBStart time.Time
+	failStatus   int
+}
+
+func (p *fakePrometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	start, _ := strconv.ParseFloat(r.Form.Get("start"), 64)
+	end, _ := strconv.ParseFloat(r.Form.Get("end"), 64)
+	step, _ := strconv.ParseFloat(r.Form.Get("step"), 64)
+
+	p.mu.Lock()
+	p.requests = append(p.requests, rangeChunk{Start: time.Unix(int64(start), 0).UTC(), End: time.Unix(int64(end), 0).UTC()})
+	p.mu.Unlock()
+
+	if p.failStatus != 0 {
+		w.WriteHeader(p.failStatus)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"timeout","error":"query timed out in expression evaluation"}`))
+		return
+	}
+
+	var a, b [][]any
+	for ts := start; ts <= end; ts += step {
+		a = append(a, []any{ts, strconv.FormatFloat(ts, 'f', -1, 64)})
+		if !p.seriesBStart.IsZero() && ts >= float64(p.seriesBStart.Unix()) {
+			b = append(b, []any{ts, "1"})
+		}
+	}
+	result := []map[string]any{{"metric": map[string]string{"__name__": "up", "job": "a"}, "values": a}}
+	if len(b) > 0 {
+		result = append(result, map[string]any{"metric": map[string]string{"__name__": "up", "job": "b"}, "values": b})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status": "success",
+		"data":   map[string]any{"resultType": "matrix", "result": result},
+	})
+}
+
+func (p *fakePrometheus) reset() []rangeChunk {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	requests := p.requests
+	p.requests = nil
+	return requests
+}
+
+func setupRangeSplit(t *testing.T, jsonData string, prom *fakePrometheus) *QueryData {
+	t.Helper()
+
+	server := httptest.NewServer(prom)
+	t.Cleanup(server.Close)
+
+	qd, err := New(server.Client(), backend.DataSourceInstanceSettings{
+		URL:      server.URL,
+		JSONData: json.RawMessage(jsonData),
+	}, log.New())
+	require.NoError(t, err)
+	return qd
+}
+
+func executeRangeQuery(t *testing.T, qd *QueryData, from, to time.Time) backend.DataResponse {
+	t.Helper()
+	return executeRangeQueryAs(t, qd, from, to, nil, nil)
+}
+
+// executeRangeQueryAs executes the range query as the user, with the forwarded headers.
+func executeRangeQueryAs(t *testing.T, qd *QueryData, from, to time.Time, user *backend.User, headers map[string]string) backend.DataResponse {
+	t.Helper()
+
+	req := &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{User: user},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      []byte(`{"expr": "up", "range": true, "interval": "1h"}`),
+			TimeRange: backend.TimeRange{From: from, To: to},
+		}},
+	}
+	for k, v := range headers {
+		req.SetHTTPHeader(k, v)
+	}
+	res, err := qd.Execute(context.Background(), req)
+	require.NoError(t, err)
+	return res.Responses["A"]
+}
+
+func TestRangeSplit(t *testing.T) {
+	from := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+
+	t.Run("splits the time range into aligned chunks and merges the series", func(t *testing.T) {
+		prom := &fakePrometheus{seriesBStart: from.Add(36 * time.Hour)}
+		qd := setupRangeSplit(t, `{"rangeSplitInterval": "1d", "rangeSplitConcurrency": 2}`, prom)
+
+		res := executeRangeQuery(t, qd, from, to)
+		require.NoError(t, res.Error)
+
+		requests := prom.reset()
+		require.ElementsMatch(t, []rangeChunk{
+			{Start: from, End: time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)},
+			{Start: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 2, 23, 0, 0, 0, time.UTC)},
+			{Start: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC), End: to},
+		}, requests)
+
+		require.Len(t, res.Frames, 2)
+		a := res.Frames[0]
+		require.Equal(t, data.Labels{"__name__": "up", "job": "a"}, a.Fields[1].Labels)
+		require.Equal(t, 49, a.Rows())
+		for i := 0; i < a.Rows(); i++ {
+			require.Equal(t, from.Add(time.Duration(i)*time.Hour), a.Fields[0].At(i).(time.Time).UTC())
+		}
+		require.Equal(t, "Expr: up\nStep: 1h0m0s", a.Meta.ExecutedQueryString)
+
+		b := res.Frames[1]
+		require.Equal(t, data.Labels{"__name__": "up", "job": "b"}, b.Fields[1].Labels)
+		require.Equal(t, 13, b.Rows())
+		require.Empty(t, b.Meta.ExecutedQueryString)
+	})
+
+	t.Run("does not split short time ranges", func(t *testing.T) {
+		prom := &fakePrometheus{}
+		qd := setupRangeSplit(t, `{"rangeSplitInterval": "1d"}`, prom)
+
+		res := executeRangeQuery(t, qd, from, from.Add(12*time.Hour))
+		require.NoError(t, res.Error)
+		require.Len(t, prom.reset(), 1)
+		require.Equal(t, 13, res.Frames[0].Rows())
+	})
+
+	t.Run("does not split without interval", func(t *testing.T) {
+		prom := &fakePrometheus{}
+		qd := setupRangeSplit(t, `{}`, prom)
+
+		res := executeRangeQuery(t, qd, from, to)
+		require.NoError(t, res.Error)
+		require.Len(t, prom.reset(), 1)
+	})
+
+	t.Run("returns the error of failed chunks", func(t *testing.T) {
+		prom := &fakePrometheus{failStatus: http.StatusServiceUnavailable}
+		qd := setupRangeSplit(t, `{"rangeSplitInterval": "1d"}`, prom)
+
+		res := executeRangeQuery(t, qd, from, to)
+		require.Error(t, res.Error)
+		require.Equal(t, backend.Status(http.StatusServiceUnavailable), res.Status)
+	})
+
+	t.Run("refreshes only query the newest chunks", func(t *testing.T) {
+		prom := &fakePrometheus{}
+		qd := setupRangeSplit(t, `{"rangeSplitInterval": "1d", "rangeSplitCacheTTL": "5m", "incrementalQueryOverlapWindow": "2h"}`, prom)
+
+		now := time.Now().UTC()
+		res := executeRangeQuery(t, qd, now.Add(-72*time.Hour), now)
+		require.NoError(t, res.Error)
+		first := prom.reset()
+		require.Len(t, first, 4)
+
+		refreshed := executeRangeQuery(t, qd, now.Add(-72*time.Hour), now)
+		require.NoError(t, refreshed.Error)
+		requests := prom.reset()
+		require.NotEmpty(t, requests)
+		require.Less(t, len(requests), len(first))
+		for _, r := range requests {
+			require.True(t, r.End.After(now.Add(-3*time.Hour)), "chunk %v should have been cached", r)
+		}
+		require.Equal(t, res.Frames[0].Rows(), refreshed.Frames[0].Rows())
+	})
+
+	t.Run("does not share cached chunks between callers", func(t *testing.T) {
+		prom := &fakePrometheus{}
+		qd := setupRangeSplit(t, `{"rangeSplitInterval": "1d", "rangeSplitCacheTTL": "5m", "incrementalQueryOverlapWindow": "2h"}`, prom)
+
+		now := time.Now().UTC()
+		alice := &backend.User{Login: "alice"}
+		bob := &backend.User{Login: "bob"}
+		query := func(user *backend.User, headers map[string]string) int {
+			res := executeRangeQueryAs(t, qd, now.Add(-72*time.Hour), now, user, headers)
+			require.NoError(t, res.Error)
+			return len(prom.reset())
+		}
+
+		require.Equal(t, 4, query(alice, map[string]string{"Authorization": "Bearer alice"}))
+		require.Equal(t, 4, query(bob, map[string]string{"Authorization": "Bearer bob"}))
+		// Forwarded headers are part of the identity, e.g. for tenant headers of multi-tenant Prometheus.
+		require.Equal(t, 4, query(alice, map[string]string{"Authorization": "Bearer alice", "X-Scope-OrgID": "other"}))
+
+		// Headers describing the request are not, so that the cache is shared between panels.
+		cached := query(alice, map[string]string{"Authorization": "Bearer alice", "X-Panel-Id": "2"})
+		require.Less(t, cached, 4)
+	})
+
+	t.Run("does not cache without TTL", func(t *testing.T) {
+		prom := &fakePrometheus{}
+		qd := setupRangeSplit(t, `{"rangeSplitInterval": "1d"}`, prom)
+
+		for i := 0; i < 2; i++ {
+			res := executeRangeQuery(t, qd, from, to)
+			require.NoError(t, res.Error)
+			require.Len(t, prom.reset(), 3)
+		}
+	})
+}
+
+func TestSplitTimeRange(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	t.Run("chunk size is a multiple of step", func(t *testing.T) {
+		q := &models.Query{Start: start, End: start.Add(100 * time.Minute), Step: 7 * time.Minute}
+		chunks := splitTimeRange(q, 30*time.Minute)
+		require.NotEmpty(t, chunks)
+		for i, c := range chunks {
+			require.Zero(t, c.Start.Sub(q.TimeRange().Start)%q.Step, "chunk %d", i)
+			if i > 0 {
+				require.Equal(t, chunks[i-1].End.Add(q.Step), c.Start)
+			}
+		}
+		require.Equal(t, q.TimeRange().End, chunks[len(chunks)-1].End)
+	})
+
+	t.Run("interval below step", func(t *testing.T) {
+		q := &models.Query{Start: start, End: start.Add(3 * time.Hour), Step: time.Hour}
+		require.Len(t, splitTimeRange(q, time.Minute), 4)
+	})
+
+	t.Run("utc offset", func(t *testing.T) {
+		q := &models.Query{Start: start, End: start.Add(30 * time.Hour), Step: time.Hour, UtcOffsetSec: 3600}
+		chunks := splitTimeRange(q, 24*time.Hour)
+		require.Len(t, chunks, 2)
+		require.Equal(t, time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC), chunks[1].Start)
+	})
+}
+
+func TestParseRangeSplitOptions(t *testing.T) {
+	opts, err := parseRangeSplitOptions(map[string]any{})
+	require.NoError(t, err)
+	require.Equal(t, rangeSplitOptions{Concurrency: defaultRangeSplitConcurrency, OverlapWindow: defaultIncrementalQueryOverlap}, opts)
+
+	opts, err = parseRangeSplitOptions(map[string]any{
+		"rangeSplitInterval":            "1d",
+		"rangeSplitConcurrency":         float64(8),
+		"rangeSplitCacheTTL":            "2m",
+		"incrementalQueryOverlapWindow": "30m",
+	})
+	require.NoError(t, err)
+	require.Equal(t, rangeSplitOptions{Interval: 24 * time.Hour, Concurrency: 8, CacheTTL: 2 * time.Minute, OverlapWindow: 30 * time.Minute}, opts)
+
+	for _, jsonData := range []map[string]any{
+		{"rangeSplitInterval": "one day"},
+		{"rangeSplitConcurrency": float64(0)},
+		{"rangeSplitConcurrency": "4"},
+	} {
+		_, err := parseRangeSplitOptions(jsonData)
+		require.Error(t, err, fmt.Sprint(jsonData))
+	}
+}
+
+func TestChunkCache(t *testing.T) {
+	c := newChunkCache(time.Minute, 2)
+	c.set("a", data.Frames{data.NewFrame("a")})
+	c.set("b", data.Frames{data.NewFrame("b")})
+	c.set("c", data.Frames{data.NewFrame("c")})
+
+	_, ok := c.get("a")
+	require.False(t, ok)
+	frames, ok := c.get("c")
+	require.True(t, ok)
+	require.Equal(t, "c", frames[0].Name)
+
+	disabled := newChunkCache(0, 2)
+	disabled.set("a", data.Frames{})
+	_, ok = disabled.get("a")
+	require.False(t, ok)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -16,6 +17,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
 
 	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/converter"
 	"github.com/grafana/grafana/pkg/promlib/intervalv2"
 	"github.com/grafana/grafana/pkg/promlib/models"
 	"github.com/grafana/grafana/pkg/promlib/querydata/exemplar"
@@ -24,6 +26,13 @@ import (
 
 const legendFormatAuto = "__auto"
 
+// headerMaxSeries and headerMaxPoints are the headers of the limits of series and data points of query
+// results, which are set by the query limits of Grafana.
+const (
+	headerMaxSeries = "QueryLimitsMaxSeries"
+	headerMaxPoints = "QueryLimitsMaxPoints"
+)
+
 var legendFormatRegexp = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 
 type ExemplarEvent struct {
@@ -43,6 +52,8 @@ type QueryData struct {
 	URL                string
 	TimeInterval       string
 	exemplarSampler    func() exemplar.Sampler
+	rangeSplit         rangeSplitOptions
+	chunkCache         *chunkCache
 }
 
 func New(
@@ -61,6 +72,11 @@ func New(
 		return nil, err
 	}
 
+	rangeSplit, err := parseRangeSplitOptions(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
 	if httpMethod == "" {
 		httpMethod = http.MethodPost
 	}
@@ -79,6 +95,8 @@ func New(
 		ID:                 settings.ID,
 		URL:                settings.URL,
 		exemplarSampler:    exemplarSampler,
+		rangeSplit:         rangeSplit,
+		chunkCache:         newChunkCache(rangeSplit.CacheTTL, maxRangeSplitCacheEntries),
 	}, nil
 }
 
@@ -91,6 +109,10 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	cfg := backend.GrafanaConfigFromContext(ctx)
 	hasPromQLScopeFeatureFlag := cfg.FeatureToggles().IsEnabled("promQLScope")
 	hasPrometheusDataplaneFeatureFlag := cfg.FeatureToggles().IsEnabled("prometheusDataplane")
+	if s.rangeSplit.CacheTTL > 0 {
+		ctx = withChunkCacheIdentity(ctx, req)
+	}
+	ctx = withQueryLimits(ctx, req)
 
 	for _, q := range req.Queries {
 		r := s.handleQuery(ctx, q, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag)
@@ -103,6 +125,23 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	return &result, nil
 }
 
+type queryLimitsKey struct{}
+
+// withQueryLimits adds the limits of series and data points in the headers of the request to the
+// context, so that parsing a response stops once it exceeds them.
+func withQueryLimits(ctx context.Context, req *backend.QueryDataRequest) context.Context {
+	maxSeries, _ := strconv.ParseInt(req.Headers[headerMaxSeries], 10, 64)
+	maxPoints, _ := strconv.ParseInt(req.Headers[headerMaxPoints], 10, 64)
+	return context.WithValue(ctx, queryLimitsKey{}, converter.Options{MaxSeries: maxSeries, MaxPoints: maxPoints})
+}
+
+// converterOptions returns the options of parsing a response, with the query limits of the context.
+func converterOptions(ctx context.Context, enablePrometheusDataplaneFlag bool) converter.Options {
+	opts, _ := ctx.Value(queryLimitsKey{}).(converter.Options)
+	opts.Dataplane = enablePrometheusDataplaneFlag
+	return opts
+}
+
 func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool) *backend.DataResponse {
 	traceCtx, span := s.tracer.Start(ctx, "datasource.prometheus")
 	defer span.End()
@@ -165,6 +204,13 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 }
 
 func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	if chunks := splitTimeRange(q, s.rangeSplit.Interval); len(chunks) > 1 {
+		return s.splitRangeQuery(ctx, c, q, chunks, enablePrometheusDataplaneFlag)
+	}
+	return s.queryRange(ctx, c, q, enablePrometheusDataplaneFlag)
+}
+
+func (s *QueryData) queryRange(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
 	res, err := c.QueryRange(ctx, q)
 	if err != nil {
 		return backend.DataResponse{
@@ -29,9 +29,7 @@ func (s *QueryData) parseResponse(ctx context.Context, q *models.Query, res *htt
 	defer endSpan()
 
 	iter := jsoniter.Parse(jsoniter.ConfigDefault, res.Body, 1024)
-	r := converter.ReadPrometheusStyleResult(iter, converter.Options{
-		Dataplane: enablePrometheusDataplaneFlag,
-	})
+	r := converter.ReadPrometheusStyleResult(iter, converterOptions(ctx, enablePrometheusDataplaneFlag))
 	r.Status = backend.Status(res.StatusCode)
 
 	// Add frame to attach metadata
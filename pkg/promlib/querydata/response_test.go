@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/grafana/grafana/pkg/promlib/models"
@@ -48,6 +49,20 @@ func TestQueryData_parseResponse(t *testing.T) {
 		assert.Equal(t, result.Error.Error(), "unknown result type: ")
 	})
 
+	t.Run("parsing stops at the query limits of the request", func(t *testing.T) {
+		resBody := `{"data":{"resultType":"matrix", "result":[{"metric":{"job":"a"},"values":[[1,"1"],[2,"2"]]},{"metric":{"job":"b"},"values":[[1,"3"]]}]},"status":"success"}`
+		ctx := withQueryLimits(context.Background(), &backend.QueryDataRequest{Headers: map[string]string{headerMaxSeries: "1"}})
+		res := &http.Response{Body: io.NopCloser(bytes.NewBufferString(resBody))}
+		result := qd.parseResponse(ctx, &models.Query{}, res, false)
+		assert.EqualError(t, result.Error, "query result exceeds the limit of 1 series")
+
+		ctx = withQueryLimits(context.Background(), &backend.QueryDataRequest{Headers: map[string]string{headerMaxSeries: "2", headerMaxPoints: "3"}})
+		res = &http.Response{Body: io.NopCloser(bytes.NewBufferString(resBody))}
+		result = qd.parseResponse(ctx, &models.Query{}, res, false)
+		assert.Nil(t, result.Error)
+		assert.Len(t, result.Frames, 2)
+	})
+
 	t.Run("resultType is set as empty string after result", func(t *testing.T) {
 		resBody := `{"data":{"result":[{"metric":{"__name__":"some_name","environment":"some_env","id":"some_id","instance":"some_instance:1234","job":"some_job","name":"another_name","region":"some_region"},"value":[1.1,"2"]}],"resultType":""},"status":"success"}`
 		res := &http.Response{Body: io.NopCloser(bytes.NewBufferString(resBody))}
@@ -28,8 +28,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	"github.com/grafana/grafana/pkg/services/apiserver/utils"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
@@ -43,29 +45,37 @@ var resourceInfo = dashboardsnapshot.DashboardSnapshotResourceInfo
 
 // This is used just so wire has something unique to return
 type SnapshotsAPIBuilder struct {
-	service    dashboardsnapshots.Service
-	namespacer request.NamespaceMapper
-	options    sharingOptionsGetter
-	exporter   *dashExporter
-	logger     log.Logger
+	service          dashboardsnapshots.Service
+	dashboardService dashboards.DashboardService
+	queryService     query.Service
+	namespacer       request.NamespaceMapper
+	options          sharingOptionsGetter
+	exporter         *dashExporter
+	logger           log.Logger
 }
 
 func NewSnapshotsAPIBuilder(
 	p dashboardsnapshots.Service,
+	dashboardService dashboards.DashboardService,
+	queryService query.Service,
 	cfg *setting.Cfg,
 	exporter *dashExporter,
 ) *SnapshotsAPIBuilder {
 	return &SnapshotsAPIBuilder{
-		service:    p,
-		options:    newSharingOptionsGetter(cfg),
-		namespacer: request.GetNamespaceMapper(cfg),
-		exporter:   exporter,
-		logger:     log.New("snapshots::RawHandlers"),
+		service:          p,
+		dashboardService: dashboardService,
+		queryService:     queryService,
+		options:          newSharingOptionsGetter(cfg),
+		namespacer:       request.GetNamespaceMapper(cfg),
+		exporter:         exporter,
+		logger:           log.New("snapshots::RawHandlers"),
 	}
 }
 
 func RegisterAPIService(
 	service dashboardsnapshots.Service,
+	dashboardService dashboards.DashboardService,
+	queryService query.Service,
 	apiregistration builder.APIRegistrar,
 	cfg *setting.Cfg,
 	features featuremgmt.FeatureToggles,
@@ -74,7 +84,7 @@ func RegisterAPIService(
 	if !features.IsEnabledGlobally(featuremgmt.FlagGrafanaAPIServerWithExperimentalAPIs) {
 		return nil // skip registration unless opting into experimental apis
 	}
-	builder := NewSnapshotsAPIBuilder(service, cfg, &dashExporter{
+	builder := NewSnapshotsAPIBuilder(service, dashboardService, queryService, cfg, &dashExporter{
 		service: service,
 		sql:     sql,
 	})
@@ -177,6 +187,21 @@ func (b *SnapshotsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 	createCmd := defs["github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1.DashboardCreateCommand"].Schema
 	createExample := `{"dashboard":{"annotations":{"list":[{"name":"Annotations & Alerts","enable":true,"iconColor":"rgba(0, 211, 255, 1)","snapshotData":[],"type":"dashboard","builtIn":1,"hide":true}]},"editable":true,"fiscalYearStartMonth":0,"graphTooltip":0,"id":203,"links":[],"liveNow":false,"panels":[{"datasource":null,"fieldConfig":{"defaults":{"color":{"mode":"palette-classic"},"custom":{"axisBorderShow":false,"axisCenteredZero":false,"axisColorMode":"text","axisLabel":"","axisPlacement":"auto","barAlignment":0,"drawStyle":"line","fillOpacity":43,"gradientMode":"opacity","hideFrom":{"legend":false,"tooltip":false,"viz":false},"insertNulls":false,"lineInterpolation":"smooth","lineWidth":1,"pointSize":5,"scaleDistribution":{"type":"linear"},"showPoints":"auto","spanNulls":false,"stacking":{"group":"A","mode":"none"},"thresholdsStyle":{"mode":"off"}},"mappings":[],"thresholds":{"mode":"absolute","steps":[{"color":"green","value":null},{"color":"red","value":80}]},"unitScale":true},"overrides":[]},"gridPos":{"h":8,"w":12,"x":0,"y":0},"id":1,"options":{"legend":{"calcs":[],"displayMode":"list","placement":"bottom","showLegend":true},"tooltip":{"mode":"single","sort":"none"}},"pluginVersion":"10.4.0-pre","snapshotData":[{"fields":[{"config":{"color":{"mode":"palette-classic"},"custom":{"axisBorderShow":false,"axisCenteredZero":false,"axisColorMode":"text","axisPlacement":"auto","barAlignment":0,"drawStyle":"line","fillOpacity":43,"gradientMode":"opacity","hideFrom":{"legend":false,"tooltip":false,"viz":false},"lineInterpolation":"smooth","lineWidth":1,"pointSize":5,"showPoints":"auto","thresholdsStyle":{"mode":"off"}},"thresholds":{"mode":"absolute","steps":[{"color":"green","value":null},{"color":"red","value":80}]},"unitScale":true},"name":"time","type":"time","values":[1706030536378,1706034856378,1706039176378,1706043496378,1706047816378,1706052136378]},{"config":{"color":{"mode":"palette-classic"},"custom":{"axisBorderShow":false,"axisCenteredZero":false,"axisColorMode":"text","axisLabel":"","axisPlacement":"auto","barAlignment":0,"drawStyle":"line","fillOpacity":43,"gradientMode":"opacity","hideFrom":{"legend":false,"tooltip":false,"viz":false},"insertNulls":false,"lineInterpolation":"smooth","lineWidth":1,"pointSize":5,"scaleDistribution":{"type":"linear"},"showPoints":"auto","spanNulls":false,"stacking":{"group":"A","mode":"none"},"thresholdsStyle":{"mode":"off"}},"mappings":[],"thresholds":{"mode":"absolute","steps":[{"color":"green","value":null},{"color":"red","value":80}]},"unitScale":true},"name":"A-series","type":"number","values":[1,20,90,30,50,0]}],"refId":"A"}],"targets":[],"title":"Simple example","type":"timeseries","links":[]}],"refresh":"","schemaVersion":39,"snapshot":{"timestamp":"2024-01-23T23:22:16.377Z"},"tags":[],"templating":{"list":[]},"time":{"from":"2024-01-23T17:22:20.380Z","to":"2024-01-23T23:22:20.380Z","raw":{"from":"now-6h","to":"now"}},"timepicker":{},"timezone":"","title":"simple and small","uid":"b22ec8db-399b-403b-b6c7-b0fb30ccb2a5","version":1,"weekStart":""},"name":"simple and small","expires":86400}`
 	createRsp := defs["github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1.DashboardCreateResponse"].Schema
+	serverSideCmd := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:     []string{"object"},
+			Required: []string{"dashboardUid"},
+			Properties: map[string]spec.Schema{
+				"dashboardUid": *spec.StringProperty().WithDescription("The dashboard to snapshot"),
+				"name":         *spec.StringProperty().WithDescription("Snapshot name, defaults to the dashboard title"),
+				"from":         *spec.StringProperty().WithDescription("Start of the time range, defaults to the dashboard time range"),
+				"to":           *spec.StringProperty().WithDescription("End of the time range, defaults to the dashboard time range"),
+				"variables":    *spec.MapProperty(spec.ArrayProperty(spec.StringProperty())).WithDescription("Values of the template variables"),
+				"expires":      *spec.Int64Property().WithDescription("When the snapshot should expire in seconds"),
+			},
+		},
+	}
+	serverSideExample := `{"dashboardUid":"b22ec8db-399b-403b-b6c7-b0fb30ccb2a5","from":"now-1h","to":"now","variables":{"instance":["host-1","host-2"]},"expires":86400}`
 
 	tags := []string{dashboardsnapshot.DashboardSnapshotResourceInfo.GroupVersionKind().Kind}
 	routes := &builder.APIRoutes{
@@ -244,33 +269,96 @@ func (b *SnapshotsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 					},
 				},
 				Handler: func(w http.ResponseWriter, r *http.Request) {
-					user, err := appcontext.User(r.Context())
-					if err != nil {
-						errhttp.Write(r.Context(), err, w)
+					wrap, info, ok := b.wrapNamespacedRequest(w, r)
+					if !ok {
 						return
 					}
-					wrap := &contextmodel.ReqContext{
-						Logger: b.logger,
-						Context: &web.Context{
-							Req:  r,
-							Resp: web.NewResponseWriter(r.Method, w),
-						},
-						SignedInUser: user,
+
+					cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{}
+					if err := web.Bind(wrap.Req, &cmd); err != nil {
+						wrap.JsonApiErr(http.StatusBadRequest, "bad request data", err)
+						return
 					}
 
-					vars := mux.Vars(r)
-					info, err := request.ParseNamespace(vars["namespace"])
+					opts, err := b.options(info.Value)
 					if err != nil {
-						wrap.JsonApiErr(http.StatusBadRequest, "expected namespace", nil)
+						wrap.JsonApiErr(http.StatusBadRequest, "error getting options", err)
 						return
 					}
-					if info.OrgID != user.OrgID {
-						wrap.JsonApiErr(http.StatusBadRequest,
-							fmt.Sprintf("user orgId does not match namespace (%d != %d)", info.OrgID, user.OrgID), nil)
+
+					// Use the existing snapshot service
+					dashboardsnapshots.CreateDashboardSnapshot(wrap, opts.Spec, cmd, b.service)
+				},
+			},
+			{
+				Path: prefix + "/create-server-side",
+				Spec: &spec3.PathProps{
+					Post: &spec3.Operation{
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: map[string]any{
+								"x-grafana-action": "create",
+								"x-kubernetes-group-version-kind": metav1.GroupVersionKind{
+									Group:   dashboardsnapshot.GROUP,
+									Version: dashboardsnapshot.VERSION,
+									Kind:    "DashboardCreateResponse",
+								},
+							},
+						},
+						OperationProps: spec3.OperationProps{
+							Tags:        tags,
+							Summary:     "Server-side snapshot",
+							Description: "Snapshot a saved dashboard, executing the queries of its panels on the server",
+							Parameters: []*spec3.Parameter{
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "namespace",
+										In:          "path",
+										Required:    true,
+										Example:     "default",
+										Description: "workspace",
+										Schema:      spec.StringProperty(),
+									},
+								},
+							},
+							RequestBody: &spec3.RequestBody{
+								RequestBodyProps: spec3.RequestBodyProps{
+									Content: map[string]*spec3.MediaType{
+										"application/json": {
+											MediaTypeProps: spec3.MediaTypeProps{
+												Schema:  &serverSideCmd,
+												Example: serverSideExample, // raw JSON body
+											},
+										},
+									},
+								},
+							},
+							Responses: &spec3.Responses{
+								ResponsesProps: spec3.ResponsesProps{
+									StatusCodeResponses: map[int]*spec3.Response{
+										200: {
+											ResponseProps: spec3.ResponseProps{
+												Content: map[string]*spec3.MediaType{
+													"application/json": {
+														MediaTypeProps: spec3.MediaTypeProps{
+															Schema: &createRsp,
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				Handler: func(w http.ResponseWriter, r *http.Request) {
+					wrap, info, ok := b.wrapNamespacedRequest(w, r)
+					if !ok {
 						return
 					}
 
-					cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{}
+					cmd := dashboardsnapshots.CreateServerSideSnapshotCommand{}
 					if err := web.Bind(wrap.Req, &cmd); err != nil {
 						wrap.JsonApiErr(http.StatusBadRequest, "bad request data", err)
 						return
@@ -282,8 +370,7 @@ func (b *SnapshotsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 						return
 					}
 
-					// Use the existing snapshot service
-					dashboardsnapshots.CreateDashboardSnapshot(wrap, opts.Spec, cmd, b.service)
+					dashboardsnapshots.CreateServerSideDashboardSnapshot(wrap, opts.Spec, cmd, b.service, b.dashboardService, b.queryService)
 				},
 			},
 			{
@@ -333,6 +420,37 @@ func (b *SnapshotsAPIBuilder) GetAPIRoutes() *builder.APIRoutes {
 	return routes
 }
 
+// wrapNamespacedRequest wraps a request to a namespaced route so it can be served by the legacy handlers.
+// It writes an error and returns false if the namespace does not match the org of the user.
+func (b *SnapshotsAPIBuilder) wrapNamespacedRequest(w http.ResponseWriter, r *http.Request) (*contextmodel.ReqContext, request.NamespaceInfo, bool) {
+	user, err := appcontext.User(r.Context())
+	if err != nil {
+		errhttp.Write(r.Context(), err, w)
+		return nil, request.NamespaceInfo{}, false
+	}
+	wrap := &contextmodel.ReqContext{
+		Logger: b.logger,
+		Context: &web.Context{
+			Req:  r,
+			Resp: web.NewResponseWriter(r.Method, w),
+		},
+		SignedInUser: user,
+	}
+
+	vars := mux.Vars(r)
+	info, err := request.ParseNamespace(vars["namespace"])
+	if err != nil {
+		wrap.JsonApiErr(http.StatusBadRequest, "expected namespace", nil)
+		return nil, info, false
+	}
+	if info.OrgID != user.OrgID {
+		wrap.JsonApiErr(http.StatusBadRequest,
+			fmt.Sprintf("user orgId does not match namespace (%d != %d)", info.OrgID, user.OrgID), nil)
+		return nil, info, false
+	}
+	return wrap, info, true
+}
+
 func (b *SnapshotsAPIBuilder) GetAuthorizer() authorizer.Authorizer {
 	// TODO: this behavior must match the existing logic (it is currently more restrictive)
 	//
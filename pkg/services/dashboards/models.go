@@ -194,13 +194,16 @@ type ValidateDashboardBeforeSaveResult struct {
 //
 
 type SaveDashboardCommand struct {
-	Dashboard    *simplejson.Json `json:"dashboard" binding:"Required"`
-	UserID       int64            `json:"userId" xorm:"user_id"`
-	Overwrite    bool             `json:"overwrite"`
-	Message      string           `json:"message"`
-	OrgID        int64            `json:"-" xorm:"org_id"`
-	RestoredFrom int              `json:"-"`
-	PluginID     string           `json:"-" xorm:"plugin_id"`
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+	UserID    int64            `json:"userId" xorm:"user_id"`
+	Overwrite bool             `json:"overwrite"`
+	// Merge is whether to merge the changes into the stored dashboard when someone else saved it in between,
+	// instead of failing with a version mismatch.
+	Merge        bool   `json:"merge"`
+	Message      string `json:"message"`
+	OrgID        int64  `json:"-" xorm:"org_id"`
+	RestoredFrom int    `json:"-"`
+	PluginID     string `json:"-" xorm:"plugin_id"`
 	// Deprecated: use FolderUID instead
 	FolderID  int64  `json:"folderId" xorm:"folder_id"`
 	FolderUID string `json:"folderUid" xorm:"folder_uid"`
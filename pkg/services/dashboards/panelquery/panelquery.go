@@ -0,0 +1,376 @@
+// Package panelquery executes the queries of the panels of a stored dashboard on the backend, the way the frontend
+// would for a time range and template variables. It is shared by the features that need panel data without a
+// browser, like server-side dashboard snapshots and the native renderer.
+package panelquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+const (
+	// defaultMaxDataPoints is the maximum number of data points of the queries of panels
+	// that do not define it. The browser derives it from the width of the panel.
+	defaultMaxDataPoints = 1000
+
+	mixedDatasourceUID     = "-- Mixed --"
+	dashboardDatasourceUID = "-- Dashboard --"
+	allVariableValue       = "$__all"
+)
+
+var ErrPanelNotFound = errutil.NotFound("panelquery.panel-not-found", errutil.WithPublicMessage("Panel not found"))
+
+// variableRegex matches the $var, ${var}, ${var:format} and [[var]] variable syntaxes of the frontend.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+)\]\]|\$\{(\w+)(?::(\w+))?\}`)
+
+// Querier executes the queries of the panels of a dashboard.
+type Querier struct {
+	user    identity.Requester
+	service query.Service
+	vars    map[string][]string
+
+	// From and To are the time range of the queries, and RawFrom and RawTo the time range they were parsed from.
+	From, To       time.Time
+	RawFrom, RawTo string
+}
+
+// New returns a copy of the dashboard model, and a querier for the queries of its panels. The time range defaults
+// to the time range of the dashboard, and the variables to their current values, which are updated in the copy
+// for the overridden ones.
+func New(user identity.Requester, dashboard *simplejson.Json, from, to string, overrides map[string][]string, queryService query.Service, now time.Time) (map[string]any, *Querier, error) {
+	raw, err := dashboard.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	var model map[string]any
+	if err := json.Unmarshal(raw, &model); err != nil {
+		return nil, nil, err
+	}
+
+	if from == "" {
+		from = dashboard.GetPath("time", "from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dashboard.GetPath("time", "to").MustString("now")
+	}
+	tr := legacydata.DataTimeRange{From: from, To: to, Now: now}
+	fromTime, err := tr.ParseFrom()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid from: %w", err)
+	}
+	toTime, err := tr.ParseTo()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid to: %w", err)
+	}
+	if !fromTime.Before(toTime) {
+		return nil, nil, errors.New("from must be before to")
+	}
+
+	variables := dashboardVariables(model, overrides)
+	variables["__from"] = []string{strconv.FormatInt(fromTime.UnixMilli(), 10)}
+	variables["__to"] = []string{strconv.FormatInt(toTime.UnixMilli(), 10)}
+
+	return model, &Querier{
+		user:    user,
+		service: queryService,
+		vars:    variables,
+		From:    fromTime,
+		To:      toTime,
+		RawFrom: from,
+		RawTo:   to,
+	}, nil
+}
+
+// QueryPanel executes the queries of the panel in a single request, so that they can be used by its expressions.
+// It returns the refIDs of the queries that were executed, and a nil response if the panel has nothing to query.
+func (q *Querier) QueryPanel(ctx context.Context, panel map[string]any) ([]string, *backend.QueryDataResponse, error) {
+	targets, _ := panel["targets"].([]any)
+	if len(targets) == 0 {
+		return nil, nil, nil
+	}
+	panelDS := interpolateVariables(panel["datasource"], q.vars)
+	if datasourceUID(panelDS) == dashboardDatasourceUID {
+		// The panel reuses the results of another panel, which are not available on the backend.
+		return nil, nil, nil
+	}
+
+	maxDataPoints := int64(defaultMaxDataPoints)
+	if v, ok := panel["maxDataPoints"].(float64); ok && v > 0 {
+		maxDataPoints = int64(v)
+	}
+	interval := q.To.Sub(q.From) / time.Duration(maxDataPoints)
+	if s, ok := interpolateVariables(panel["interval"], q.vars).(string); ok && s != "" {
+		if minInterval, err := gtime.ParseInterval(strings.TrimPrefix(s, ">")); err == nil && minInterval > interval {
+			interval = minInterval
+		}
+	}
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+
+	queries := make([]*simplejson.Json, 0, len(targets))
+	refIDs := make([]string, 0, len(targets))
+	for _, t := range targets {
+		target, ok := interpolateVariables(t, q.vars).(map[string]any)
+		if !ok {
+			continue
+		}
+		if hide, _ := target["hide"].(bool); hide {
+			continue
+		}
+		if datasourceUID(target["datasource"]) == "" {
+			if datasourceUID(panelDS) == mixedDatasourceUID {
+				continue
+			}
+			target["datasource"] = panelDS
+		}
+		target["maxDataPoints"] = maxDataPoints
+		target["intervalMs"] = interval.Milliseconds()
+		queries = append(queries, simplejson.NewFromAny(target))
+		refID, _ := target["refId"].(string)
+		refIDs = append(refIDs, refID)
+	}
+	if len(queries) == 0 {
+		return nil, nil, nil
+	}
+
+	resp, err := q.service.QueryData(ctx, q.user, false, dtos.MetricRequest{
+		From:    strconv.FormatInt(q.From.UnixMilli(), 10),
+		To:      strconv.FormatInt(q.To.UnixMilli(), 10),
+		Queries: queries,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return refIDs, resp, nil
+}
+
+// Result is the result of the queries of a single panel of a dashboard.
+type Result struct {
+	// Panel is the model of the panel.
+	Panel map[string]any
+	// From and To are the time range of the queries.
+	From, To time.Time
+	// Frames are the frames of all queries of the panel, in the order of the queries.
+	Frames data.Frames
+	// Errors are the errors of the queries of the panel that failed.
+	Errors []error
+}
+
+// Query executes the queries of a single panel of the dashboard for the time range and variables.
+func Query(ctx context.Context, user identity.Requester, dashboard *simplejson.Json, panelID int64, from, to string, variables map[string][]string, queryService query.Service, now time.Time) (*Result, error) {
+	model, q, err := New(user, dashboard, from, to, variables, queryService, now)
+	if err != nil {
+		return nil, err
+	}
+	panels, _ := model["panels"].([]any)
+	panel := FindPanel(panels, panelID)
+	if panel == nil {
+		return nil, ErrPanelNotFound.Errorf("panel %d not found in dashboard", panelID)
+	}
+
+	refIDs, resp, err := q.QueryPanel(ctx, panel)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Panel: panel, From: q.From, To: q.To}
+	if resp == nil {
+		return result, nil
+	}
+	for _, refID := range refIDs {
+		res, ok := resp.Responses[refID]
+		if !ok {
+			continue
+		}
+		if res.Error != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("query %s: %w", refID, res.Error))
+			continue
+		}
+		result.Frames = append(result.Frames, res.Frames...)
+	}
+	return result, nil
+}
+
+// FindPanel returns the panel with the ID, including the panels of collapsed rows.
+func FindPanel(panels []any, id int64) map[string]any {
+	for _, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if panelID, ok := panel["id"].(float64); ok && int64(panelID) == id {
+			return panel
+		}
+		if nested, ok := panel["panels"].([]any); ok {
+			if found := FindPanel(nested, id); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// dashboardVariables returns the values of the template variables of the dashboard, overridden by the given ones.
+// The current values of the overridden variables are updated in the dashboard model.
+func dashboardVariables(model map[string]any, overrides map[string][]string) map[string][]string {
+	variables := make(map[string][]string)
+	templating, _ := model["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+	for _, item := range list {
+		v, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := v["name"].(string)
+		if name == "" {
+			continue
+		}
+		if values, ok := overrides[name]; ok {
+			v["current"] = map[string]any{"text": values, "value": values}
+			variables[name] = values
+			continue
+		}
+		current, _ := v["current"].(map[string]any)
+		values := toStrings(current["value"])
+		if len(values) == 1 && values[0] == allVariableValue {
+			values = allVariableValues(v)
+		}
+		variables[name] = values
+	}
+	for name, values := range overrides {
+		if _, ok := variables[name]; !ok {
+			variables[name] = values
+		}
+	}
+	return variables
+}
+
+// allVariableValues returns the values of the "All" option of a variable.
+func allVariableValues(v map[string]any) []string {
+	if allValue, ok := v["allValue"].(string); ok && allValue != "" {
+		return []string{allValue}
+	}
+	options, _ := v["options"].([]any)
+	values := make([]string, 0, len(options))
+	for _, o := range options {
+		option, ok := o.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, value := range toStrings(option["value"]) {
+			if value != allVariableValue {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, s := range v {
+			values = append(values, fmt.Sprint(s))
+		}
+		return values
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// interpolateVariables replaces the variables in all strings of the value, which must be JSON compatible.
+// Unknown variables, like the $__interval macros handled by the data sources, are left as is.
+func interpolateVariables(v any, variables map[string][]string) any {
+	switch v := v.(type) {
+	case string:
+		return interpolateString(v, variables)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, val := range v {
+			result[k] = interpolateVariables(val, variables)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, val := range v {
+			result[i] = interpolateVariables(val, variables)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func interpolateString(s string, variables map[string][]string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name := groups[1] + groups[2] + groups[3]
+		values, ok := variables[name]
+		if !ok {
+			return match
+		}
+		return formatVariable(values, groups[4])
+	})
+}
+
+// formatVariable formats the values of a variable like the variable formats of the frontend.
+// Multiple values default to the glob format.
+func formatVariable(values []string, format string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if len(values) == 1 && format != "regex" {
+		return values[0]
+	}
+	switch format {
+	case "csv", "raw":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, 0, len(values))
+		for _, v := range values {
+			escaped = append(escaped, regexp.QuoteMeta(v))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	default:
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+func datasourceUID(ds any) string {
+	switch ds := ds.(type) {
+	case string:
+		return ds
+	case map[string]any:
+		uid, _ := ds["uid"].(string)
+		return uid
+	default:
+		return ""
+	}
+}
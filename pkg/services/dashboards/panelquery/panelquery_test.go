@@ -0,0 +1,103 @@
+package panelquery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestQuery(t *testing.T) {
+	now := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"uid": "dash",
+		"time": {"from": "now-6h", "to": "now"},
+		"templating": {"list": [{"name": "job", "current": {"value": "api"}}]},
+		"panels": [
+			{"id": 1, "title": "Text", "type": "text"},
+			{
+				"type": "row", "title": "Collapsed", "collapsed": true,
+				"panels": [
+					{
+						"id": 2, "title": "Requests", "type": "timeseries",
+						"datasource": {"type": "prometheus", "uid": "prom"},
+						"targets": [
+							{"refId": "A", "expr": "up{job=\"$job\"}"},
+							{"refId": "B", "expr": "broken"}
+						]
+					}
+				]
+			}
+		]
+	}`))
+	require.NoError(t, err)
+
+	frame := data.NewFrame("up", data.NewField("value", nil, []float64{1}))
+	var requests []dtos.MetricRequest
+	qs := query.NewFakeQueryService(t)
+	qs.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Run(func(args mock.Arguments) {
+		requests = append(requests, args.Get(3).(dtos.MetricRequest))
+	}).Return(func(_ context.Context, _ identity.Requester, _ bool, req dtos.MetricRequest) *backend.QueryDataResponse {
+		resp := backend.NewQueryDataResponse()
+		resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{frame}}
+		resp.Responses["B"] = backend.DataResponse{Error: errors.New("parse error")}
+		return resp
+	}, nil).Maybe()
+
+	t.Run("queries the panel of a collapsed row", func(t *testing.T) {
+		result, err := Query(context.Background(), &user.SignedInUser{OrgID: 1}, dashboard, 2, "now-1h", "", map[string][]string{"job": {"web"}}, qs, now)
+		require.NoError(t, err)
+		require.Equal(t, "Requests", result.Panel["title"])
+		require.Equal(t, now.Add(-time.Hour), result.From)
+		require.Equal(t, now, result.To)
+		require.Equal(t, data.Frames{frame}, result.Frames)
+		require.Len(t, result.Errors, 1)
+		require.EqualError(t, result.Errors[0], "query B: parse error")
+
+		require.Len(t, requests, 1)
+		require.Equal(t, `up{job="web"}`, requests[0].Queries[0].Get("expr").MustString())
+		require.Equal(t, "prom", requests[0].Queries[0].GetPath("datasource", "uid").MustString())
+		require.EqualValues(t, defaultMaxDataPoints, requests[0].Queries[0].Get("maxDataPoints").MustInt64())
+	})
+
+	t.Run("panels without queries return no frames", func(t *testing.T) {
+		result, err := Query(context.Background(), &user.SignedInUser{OrgID: 1}, dashboard, 1, "", "", nil, qs, now)
+		require.NoError(t, err)
+		require.Empty(t, result.Frames)
+		require.Equal(t, now.Add(-6*time.Hour), result.From)
+	})
+
+	t.Run("unknown panel", func(t *testing.T) {
+		_, err := Query(context.Background(), &user.SignedInUser{OrgID: 1}, dashboard, 3, "", "", nil, qs, now)
+		require.ErrorIs(t, err, ErrPanelNotFound)
+	})
+}
+
+func TestFormatVariable(t *testing.T) {
+	testCases := []struct {
+		values   []string
+		format   string
+		expected string
+	}{
+		{values: []string{"a"}, expected: "a"},
+		{values: []string{"a", "b"}, expected: "{a,b}"},
+		{values: []string{"a", "b"}, format: "csv", expected: "a,b"},
+		{values: []string{"a", "b"}, format: "pipe", expected: "a|b"},
+		{values: []string{"a.b"}, format: "regex", expected: `a\.b`},
+		{values: nil, expected: ""},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, formatVariable(tc.values, tc.format))
+	}
+}
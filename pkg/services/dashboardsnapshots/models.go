@@ -97,3 +97,31 @@ type CreateExternalSnapshotResponse struct {
 	Url       string `json:"url"`
 	DeleteUrl string `json:"deleteUrl"`
 }
+
+// swagger:model
+type CreateServerSideSnapshotCommand struct {
+	// The UID of the dashboard to snapshot.
+	// required:true
+	DashboardUID string `json:"dashboardUid" binding:"Required"`
+
+	// Snapshot name. Defaults to the title of the dashboard.
+	// required:false
+	Name string `json:"name"`
+
+	// The start of the time range of the queries, e.g. `now-6h` or an epoch in milliseconds. Defaults to the time range of the dashboard.
+	// required:false
+	From string `json:"from"`
+
+	// The end of the time range of the queries. Defaults to the time range of the dashboard.
+	// required:false
+	To string `json:"to"`
+
+	// The values of the template variables, by variable name. Variables that are not set keep their current value in the dashboard.
+	// required:false
+	Variables map[string][]string `json:"variables"`
+
+	// When the snapshot should expire in seconds. Default is never to expire.
+	// required:false
+	// default:0
+	Expires int64 `json:"expires"`
+}
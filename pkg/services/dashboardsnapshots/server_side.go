@@ -0,0 +1,237 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/panelquery"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+// CreateServerSideDashboardSnapshot creates a snapshot of a stored dashboard. Unlike CreateDashboardSnapshot,
+// the queries of the panels are executed on the backend, so snapshots can be taken without a browser.
+func CreateServerSideDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.SnapshotSharingOptions, cmd CreateServerSideSnapshotCommand, svc Service, dashboardService dashboards.DashboardService, queryService query.Service) {
+	if !cfg.SnapshotsEnabled {
+		c.JsonApiErr(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+		return
+	}
+
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	g, err := guardian.NewByUID(ctx, cmd.DashboardUID, orgID, c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			c.JsonApiErr(http.StatusNotFound, "Dashboard not found", err)
+			return
+		}
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to get dashboard", err)
+		return
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		c.JsonApiErr(http.StatusForbidden, "forbidden", err)
+		return
+	}
+
+	dash, err := dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			c.JsonApiErr(http.StatusNotFound, "Dashboard not found", err)
+			return
+		}
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to get dashboard", err)
+		return
+	}
+
+	model, err := BuildServerSideSnapshot(ctx, c.SignedInUser, dash.Data, cmd, queryService, time.Now())
+	if err != nil {
+		c.JsonApiErr(http.StatusBadRequest, "Failed to query the dashboard", err)
+		return
+	}
+
+	name := cmd.Name
+	if name == "" {
+		name = dash.Title
+	}
+	CreateDashboardSnapshot(c, cfg, CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Name:      name,
+			Dashboard: &common.Unstructured{Object: model},
+			Expires:   cmd.Expires,
+		},
+	}, svc)
+}
+
+// BuildServerSideSnapshot returns a copy of the dashboard model where the queries of every panel were executed
+// for the time range and variables of the command, and their results embedded as the snapshot data of the panel.
+// The queries of a panel are executed in a single request, so that they can be used by its expressions.
+func BuildServerSideSnapshot(ctx context.Context, user identity.Requester, dashboard *simplejson.Json, cmd CreateServerSideSnapshotCommand, queryService query.Service, now time.Time) (map[string]any, error) {
+	model, querier, err := panelquery.New(user, dashboard, cmd.From, cmd.To, cmd.Variables, queryService, now)
+	if err != nil {
+		return nil, err
+	}
+	q := &snapshotQuerier{Querier: querier}
+	if panels, ok := model["panels"].([]any); ok {
+		if err := q.snapshotPanels(ctx, panels); err != nil {
+			return nil, err
+		}
+	}
+
+	model["time"] = map[string]any{
+		"from": q.From.UTC().Format(time.RFC3339Nano),
+		"to":   q.To.UTC().Format(time.RFC3339Nano),
+		"raw":  map[string]any{"from": q.RawFrom, "to": q.RawTo},
+	}
+	model["snapshot"] = map[string]any{"timestamp": now.UTC().Format(time.RFC3339Nano)}
+	return model, nil
+}
+
+// snapshotQuerier executes the queries of the panels of a dashboard, and embeds their results in the panels.
+type snapshotQuerier struct {
+	*panelquery.Querier
+}
+
+func (q *snapshotQuerier) snapshotPanels(ctx context.Context, panels []any) error {
+	for _, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		// Collapsed rows hold their panels.
+		if nested, ok := panel["panels"].([]any); ok {
+			if err := q.snapshotPanels(ctx, nested); err != nil {
+				return err
+			}
+		}
+		if err := q.snapshotPanel(ctx, panel); err != nil {
+			title, _ := panel["title"].(string)
+			return fmt.Errorf("panel %q: %w", title, err)
+		}
+	}
+	return nil
+}
+
+// snapshotPanel executes the queries of the panel, and sets their results as the snapshot data of the panel.
+func (q *snapshotQuerier) snapshotPanel(ctx context.Context, panel map[string]any) error {
+	refIDs, resp, err := q.QueryPanel(ctx, panel)
+	if err != nil || resp == nil {
+		return err
+	}
+
+	snapshotData := make([]any, 0, len(refIDs))
+	for _, refID := range refIDs {
+		res, ok := resp.Responses[refID]
+		if !ok {
+			continue
+		}
+		snapshotData = append(snapshotData, snapshotDataFromResponse(refID, res)...)
+	}
+	panel["snapshotData"] = snapshotData
+	return nil
+}
+
+// snapshotDataFromResponse converts the response of a query to the data frames the frontend expects as
+// the snapshot data of a panel. Errors are kept as error notices of an empty frame, so they are shown in the panel.
+func snapshotDataFromResponse(refID string, res backend.DataResponse) []any {
+	if res.Error != nil {
+		return []any{map[string]any{
+			"refId":  refID,
+			"fields": []any{},
+			"meta": map[string]any{
+				"notices": []any{map[string]any{"severity": "error", "text": res.Error.Error()}},
+			},
+		}}
+	}
+	frames := make([]any, 0, len(res.Frames))
+	for _, frame := range res.Frames {
+		frames = append(frames, snapshotFrame(refID, frame))
+	}
+	return frames
+}
+
+// snapshotFrame converts a data frame to the DataFrameDTO format of the frontend, with the values inlined in fields.
+func snapshotFrame(refID string, frame *data.Frame) map[string]any {
+	fields := make([]any, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		f := map[string]any{
+			"name":   field.Name,
+			"type":   snapshotFieldType(field.Type()),
+			"values": snapshotFieldValues(field),
+		}
+		if field.Config != nil {
+			f["config"] = field.Config
+		} else {
+			f["config"] = map[string]any{}
+		}
+		if len(field.Labels) > 0 {
+			f["labels"] = field.Labels
+		}
+		fields = append(fields, f)
+	}
+	result := map[string]any{
+		"refId":  refID,
+		"fields": fields,
+	}
+	if frame.Name != "" {
+		result["name"] = frame.Name
+	}
+	if frame.Meta != nil {
+		result["meta"] = frame.Meta
+	}
+	return result
+}
+
+func snapshotFieldType(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "time"
+	case t.Numeric():
+		return "number"
+	case t == data.FieldTypeString || t == data.FieldTypeNullableString:
+		return "string"
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "boolean"
+	default:
+		return "other"
+	}
+}
+
+// snapshotFieldValues returns the values of the field. Times are converted to epochs in milliseconds,
+// and values that cannot be encoded in JSON, like NaN, to null.
+func snapshotFieldValues(field *data.Field) []any {
+	values := make([]any, field.Len())
+	for i := range values {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case time.Time:
+			values[i] = v.UnixMilli()
+		case float64:
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				values[i] = v
+			}
+		case float32:
+			if !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0) {
+				values[i] = v
+			}
+		default:
+			values[i] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,159 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestBuildServerSideSnapshot(t *testing.T) {
+	now := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"uid": "dash",
+		"time": {"from": "now-6h", "to": "now"},
+		"templating": {"list": [
+			{"name": "job", "current": {"value": "api"}},
+			{"name": "instance", "current": {"value": "$__all"}, "options": [
+				{"value": "$__all"}, {"value": "host-1"}, {"value": "host-2"}
+			]}
+		]},
+		"panels": [
+			{
+				"id": 1, "title": "Requests", "type": "timeseries",
+				"datasource": {"type": "prometheus", "uid": "prom"},
+				"maxDataPoints": 100,
+				"targets": [
+					{"refId": "A", "expr": "up{job=\"$job\", instance=~\"${instance:regex}\"}"},
+					{"refId": "B", "expr": "hidden", "hide": true},
+					{"refId": "C", "datasource": {"type": "__expr__", "uid": "__expr__"}, "type": "math", "expression": "$A * 2"}
+				]
+			},
+			{
+				"type": "row", "title": "Collapsed", "collapsed": true,
+				"panels": [
+					{"id": 2, "title": "Nested", "datasource": {"uid": "loki"}, "targets": [{"refId": "A", "expr": "{job=\"$job\"}"}]}
+				]
+			},
+			{"id": 3, "title": "Text", "type": "text"},
+			{"id": 4, "title": "Reused", "datasource": {"uid": "-- Dashboard --"}, "targets": [{"refId": "A", "panelId": 1}]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	frame := data.NewFrame("up",
+		data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+		data.NewField("value", data.Labels{"job": "api"}, []*float64{nil, pointer(math.NaN())}),
+	)
+
+	var requests []dtos.MetricRequest
+	qs := query.NewFakeQueryService(t)
+	qs.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Run(func(args mock.Arguments) {
+		requests = append(requests, args.Get(3).(dtos.MetricRequest))
+	}).Return(func(_ context.Context, _ identity.Requester, _ bool, req dtos.MetricRequest) *backend.QueryDataResponse {
+		resp := backend.NewQueryDataResponse()
+		for _, q := range req.Queries {
+			refID := q.Get("refId").MustString()
+			if q.GetPath("datasource", "uid").MustString() == "loki" {
+				resp.Responses[refID] = backend.DataResponse{Error: errors.New("loki is down")}
+				continue
+			}
+			resp.Responses[refID] = backend.DataResponse{Frames: data.Frames{frame}}
+		}
+		return resp
+	}, nil)
+
+	cmd := CreateServerSideSnapshotCommand{
+		DashboardUID: "dash",
+		From:         "now-1h",
+		Variables:    map[string][]string{"job": {"web"}},
+	}
+	model, err := BuildServerSideSnapshot(context.Background(), &user.SignedInUser{OrgID: 1}, dashboard, cmd, qs, now)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2, "panels without queries or reusing other panels should not be queried")
+	from, to := now.Add(-time.Hour).UnixMilli(), now.UnixMilli()
+
+	t.Run("queries of a panel are sent in one request with the variables interpolated", func(t *testing.T) {
+		req := requests[0]
+		require.Equal(t, "1706007600000", req.From)
+		require.Equal(t, "1706011200000", req.To)
+		require.Len(t, req.Queries, 2)
+		require.Equal(t, `up{job="web", instance=~"(host-1|host-2)"}`, req.Queries[0].Get("expr").MustString())
+		require.Equal(t, "prom", req.Queries[0].GetPath("datasource", "uid").MustString())
+		require.EqualValues(t, 100, req.Queries[0].Get("maxDataPoints").MustInt64())
+		require.EqualValues(t, (to-from)/100, req.Queries[0].Get("intervalMs").MustInt64())
+		require.Equal(t, "__expr__", req.Queries[1].GetPath("datasource", "uid").MustString())
+		require.Equal(t, "$A * 2", req.Queries[1].Get("expression").MustString(), "unknown variables should be left as is")
+	})
+
+	t.Run("panels of collapsed rows are queried", func(t *testing.T) {
+		require.Equal(t, `{job="web"}`, requests[1].Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("frames are embedded as snapshot data", func(t *testing.T) {
+		panels := model["panels"].([]any)
+		snapshotData := panels[0].(map[string]any)["snapshotData"].([]any)
+		require.Len(t, snapshotData, 2)
+		f := snapshotData[0].(map[string]any)
+		require.Equal(t, "A", f["refId"])
+		require.Equal(t, "up", f["name"])
+		fields := f["fields"].([]any)
+		require.Equal(t, "time", fields[0].(map[string]any)["type"])
+		require.Equal(t, []any{now.Add(-time.Minute).UnixMilli(), now.UnixMilli()}, fields[0].(map[string]any)["values"])
+		require.Equal(t, "number", fields[1].(map[string]any)["type"])
+		require.Equal(t, []any{nil, nil}, fields[1].(map[string]any)["values"])
+		require.Equal(t, data.Labels{"job": "api"}, fields[1].(map[string]any)["labels"])
+
+		_, ok := panels[2].(map[string]any)["snapshotData"]
+		require.False(t, ok)
+	})
+
+	t.Run("query errors are embedded as notices", func(t *testing.T) {
+		nested := model["panels"].([]any)[1].(map[string]any)["panels"].([]any)[0].(map[string]any)
+		snapshotData := nested["snapshotData"].([]any)
+		require.Len(t, snapshotData, 1)
+		notices := snapshotData[0].(map[string]any)["meta"].(map[string]any)["notices"].([]any)
+		require.Equal(t, "loki is down", notices[0].(map[string]any)["text"])
+	})
+
+	t.Run("time range, variables and snapshot timestamp are set", func(t *testing.T) {
+		require.Equal(t, "2024-01-23T11:00:00Z", model["time"].(map[string]any)["from"])
+		require.Equal(t, "2024-01-23T12:00:00Z", model["time"].(map[string]any)["to"])
+		require.Equal(t, "2024-01-23T12:00:00Z", model["snapshot"].(map[string]any)["timestamp"])
+		job := model["templating"].(map[string]any)["list"].([]any)[0].(map[string]any)
+		require.Equal(t, []string{"web"}, job["current"].(map[string]any)["value"])
+	})
+
+	t.Run("the dashboard is not modified", func(t *testing.T) {
+		_, ok := dashboard.Get("panels").GetIndex(0).CheckGet("snapshotData")
+		require.False(t, ok)
+	})
+}
+
+func TestBuildServerSideSnapshotInvalidTimeRange(t *testing.T) {
+	dashboard := simplejson.NewFromAny(map[string]any{"uid": "dash"})
+	qs := query.NewFakeQueryService(t)
+
+	_, err := BuildServerSideSnapshot(context.Background(), &user.SignedInUser{}, dashboard, CreateServerSideSnapshotCommand{From: "now", To: "now-1h"}, qs, time.Now())
+	require.ErrorContains(t, err, "from must be before to")
+
+	_, err = BuildServerSideSnapshot(context.Background(), &user.SignedInUser{}, dashboard, CreateServerSideSnapshotCommand{From: "yesterday"}, qs, time.Now())
+	require.ErrorContains(t, err, "invalid from")
+}
+
+func pointer[T any](v T) *T {
+	return &v
+}
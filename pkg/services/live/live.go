@@ -17,6 +17,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/gobwas/glob"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 	jsoniter "github.com/json-iterator/go"
 	"golang.org/x/sync/errgroup"
@@ -56,6 +57,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web"
@@ -138,7 +140,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	var managedStreamRunner *managedstream.Runner
 	var redisClient *redis.Client
 	if g.IsHA() && redisHealthy {
-		redisClient := redis.NewClient(&redis.Options{
+		redisClient = redis.NewClient(&redis.Options{
 			Addr:     g.Cfg.LiveHAEngineAddress,
 			Password: g.Cfg.LiveHAEnginePassword,
 		})
@@ -149,17 +151,32 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	var runnerOptions []managedstream.RunnerOption
+	historyConfig := managedstream.HistoryConfig{
+		MaxAge:    g.Cfg.LiveHistoryMaxAge,
+		MaxPoints: g.Cfg.LiveHistoryMaxPoints,
+	}
+	historyEnabled := historyConfig.MaxAge > 0 || historyConfig.MaxPoints > 0
+
 	if redisClient != nil {
+		if historyEnabled {
+			runnerOptions = append(runnerOptions, managedstream.WithFrameHistory(managedstream.NewRedisFrameHistory(redisClient, historyConfig)))
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			runnerOptions...,
 		)
 	} else {
+		if historyEnabled {
+			runnerOptions = append(runnerOptions, managedstream.WithFrameHistory(managedstream.NewMemoryFrameHistory(historyConfig)))
+		}
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			runnerOptions...,
 		)
 	}
 
@@ -169,6 +186,12 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
 	g.runStreamManager = runstream.NewManager(pipelinedChannelLocalPublisher, numLocalSubscribersGetter, g.contextGetter)
+	g.pipelineInputs = pipeline.NewInputManager(pipeline.InputProcessorFunc(func(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+		if g.Pipeline == nil {
+			return false, nil
+		}
+		return g.Pipeline.ProcessInput(ctx, orgID, channelID, body)
+	}), numLocalSubscribersGetter)
 
 	// Initialize the main features
 	dash := &features.DashboardHandler{
@@ -399,6 +422,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	pipelineInputs      *pipeline.InputManager
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -459,6 +483,18 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.pipelineInputs != nil {
+		eGroup.Go(func() error {
+			return g.pipelineInputs.Run(eCtx)
+		})
+	}
+
+	if g.Pipeline != nil {
+		eGroup.Go(func() error {
+			return g.Pipeline.Run(eCtx)
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -1035,6 +1071,44 @@ func (g *GrafanaLive) HandleListHTTP(c *contextmodel.ReqContext) response.Respon
 	return response.JSONStreaming(http.StatusOK, info)
 }
 
+// HandleHistoryHTTP returns buffered history of a managed stream channel.
+func (g *GrafanaLive) HandleHistoryHTTP(c *contextmodel.ReqContext) response.Response {
+	channel := web.Params(c.Req)["*"]
+	addr, err := live.ParseChannel(channel)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid channel ID", err)
+	}
+	if addr.Scope != live.ScopeStream {
+		return response.Error(http.StatusBadRequest, "History is only available for stream channels", nil)
+	}
+
+	var from, to time.Time
+	timeRange := legacydata.NewDataTimeRange(c.Query("from"), c.Query("to"))
+	if timeRange.From != "" {
+		if from, err = timeRange.ParseFrom(); err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid from", err)
+		}
+	}
+	if timeRange.To != "" {
+		if to, err = timeRange.ParseTo(); err != nil {
+			return response.Error(http.StatusBadRequest, "Invalid to", err)
+		}
+	}
+
+	frame, ok, err := g.ManagedStreamRunner.GetHistory(c.Req.Context(), c.SignedInUser.GetOrgID(), channel, from, to)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error getting channel history", err)
+	}
+	if !ok {
+		return response.JSON(http.StatusOK, util.DynMap{"data": nil})
+	}
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error encoding channel history", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"data": json.RawMessage(frameJSON)})
+}
+
 // HandleInfoHTTP special http response for
 func (g *GrafanaLive) HandleInfoHTTP(ctx *contextmodel.ReqContext) response.Response {
 	path := web.Params(ctx.Req)["*"]
@@ -1054,8 +1128,12 @@ func (g *GrafanaLive) HandleChannelRulesListHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to get channel rules", err)
 	}
+	rules := make([]pipeline.ChannelRuleDto, 0, len(result))
+	for _, r := range result {
+		rules = append(rules, pipeline.ChannelRuleToDto(r))
+	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rules": result,
+		"rules": rules,
 	})
 }
 
@@ -1129,6 +1207,7 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 		FrameStorage:         pipeline.NewFrameStorage(),
 		Storage:              storage,
 		ChannelHandlerGetter: g,
+		InputManager:         g.pipelineInputs,
 	}
 	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
 	pipe, err := pipeline.New(channelRuleGetter)
@@ -1170,7 +1249,7 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rule": rule,
+		"rule": pipeline.ChannelRuleToDto(rule),
 	})
 }
 
@@ -1193,7 +1272,7 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
 	}
 	return response.JSON(http.StatusOK, util.DynMap{
-		"rule": rule,
+		"rule": pipeline.ChannelRuleToDto(rule),
 	})
 }
 
@@ -0,0 +1,126 @@
+package managedstream
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameHistory keeps recent frames of channels, so that subscribers get the
+// recent window of a stream instead of the last frame only.
+type FrameHistory interface {
+	// Add adds a frame to the history of a channel in org.
+	Add(ctx context.Context, orgID int64, channel string, frame *data.Frame) error
+	// Get returns the history of a channel in org merged into one frame with rows in
+	// the time range. Zero from or to time means the range is not bounded.
+	Get(ctx context.Context, orgID int64, channel string, from, to time.Time) (*data.Frame, bool, error)
+}
+
+// HistoryConfig bounds the history kept per channel.
+type HistoryConfig struct {
+	// MaxAge is a maximum age of kept frames, not limited when zero.
+	MaxAge time.Duration
+	// MaxPoints is a maximum number of kept rows.
+	MaxPoints int
+}
+
+// maxHistoryPoints bounds history when only MaxAge is configured.
+const maxHistoryPoints = 100000
+
+func (c HistoryConfig) maxPoints() int {
+	if c.MaxPoints <= 0 {
+		return maxHistoryPoints
+	}
+	return c.MaxPoints
+}
+
+type historyRow struct {
+	frame *data.Frame
+	row   int
+}
+
+// historyFrame merges the frames having the schema of the last frame into one
+// frame. Rows outside the time range are skipped, and at most maxPoints latest
+// rows are kept. Frames without time field are not filtered by time.
+func historyFrame(frames []*data.Frame, from, to time.Time, maxPoints int) (*data.Frame, bool) {
+	if len(frames) == 0 {
+		return nil, false
+	}
+	last := frames[len(frames)-1]
+
+	var rows []historyRow
+	for _, frame := range frames {
+		if !sameSchema(frame, last) {
+			continue
+		}
+		timeField := -1
+		for i, f := range frame.Fields {
+			if f.Type().Time() {
+				timeField = i
+				break
+			}
+		}
+		rowLen, _ := frame.RowLen()
+		for row := 0; row < rowLen; row++ {
+			if timeField >= 0 && !inTimeRange(frame.Fields[timeField], row, from, to) {
+				continue
+			}
+			rows = append(rows, historyRow{frame: frame, row: row})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, false
+	}
+	if maxPoints > 0 && len(rows) > maxPoints {
+		rows = rows[len(rows)-maxPoints:]
+	}
+
+	fields := make([]*data.Field, len(last.Fields))
+	for i, f := range last.Fields {
+		fields[i] = data.NewFieldFromFieldType(f.Type(), len(rows))
+		fields[i].Name = f.Name
+		fields[i].Labels = f.Labels
+		fields[i].Config = f.Config
+	}
+	for j, r := range rows {
+		for i, f := range r.frame.Fields {
+			fields[i].Set(j, f.At(r.row))
+		}
+	}
+	result := data.NewFrame(last.Name, fields...)
+	result.Meta = last.Meta
+	return result, true
+}
+
+func sameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
+
+func inTimeRange(field *data.Field, row int, from, to time.Time) bool {
+	var t time.Time
+	switch v := field.At(row).(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return false
+		}
+		t = *v
+	}
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package managedstream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// historyEvictInterval is how often Add evicts expired history of all channels, so that channels
+// which stopped receiving frames do not keep their history until restart.
+const historyEvictInterval = time.Minute
+
+// MemoryFrameHistory keeps history of channels in memory of this Grafana instance.
+type MemoryFrameHistory struct {
+	mu          sync.Mutex
+	config      HistoryConfig
+	channels    map[int64]map[string][]historyEntry
+	now         func() time.Time
+	lastEvicted time.Time
+}
+
+type historyEntry struct {
+	added time.Time
+	frame *data.Frame
+	rows  int
+}
+
+// NewMemoryFrameHistory creates new MemoryFrameHistory.
+func NewMemoryFrameHistory(config HistoryConfig) *MemoryFrameHistory {
+	return &MemoryFrameHistory{
+		config:   config,
+		channels: map[int64]map[string][]historyEntry{},
+		now:      time.Now,
+	}
+}
+
+func (h *MemoryFrameHistory) Add(_ context.Context, orgID int64, channel string, frame *data.Frame) error {
+	rows, err := frame.RowLen()
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.channels[orgID]; !ok {
+		h.channels[orgID] = map[string][]historyEntry{}
+	}
+	now := h.now()
+	entries := append(h.channels[orgID][channel], historyEntry{added: now, frame: frame, rows: rows})
+
+	// Drop entries which are too old, or not needed to keep max points.
+	var total int
+	for _, e := range entries {
+		total += e.rows
+	}
+	var drop int
+	for drop < len(entries)-1 {
+		e := entries[drop]
+		tooOld := h.config.MaxAge > 0 && now.Sub(e.added) > h.config.MaxAge
+		if !tooOld && total-e.rows < h.config.maxPoints() {
+			break
+		}
+		total -= e.rows
+		drop++
+	}
+	clear(entries[:drop])
+	h.channels[orgID][channel] = entries[drop:]
+
+	if now.Sub(h.lastEvicted) >= historyEvictInterval {
+		h.lastEvicted = now
+		for orgID, channels := range h.channels {
+			for channel := range channels {
+				h.evict(orgID, channel, now)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *MemoryFrameHistory) Get(_ context.Context, orgID int64, channel string, from, to time.Time) (*data.Frame, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.evict(orgID, channel, h.now())
+	entries := h.channels[orgID][channel]
+	frames := make([]*data.Frame, 0, len(entries))
+	for _, e := range entries {
+		frames = append(frames, e.frame)
+	}
+	frame, ok := historyFrame(frames, from, to, h.config.maxPoints())
+	return frame, ok, nil
+}
+
+// evict drops entries of a channel which are older than MaxAge, and the channel itself
+// when no entries are left. Must be called with the lock held.
+func (h *MemoryFrameHistory) evict(orgID int64, channel string, now time.Time) {
+	if h.config.MaxAge <= 0 {
+		return
+	}
+	entries := h.channels[orgID][channel]
+	var drop int
+	for drop < len(entries) && now.Sub(entries[drop].added) > h.config.MaxAge {
+		drop++
+	}
+	switch drop {
+	case 0:
+	case len(entries):
+		delete(h.channels[orgID], channel)
+		if len(h.channels[orgID]) == 0 {
+			delete(h.channels, orgID)
+		}
+	default:
+		clear(entries[:drop])
+		h.channels[orgID][channel] = entries[drop:]
+	}
+}
@@ -0,0 +1,93 @@
+package managedstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testHistoryFrame(start time.Time, values ...float64) *data.Frame {
+	times := make([]time.Time, len(values))
+	for i := range values {
+		times[i] = start.Add(time.Duration(i) * time.Second)
+	}
+	return data.NewFrame("test",
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+	)
+}
+
+// testFrameHistory expects history to keep at most 5 points.
+func testFrameHistory(t *testing.T, h FrameHistory) {
+	ctx := context.Background()
+	start := time.Unix(1000, 0).UTC()
+
+	_, ok, err := h.Get(ctx, 1, "stream/test/history", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Frames with previous schema are skipped.
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", data.NewFrame("test", data.NewField("other", nil, []string{"a"}))))
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", testHistoryFrame(start, 1, 2, 3)))
+	require.NoError(t, h.Add(ctx, 1, "stream/test/history", testHistoryFrame(start.Add(3*time.Second), 4, 5, 6)))
+
+	frame, ok, err := h.Get(ctx, 1, "stream/test/history", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 5, frame.Rows())
+	require.Equal(t, 2.0, frame.Fields[1].At(0))
+	require.Equal(t, 6.0, frame.Fields[1].At(4))
+
+	frame, ok, err = h.Get(ctx, 1, "stream/test/history", start.Add(2*time.Second), start.Add(4*time.Second))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, start.Add(2*time.Second), frame.Fields[0].At(0))
+
+	// Other orgs have separate history.
+	_, ok, err = h.Get(ctx, 2, "stream/test/history", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestMemoryFrameHistory(t *testing.T) {
+	h := NewMemoryFrameHistory(HistoryConfig{MaxPoints: 5, MaxAge: time.Minute})
+	now := time.Now()
+	h.now = func() time.Time { return now }
+	testFrameHistory(t, h)
+
+	// Only entries needed to keep max points are stored.
+	require.Len(t, h.channels[1]["stream/test/history"], 2)
+
+	now = now.Add(2 * time.Minute)
+	_, ok, err := h.Get(context.Background(), 1, "stream/test/history", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Expired history is evicted when it is read.
+	require.NotContains(t, h.channels, int64(1))
+}
+
+func TestMemoryFrameHistory_EvictsIdleChannels(t *testing.T) {
+	ctx := context.Background()
+	h := NewMemoryFrameHistory(HistoryConfig{MaxAge: time.Minute})
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	require.NoError(t, h.Add(ctx, 1, "stream/test/idle", testHistoryFrame(now, 1)))
+	require.NoError(t, h.Add(ctx, 2, "stream/test/idle", testHistoryFrame(now, 1)))
+
+	// The idle channels are never read, and are evicted by frames added to another channel.
+	now = now.Add(30 * time.Second)
+	require.NoError(t, h.Add(ctx, 1, "stream/test/active", testHistoryFrame(now, 1)))
+	require.Contains(t, h.channels[1], "stream/test/idle")
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, h.Add(ctx, 1, "stream/test/active", testHistoryFrame(now, 2)))
+	require.NotContains(t, h.channels[1], "stream/test/idle")
+	require.NotContains(t, h.channels, int64(2))
+	require.Len(t, h.channels[1]["stream/test/active"], 1)
+}
@@ -0,0 +1,90 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// RedisFrameHistory keeps history of channels in Redis, so it is shared by all
+// Grafana instances of HA setup.
+type RedisFrameHistory struct {
+	redisClient *redis.Client
+	config      HistoryConfig
+	now         func() time.Time
+}
+
+type redisHistoryEntry struct {
+	// Added is a unix time in milliseconds the frame was added at.
+	Added int64           `json:"added"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// NewRedisFrameHistory creates new RedisFrameHistory.
+func NewRedisFrameHistory(redisClient *redis.Client, config HistoryConfig) *RedisFrameHistory {
+	return &RedisFrameHistory{
+		redisClient: redisClient,
+		config:      config,
+		now:         time.Now,
+	}
+}
+
+func (h *RedisFrameHistory) Add(ctx context.Context, orgID int64, channel string, frame *data.Frame) error {
+	frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+	if err != nil {
+		return err
+	}
+	entry, err := json.Marshal(redisHistoryEntry{Added: h.now().UnixMilli(), Frame: frameJSON})
+	if err != nil {
+		return err
+	}
+	ttl := frameCacheTTL
+	if h.config.MaxAge > 0 {
+		ttl = h.config.MaxAge
+	}
+
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	pipe := h.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+	pipe.RPush(ctx, key, entry)
+	// Every entry has at least one row, so this is enough to keep max points.
+	pipe.LTrim(ctx, key, -int64(h.config.maxPoints()), -1)
+	pipe.PExpire(ctx, key, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisFrameHistory) Get(ctx context.Context, orgID int64, channel string, from, to time.Time) (*data.Frame, bool, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := h.redisClient.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	now := h.now()
+	frames := make([]*data.Frame, 0, len(result))
+	for _, item := range result {
+		var entry redisHistoryEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, false, err
+		}
+		if h.config.MaxAge > 0 && now.Sub(time.UnixMilli(entry.Added)) > h.config.MaxAge {
+			continue
+		}
+		var frame data.Frame
+		if err := json.Unmarshal(entry.Frame, &frame); err != nil {
+			return nil, false, err
+		}
+		frames = append(frames, &frame)
+	}
+	frame, ok := historyFrame(frames, from, to, h.config.maxPoints())
+	return frame, ok, nil
+}
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream.history." + channelID
+}
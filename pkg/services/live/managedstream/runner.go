@@ -42,20 +42,45 @@ type Runner struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        FrameHistory
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
+// RunnerOption modifies Runner behavior.
+type RunnerOption func(*Runner)
+
+// WithFrameHistory makes streams keep recent frames of channels and send them to
+// subscribers on subscribe.
+func WithFrameHistory(history FrameHistory) RunnerOption {
+	return func(r *Runner) {
+		r.history = history
+	}
+}
+
 // NewRunner creates new Runner.
-func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
-	return &Runner{
+func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, opts ...RunnerOption) *Runner {
+	r := &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// GetHistory returns frame with buffered history of a channel in the time range.
+// Returns false if history is not enabled or there is no history in the range.
+func (r *Runner) GetHistory(ctx context.Context, orgID int64, channel string, from, to time.Time) (*data.Frame, bool, error) {
+	if r.history == nil {
+		return nil, false, nil
+	}
+	return r.history.Get(ctx, orgID, channel, from, to)
 }
 
 func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
@@ -126,6 +151,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
 		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s.history = r.history
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -139,6 +165,7 @@ type NamespaceStream struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	history        FrameHistory
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -171,6 +198,7 @@ func NewNamespaceStream(orgID int64, scope string, namespace string, publisher m
 // Push sends frame to the stream and saves it for later retrieval by subscribers.
 // * Saves the entire frame to cache.
 // * If schema has been changed sends entire frame to channel, otherwise only data.
+// * Adds the frame to channel history when history is enabled.
 func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Frame) error {
 	jsonFrameCache, err := data.FrameToJSONCache(frame)
 	if err != nil {
@@ -186,6 +214,12 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		return err
 	}
 
+	if s.history != nil {
+		if err := s.history.Add(ctx, s.orgID, channel, frame); err != nil {
+			logger.Error("Error adding frame to managed stream history", "channel", channel, "error", err)
+		}
+	}
+
 	// When the schema has not changed, just send the data.
 	include := data.IncludeDataOnly
 	if isUpdated {
@@ -241,6 +275,19 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (model.ChannelHandler, err
 
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := model.SubscribeReply{}
+	if s.history != nil {
+		frame, ok, err := s.history.Get(ctx, u.GetOrgID(), e.Channel, time.Time{}, time.Time{})
+		if err != nil {
+			logger.Error("Error getting managed stream history", "channel", e.Channel, "error", err)
+		} else if ok {
+			frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+			if err != nil {
+				return reply, 0, err
+			}
+			reply.Data = frameJSON
+			return reply, backend.SubscribeStreamStatusOK, nil
+		}
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
 	if err != nil {
 		return reply, 0, err
@@ -2,11 +2,16 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type testPublisher struct {
@@ -83,3 +88,27 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestManagedStreamHistory(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	runner := NewRunner(publisher.publish, nil, NewMemoryFrameCache(), WithFrameHistory(NewMemoryFrameHistory(HistoryConfig{MaxPoints: 10})))
+	s, err := runner.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, s.Push(context.Background(), "cpu", testHistoryFrame(start, 1)))
+	require.NoError(t, s.Push(context.Background(), "cpu", testHistoryFrame(start.Add(time.Second), 2)))
+
+	// Subscribers get the history as initial data.
+	reply, status, err := s.OnSubscribe(context.Background(), &user.SignedInUser{OrgID: 1}, model.SubscribeEvent{Channel: "stream/test/cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &frame))
+	require.Equal(t, 2, frame.Rows())
+
+	frame2, ok, err := runner.GetHistory(context.Background(), 1, "stream/test/cpu", start.Add(time.Second), time.Time{})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, frame2.Rows())
+}
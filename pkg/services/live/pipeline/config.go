@@ -32,6 +32,8 @@ type ChannelRule struct {
 	OrgId    int64               `json:"-"`
 	Pattern  string              `json:"pattern"`
 	Settings ChannelRuleSettings `json:"settings"`
+	// SecureSettings are the encrypted credentials of the subscribers of the rule.
+	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
 }
 
 type ConverterConfig struct {
@@ -50,11 +52,30 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type WindowAggregateFrameProcessorConfig struct {
+	// Window is a duration of aggregation windows, e.g. 1s.
+	Window string `json:"window"`
+	// Slide is an optional interval between sliding windows, windows are
+	// tumbling when not set.
+	Slide string `json:"slide,omitempty"`
+	// TimeField is a name of the time field, first time field is used when not set.
+	// Points are aggregated by the time they are processed at without time field.
+	TimeField string `json:"timeField,omitempty"`
+	// Fields to aggregate, all numeric fields are aggregated when not set.
+	Fields []string `json:"fields,omitempty"`
+	// Aggregations of each field, mean when not set.
+	Aggregations []WindowAggregation `json:"aggregations,omitempty"`
+	// GroupBy is an optional list of fields (e.g. a labels column) to aggregate
+	// points with different values separately.
+	GroupBy []string `json:"groupBy,omitempty"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                           string                               `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig      *DropFieldsFrameProcessorConfig      `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig      *KeepFieldsFrameProcessorConfig      `json:"keepFields,omitempty"`
+	MultipleProcessorConfig        *MultipleFrameProcessorConfig        `json:"multiple,omitempty"`
+	WindowAggregateProcessorConfig *WindowAggregateFrameProcessorConfig `json:"windowAggregate,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -83,9 +104,39 @@ type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
 
+type MQTTSubscriberConfig struct {
+	// Broker URL, e.g. tcp://localhost:1883 or ssl://localhost:8883.
+	Broker string `json:"broker"`
+	// Topic to subscribe to, may contain MQTT wildcards.
+	Topic string `json:"topic"`
+	// QoS of the subscription (0, 1 or 2).
+	QoS byte `json:"qos,omitempty"`
+	// ClientID is an optional client ID, generated when not set.
+	ClientID string `json:"clientId,omitempty"`
+	// Username of the connection. The password is the mqttPassword secure
+	// setting of the channel rule.
+	Username string `json:"username,omitempty"`
+}
+
+type KafkaSubscriberConfig struct {
+	// Brokers to bootstrap the connection with, e.g. localhost:9092.
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+	// GroupID is an optional consumer group. Without a group every Grafana
+	// instance consumes all new records of the topic.
+	GroupID string `json:"groupId,omitempty"`
+	// TLS enables TLS connections to the brokers.
+	TLS bool `json:"tls,omitempty"`
+	// Username enables SASL/PLAIN authentication. The password is the
+	// kafkaPassword secure setting of the channel rule.
+	Username string `json:"username,omitempty"`
+}
+
 type SubscriberConfig struct {
 	Type                     string                    `json:"type" ts_type:"Omit<keyof SubscriberConfig, 'type'>"`
 	MultipleSubscriberConfig *MultipleSubscriberConfig `json:"multiple,omitempty"`
+	MQTTSubscriberConfig     *MQTTSubscriberConfig     `json:"mqtt,omitempty"`
+	KafkaSubscriberConfig    *KafkaSubscriberConfig    `json:"kafka,omitempty"`
 }
 
 // RedirectDataOutputConfig ...
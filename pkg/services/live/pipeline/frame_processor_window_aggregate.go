@@ -0,0 +1,432 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type WindowAggregation string
+
+const (
+	WindowAggregationMean  WindowAggregation = "mean"
+	WindowAggregationMin   WindowAggregation = "min"
+	WindowAggregationMax   WindowAggregation = "max"
+	WindowAggregationLast  WindowAggregation = "last"
+	WindowAggregationCount WindowAggregation = "count"
+)
+
+func (a WindowAggregation) valid() bool {
+	switch a {
+	case WindowAggregationMean, WindowAggregationMin, WindowAggregationMax, WindowAggregationLast, WindowAggregationCount:
+		return true
+	}
+	return false
+}
+
+// maxWindowPoints limits the number of points buffered per channel, oldest
+// points are dropped when a channel gets more points within a window.
+const maxWindowPoints = 100000
+
+// WindowAggregateFrameProcessor buffers frames per channel and replaces them
+// with aggregates of tumbling or sliding time windows. Windows are closed by
+// the time of incoming points, so a frame with aggregates of all windows closed
+// by a frame is returned instead of it, and nothing is returned while windows
+// are still open. Windows are also closed by Flush when they are due without
+// new frames. Aggregates are timestamped at the end of their window.
+type WindowAggregateFrameProcessor struct {
+	config       WindowAggregateFrameProcessorConfig
+	window       time.Duration
+	slide        time.Duration
+	aggregations []WindowAggregation
+	now          func() time.Time
+
+	mu     sync.Mutex
+	states map[windowStateKey]*windowState
+}
+
+type windowStateKey struct {
+	orgID   int64
+	channel string
+}
+
+type windowPoint struct {
+	time  time.Time
+	group string
+	field string
+	value float64
+}
+
+type windowField struct {
+	name   string
+	labels data.Labels
+}
+
+type windowState struct {
+	windowEnd time.Time
+	points    []windowPoint
+	// fields are kept in order of appearance to keep frame schema stable
+	// between windows.
+	fields     []string
+	fieldsInfo map[string]windowField
+	// groupValues are the values of the groups with points in open windows.
+	groupValues map[string][]string
+
+	// lastSeen is the time the last frame of the channel was processed at.
+	lastSeen time.Time
+	// eventTime is true when points are timestamped by a time field instead
+	// of the time they are processed at.
+	eventTime     bool
+	frameName     string
+	timeFieldName string
+}
+
+// windowRows are the rows of aggregates of closed windows.
+type windowRows struct {
+	times  []time.Time
+	groups [][]string
+	values [][]*float64
+}
+
+func NewWindowAggregateFrameProcessor(config WindowAggregateFrameProcessorConfig) (*WindowAggregateFrameProcessor, error) {
+	window, err := time.ParseDuration(config.Window)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid window: %q", config.Window)
+	}
+	slide := window
+	if config.Slide != "" {
+		slide, err = time.ParseDuration(config.Slide)
+		if err != nil || slide <= 0 || slide > window {
+			return nil, fmt.Errorf("invalid slide: %q, must be positive and not greater than window", config.Slide)
+		}
+	}
+	aggregations := config.Aggregations
+	if len(aggregations) == 0 {
+		aggregations = []WindowAggregation{WindowAggregationMean}
+	}
+	for _, a := range aggregations {
+		if !a.valid() {
+			return nil, fmt.Errorf("unknown aggregation: %s", a)
+		}
+	}
+	return &WindowAggregateFrameProcessor{
+		config:       config,
+		window:       window,
+		slide:        slide,
+		aggregations: aggregations,
+		now:          time.Now,
+		states:       map[windowStateKey]*windowState{},
+	}, nil
+}
+
+const FrameProcessorTypeWindowAggregate = "windowAggregate"
+
+func (p *WindowAggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeWindowAggregate
+}
+
+func (p *WindowAggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	timeField := p.timeField(frame)
+	groupFields := make([]*data.Field, 0, len(p.config.GroupBy))
+	for _, name := range p.config.GroupBy {
+		if f, _ := frame.FieldByName(name); f != nil {
+			groupFields = append(groupFields, f)
+		}
+	}
+	valueFields := p.valueFields(frame, timeField, groupFields)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := windowStateKey{orgID: vars.OrgID, channel: vars.Channel}
+	state, ok := p.states[key]
+	if !ok {
+		state = &windowState{fieldsInfo: map[string]windowField{}, groupValues: map[string][]string{}}
+		p.states[key] = state
+	}
+	state.lastSeen = p.now()
+	state.eventTime = timeField != nil
+	state.frameName = frame.Name
+	state.timeFieldName = "time"
+	if timeField != nil {
+		state.timeFieldName = timeField.Name
+	}
+
+	var rows windowRows
+	rowCount, _ := frame.RowLen()
+	for i := 0; i < rowCount; i++ {
+		t := p.now()
+		if timeField != nil {
+			var ok bool
+			t, ok = timeAt(timeField, i)
+			if !ok {
+				continue
+			}
+		}
+
+		if state.windowEnd.IsZero() {
+			state.windowEnd = t.Truncate(p.slide).Add(p.slide)
+		}
+		p.closeWindows(state, t, &rows)
+		if t.Before(state.windowEnd.Add(-p.window)) {
+			// Too late for all open windows.
+			continue
+		}
+
+		groupKey, groupValues := windowGroup(groupFields, i)
+		if _, ok := state.groupValues[groupKey]; !ok {
+			state.groupValues[groupKey] = groupValues
+		}
+		for _, f := range valueFields {
+			v, err := f.NullableFloatAt(i)
+			if err != nil || v == nil || math.IsNaN(*v) {
+				continue
+			}
+			fieldKey := f.Name + f.Labels.String()
+			if _, ok := state.fieldsInfo[fieldKey]; !ok {
+				state.fieldsInfo[fieldKey] = windowField{name: f.Name, labels: f.Labels}
+				state.fields = append(state.fields, fieldKey)
+			}
+			state.points = append(state.points, windowPoint{time: t, group: groupKey, field: fieldKey, value: *v})
+		}
+	}
+	if len(state.points) > maxWindowPoints {
+		state.points = state.points[len(state.points)-maxWindowPoints:]
+	}
+
+	if len(rows.times) == 0 {
+		return nil, nil
+	}
+	return p.buildFrame(state, rows), nil
+}
+
+// Flush closes the windows which are due without new frames, and returns
+// frames with their aggregates. Windows of points timestamped at processing
+// time are closed when they end. Channels without frames for the length of a
+// window are idle: their windows are closed with the points they have, since
+// late points would not fit in them anyway, and their state is evicted.
+func (p *WindowAggregateFrameProcessor) Flush(now time.Time) ([]*FlushedFrame, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var flushed []*FlushedFrame
+	for key, state := range p.states {
+		var rows windowRows
+		if !state.eventTime && !state.windowEnd.IsZero() {
+			p.closeWindows(state, now, &rows)
+		}
+		if now.Sub(state.lastSeen) >= p.window {
+			for len(state.points) > 0 {
+				p.closeWindow(state, &rows)
+				state.windowEnd = state.windowEnd.Add(p.slide)
+				p.prune(state)
+			}
+			delete(p.states, key)
+		}
+		if len(rows.times) > 0 {
+			flushed = append(flushed, &FlushedFrame{OrgID: key.orgID, Channel: key.channel, Frame: p.buildFrame(state, rows)})
+		}
+	}
+	return flushed, len(p.states) > 0
+}
+
+func (p *WindowAggregateFrameProcessor) timeField(frame *data.Frame) *data.Field {
+	for _, f := range frame.Fields {
+		if !f.Type().Time() {
+			continue
+		}
+		if p.config.TimeField == "" || f.Name == p.config.TimeField {
+			return f
+		}
+	}
+	return nil
+}
+
+func (p *WindowAggregateFrameProcessor) valueFields(frame *data.Frame, timeField *data.Field, groupFields []*data.Field) []*data.Field {
+	var fields []*data.Field
+	for _, f := range frame.Fields {
+		if f == timeField || !f.Type().Numeric() {
+			continue
+		}
+		if len(p.config.Fields) > 0 && !stringInSlice(f.Name, p.config.Fields) {
+			continue
+		}
+		isGroupField := false
+		for _, g := range groupFields {
+			isGroupField = isGroupField || g == f
+		}
+		if !isGroupField {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// closeWindows closes the windows which end at or before t.
+func (p *WindowAggregateFrameProcessor) closeWindows(state *windowState, t time.Time, rows *windowRows) {
+	for !t.Before(state.windowEnd) {
+		p.closeWindow(state, rows)
+		state.windowEnd = state.windowEnd.Add(p.slide)
+		p.prune(state)
+		if len(state.points) == 0 && !t.Before(state.windowEnd) {
+			// Skip empty windows after a gap in data.
+			state.windowEnd = t.Truncate(p.slide).Add(p.slide)
+		}
+	}
+}
+
+// closeWindow adds a row of aggregates for each group with points in the
+// window ending at state.windowEnd.
+func (p *WindowAggregateFrameProcessor) closeWindow(state *windowState, rows *windowRows) {
+	start := state.windowEnd.Add(-p.window)
+	byGroup := map[string][]windowPoint{}
+	for _, point := range state.points {
+		if point.time.Before(start) || !point.time.Before(state.windowEnd) {
+			continue
+		}
+		byGroup[point.group] = append(byGroup[point.group], point)
+	}
+	groups := make([]string, 0, len(byGroup))
+	for g := range byGroup {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+
+	for _, g := range groups {
+		rows.times = append(rows.times, state.windowEnd)
+		rows.groups = append(rows.groups, state.groupValues[g])
+		rows.values = append(rows.values, p.aggregate(state.fields, byGroup[g]))
+	}
+}
+
+// aggregate returns the aggregates of the points, ordered by field and then by aggregation.
+func (p *WindowAggregateFrameProcessor) aggregate(fields []string, points []windowPoint) []*float64 {
+	type accumulator struct {
+		count, sum, min, max, last float64
+	}
+	acc := map[string]*accumulator{}
+	for _, point := range points {
+		a, ok := acc[point.field]
+		if !ok {
+			a = &accumulator{min: point.value, max: point.value}
+			acc[point.field] = a
+		}
+		a.count++
+		a.sum += point.value
+		a.min = math.Min(a.min, point.value)
+		a.max = math.Max(a.max, point.value)
+		a.last = point.value
+	}
+
+	values := make([]*float64, 0, len(fields)*len(p.aggregations))
+	for _, field := range fields {
+		a, ok := acc[field]
+		for _, aggregation := range p.aggregations {
+			if !ok {
+				values = append(values, nil)
+				continue
+			}
+			var v float64
+			switch aggregation {
+			case WindowAggregationMean:
+				v = a.sum / a.count
+			case WindowAggregationMin:
+				v = a.min
+			case WindowAggregationMax:
+				v = a.max
+			case WindowAggregationLast:
+				v = a.last
+			case WindowAggregationCount:
+				v = a.count
+			}
+			values = append(values, &v)
+		}
+	}
+	return values
+}
+
+// prune removes the points which are before all open windows, and the values
+// of the groups without points left.
+func (p *WindowAggregateFrameProcessor) prune(state *windowState) {
+	start := state.windowEnd.Add(-p.window)
+	groups := make(map[string]struct{}, len(state.groupValues))
+	n := 0
+	for _, point := range state.points {
+		if !point.time.Before(start) {
+			state.points[n] = point
+			groups[point.group] = struct{}{}
+			n++
+		}
+	}
+	state.points = state.points[:n]
+	for g := range state.groupValues {
+		if _, ok := groups[g]; !ok {
+			delete(state.groupValues, g)
+		}
+	}
+}
+
+func (p *WindowAggregateFrameProcessor) buildFrame(state *windowState, rows windowRows) *data.Frame {
+	fields := []*data.Field{data.NewField(state.timeFieldName, nil, rows.times)}
+
+	for i, groupName := range p.config.GroupBy {
+		values := make([]string, len(rows.groups))
+		for j, groupValues := range rows.groups {
+			if i < len(groupValues) {
+				values[j] = groupValues[i]
+			}
+		}
+		fields = append(fields, data.NewField(groupName, nil, values))
+	}
+
+	column := 0
+	for _, fieldKey := range state.fields {
+		info := state.fieldsInfo[fieldKey]
+		for _, aggregation := range p.aggregations {
+			values := make([]*float64, len(rows.values))
+			for j, row := range rows.values {
+				// Fields seen for the first time in later rows are missing in earlier rows.
+				if column < len(row) {
+					values[j] = row[column]
+				}
+			}
+			fields = append(fields, data.NewField(info.name+"_"+string(aggregation), info.labels, values))
+			column++
+		}
+	}
+	return data.NewFrame(state.frameName, fields...)
+}
+
+func timeAt(field *data.Field, i int) (time.Time, bool) {
+	switch v := field.At(i).(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func windowGroup(groupFields []*data.Field, i int) (string, []string) {
+	if len(groupFields) == 0 {
+		return "", nil
+	}
+	values := make([]string, len(groupFields))
+	for j, f := range groupFields {
+		if v, ok := f.ConcreteAt(i); ok {
+			values[j] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(values, "\x00"), values
+}
@@ -0,0 +1,204 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func windowTestFrame(start time.Time, offsets []time.Duration, hosts []string, values []float64) *data.Frame {
+	times := make([]time.Time, len(offsets))
+	for i, o := range offsets {
+		times[i] = start.Add(o)
+	}
+	return data.NewFrame("test",
+		data.NewField("time", nil, times),
+		data.NewField("host", nil, hosts),
+		data.NewField("value", nil, values),
+	)
+}
+
+func TestWindowAggregateFrameProcessor_Tumbling(t *testing.T) {
+	p, err := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		Window:       "1s",
+		Aggregations: []WindowAggregation{WindowAggregationMean, WindowAggregationMin, WindowAggregationMax, WindowAggregationLast, WindowAggregationCount},
+	})
+	require.NoError(t, err)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+	start := time.Unix(100, 0)
+
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{0, 200 * time.Millisecond, 500 * time.Millisecond},
+		[]string{"a", "a", "a"}, []float64{1, 5, 3}))
+	require.NoError(t, err)
+	require.Nil(t, frame, "window is still open")
+
+	// Other channels have separate windows.
+	frame, err = p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/other"}, windowTestFrame(start,
+		[]time.Duration{2 * time.Second}, []string{"a"}, []float64{100}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{1100 * time.Millisecond}, []string{"a"}, []float64{10}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(0))
+
+	expected := map[string]float64{"value_mean": 3, "value_min": 1, "value_max": 5, "value_last": 3, "value_count": 3}
+	require.Len(t, frame.Fields, len(expected)+1)
+	for name, value := range expected {
+		f, _ := frame.FieldByName(name)
+		require.NotNil(t, f, name)
+		require.Equal(t, value, *f.At(0).(*float64), name)
+	}
+
+	// Points too late for open windows are ignored, empty windows are skipped.
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{500 * time.Millisecond, 10 * time.Second}, []string{"a", "a"}, []float64{1000, 1}))
+	require.NoError(t, err)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start.Add(2*time.Second), frame.Fields[0].At(0))
+	require.Equal(t, 10.0, *frame.Fields[1].At(0).(*float64))
+}
+
+func TestWindowAggregateFrameProcessor_SlidingGroupBy(t *testing.T) {
+	p, err := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		Window:  "2s",
+		Slide:   "1s",
+		GroupBy: []string{"host"},
+	})
+	require.NoError(t, err)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+	start := time.Unix(100, 0)
+
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{0, 100 * time.Millisecond, 1100 * time.Millisecond, 1200 * time.Millisecond, 2100 * time.Millisecond},
+		[]string{"a", "b", "a", "b", "a"}, []float64{1, 10, 3, 30, 0}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+
+	host, _ := frame.FieldByName("host")
+	value, _ := frame.FieldByName("value_mean")
+	require.Equal(t, 4, frame.Rows())
+	expected := []struct {
+		end   time.Time
+		host  string
+		value float64
+	}{
+		{start.Add(time.Second), "a", 1},
+		{start.Add(time.Second), "b", 10},
+		{start.Add(2 * time.Second), "a", 2},
+		{start.Add(2 * time.Second), "b", 20},
+	}
+	for i, e := range expected {
+		require.Equal(t, e.end, frame.Fields[0].At(i))
+		require.Equal(t, e.host, host.At(i))
+		require.Equal(t, e.value, *value.At(i).(*float64))
+	}
+}
+
+func TestWindowAggregateFrameProcessor_Flush(t *testing.T) {
+	start := time.Unix(100, 0)
+	now := start
+
+	t.Run("last window of an idle channel is flushed and its state evicted", func(t *testing.T) {
+		p, err := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+			Window:  "1s",
+			GroupBy: []string{"host"},
+		})
+		require.NoError(t, err)
+		p.now = func() time.Time { return now }
+		vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+		frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+			[]time.Duration{0, 100 * time.Millisecond}, []string{"a", "b"}, []float64{1, 10}))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+
+		flushed, ok := p.Flush(now.Add(500 * time.Millisecond))
+		require.Empty(t, flushed, "channel is not idle yet")
+		require.True(t, ok)
+
+		flushed, ok = p.Flush(now.Add(time.Second))
+		require.False(t, ok, "no state is left")
+		require.Empty(t, p.states)
+		require.Len(t, flushed, 1)
+		require.Equal(t, int64(1), flushed[0].OrgID)
+		require.Equal(t, "stream/test/sensor", flushed[0].Channel)
+
+		frame = flushed[0].Frame
+		require.Equal(t, "test", frame.Name)
+		require.Equal(t, 2, frame.Rows())
+		host, _ := frame.FieldByName("host")
+		value, _ := frame.FieldByName("value_mean")
+		require.Equal(t, start.Add(time.Second), frame.Fields[0].At(0))
+		require.Equal(t, "a", host.At(0))
+		require.Equal(t, 1.0, *value.At(0).(*float64))
+		require.Equal(t, "b", host.At(1))
+		require.Equal(t, 10.0, *value.At(1).(*float64))
+	})
+
+	t.Run("windows of processing time close when they end", func(t *testing.T) {
+		now = start
+		p, err := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{Window: "10s"})
+		require.NoError(t, err)
+		p.now = func() time.Time { return now }
+		vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+		frame, err := p.ProcessFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", nil, []float64{4})))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+
+		now = start.Add(5 * time.Second)
+		flushed, ok := p.Flush(now)
+		require.Empty(t, flushed)
+		require.True(t, ok)
+		frame, err = p.ProcessFrame(context.Background(), vars, data.NewFrame("test", data.NewField("value", nil, []float64{6})))
+		require.NoError(t, err)
+		require.Nil(t, frame)
+
+		flushed, ok = p.Flush(start.Add(10 * time.Second))
+		require.True(t, ok, "channel is not idle")
+		require.Len(t, flushed, 1)
+		require.Equal(t, start.Add(10*time.Second), flushed[0].Frame.Fields[0].At(0))
+		require.Equal(t, 5.0, *flushed[0].Frame.Fields[1].At(0).(*float64))
+
+		flushed, ok = p.Flush(start.Add(20 * time.Second))
+		require.Empty(t, flushed)
+		require.False(t, ok)
+	})
+
+	t.Run("values of groups without points are evicted", func(t *testing.T) {
+		p, err := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+			Window:  "1s",
+			GroupBy: []string{"host"},
+		})
+		require.NoError(t, err)
+		vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+		for i := 0; i < 10; i++ {
+			_, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+				[]time.Duration{time.Duration(i) * time.Second}, []string{fmt.Sprint(i)}, []float64{1}))
+			require.NoError(t, err)
+		}
+		state := p.states[windowStateKey{orgID: 1, channel: "stream/test/sensor"}]
+		require.Equal(t, map[string][]string{"9": {"9"}}, state.groupValues)
+	})
+}
+
+func TestNewWindowAggregateFrameProcessor_Invalid(t *testing.T) {
+	for _, config := range []WindowAggregateFrameProcessorConfig{
+		{Window: ""},
+		{Window: "1s", Slide: "2s"},
+		{Window: "1s", Aggregations: []WindowAggregation{"median"}},
+	} {
+		_, err := NewWindowAggregateFrameProcessor(config)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,263 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// InputProcessor processes data consumed by inputs, usually the Pipeline.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// InputProcessorFunc is an adapter to use a function as InputProcessor.
+type InputProcessorFunc func(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+
+func (f InputProcessorFunc) ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+	return f(ctx, orgID, channelID, body)
+}
+
+// NumLocalSubscribersGetter returns the number of subscribers of a channel on this node.
+type NumLocalSubscribersGetter interface {
+	GetNumLocalSubscribers(channel string) (int, error)
+}
+
+// InputSource consumes messages from an external system (e.g. an MQTT broker or a
+// Kafka topic) and writes them to the buffer until the context is done or the
+// connection is lost.
+type InputSource interface {
+	Run(ctx context.Context, buffer *InputBuffer) error
+}
+
+// InputBuffer is a bounded buffer between an InputSource and the pipeline, so that
+// slow processing does not make sources accumulate messages without limit.
+type InputBuffer struct {
+	messages chan []byte
+	dropped  atomic.Int64
+}
+
+func newInputBuffer(size int) *InputBuffer {
+	return &InputBuffer{messages: make(chan []byte, size)}
+}
+
+// Write buffers the message, blocking while the buffer is full. Sources which can
+// pause consuming (like Kafka) should use it.
+func (b *InputBuffer) Write(ctx context.Context, message []byte) error {
+	select {
+	case b.messages <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryWrite buffers the message if the buffer is not full and drops it otherwise.
+// Sources which must not block (like MQTT) should use it.
+func (b *InputBuffer) TryWrite(message []byte) bool {
+	select {
+	case b.messages <- message:
+		return true
+	default:
+		b.dropped.Add(1)
+		return false
+	}
+}
+
+// InputManager runs inputs of channels while they have subscribers. There is
+// one running input per channel, inputs are stopped when channel has no local
+// subscribers left.
+type InputManager struct {
+	mu             sync.Mutex
+	baseCtx        context.Context
+	processor      InputProcessor
+	presenceGetter NumLocalSubscribersGetter
+	inputs         map[inputKey]context.CancelFunc
+	bufferSize     int
+	checkInterval  time.Duration
+	maxChecks      int
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+}
+
+type inputKey struct {
+	orgID   int64
+	channel string
+}
+
+// InputManagerOption modifies InputManager behavior (used for tests for example).
+type InputManagerOption func(*InputManager)
+
+// WithInputCheckConfig allows setting custom subscriber check rules.
+func WithInputCheckConfig(interval time.Duration, maxChecks int) InputManagerOption {
+	return func(m *InputManager) {
+		m.checkInterval = interval
+		m.maxChecks = maxChecks
+	}
+}
+
+// WithInputBackoff allows setting custom reconnect backoff.
+func WithInputBackoff(minBackoff, maxBackoff time.Duration) InputManagerOption {
+	return func(m *InputManager) {
+		m.minBackoff = minBackoff
+		m.maxBackoff = maxBackoff
+	}
+}
+
+// WithInputBufferSize allows setting the number of messages buffered per input.
+func WithInputBufferSize(size int) InputManagerOption {
+	return func(m *InputManager) {
+		m.bufferSize = size
+	}
+}
+
+const (
+	defaultInputBufferSize    = 1024
+	defaultInputCheckInterval = 5 * time.Second
+	defaultInputMaxChecks     = 3
+	defaultInputMinBackoff    = time.Second
+	defaultInputMaxBackoff    = 30 * time.Second
+)
+
+// NewInputManager creates new InputManager.
+func NewInputManager(processor InputProcessor, presenceGetter NumLocalSubscribersGetter, opts ...InputManagerOption) *InputManager {
+	m := &InputManager{
+		baseCtx:        context.Background(),
+		processor:      processor,
+		presenceGetter: presenceGetter,
+		inputs:         make(map[inputKey]context.CancelFunc),
+		bufferSize:     defaultInputBufferSize,
+		checkInterval:  defaultInputCheckInterval,
+		maxChecks:      defaultInputMaxChecks,
+		minBackoff:     defaultInputMinBackoff,
+		maxBackoff:     defaultInputMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Run sets the context inputs are running with and stops all inputs when it is done.
+func (m *InputManager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	m.baseCtx = ctx
+	m.mu.Unlock()
+	<-ctx.Done()
+	m.mu.Lock()
+	for key, cancel := range m.inputs {
+		cancel()
+		delete(m.inputs, key)
+	}
+	m.mu.Unlock()
+	return ctx.Err()
+}
+
+// Start runs the input of a channel unless it is running already.
+func (m *InputManager) Start(orgID int64, channel string, source InputSource) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := inputKey{orgID: orgID, channel: channel}
+	if _, ok := m.inputs[key]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(m.baseCtx)
+	m.inputs[key] = cancel
+	go m.runInput(ctx, key, source)
+}
+
+func (m *InputManager) running(orgID int64, channel string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.inputs[inputKey{orgID: orgID, channel: channel}]
+	return ok
+}
+
+func (m *InputManager) stop(key inputKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cancel, ok := m.inputs[key]; ok {
+		cancel()
+		delete(m.inputs, key)
+	}
+}
+
+func (m *InputManager) runInput(ctx context.Context, key inputKey, source InputSource) {
+	defer m.stop(key)
+
+	buffer := newInputBuffer(m.bufferSize)
+	go m.consume(ctx, key, source, buffer)
+	go m.process(ctx, key, buffer)
+
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+
+	var numNoSubscribersChecks int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if dropped := buffer.dropped.Swap(0); dropped > 0 {
+				logger.Warn("Input messages dropped since buffer is full", "orgId", key.orgID, "channel", key.channel, "dropped", dropped)
+			}
+			numSubscribers, err := m.presenceGetter.GetNumLocalSubscribers(orgchannel.PrependOrgID(key.orgID, key.channel))
+			if err != nil {
+				logger.Error("Error checking input subscribers", "orgId", key.orgID, "channel", key.channel, "error", err)
+				continue
+			}
+			if numSubscribers > 0 {
+				numNoSubscribersChecks = 0
+				continue
+			}
+			numNoSubscribersChecks++
+			if numNoSubscribersChecks >= m.maxChecks {
+				logger.Debug("Stopping input without subscribers", "orgId", key.orgID, "channel", key.channel)
+				return
+			}
+		}
+	}
+}
+
+// consume runs the source, reconnecting with exponential backoff when it fails.
+func (m *InputManager) consume(ctx context.Context, key inputKey, source InputSource, buffer *InputBuffer) {
+	backoff := m.minBackoff
+	for {
+		started := time.Now()
+		err := source.Run(ctx, buffer)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > m.maxBackoff {
+			// Source was running for a while, so this is a new failure.
+			backoff = m.minBackoff
+		}
+		logger.Error("Input stopped, reconnecting", "orgId", key.orgID, "channel", key.channel, "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+	}
+}
+
+func (m *InputManager) process(ctx context.Context, key inputKey, buffer *InputBuffer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-buffer.messages:
+			_, err := m.processor.ProcessInput(ctx, key.orgID, key.channel, message)
+			if err != nil {
+				logger.Error("Error processing input message", "orgId", key.orgID, "channel", key.channel, "error", err)
+			}
+		}
+	}
+}
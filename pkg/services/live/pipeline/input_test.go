@@ -0,0 +1,101 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type testInputSource struct {
+	runs     atomic.Int32
+	messages [][]byte
+}
+
+// Run writes the messages and fails on first run to test reconnects.
+func (s *testInputSource) Run(ctx context.Context, buffer *InputBuffer) error {
+	if s.runs.Add(1) == 1 {
+		return errors.New("connection refused")
+	}
+	for _, message := range s.messages {
+		if err := buffer.Write(ctx, message); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type testNumSubscribersGetter struct {
+	numSubscribers atomic.Int32
+}
+
+func (g *testNumSubscribersGetter) GetNumLocalSubscribers(_ string) (int, error) {
+	return int(g.numSubscribers.Load()), nil
+}
+
+type testFrameCollector struct {
+	mu     sync.Mutex
+	frames []*data.Frame
+}
+
+func (c *testFrameCollector) Type() string {
+	return "test"
+}
+
+func (c *testFrameCollector) OutputFrame(_ context.Context, _ Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frames = append(c.frames, frame)
+	return nil, nil
+}
+
+func (c *testFrameCollector) numFrames() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.frames)
+}
+
+func TestInputManager(t *testing.T) {
+	collector := &testFrameCollector{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/mqtt/test": {
+				Converter:       NewAutoJsonConverter(AutoJsonConverterConfig{}),
+				FrameOutputters: []FrameOutputter{collector},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	subscribers := &testNumSubscribersGetter{}
+	subscribers.numSubscribers.Store(1)
+	m := NewInputManager(p, subscribers,
+		WithInputCheckConfig(10*time.Millisecond, 2),
+		WithInputBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+	source := &testInputSource{messages: [][]byte{[]byte(`{"value": 1}`), []byte(`{"value": 2}`)}}
+
+	m.Start(1, "stream/mqtt/test", source)
+	// Input is started once per channel.
+	m.Start(1, "stream/mqtt/test", source)
+
+	require.Eventually(t, func() bool { return collector.numFrames() == 2 }, time.Second, 5*time.Millisecond)
+	require.Equal(t, int32(2), source.runs.Load())
+	require.True(t, m.running(1, "stream/mqtt/test"))
+
+	subscribers.numSubscribers.Store(0)
+	require.Eventually(t, func() bool { return !m.running(1, "stream/mqtt/test") }, time.Second, 5*time.Millisecond)
+}
+
+func TestInputBuffer_TryWrite(t *testing.T) {
+	buffer := newInputBuffer(1)
+	require.True(t, buffer.TryWrite([]byte("1")))
+	require.False(t, buffer.TryWrite([]byte("2")))
+	require.Equal(t, int64(1), buffer.dropped.Load())
+}
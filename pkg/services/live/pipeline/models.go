@@ -146,14 +146,34 @@ func checkRulesValid(orgID int64, rules []ChannelRule) (ok bool, reason string)
 	return ok, reason
 }
 
+func ChannelRuleToDto(r ChannelRule) ChannelRuleDto {
+	secureFields := make(map[string]bool, len(r.SecureSettings))
+	for k := range r.SecureSettings {
+		secureFields[k] = true
+	}
+	return ChannelRuleDto{
+		Pattern:      r.Pattern,
+		Settings:     r.Settings,
+		SecureFields: secureFields,
+	}
+}
+
+type ChannelRuleDto struct {
+	Pattern      string              `json:"pattern"`
+	Settings     ChannelRuleSettings `json:"settings"`
+	SecureFields map[string]bool     `json:"secureFields"`
+}
+
 type ChannelRuleCreateCmd struct {
-	Pattern  string              `json:"pattern"`
-	Settings ChannelRuleSettings `json:"settings"`
+	Pattern        string              `json:"pattern"`
+	Settings       ChannelRuleSettings `json:"settings"`
+	SecureSettings map[string]string   `json:"secureSettings"`
 }
 
 type ChannelRuleUpdateCmd struct {
-	Pattern  string              `json:"pattern"`
-	Settings ChannelRuleSettings `json:"settings"`
+	Pattern        string              `json:"pattern"`
+	Settings       ChannelRuleSettings `json:"settings"`
+	SecureSettings map[string]string   `json:"secureSettings"`
 }
 
 type ChannelRuleDeleteCmd struct {
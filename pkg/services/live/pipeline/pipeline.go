@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -98,6 +100,23 @@ type FrameProcessor interface {
 	ProcessFrame(ctx context.Context, vars Vars, frame *data.Frame) (*data.Frame, error)
 }
 
+// FlushingFrameProcessor is a FrameProcessor which holds back frames, like the
+// aggregates of open time windows, and can release them without new frames.
+type FlushingFrameProcessor interface {
+	FrameProcessor
+	// Flush returns the frames which are due at now, and whether the processor
+	// still holds state to flush later.
+	Flush(now time.Time) ([]*FlushedFrame, bool)
+}
+
+// FlushedFrame is a frame released by a FlushingFrameProcessor. It is passed
+// to the processors of the rule of the channel that follow the flushing one.
+type FlushedFrame struct {
+	OrgID   int64
+	Channel string
+	Frame   *data.Frame
+}
+
 // FrameOutputter outputs data.Frame to a custom destination. Or simply
 // do nothing if some conditions not met.
 type FrameOutputter interface {
@@ -182,12 +201,18 @@ type ChannelRuleGetter interface {
 type Pipeline struct {
 	ruleGetter ChannelRuleGetter
 	tracer     trace.Tracer
+
+	flushMu sync.Mutex
+	// flushers are the flushing processors which processed frames since they
+	// last held no state.
+	flushers map[FlushingFrameProcessor]struct{}
 }
 
 // New creates new Pipeline.
 func New(ruleGetter ChannelRuleGetter) (*Pipeline, error) {
 	p := &Pipeline{
 		ruleGetter: ruleGetter,
+		flushers:   map[FlushingFrameProcessor]struct{}{},
 	}
 
 	if os.Getenv("GF_LIVE_PIPELINE_TRACE") != "" {
@@ -214,6 +239,77 @@ func (p *Pipeline) Get(orgID int64, channel string) (*LiveChannelRule, bool, err
 	return p.ruleGetter.Get(orgID, channel)
 }
 
+// processorFlushInterval is how often the frames held back by processors are flushed.
+const processorFlushInterval = time.Second
+
+// Run flushes the frames held back by processors until the context is done.
+func (p *Pipeline) Run(ctx context.Context) error {
+	ticker := time.NewTicker(processorFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			p.flush(ctx, now)
+		}
+	}
+}
+
+func (p *Pipeline) flush(ctx context.Context, now time.Time) {
+	p.flushMu.Lock()
+	flushers := make([]FlushingFrameProcessor, 0, len(p.flushers))
+	for f := range p.flushers {
+		flushers = append(flushers, f)
+	}
+	p.flushMu.Unlock()
+
+	for _, f := range flushers {
+		// Processors are only forgotten while holding the lock, so that a frame
+		// processed concurrently registers the processor again.
+		p.flushMu.Lock()
+		frames, ok := f.Flush(now)
+		if !ok {
+			delete(p.flushers, f)
+		}
+		p.flushMu.Unlock()
+
+		for _, flushed := range frames {
+			if err := p.processFlushedFrame(ctx, f, flushed); err != nil {
+				logger.Error("Error processing flushed frame", "error", err, "channel", flushed.Channel)
+			}
+		}
+	}
+}
+
+// processFlushedFrame applies the processors which follow the flushing one and
+// the outputs of the rule of the channel to the frame. The frame is dropped if
+// the rule no longer has the processor.
+func (p *Pipeline) processFlushedFrame(ctx context.Context, proc FlushingFrameProcessor, flushed *FlushedFrame) error {
+	rule, ok, err := p.ruleGetter.Get(flushed.OrgID, flushed.Channel)
+	if err != nil || !ok {
+		return err
+	}
+	for i, ruleProc := range rule.FrameProcessors {
+		if ruleProc != FrameProcessor(proc) {
+			continue
+		}
+		vars, err := channelVars(flushed.OrgID, flushed.Channel)
+		if err != nil {
+			return err
+		}
+		frames, err := p.applyRule(ctx, rule, vars, flushed.Frame, rule.FrameProcessors[i+1:])
+		if err != nil {
+			return err
+		}
+		if len(frames) > 0 {
+			return p.processChannelFrames(ctx, flushed.OrgID, flushed.Channel, frames, map[string]struct{}{flushed.Channel: {}})
+		}
+		return nil
+	}
+	return nil
+}
+
 func (p *Pipeline) ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error) {
 	var span trace.Span
 	if p.tracer != nil {
@@ -391,30 +487,45 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		return nil, err
 	}
 
-	ch, err := live.ParseChannel(channelID)
+	vars, err := channelVars(orgID, channelID)
 	if err != nil {
 		logger.Error("Error parsing channel", "error", err, "channel", channelID)
 		return nil, err
 	}
 
-	vars := Vars{
+	return p.applyRule(ctx, rule, vars, frame, rule.FrameProcessors)
+}
+
+func channelVars(orgID int64, channelID string) (Vars, error) {
+	ch, err := live.ParseChannel(channelID)
+	if err != nil {
+		return Vars{}, err
+	}
+	return Vars{
 		OrgID:     orgID,
 		Channel:   channelID,
 		Scope:     ch.Scope,
 		Namespace: ch.Namespace,
 		Path:      ch.Path,
-	}
+	}, nil
+}
 
-	if len(rule.FrameProcessors) > 0 {
-		for _, proc := range rule.FrameProcessors {
-			frame, err = p.execProcessor(ctx, proc, vars, frame)
-			if err != nil {
-				logger.Error("Error processing frame", "error", err)
-				return nil, err
-			}
-			if frame == nil {
-				return nil, nil
-			}
+// applyRule applies the processors and then the outputs of the rule to the frame.
+func (p *Pipeline) applyRule(ctx context.Context, rule *LiveChannelRule, vars Vars, frame *data.Frame, processors []FrameProcessor) ([]*ChannelFrame, error) {
+	var err error
+	for _, proc := range processors {
+		frame, err = p.execProcessor(ctx, proc, vars, frame)
+		if err != nil {
+			logger.Error("Error processing frame", "error", err)
+			return nil, err
+		}
+		if f, ok := proc.(FlushingFrameProcessor); ok {
+			p.flushMu.Lock()
+			p.flushers[f] = struct{}{}
+			p.flushMu.Unlock()
+		}
+		if frame == nil {
+			return nil, nil
 		}
 	}
 
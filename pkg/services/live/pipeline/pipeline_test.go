@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -100,6 +101,38 @@ func TestPipeline(t *testing.T) {
 	require.NotNil(t, outputter.frame)
 }
 
+func TestPipeline_Flush(t *testing.T) {
+	windowAggregate, err := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{Window: "1s"})
+	require.NoError(t, err)
+	start := time.Unix(100, 0)
+	windowAggregate.now = func() time.Time { return start }
+
+	outputter := &testOutputter{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				Converter: &testConverter{"", data.NewFrame("test",
+					data.NewField("time", nil, []time.Time{start}),
+					data.NewField("value", nil, []float64{5}),
+				)},
+				FrameProcessors: []FrameProcessor{windowAggregate, &testProcessor{}},
+				FrameOutputters: []FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+	ok, err := p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Nil(t, outputter.frame, "window is still open")
+	require.Len(t, p.flushers, 1)
+
+	p.flush(context.Background(), start.Add(time.Second))
+	require.NotNil(t, outputter.frame)
+	require.Equal(t, 5.0, *outputter.frame.Fields[1].At(0).(*float64))
+	require.Empty(t, p.flushers, "processors without state are forgotten")
+}
+
 func TestPipeline_OutputError(t *testing.T) {
 	boomErr := errors.New("boom")
 	outputter := &testOutputter{err: boomErr}
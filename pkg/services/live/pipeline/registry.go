@@ -15,6 +15,22 @@ var SubscribersRegistry = []EntityInfo{
 		Type:        SubscriberTypeManagedStream,
 		Description: "apply managed stream subscribe logic",
 	},
+	{
+		Type:        SubscriberTypeMQTT,
+		Description: "consume channel data from an MQTT topic while channel has subscribers",
+		Example: MQTTSubscriberConfig{
+			Broker: "tcp://localhost:1883",
+			Topic:  "sensors/#",
+		},
+	},
+	{
+		Type:        SubscriberTypeKafka,
+		Description: "consume channel data from a Kafka topic while channel has subscribers",
+		Example: KafkaSubscriberConfig{
+			Brokers: []string{"localhost:9092"},
+			Topic:   "sensors",
+		},
+	},
 }
 
 var FrameOutputsRegistry = []EntityInfo{
@@ -79,6 +95,14 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeWindowAggregate,
+		Description: "aggregate fields over tumbling or sliding time windows",
+		Example: WindowAggregateFrameProcessorConfig{
+			Window:       "1s",
+			Aggregations: []WindowAggregation{WindowAggregationMean, WindowAggregationMax},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
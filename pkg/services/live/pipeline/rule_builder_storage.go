@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/centrifugal/centrifuge"
@@ -17,9 +18,17 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	InputManager         *InputManager
 }
 
-func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
+const (
+	// secureSettingMQTTPassword is the secure setting of a channel rule with the password of its MQTT subscribers.
+	secureSettingMQTTPassword = "mqttPassword"
+	// secureSettingKafkaPassword is the secure setting of a channel rule with the password of its Kafka subscribers.
+	secureSettingKafkaPassword = "kafkaPassword"
+)
+
+func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig, secureSettings map[string][]byte) (Subscriber, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -29,6 +38,35 @@ func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscr
 		return NewBuiltinSubscriber(f.ChannelHandlerGetter), nil
 	case SubscriberTypeManagedStream:
 		return NewManagedStreamSubscriber(f.ManagedStream), nil
+	case SubscriberTypeMQTT:
+		if config.MQTTSubscriberConfig == nil {
+			return nil, missingConfiguration
+		}
+		c := *config.MQTTSubscriberConfig
+		if c.Broker == "" || c.Topic == "" {
+			return nil, errors.New("mqtt subscriber requires broker and topic")
+		}
+		if c.QoS > 2 {
+			return nil, fmt.Errorf("invalid mqtt qos: %d", c.QoS)
+		}
+		password, err := f.decryptSecureSetting(secureSettings, secureSettingMQTTPassword)
+		if err != nil {
+			return nil, err
+		}
+		return NewMQTTSubscriber(f.InputManager, c, password), nil
+	case SubscriberTypeKafka:
+		if config.KafkaSubscriberConfig == nil {
+			return nil, missingConfiguration
+		}
+		c := *config.KafkaSubscriberConfig
+		if len(c.Brokers) == 0 || c.Topic == "" {
+			return nil, errors.New("kafka subscriber requires brokers and topic")
+		}
+		password, err := f.decryptSecureSetting(secureSettings, secureSettingKafkaPassword)
+		if err != nil {
+			return nil, err
+		}
+		return NewKafkaSubscriber(f.InputManager, c, password), nil
 	case SubscriberTypeMultiple:
 		if config.MultipleSubscriberConfig == nil {
 			return nil, missingConfiguration
@@ -36,7 +74,7 @@ func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscr
 		var subscribers []Subscriber
 		for _, outConf := range config.MultipleSubscriberConfig.Subscribers {
 			out := outConf
-			sub, err := f.extractSubscriber(&out)
+			sub, err := f.extractSubscriber(&out, secureSettings)
 			if err != nil {
 				return nil, err
 			}
@@ -90,6 +128,11 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			return nil, missingConfiguration
 		}
 		return NewKeepFieldsFrameProcessor(*config.KeepFieldsProcessorConfig), nil
+	case FrameProcessorTypeWindowAggregate:
+		if config.WindowAggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewWindowAggregateFrameProcessor(*config.WindowAggregateProcessorConfig)
 	case FrameProcessorTypeMultiple:
 		if config.MultipleProcessorConfig == nil {
 			return nil, missingConfiguration
@@ -140,6 +183,22 @@ func (f *StorageRuleBuilder) extractFrameConditionChecker(config *FrameCondition
 	}
 }
 
+// decryptSecureSetting returns the decrypted secure setting of a channel rule, or an empty string
+// when it is not set.
+func (f *StorageRuleBuilder) decryptSecureSetting(secureSettings map[string][]byte, key string) (string, error) {
+	if len(secureSettings[key]) == 0 {
+		return "", nil
+	}
+	if f.SecretsService == nil {
+		return "", fmt.Errorf("%s can't be decrypted: secrets are not available", key)
+	}
+	value, err := f.SecretsService.Decrypt(context.Background(), secureSettings[key])
+	if err != nil {
+		return "", fmt.Errorf("%s can't be decrypted: %w", key, err)
+	}
+	return string(value), nil
+}
+
 func (f *StorageRuleBuilder) constructBasicAuth(writeConfig WriteConfig) (*BasicAuth, error) {
 	if writeConfig.Settings.BasicAuth == nil {
 		return nil, nil
@@ -365,7 +424,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 
 		var subscribers []Subscriber
 		for _, subConfig := range ruleConfig.Settings.Subscribers {
-			sub, err := f.extractSubscriber(subConfig)
+			sub, err := f.extractSubscriber(subConfig, ruleConfig.SecureSettings)
 			if err != nil {
 				return nil, fmt.Errorf("error building subscriber for %s: %w", rule.Pattern, err)
 			}
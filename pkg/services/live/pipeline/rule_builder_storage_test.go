@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+// prefixSecretsService marks encrypted values with a prefix, to check that values are decrypted.
+type prefixSecretsService struct {
+	fakes.FakeSecretsService
+}
+
+func (s prefixSecretsService) EncryptJsonData(_ context.Context, kv map[string]string, _ secrets.EncryptionOptions) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(kv))
+	for key, value := range kv {
+		result[key] = []byte("encrypted:" + value)
+	}
+	return result, nil
+}
+
+func (s prefixSecretsService) Decrypt(_ context.Context, payload []byte) ([]byte, error) {
+	value, ok := strings.CutPrefix(string(payload), "encrypted:")
+	if !ok {
+		return nil, errors.New("not encrypted")
+	}
+	return []byte(value), nil
+}
+
+func TestStorageRuleBuilder_SubscriberPasswords(t *testing.T) {
+	dataPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "pipeline"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dataPath, "pipeline", "live-channel-rules.json"), []byte(`{"rules": []}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dataPath, "pipeline", "write-configs.json"), []byte(`{"writeConfigs": []}`), 0o600))
+
+	secretsService := prefixSecretsService{}
+	storage := &FileStorage{DataPath: dataPath, SecretsService: secretsService}
+
+	rule, err := storage.CreateChannelRule(context.Background(), 1, ChannelRuleCreateCmd{
+		Pattern: "stream/sensors/temperature",
+		Settings: ChannelRuleSettings{
+			Subscribers: []*SubscriberConfig{
+				{Type: SubscriberTypeMQTT, MQTTSubscriberConfig: &MQTTSubscriberConfig{Broker: "tcp://localhost:1883", Topic: "sensors", Username: "grafana"}},
+				{Type: SubscriberTypeKafka, KafkaSubscriberConfig: &KafkaSubscriberConfig{Brokers: []string{"localhost:9092"}, Topic: "sensors", Username: "grafana"}},
+			},
+		},
+		SecureSettings: map[string]string{
+			secureSettingMQTTPassword:  "mqtt-secret",
+			secureSettingKafkaPassword: "kafka-secret",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{secureSettingMQTTPassword: true, secureSettingKafkaPassword: true}, ChannelRuleToDto(rule).SecureFields)
+
+	builder := &StorageRuleBuilder{Storage: storage, SecretsService: secretsService}
+	rules, err := builder.BuildRules(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Len(t, rules[0].Subscribers, 2)
+
+	mqttSubscriber, ok := rules[0].Subscribers[0].(*MQTTSubscriber)
+	require.True(t, ok)
+	require.Equal(t, "mqtt-secret", mqttSubscriber.password)
+	kafkaSubscriber, ok := rules[0].Subscribers[1].(*KafkaSubscriber)
+	require.True(t, ok)
+	require.Equal(t, "kafka-secret", kafkaSubscriber.password)
+
+	t.Run("secure settings that can't be decrypted fail the rule", func(t *testing.T) {
+		_, err := builder.extractSubscriber(&SubscriberConfig{
+			Type:                 SubscriberTypeMQTT,
+			MQTTSubscriberConfig: &MQTTSubscriberConfig{Broker: "tcp://localhost:1883", Topic: "sensors"},
+		}, map[string][]byte{secureSettingMQTTPassword: []byte("plain")})
+		require.ErrorContains(t, err, "mqttPassword can't be decrypted")
+	})
+}
@@ -158,16 +158,22 @@ func (f *FileStorage) ListChannelRules(_ context.Context, orgID int64) ([]Channe
 	return rules, nil
 }
 
-func (f *FileStorage) CreateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+func (f *FileStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
 	channelRules, err := f.readRules()
 	if err != nil {
 		return ChannelRule{}, fmt.Errorf("can't read channel rules: %w", err)
 	}
 
+	secureSettings, err := f.SecretsService.EncryptJsonData(ctx, cmd.SecureSettings, secrets.WithoutScope())
+	if err != nil {
+		return ChannelRule{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+
 	rule := ChannelRule{
-		OrgId:    orgID,
-		Pattern:  cmd.Pattern,
-		Settings: cmd.Settings,
+		OrgId:          orgID,
+		Pattern:        cmd.Pattern,
+		Settings:       cmd.Settings,
+		SecureSettings: secureSettings,
 	}
 
 	ok, reason := rule.Valid()
@@ -198,10 +204,16 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 		return ChannelRule{}, fmt.Errorf("can't read channel rules: %w", err)
 	}
 
+	secureSettings, err := f.SecretsService.EncryptJsonData(ctx, cmd.SecureSettings, secrets.WithoutScope())
+	if err != nil {
+		return ChannelRule{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+
 	rule := ChannelRule{
-		OrgId:    orgID,
-		Pattern:  cmd.Pattern,
-		Settings: cmd.Settings,
+		OrgId:          orgID,
+		Pattern:        cmd.Pattern,
+		Settings:       cmd.Settings,
+		SecureSettings: secureSettings,
 	}
 
 	ok, reason := rule.Valid()
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+// KafkaSubscriber starts consuming a Kafka topic into the channel when
+// the channel is subscribed. Records are processed by the channel rule
+// (converter, processors and outputs) like data pushed over HTTP or WebSocket.
+type KafkaSubscriber struct {
+	inputManager *InputManager
+	config       KafkaSubscriberConfig
+	password     string
+}
+
+const SubscriberTypeKafka = "kafka"
+
+// NewKafkaSubscriber returns a subscriber of the topic of the config. The password is the
+// decrypted kafkaPassword secure setting of the channel rule.
+func NewKafkaSubscriber(inputManager *InputManager, config KafkaSubscriberConfig, password string) *KafkaSubscriber {
+	return &KafkaSubscriber{inputManager: inputManager, config: config, password: password}
+}
+
+func (s *KafkaSubscriber) Type() string {
+	return SubscriberTypeKafka
+}
+
+func (s *KafkaSubscriber) Subscribe(_ context.Context, vars Vars, _ []byte) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if s.inputManager == nil {
+		return model.SubscribeReply{}, 0, errors.New("inputs are not available")
+	}
+	s.inputManager.Start(vars.OrgID, vars.Channel, &kafkaInputSource{config: s.config, password: s.password})
+	return model.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+type kafkaInputSource struct {
+	config   KafkaSubscriberConfig
+	password string
+}
+
+func (s *kafkaInputSource) clientOptions() []kgo.Opt {
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(s.config.Brokers...),
+		kgo.ConsumeTopics(s.config.Topic),
+		// Live channels show new data only.
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtEnd()),
+	}
+	if s.config.GroupID != "" {
+		opts = append(opts, kgo.ConsumerGroup(s.config.GroupID))
+	}
+	if s.config.TLS {
+		opts = append(opts, kgo.DialTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}))
+	}
+	if s.config.Username != "" {
+		opts = append(opts, kgo.SASL(plain.Auth{
+			User: s.config.Username,
+			Pass: s.password,
+		}.AsMechanism()))
+	}
+	return opts
+}
+
+// Run consumes the topic until the context is done or fetching fails. Fetching
+// pauses while the buffer is full, so records are not lost when processing is slow.
+func (s *kafkaInputSource) Run(ctx context.Context, buffer *InputBuffer) error {
+	client, err := kgo.NewClient(s.clientOptions()...)
+	if err != nil {
+		return fmt.Errorf("error creating kafka client: %w", err)
+	}
+	defer client.Close()
+
+	for {
+		fetches := client.PollFetches(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if fetches.IsClientClosed() {
+			return errors.New("kafka client closed")
+		}
+		if errs := fetches.Errors(); len(errs) > 0 {
+			return fmt.Errorf("error fetching from %s: %w", errs[0].Topic, errs[0].Err)
+		}
+		iter := fetches.RecordIter()
+		for !iter.Done() {
+			if err := buffer.Write(ctx, iter.Next().Value); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MQTTSubscriber starts consuming an MQTT topic into the channel when
+// the channel is subscribed. Messages are processed by the channel rule
+// (converter, processors and outputs) like data pushed over HTTP or WebSocket.
+type MQTTSubscriber struct {
+	inputManager *InputManager
+	config       MQTTSubscriberConfig
+	password     string
+}
+
+const SubscriberTypeMQTT = "mqtt"
+
+const mqttConnectTimeout = 10 * time.Second
+
+// NewMQTTSubscriber returns a subscriber of the topic of the config. The password is the
+// decrypted mqttPassword secure setting of the channel rule.
+func NewMQTTSubscriber(inputManager *InputManager, config MQTTSubscriberConfig, password string) *MQTTSubscriber {
+	return &MQTTSubscriber{inputManager: inputManager, config: config, password: password}
+}
+
+func (s *MQTTSubscriber) Type() string {
+	return SubscriberTypeMQTT
+}
+
+func (s *MQTTSubscriber) Subscribe(_ context.Context, vars Vars, _ []byte) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if s.inputManager == nil {
+		return model.SubscribeReply{}, 0, errors.New("inputs are not available")
+	}
+	s.inputManager.Start(vars.OrgID, vars.Channel, &mqttInputSource{config: s.config, password: s.password})
+	return model.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+type mqttInputSource struct {
+	config   MQTTSubscriberConfig
+	password string
+}
+
+// Run consumes the topic until the context is done or the connection is lost.
+// Messages are dropped when the buffer is full since blocking the MQTT client
+// would make it miss keepalives.
+func (s *mqttInputSource) Run(ctx context.Context, buffer *InputBuffer) error {
+	clientID := s.config.ClientID
+	if clientID == "" {
+		clientID = "grafana-live-" + util.GenerateShortUID()
+	}
+	connectionLost := make(chan error, 1)
+	opts := mqtt.NewClientOptions().
+		AddBroker(s.config.Broker).
+		SetClientID(clientID).
+		SetUsername(s.config.Username).
+		SetPassword(s.password).
+		SetConnectTimeout(mqttConnectTimeout).
+		// Reconnects are handled by InputManager.
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			select {
+			case connectionLost <- err:
+			default:
+			}
+		})
+
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("error connecting to %s: %w", s.config.Broker, err)
+	}
+	defer client.Disconnect(250)
+
+	token := client.Subscribe(s.config.Topic, s.config.QoS, func(_ mqtt.Client, message mqtt.Message) {
+		buffer.TryWrite(message.Payload())
+	})
+	if err := waitMQTTToken(ctx, token); err != nil {
+		return fmt.Errorf("error subscribing to %s: %w", s.config.Topic, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-connectionLost:
+		return fmt.Errorf("connection lost: %w", err)
+	}
+}
+
+func waitMQTTToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-token.Done():
+		return token.Error()
+	}
+}
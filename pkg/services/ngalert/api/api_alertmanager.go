@@ -211,6 +211,24 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *contextmodel.ReqContext, b
 	return response.ErrOrFallback(http.StatusInternalServerError, err.Error(), err)
 }
 
+// RoutePostAlertingConfigDryRun validates the configuration and compares it with the current one without applying it.
+// Validation errors, including changes to provisioned resources, are returned in the result rather than as an error.
+func (srv AlertmanagerSrv) RoutePostAlertingConfigDryRun(c *contextmodel.ReqContext, body apimodels.PostableUserConfig) response.Response {
+	notifier.RemoveAutogenConfigIfExists(body.AlertmanagerConfig.Route)
+	result, err := srv.mam.DryRunAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID(), body)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to validate configuration")
+	}
+	currentConfig, err := srv.mam.GetAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID(), false)
+	if err == nil {
+		if err := srv.provenanceGuard(currentConfig, body); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			result.Valid = false
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 func (srv AlertmanagerSrv) RouteGetReceivers(c *contextmodel.ReqContext) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
 	if errResp != nil {
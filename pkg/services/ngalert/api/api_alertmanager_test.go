@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -325,6 +326,74 @@ func TestAlertmanagerConfig(t *testing.T) {
 	})
 }
 
+func TestRoutePostAlertingConfigDryRun(t *testing.T) {
+	asDryRunResult := func(t *testing.T, r response.Response) apimodels.AlertingConfigDryRunResult {
+		t.Helper()
+		require.Equal(t, http.StatusOK, r.Status())
+		var result apimodels.AlertingConfigDryRunResult
+		require.NoError(t, json.Unmarshal(r.Body(), &result))
+		return result
+	}
+
+	t.Run("valid config is compared with the current one and not applied", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createRequestCtxInOrg(1)
+		before := sut.RouteGetAlertingConfig(rc).Body()
+
+		request := createAmConfigRequest(t, `{
+			"template_files": {"b": "{{ define \"b\" }}b{{ end }}"},
+			"alertmanager_config": {
+				"route": {
+					"receiver": "grafana-default-email",
+					"routes": [{"receiver": "slack", "object_matchers": [["team", "=", "a"], ["team", "=", "b"]]}]
+				},
+				"receivers": [
+					{"name": "grafana-default-email", "grafana_managed_receiver_configs": [{"uid": "", "name": "email receiver", "type": "email", "settings": {"addresses": "<example@email.com>"}}]},
+					{"name": "slack", "grafana_managed_receiver_configs": [{"uid": "", "name": "slack", "type": "slack", "settings": {"recipient": "#alerts"}, "secureSettings": {"token": "secret"}}]}
+				]
+			}
+		}`)
+
+		result := asDryRunResult(t, sut.RoutePostAlertingConfigDryRun(rc, request))
+
+		require.True(t, result.Valid, "errors: %v", result.Errors)
+		require.Equal(t, apimodels.ConfigResourceDiff{Added: []string{"slack"}}, result.Diff.Receivers)
+		require.Equal(t, apimodels.ConfigResourceDiff{Added: []string{"/0"}}, result.Diff.Routes)
+		require.Equal(t, apimodels.ConfigResourceDiff{Added: []string{"b"}, Removed: []string{"a"}}, result.Diff.Templates)
+		require.Equal(t, []string{`route /0 can never match: matchers team="a" and team="b" conflict`}, result.Warnings)
+		require.Equal(t, before, sut.RouteGetAlertingConfig(rc).Body())
+	})
+
+	t.Run("invalid receiver settings are reported", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createRequestCtxInOrg(1)
+		request := createAmConfigRequest(t, `{
+			"alertmanager_config": {
+				"route": {"receiver": "slack"},
+				"receivers": [{"name": "slack", "grafana_managed_receiver_configs": [{"uid": "", "name": "slack", "type": "slack", "settings": {"recipient": "#alerts"}}]}]
+			}
+		}`)
+
+		result := asDryRunResult(t, sut.RoutePostAlertingConfigDryRun(rc, request))
+
+		require.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		require.Contains(t, result.Errors[0], `invalid receiver "slack"`)
+	})
+
+	t.Run("changes to provisioned resources are reported", func(t *testing.T) {
+		sut := createSut(t)
+		rc := createRequestCtxInOrg(1)
+		setRouteProvenance(t, 1, sut.mam.ProvStore)
+		request := createAmConfigRequest(t, strings.ReplaceAll(validConfig, `"receiver": "grafana-default-email"`, `"receiver": "grafana-default-email", "group_by": ["alertname"]`))
+
+		result := asDryRunResult(t, sut.RoutePostAlertingConfigDryRun(rc, request))
+
+		require.False(t, result.Valid)
+		require.Equal(t, apimodels.ConfigResourceDiff{Changed: []string{"/"}}, result.Diff.Routes)
+	})
+}
+
 func TestAlertmanagerAutogenConfig(t *testing.T) {
 	createSutForAutogen := func(t *testing.T) (AlertmanagerSrv, map[int64]*ngmodels.AlertConfiguration) {
 		sut := createSut(t)
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 
@@ -25,10 +27,16 @@ import (
 )
 
 type PrometheusSrv struct {
-	log     log.Logger
-	manager state.AlertInstanceManager
-	store   RuleStore
-	authz   RuleAccessControlService
+	log          log.Logger
+	manager      state.AlertInstanceManager
+	acknowledger AlertInstanceAcknowledger
+	store        RuleStore
+	authz        RuleAccessControlService
+}
+
+// AlertInstanceAcknowledger sets and removes the acknowledgements of alert instances.
+type AlertInstanceAcknowledger interface {
+	AcknowledgeState(ctx context.Context, orgID int64, alertRuleUID string, lbs data.Labels, ack *ngmodels.AlertInstanceAcknowledgement) (*state.State, error)
 }
 
 const queryIncludeInternalLabels = "includeInternalLabels"
@@ -102,15 +110,107 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 
 			// TODO: or should we make this two fields? Using one field lets the
 			// frontend use the same logic for parsing text on annotations and this.
-			State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-			ActiveAt: &startsAt,
-			Value:    valString,
+			State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+			ActiveAt:        &startsAt,
+			Value:           valString,
+			Acknowledgement: toAlertAcknowledgement(alertState.Acknowledgement),
 		})
 	}
 
 	return alertResponse
 }
 
+func toAlertAcknowledgement(ack *ngmodels.AlertInstanceAcknowledgement) *apimodels.AlertAcknowledgement {
+	if ack == nil {
+		return nil
+	}
+	return &apimodels.AlertAcknowledgement{
+		By:                          ack.By,
+		At:                          ack.At,
+		Note:                        ack.Note,
+		SuppressRepeatNotifications: ack.SuppressRepeatNotifications,
+	}
+}
+
+// RoutePostAlertAcknowledgement records that the signed in user has taken ownership of a firing alert instance.
+func (srv PrometheusSrv) RoutePostAlertAcknowledgement(c *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement) response.Response {
+	ack := &ngmodels.AlertInstanceAcknowledgement{
+		By:                          c.SignedInUser.GetLogin(),
+		At:                          time.Now(),
+		Note:                        body.Note,
+		SuppressRepeatNotifications: body.SuppressRepeatNotifications,
+	}
+	return srv.setAlertAcknowledgement(c, body, ack)
+}
+
+// RouteDeleteAlertAcknowledgement removes the acknowledgement of an alert instance. The labels of the instance are
+// read from the "label" query parameters, as name=value pairs.
+func (srv PrometheusSrv) RouteDeleteAlertAcknowledgement(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	lbs, err := getLabelsFromQuery(c.Req.URL.Query())
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return srv.setAlertAcknowledgement(c, apimodels.PostableAlertAcknowledgement{RuleUID: ruleUID, Labels: lbs}, nil)
+}
+
+func getLabelsFromQuery(v url.Values) (map[string]string, error) {
+	lbs := make(map[string]string, len(v["label"]))
+	for _, s := range v["label"] {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("bad label %q: expected name=value", s)
+		}
+		lbs[name] = value
+	}
+	return lbs, nil
+}
+
+func (srv PrometheusSrv) setAlertAcknowledgement(c *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement, ack *ngmodels.AlertInstanceAcknowledgement) response.Response {
+	if body.RuleUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("rule UID is required"), "")
+	}
+	if err := srv.authorizeRuleAccess(c, body.RuleUID); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return errorToResponse(err)
+	}
+
+	alertState, err := srv.acknowledger.AcknowledgeState(c.Req.Context(), c.SignedInUser.GetOrgID(), body.RuleUID, body.Labels, ack)
+	if err != nil {
+		return errorToResponse(err)
+	}
+
+	startsAt := alertState.StartsAt
+	valString := ""
+	if alertState.State == eval.Alerting {
+		valString = formatValues(alertState)
+	}
+	return response.JSON(http.StatusOK, apimodels.Alert{
+		Labels:          alertState.GetLabels(ngmodels.WithoutInternalLabels()),
+		Annotations:     alertState.Annotations,
+		State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+		ActiveAt:        &startsAt,
+		Value:           valString,
+		Acknowledgement: toAlertAcknowledgement(alertState.Acknowledgement),
+	})
+}
+
+// authorizeRuleAccess checks that the user can access the group of the rule with the given UID.
+func (srv PrometheusSrv) authorizeRuleAccess(c *contextmodel.ReqContext, ruleUID string) error {
+	rules, err := srv.store.GetAlertRulesGroupByRuleUID(c.Req.Context(), &ngmodels.GetAlertRulesGroupByRuleUIDQuery{
+		UID:   ruleUID,
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return ngmodels.ErrAlertRuleNotFound
+	}
+	return srv.authz.AuthorizeAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules)
+}
+
 func formatValues(alertState *state.State) string {
 	var fv string
 	values := alertState.GetLastEvaluationValuesForCondition()
@@ -449,9 +549,10 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 
 				// TODO: or should we make this two fields? Using one field lets the
 				// frontend use the same logic for parsing text on annotations and this.
-				State:    state.FormatStateAndReason(alertState.State, alertState.StateReason),
-				ActiveAt: &activeAt,
-				Value:    valString,
+				State:           state.FormatStateAndReason(alertState.State, alertState.StateReason),
+				ActiveAt:        &activeAt,
+				Value:           valString,
+				Acknowledgement: toAlertAcknowledgement(alertState.Acknowledgement),
 			}
 
 			if alertState.LastEvaluationTime.After(newRule.LastEvaluation) {
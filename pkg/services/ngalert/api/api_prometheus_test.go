@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -1376,3 +1377,76 @@ func withExpressionsMultiQuery() ngmodels.AlertRuleMutator {
 		r.Data = queries
 	}
 }
+
+func TestRouteAlertAcknowledgement(t *testing.T) {
+	orgID := int64(1)
+	ruleStore := fakes.NewRuleStore(t)
+	rule := ngmodels.RuleGen.With(ngmodels.RuleGen.WithOrgID(orgID)).GenerateRef()
+	ruleStore.PutRule(context.Background(), rule)
+
+	manager := state.NewManager(state.ManagerCfg{Log: log.NewNopLogger()}, state.NewNoopPersister())
+	manager.Put([]*state.State{{
+		OrgID:        orgID,
+		AlertRuleUID: rule.UID,
+		CacheID:      "firing",
+		State:        eval.Alerting,
+		Labels:       data.Labels{"instance": "a", alertingModels.RuleUIDLabel: rule.UID},
+	}})
+
+	api := PrometheusSrv{
+		log:          log.NewNopLogger(),
+		manager:      manager,
+		acknowledger: manager,
+		store:        ruleStore,
+		authz:        &fakeRuleAccessControlService{},
+	}
+	c := createRequestContext(orgID, nil)
+	c.SignedInUser.Login = "admin"
+
+	t.Run("acknowledges the alert instance", func(t *testing.T) {
+		body := apimodels.PostableAlertAcknowledgement{
+			RuleUID:                     rule.UID,
+			Labels:                      map[string]string{"instance": "a"},
+			Note:                        "on it",
+			SuppressRepeatNotifications: true,
+		}
+		resp := api.RoutePostAlertAcknowledgement(c, body)
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		result := apimodels.Alert{}
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.NotNil(t, result.Acknowledgement)
+		require.Equal(t, "admin", result.Acknowledgement.By)
+		require.Equal(t, "on it", result.Acknowledgement.Note)
+		require.True(t, result.Acknowledgement.SuppressRepeatNotifications)
+
+		alerts := PrepareAlertStatuses(manager, AlertStatusesOptions{OrgID: orgID})
+		require.Len(t, alerts.Data.Alerts, 1)
+		require.Equal(t, result.Acknowledgement.By, alerts.Data.Alerts[0].Acknowledgement.By)
+	})
+
+	t.Run("removes the acknowledgement", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+		c.Req.URL.RawQuery = url.Values{"label": {"instance=a"}}.Encode()
+		resp := api.RouteDeleteAlertAcknowledgement(c, rule.UID)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Nil(t, manager.Get(orgID, rule.UID, "firing").Acknowledgement)
+	})
+
+	t.Run("returns 400 if a label is not a name=value pair", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+		c.Req.URL.RawQuery = url.Values{"label": {"instance"}}.Encode()
+		resp := api.RouteDeleteAlertAcknowledgement(c, rule.UID)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("returns 404 if the rule does not exist", func(t *testing.T) {
+		resp := api.RoutePostAlertAcknowledgement(c, apimodels.PostableAlertAcknowledgement{RuleUID: "unknown"})
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("returns 404 if the alert instance does not exist", func(t *testing.T) {
+		resp := api.RoutePostAlertAcknowledgement(c, apimodels.PostableAlertAcknowledgement{RuleUID: rule.UID, Labels: map[string]string{"instance": "b"}})
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+}
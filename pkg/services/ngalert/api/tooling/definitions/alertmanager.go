@@ -1,10 +1,14 @@
 package definitions
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/strfmt"
@@ -57,6 +61,14 @@ import (
 //     Responses:
 //       200: GettableHistoricUserConfigs
 
+// swagger:route POST /alertmanager/grafana/config/api/v1/alerts/_dry_run alertmanager RoutePostGrafanaAlertingConfigDryRun
+//
+// validates an Alerting config and compares it with the current one without applying it
+//
+//     Responses:
+//       200: AlertingConfigDryRunResult
+//       400: ValidationError
+
 // swagger:route POST /alertmanager/grafana/config/history/{id}/_activate alertmanager RoutePostGrafanaAlertingConfigHistoryActivate
 //
 // revert Alerting configuration to the historical configuration specified by the given id
@@ -541,12 +553,48 @@ type PostableAlerts struct {
 	PostableAlerts []amv2.PostableAlert `yaml:"" json:""`
 }
 
-// swagger:parameters RoutePostAlertingConfig RoutePostGrafanaAlertingConfig
+// swagger:parameters RoutePostAlertingConfig RoutePostGrafanaAlertingConfig RoutePostGrafanaAlertingConfigDryRun
 type BodyAlertingConfig struct {
 	// in:body
 	Body PostableUserConfig
 }
 
+// AlertingConfigDryRunResult is the result of validating an Alerting config without applying it.
+// swagger:model
+type AlertingConfigDryRunResult struct {
+	// Valid is true if the config can be applied.
+	Valid bool `json:"valid"`
+	// Errors are the reasons why the config cannot be applied.
+	Errors []string `json:"errors,omitempty"`
+	// Warnings are the problems of the config that do not prevent it from being applied,
+	// such as routes that can never match.
+	Warnings []string `json:"warnings,omitempty"`
+	// Diff is the difference between the current config and the validated one.
+	Diff AlertingConfigDiff `json:"diff"`
+}
+
+// AlertingConfigDiff is the difference between two Alerting configs.
+type AlertingConfigDiff struct {
+	Receivers     ConfigResourceDiff `json:"receivers"`
+	Routes        ConfigResourceDiff `json:"routes"`
+	TimeIntervals ConfigResourceDiff `json:"timeIntervals"`
+	Templates     ConfigResourceDiff `json:"templates"`
+}
+
+// ConfigResourceDiff lists the resources of a kind that were added, removed or changed.
+// Receivers, time intervals and templates are identified by name, routes by their path in the routing tree,
+// such as "/" for the root route and "/0/1" for the second child of its first child.
+type ConfigResourceDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// IsEmpty returns true if no resource was added, removed or changed.
+func (d ConfigResourceDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
 // swagger:parameters RoutePostGrafanaAlertingConfigHistoryActivate
 type HistoricalConfigId struct {
 	// Id should be the id of the GettableHistoricUserConfig
@@ -572,7 +620,11 @@ type DatasourceUIDReference struct {
 type PostableUserConfig struct {
 	TemplateFiles      map[string]string         `yaml:"template_files" json:"template_files"`
 	AlertmanagerConfig PostableApiAlertingConfig `yaml:"alertmanager_config" json:"alertmanager_config"`
-	amSimple           map[string]interface{}    `yaml:"-" json:"-"`
+	// RouteFallbackReceivers are the fallback receivers set with the fallback_receiver field of the routes, keyed by
+	// the position of the route in the tree. The route type is shared with the Alertmanager and has no such field,
+	// so they are read from the routes when the configuration is decoded, and written back when it is encoded.
+	RouteFallbackReceivers map[string]string      `yaml:"-" json:"-"`
+	amSimple               map[string]interface{} `yaml:"-" json:"-"`
 }
 
 func (c *PostableUserConfig) UnmarshalJSON(b []byte) error {
@@ -581,11 +633,6 @@ func (c *PostableUserConfig) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	// validate first
-	if err := c.validate(); err != nil {
-		return err
-	}
-
 	type intermediate struct {
 		AlertmanagerConfig map[string]interface{} `yaml:"alertmanager_config" json:"alertmanager_config"`
 	}
@@ -594,12 +641,36 @@ func (c *PostableUserConfig) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &tmp); err != nil {
 		return err
 	}
+	c.RouteFallbackReceivers = decodeRouteFallbackReceivers(tmp.AlertmanagerConfig)
+
+	if err := c.validate(); err != nil {
+		return err
+	}
+
 	// store the map[string]interface{} variant for re-encoding later without redaction
 	c.amSimple = tmp.AlertmanagerConfig
 
 	return nil
 }
 
+// MarshalJSON implements json.Marshaler. It writes the fallback receivers back to their routes.
+func (c PostableUserConfig) MarshalJSON() ([]byte, error) {
+	type plain PostableUserConfig
+	b, err := json.Marshal(plain(c))
+	if err != nil || len(c.RouteFallbackReceivers) == 0 {
+		return b, err
+	}
+
+	var tmp map[string]json.RawMessage
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return nil, err
+	}
+	if tmp["alertmanager_config"], err = encodeRouteFallbackReceivers(tmp["alertmanager_config"], c.RouteFallbackReceivers); err != nil {
+		return nil, err
+	}
+	return json.Marshal(tmp)
+}
+
 func (c *PostableUserConfig) validate() error {
 	// Taken from https://github.com/prometheus/alertmanager/blob/master/config/config.go#L170-L191
 	// Check if we have a root route. We cannot check for it in the
@@ -614,9 +685,126 @@ func (c *PostableUserConfig) validate() error {
 		return fmt.Errorf("cannot have continue in root route")
 	}
 
+	return ValidateRouteFallbackReceivers(c.AlertmanagerConfig.Route, c.RouteFallbackReceivers, c.AlertmanagerConfig.Receivers)
+}
+
+// routeFallbackReceiverField is the field of a route that sets the receiver that re-delivers the notifications of
+// the route when all integrations of its receiver fail.
+const routeFallbackReceiverField = "fallback_receiver"
+
+// RoutePath returns the position of the i-th child of the route at the given position. The root route is at the
+// empty position, and child routes are at the position of their parent followed by their index, like 0.2 for the
+// third child of the first child of the root route.
+func RoutePath(parent string, i int) string {
+	if parent == "" {
+		return strconv.Itoa(i)
+	}
+	return parent + "." + strconv.Itoa(i)
+}
+
+// RouteAt returns the route at the given position in the tree, or nil if there is none.
+func RouteAt(root *Route, path string) *Route {
+	if path == "" {
+		return root
+	}
+	r := root
+	for _, part := range strings.Split(path, ".") {
+		i, err := strconv.Atoi(part)
+		if r == nil || err != nil || i < 0 || i >= len(r.Routes) {
+			return nil
+		}
+		r = r.Routes[i]
+	}
+	return r
+}
+
+// ValidateRouteFallbackReceivers checks that the fallback receivers of the routes are defined, and that
+// routes are not their own fallback.
+func ValidateRouteFallbackReceivers(root *Route, fallbacks map[string]string, receivers []*PostableApiReceiver) error {
+	if len(fallbacks) == 0 {
+		return nil
+	}
+	names := make(map[string]struct{}, len(receivers))
+	for _, r := range receivers {
+		names[r.Name] = struct{}{}
+	}
+	paths := make([]string, 0, len(fallbacks))
+	for path := range fallbacks {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fallback := fallbacks[path]
+		route := RouteAt(root, path)
+		if route == nil {
+			return fmt.Errorf("fallback receiver %q is set on route %q, which does not exist", fallback, path)
+		}
+		if _, ok := names[fallback]; !ok {
+			return fmt.Errorf("fallback receiver %q of route %q is undefined", fallback, path)
+		}
+		if fallback == route.Receiver {
+			return fmt.Errorf("receiver %q of route %q cannot be its fallback receiver", fallback, path)
+		}
+	}
 	return nil
 }
 
+// walkRouteFields calls fn for the decoded route and all of its child routes, with their positions in the tree.
+func walkRouteFields(route interface{}, path string, fn func(path string, route map[string]interface{})) {
+	r, ok := route.(map[string]interface{})
+	if !ok {
+		return
+	}
+	fn(path, r)
+	children, _ := r["routes"].([]interface{})
+	for i, child := range children {
+		walkRouteFields(child, RoutePath(path, i), fn)
+	}
+}
+
+// decodeRouteFallbackReceivers returns the fallback receivers set on the routes of a decoded Alertmanager configuration.
+func decodeRouteFallbackReceivers(amConfig map[string]interface{}) map[string]string {
+	var fallbacks map[string]string
+	walkRouteFields(amConfig["route"], "", func(path string, route map[string]interface{}) {
+		if fallback, ok := route[routeFallbackReceiverField].(string); ok && fallback != "" {
+			if fallbacks == nil {
+				fallbacks = make(map[string]string)
+			}
+			fallbacks[path] = fallback
+		}
+	})
+	return fallbacks
+}
+
+// encodeRouteFallbackReceivers sets the fallback receivers on the routes of an encoded Alertmanager configuration.
+func encodeRouteFallbackReceivers(amConfig json.RawMessage, fallbacks map[string]string) (json.RawMessage, error) {
+	var tmp map[string]json.RawMessage
+	if err := json.Unmarshal(amConfig, &tmp); err != nil {
+		return nil, err
+	}
+	if len(tmp["route"]) == 0 {
+		return amConfig, nil
+	}
+	// Numbers are kept as they are, rather than converted to float64 and back.
+	dec := json.NewDecoder(bytes.NewReader(tmp["route"]))
+	dec.UseNumber()
+	var route interface{}
+	if err := dec.Decode(&route); err != nil {
+		return nil, err
+	}
+	walkRouteFields(route, "", func(path string, route map[string]interface{}) {
+		if fallback, ok := fallbacks[path]; ok {
+			route[routeFallbackReceiverField] = fallback
+		}
+	})
+	b, err := json.Marshal(route)
+	if err != nil {
+		return nil, err
+	}
+	tmp["route"] = b
+	return json.Marshal(tmp)
+}
+
 // Decrypt returns a copy of the configuration struct with decrypted secure settings in receivers.
 func (c *PostableUserConfig) Decrypt(decryptFn func(payload []byte) ([]byte, error)) (PostableUserConfig, error) {
 	newCfg, ok := deepcopy.Copy(c).(*PostableUserConfig)
@@ -694,6 +882,12 @@ func (c *PostableUserConfig) UnmarshalYAML(value *yaml.Node) error {
 		return err
 	}
 
+	var amSimple map[string]interface{}
+	if err := yaml.Unmarshal([]byte(tmp.AlertmanagerConfig), &amSimple); err != nil {
+		return err
+	}
+	c.RouteFallbackReceivers = decodeRouteFallbackReceivers(amSimple)
+
 	c.TemplateFiles = tmp.TemplateFiles
 	return nil
 }
@@ -730,6 +924,7 @@ func (c *GettableUserConfig) UnmarshalYAML(value *yaml.Node) error {
 	if err := yaml.Unmarshal([]byte(tmp.AlertmanagerConfig), &c.amSimple); err != nil {
 		return err
 	}
+	c.AlertmanagerConfig.RouteFallbackReceivers = decodeRouteFallbackReceivers(c.amSimple)
 
 	c.TemplateFiles = tmp.TemplateFiles
 	return nil
@@ -783,6 +978,18 @@ type GettableApiAlertingConfig struct {
 	MuteTimeProvenances map[string]Provenance `yaml:"muteTimeProvenances,omitempty" json:"muteTimeProvenances,omitempty"`
 	// Override with our superset receiver type
 	Receivers []*GettableApiReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+	// RouteFallbackReceivers are the fallback receivers of the routes, keyed by the position of the route in the tree.
+	RouteFallbackReceivers map[string]string `yaml:"-" json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler. It writes the fallback receivers to their routes.
+func (c GettableApiAlertingConfig) MarshalJSON() ([]byte, error) {
+	type plain GettableApiAlertingConfig
+	b, err := json.Marshal(plain(c))
+	if err != nil || len(c.RouteFallbackReceivers) == 0 {
+		return b, err
+	}
+	return encodeRouteFallbackReceivers(b, c.RouteFallbackReceivers)
 }
 
 func (c *GettableApiAlertingConfig) GetReceivers() []*GettableApiReceiver {
@@ -816,6 +1023,12 @@ func (c *GettableApiAlertingConfig) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
+	var tmp map[string]interface{}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	c.RouteFallbackReceivers = decodeRouteFallbackReceivers(tmp)
+
 	return c.validate()
 }
 
@@ -858,6 +1071,37 @@ type GettableGrafanaReceiver struct {
 	Settings              RawMessage      `json:"settings,omitempty"`
 	SecureFields          map[string]bool `json:"secureFields"`
 	Provenance            Provenance      `json:"provenance,omitempty"`
+	// Status is the runtime status of the integration in the Alertmanager. It is only set by the receivers API.
+	Status *IntegrationStatus `json:"status,omitempty"`
+}
+
+// IntegrationStatus describes how the notification limits of an integration have affected its notifications,
+// and the result of its last health check.
+type IntegrationStatus struct {
+	// SuppressedNotifications is the number of notifications that were not sent as-is because a limit was exceeded.
+	SuppressedNotifications int64 `json:"suppressedNotifications"`
+	// SuppressedAlerts is the number of alerts that were collapsed into a digest message.
+	SuppressedAlerts int64 `json:"suppressedAlerts"`
+	// DigestsSent is the number of digest messages sent in place of suppressed notifications.
+	DigestsSent int64 `json:"digestsSent"`
+	// PendingDigestAlerts is the number of alerts waiting to be sent in the next digest message.
+	PendingDigestAlerts int `json:"pendingDigestAlerts"`
+	// LastSuppressedAt is the last time a notification was suppressed.
+	LastSuppressedAt *time.Time `json:"lastSuppressedAt,omitempty"`
+	// HealthCheck is the result of the last health check of the integration, if health checks are enabled.
+	HealthCheck *IntegrationHealthCheckStatus `json:"healthCheck,omitempty"`
+}
+
+// IntegrationHealthCheckStatus is the result of the last health check of an integration.
+type IntegrationHealthCheckStatus struct {
+	// LastCheckAt is the time of the last health check.
+	LastCheckAt time.Time `json:"lastCheckAt"`
+	// Healthy is true if the last health check succeeded.
+	Healthy bool `json:"healthy"`
+	// Error is the error of the last health check, if it failed.
+	Error string `json:"error,omitempty"`
+	// ConsecutiveFailures is the number of health checks that failed since the last successful one.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
 }
 
 type GettableApiReceiver struct {
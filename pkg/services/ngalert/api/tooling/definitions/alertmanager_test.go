@@ -215,3 +215,89 @@ func Test_RawMessageMarshaling(t *testing.T) {
 		assert.Equal(t, RawMessage(`{"data":"test"}`), n.Field)
 	})
 }
+
+func Test_PostableUserConfigRouteFallbackReceivers(t *testing.T) {
+	config := func(fallback string) string {
+		return `{
+			"alertmanager_config": {
+				"route": {
+					"receiver": "on-call",
+					"routes": [
+						{"receiver": "on-call", "object_matchers": [["team", "=", "ops"]], "fallback_receiver": "` + fallback + `"}
+					]
+				},
+				"receivers": [{"name": "on-call"}, {"name": "backup"}]
+			}
+		}`
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		fallback string
+		err      string
+	}{
+		{
+			desc:     "valid fallback",
+			fallback: "backup",
+		},
+		{
+			desc:     "undefined fallback",
+			fallback: "unknown",
+			err:      `fallback receiver "unknown" of route "0" is undefined`,
+		},
+		{
+			desc:     "fallback is the receiver of the route",
+			fallback: "on-call",
+			err:      `receiver "on-call" of route "0" cannot be its fallback receiver`,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var cfg PostableUserConfig
+			err := json.Unmarshal([]byte(config(tc.fallback)), &cfg)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"0": "backup"}, cfg.RouteFallbackReceivers)
+
+			b, err := json.Marshal(&cfg)
+			require.NoError(t, err)
+			var out PostableUserConfig
+			require.NoError(t, json.Unmarshal(b, &out))
+			require.Equal(t, cfg.RouteFallbackReceivers, out.RouteFallbackReceivers)
+
+			gettable := GettableApiAlertingConfig{Config: cfg.AlertmanagerConfig.Config, RouteFallbackReceivers: cfg.RouteFallbackReceivers}
+			b, err = json.Marshal(gettable)
+			require.NoError(t, err)
+			require.Contains(t, string(b), `"fallback_receiver":"backup"`)
+		})
+	}
+
+	t.Run("fallbacks are read from YAML", func(t *testing.T) {
+		var cfg PostableUserConfig
+		require.NoError(t, yaml.Unmarshal([]byte(`alertmanager_config: |
+  route:
+    receiver: on-call
+    routes:
+      - receiver: on-call
+      - receiver: on-call
+        fallback_receiver: backup
+  receivers:
+    - name: on-call
+    - name: backup
+`), &cfg))
+		require.Equal(t, map[string]string{"1": "backup"}, cfg.RouteFallbackReceivers)
+	})
+}
+
+func TestRouteAt(t *testing.T) {
+	leaf := &Route{Receiver: "leaf"}
+	root := &Route{Receiver: "root", Routes: []*Route{{Receiver: "child", Routes: []*Route{leaf}}}}
+
+	require.Equal(t, root, RouteAt(root, ""))
+	require.Equal(t, leaf, RouteAt(root, "0.0"))
+	require.Nil(t, RouteAt(root, "1"))
+	require.Nil(t, RouteAt(root, "0.0.0"))
+	require.Nil(t, RouteAt(root, "x"))
+}
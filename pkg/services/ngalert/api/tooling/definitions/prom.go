@@ -33,6 +33,27 @@ import (
 //     Responses:
 //       200: AlertResponse
 
+// swagger:route POST /prometheus/grafana/api/v1/alerts/acknowledgement prometheus RoutePostGrafanaAlertAcknowledgement
+//
+// acknowledges a firing alert instance
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: Alert
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /prometheus/grafana/api/v1/alerts/acknowledgement/{RuleUID} prometheus RouteDeleteGrafanaAlertAcknowledgement
+//
+// removes the acknowledgement of an alert instance
+//
+//     Responses:
+//       200: Alert
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route GET /prometheus/{DatasourceUID}/api/v1/alerts prometheus RouteGetAlertStatuses
 //
 // gets the current alerts
@@ -189,6 +210,50 @@ type Alert struct {
 	ActiveAt *time.Time `json:"activeAt"`
 	// required: true
 	Value string `json:"value"`
+	// Acknowledgement is set if a user has taken ownership of the alert instance.
+	Acknowledgement *AlertAcknowledgement `json:"acknowledgement,omitempty"`
+}
+
+// swagger:model
+type AlertAcknowledgement struct {
+	// required: true
+	By string `json:"by"`
+	// required: true
+	At   time.Time `json:"at"`
+	Note string    `json:"note,omitempty"`
+	// SuppressRepeatNotifications stops repeat notifications of the alert instance until it is resolved.
+	SuppressRepeatNotifications bool `json:"suppressRepeatNotifications,omitempty"`
+}
+
+// swagger:parameters RoutePostGrafanaAlertAcknowledgement
+type AlertAcknowledgementParams struct {
+	// in:body
+	Body PostableAlertAcknowledgement
+}
+
+// swagger:parameters RouteDeleteGrafanaAlertAcknowledgement
+type DeleteAlertAcknowledgementParams struct {
+	// UID of the alert rule the alert instance belongs to.
+	// in: path
+	// required: true
+	RuleUID string
+	// Labels of the alert instance, as name=value pairs. Internal labels are ignored.
+	// in: query
+	// required: false
+	Labels []string `json:"label"`
+}
+
+// swagger:model
+type PostableAlertAcknowledgement struct {
+	// UID of the alert rule the alert instance belongs to.
+	// required: true
+	RuleUID string `json:"ruleUID"`
+	// Labels of the alert instance. Internal labels are ignored.
+	// required: true
+	Labels map[string]string `json:"labels"`
+	Note   string            `json:"note,omitempty"`
+	// SuppressRepeatNotifications stops repeat notifications of the alert instance until it is resolved.
+	SuppressRepeatNotifications bool `json:"suppressRepeatNotifications,omitempty"`
 }
 
 type StateByImportance int
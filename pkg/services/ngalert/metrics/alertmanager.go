@@ -11,6 +11,8 @@ type Alertmanager struct {
 	Registerer prometheus.Registerer
 	*metrics.Alerts
 	*AlertmanagerConfigMetrics
+	*AlertmanagerNotificationLimitMetrics
+	*AlertmanagerIntegrationHealthMetrics
 }
 
 // NewAlertmanagerMetrics creates a set of metrics for the Alertmanager of each organization.
@@ -20,6 +22,9 @@ func NewAlertmanagerMetrics(r prometheus.Registerer) *Alertmanager {
 		Registerer:                r,
 		Alerts:                    metrics.NewAlerts(other),
 		AlertmanagerConfigMetrics: NewAlertmanagerConfigMetrics(r),
+
+		AlertmanagerNotificationLimitMetrics: NewAlertmanagerNotificationLimitMetrics(r),
+		AlertmanagerIntegrationHealthMetrics: NewAlertmanagerIntegrationHealthMetrics(r),
 	}
 }
 
@@ -59,3 +64,62 @@ func NewAlertmanagerConfigMetrics(r prometheus.Registerer) *AlertmanagerConfigMe
 	}
 	return m
 }
+
+type AlertmanagerNotificationLimitMetrics struct {
+	SuppressedNotifications *prometheus.CounterVec
+	SuppressedAlerts        *prometheus.CounterVec
+	DigestNotifications     *prometheus.CounterVec
+}
+
+func NewAlertmanagerNotificationLimitMetrics(r prometheus.Registerer) *AlertmanagerNotificationLimitMetrics {
+	m := &AlertmanagerNotificationLimitMetrics{
+		SuppressedNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notifications_rate_limited_total",
+			Help: "The total number of notifications suppressed by the notification limits of an integration.",
+		}, []string{"receiver", "integration"}),
+		SuppressedAlerts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notification_alerts_collapsed_total",
+			Help: "The total number of alerts collapsed into a digest by the notification limits of an integration.",
+		}, []string{"receiver", "integration", "reason"}),
+		DigestNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notification_digests_total",
+			Help: "The total number of digest notifications sent in place of suppressed notifications.",
+		}, []string{"receiver", "integration"}),
+	}
+	if r != nil {
+		r.MustRegister(m.SuppressedNotifications, m.SuppressedAlerts, m.DigestNotifications)
+	}
+	return m
+}
+
+type AlertmanagerIntegrationHealthMetrics struct {
+	HealthChecks                 *prometheus.CounterVec
+	HealthCheckFailures          *prometheus.CounterVec
+	FallbackNotifications        *prometheus.CounterVec
+	FallbackNotificationFailures *prometheus.CounterVec
+}
+
+func NewAlertmanagerIntegrationHealthMetrics(r prometheus.Registerer) *AlertmanagerIntegrationHealthMetrics {
+	m := &AlertmanagerIntegrationHealthMetrics{
+		HealthChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_integration_health_checks_total",
+			Help: "The total number of health checks of an integration.",
+		}, []string{"receiver", "integration"}),
+		HealthCheckFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_integration_health_checks_failed_total",
+			Help: "The total number of failed health checks of an integration.",
+		}, []string{"receiver", "integration"}),
+		FallbackNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notification_fallbacks_total",
+			Help: "The total number of notifications re-delivered through the fallback receiver because all integrations of the receiver failed.",
+		}, []string{"receiver", "fallback"}),
+		FallbackNotificationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "alertmanager_notification_fallbacks_failed_total",
+			Help: "The total number of notifications that could not be re-delivered through the fallback receiver.",
+		}, []string{"receiver", "fallback"}),
+	}
+	if r != nil {
+		r.MustRegister(m.HealthChecks, m.HealthCheckFailures, m.FallbackNotifications, m.FallbackNotificationFailures)
+	}
+	return m
+}
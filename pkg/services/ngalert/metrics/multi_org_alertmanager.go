@@ -86,6 +86,17 @@ type AlertmanagerAggregatedMetrics struct {
 	numNotificationRequestsFailedTotal *prometheus.Desc
 	notificationLatencySeconds         *prometheus.Desc
 
+	// exported metrics, gathered from the notification limits of the integrations
+	numSuppressedNotifications *prometheus.Desc
+	numSuppressedAlerts        *prometheus.Desc
+	numDigestNotifications     *prometheus.Desc
+
+	// exported metrics, gathered from the health checks and fallbacks of the receivers
+	numHealthChecks                 *prometheus.Desc
+	numHealthCheckFailures          *prometheus.Desc
+	numFallbackNotifications        *prometheus.Desc
+	numFallbackNotificationFailures *prometheus.Desc
+
 	// exported metrics, gathered from Alertmanager nflog
 	nflogGCDuration              *prometheus.Desc
 	nflogSnapshotDuration        *prometheus.Desc
@@ -168,6 +179,36 @@ func NewAlertmanagerAggregatedMetrics(registries *metrics.TenantRegistries) *Ale
 			"The latency of notifications in seconds.",
 			nil, nil),
 
+		numSuppressedNotifications: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notifications_rate_limited_total", Namespace, Subsystem),
+			"The total number of notifications suppressed by the notification limits of an integration.",
+			[]string{"org", "receiver", "integration"}, nil),
+		numSuppressedAlerts: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notification_alerts_collapsed_total", Namespace, Subsystem),
+			"The total number of alerts collapsed into a digest by the notification limits of an integration.",
+			[]string{"org", "receiver", "integration", "reason"}, nil),
+		numDigestNotifications: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notification_digests_total", Namespace, Subsystem),
+			"The total number of digest notifications sent in place of suppressed notifications.",
+			[]string{"org", "receiver", "integration"}, nil),
+
+		numHealthChecks: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_integration_health_checks_total", Namespace, Subsystem),
+			"The total number of health checks of an integration.",
+			[]string{"org", "receiver", "integration"}, nil),
+		numHealthCheckFailures: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_integration_health_checks_failed_total", Namespace, Subsystem),
+			"The total number of failed health checks of an integration.",
+			[]string{"org", "receiver", "integration"}, nil),
+		numFallbackNotifications: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notification_fallbacks_total", Namespace, Subsystem),
+			"The total number of notifications re-delivered through the fallback receiver because all integrations of the receiver failed.",
+			[]string{"org", "receiver", "fallback"}, nil),
+		numFallbackNotificationFailures: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_notification_fallbacks_failed_total", Namespace, Subsystem),
+			"The total number of notifications that could not be re-delivered through the fallback receiver.",
+			[]string{"org", "receiver", "fallback"}, nil),
+
 		nflogGCDuration: prometheus.NewDesc(
 			fmt.Sprintf("%s_%s_nflog_gc_duration_seconds", Namespace, Subsystem),
 			"Duration of the last notification log garbage collection cycle.",
@@ -283,6 +324,15 @@ func (a *AlertmanagerAggregatedMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- a.numNotificationRequestsFailedTotal
 	out <- a.notificationLatencySeconds
 
+	out <- a.numSuppressedNotifications
+	out <- a.numSuppressedAlerts
+	out <- a.numDigestNotifications
+
+	out <- a.numHealthChecks
+	out <- a.numHealthCheckFailures
+	out <- a.numFallbackNotifications
+	out <- a.numFallbackNotificationFailures
+
 	out <- a.nflogGCDuration
 	out <- a.nflogSnapshotDuration
 	out <- a.nflogSnapshotSize
@@ -328,6 +378,15 @@ func (a *AlertmanagerAggregatedMetrics) Collect(out chan<- prometheus.Metric) {
 	data.SendSumOfCountersPerTenant(out, a.numNotificationRequestsFailedTotal, "alertmanager_notification_requests_failed_total", metrics.WithLabels("integration"), metrics.WithSkipZeroValueMetrics)
 	data.SendSumOfHistograms(out, a.notificationLatencySeconds, "alertmanager_notification_latency_seconds")
 
+	data.SendSumOfCountersPerTenant(out, a.numSuppressedNotifications, "alertmanager_notifications_rate_limited_total", metrics.WithLabels("receiver", "integration"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.numSuppressedAlerts, "alertmanager_notification_alerts_collapsed_total", metrics.WithLabels("receiver", "integration", "reason"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.numDigestNotifications, "alertmanager_notification_digests_total", metrics.WithLabels("receiver", "integration"), metrics.WithSkipZeroValueMetrics)
+
+	data.SendSumOfCountersPerTenant(out, a.numHealthChecks, "alertmanager_integration_health_checks_total", metrics.WithLabels("receiver", "integration"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.numHealthCheckFailures, "alertmanager_integration_health_checks_failed_total", metrics.WithLabels("receiver", "integration"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.numFallbackNotifications, "alertmanager_notification_fallbacks_total", metrics.WithLabels("receiver", "fallback"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.numFallbackNotificationFailures, "alertmanager_notification_fallbacks_failed_total", metrics.WithLabels("receiver", "fallback"), metrics.WithSkipZeroValueMetrics)
+
 	data.SendSumOfSummaries(out, a.nflogGCDuration, "alertmanager_nflog_gc_duration_seconds")
 	data.SendSumOfSummaries(out, a.nflogSnapshotDuration, "alertmanager_nflog_snapshot_duration_seconds")
 	data.SendSumOfGauges(out, a.nflogSnapshotSize, "alertmanager_nflog_snapshot_size_bytes")
@@ -9,6 +9,8 @@ var (
 	ErrAlertRuleConflictBase = errutil.Conflict("alerting.alert-rule.conflict").
 					MustTemplate(errAlertRuleConflictMsg, errutil.WithPublic(errAlertRuleConflictMsg))
 	ErrAlertRuleGroupNotFound       = errutil.NotFound("alerting.alert-rule.notFound")
+	ErrAlertInstanceNotFound        = errutil.NotFound("alerting.alert-instance.notFound", errutil.WithPublicMessage("Alert instance not found"))
+	ErrAlertInstanceNotFiring       = errutil.BadRequest("alerting.alert-instance.notFiring", errutil.WithPublicMessage("Only firing alert instances can be acknowledged"))
 	ErrInvalidRelativeTimeRangeBase = errutil.BadRequest("alerting.alert-rule.invalidRelativeTime").MustTemplate("Invalid alert rule query {{ .Public.RefID }}: invalid relative time range [From: {{ .Public.From }}, To: {{ .Public.To }}]")
 )
 
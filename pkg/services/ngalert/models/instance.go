@@ -1,10 +1,22 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 )
 
+const (
+	// AcknowledgedByAnnotation, AcknowledgedAtAnnotation and AcknowledgementNoteAnnotation are added to the alerts
+	// of acknowledged alert instances sent to the Alertmanager, so they can be used in notification templates.
+	AcknowledgedByAnnotation      = "acknowledged_by"
+	AcknowledgedAtAnnotation      = "acknowledged_at"
+	AcknowledgementNoteAnnotation = "acknowledgement_note"
+	// SuppressRepeatNotificationsAnnotation is added to the alerts of acknowledged alert instances
+	// whose repeat notifications should not be sent until the instance is resolved.
+	SuppressRepeatNotificationsAnnotation = "__suppressRepeatNotifications__"
+)
+
 // AlertInstance represents a single alert instance.
 type AlertInstance struct {
 	AlertInstanceKey  `xorm:"extends"`
@@ -15,6 +27,7 @@ type AlertInstance struct {
 	CurrentStateEnd   time.Time
 	LastEvalTime      time.Time
 	ResultFingerprint string
+	Acknowledgement   *AlertInstanceAcknowledgement
 }
 
 type AlertInstanceKey struct {
@@ -23,6 +36,27 @@ type AlertInstanceKey struct {
 	LabelsHash string
 }
 
+// AlertInstanceAcknowledgement records that a user has taken ownership of a firing alert instance.
+// The acknowledgement is removed when the instance is resolved.
+type AlertInstanceAcknowledgement struct {
+	By   string    `json:"by"`
+	At   time.Time `json:"at"`
+	Note string    `json:"note,omitempty"`
+	// SuppressRepeatNotifications stops repeat notifications of the instance until it is resolved.
+	SuppressRepeatNotifications bool `json:"suppressRepeatNotifications,omitempty"`
+}
+
+// FromDB loads the acknowledgement stored in the database as JSON.
+// FromDB is part of the xorm Conversion interface.
+func (a *AlertInstanceAcknowledgement) FromDB(b []byte) error {
+	return json.Unmarshal(b, a)
+}
+
+// ToDB is part of the xorm Conversion interface.
+func (a *AlertInstanceAcknowledgement) ToDB() ([]byte, error) {
+	return json.Marshal(a)
+}
+
 // InstanceStateType is an enum for instance states.
 type InstanceStateType string
 
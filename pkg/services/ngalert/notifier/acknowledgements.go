@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"context"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// acknowledgementFilter drops the firing alerts that were acknowledged with repeat notifications suppressed.
+// Acknowledgements are removed when alert instances are resolved, so the resolved notification is still sent.
+type acknowledgementFilter struct {
+	next alertingNotify.Notifier
+}
+
+func (n *acknowledgementFilter) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	filtered := make([]*types.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if isRepeatNotificationSuppressed(a) {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	if len(filtered) == 0 {
+		return false, nil
+	}
+	return n.next.Notify(ctx, filtered...)
+}
+
+func isRepeatNotificationSuppressed(a *types.Alert) bool {
+	return !a.Resolved() && a.Annotations[model.LabelName(ngmodels.SuppressRepeatNotificationsAnnotation)] == "true"
+}
+
+// withAcknowledgements wraps the integrations of the receiver so that acknowledged alerts do not send repeat notifications.
+func withAcknowledgements(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		n := &acknowledgementFilter{next: integration}
+		result = append(result, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiver.Name))
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAcknowledgementFilter(t *testing.T) {
+	now := time.Now()
+	next := &recordingNotifier{}
+	n := &acknowledgementFilter{next: next}
+
+	acknowledged := newTestAlert("acknowledged", now)
+	acknowledged.Annotations = model.LabelSet{
+		ngmodels.AcknowledgedByAnnotation:                               "admin",
+		model.LabelName(ngmodels.SuppressRepeatNotificationsAnnotation): "true",
+	}
+	acknowledgedWithRepeats := newTestAlert("acknowledged-with-repeats", now)
+	acknowledgedWithRepeats.Annotations = model.LabelSet{ngmodels.AcknowledgedByAnnotation: "admin"}
+	firing := newTestAlert("firing", now)
+
+	t.Run("acknowledged alerts with suppressed repeat notifications are dropped", func(t *testing.T) {
+		retry, err := n.Notify(context.Background(), acknowledged, acknowledgedWithRepeats, firing)
+		require.NoError(t, err)
+		require.False(t, retry)
+		require.Equal(t, [][]*types.Alert{{acknowledgedWithRepeats, firing}}, next.Calls())
+	})
+
+	t.Run("nothing is sent if all alerts are dropped", func(t *testing.T) {
+		_, err := n.Notify(context.Background(), acknowledged)
+		require.NoError(t, err)
+		require.Len(t, next.Calls(), 1)
+	})
+
+	t.Run("resolved alerts are sent", func(t *testing.T) {
+		resolved := *acknowledged
+		resolved.EndsAt = now.Add(-time.Second)
+		_, err := n.Notify(context.Background(), &resolved)
+		require.NoError(t, err)
+		require.Len(t, next.Calls(), 2)
+	})
+}
@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/benbjohnson/clock"
 	alertingNotify "github.com/grafana/alerting/notify"
 	"github.com/grafana/alerting/receivers"
 	alertingTemplates "github.com/grafana/alerting/templates"
@@ -44,6 +46,8 @@ type stateStore interface {
 	SaveNotificationLog(ctx context.Context, st alertingNotify.State) (int64, error)
 	GetSilences(ctx context.Context) (string, error)
 	GetNotificationLog(ctx context.Context) (string, error)
+	SaveDigests(ctx context.Context, st alertingNotify.State) (int64, error)
+	GetDigests(ctx context.Context) (string, error)
 }
 
 type alertmanager struct {
@@ -60,6 +64,28 @@ type alertmanager struct {
 	orgID     int64
 
 	withAutogen bool
+
+	clock        clock.Clock
+	limitMetrics *metrics.AlertmanagerNotificationLimitMetrics
+	limitersMtx  sync.Mutex
+	// limiters are the notification limiters of the integrations that have notification limits, keyed by integration UID.
+	limiters   map[string]*notificationLimiter
+	digestsMtx sync.Mutex
+	// digests are the digest buffers of the integrations in digest mode, keyed by integration UID.
+	digests map[string]*digestBuffer
+	// restoredDigests are the saved alerts of the digest buffers, until the buffers are created.
+	restoredDigests digestState
+	digestsSaveMtx  sync.Mutex
+
+	healthMetrics     *metrics.AlertmanagerIntegrationHealthMetrics
+	healthCheckersMtx sync.Mutex
+	// healthCheckers are the health checkers of the integrations that have health checks enabled, keyed by integration UID.
+	healthCheckers map[string]*healthChecker
+	// pendingHealthChecks are the health checks of the configuration that is being applied.
+	pendingHealthChecks []pendingHealthCheck
+	fallbacksMtx        sync.RWMutex
+	// fallbacks are the fallback receivers of the routes of the applied configuration.
+	fallbacks routeFallbacks
 }
 
 // maintenanceOptions represent the options for components that need maintenance on a frequency within the Alertmanager.
@@ -100,6 +126,10 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 	if err != nil {
 		return nil, err
 	}
+	digests, err := stateStore.GetDigests(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	silencesOptions := maintenanceOptions{
 		initialState:         silences,
@@ -130,6 +160,10 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 	}
 
 	l := log.New("ngalert.notifier.alertmanager", "org", orgID)
+	restoredDigests, err := restoreDigests(digests)
+	if err != nil {
+		l.Error("Failed to read the saved digest buffers, buffered alerts are discarded", "error", err)
+	}
 	gam, err := alertingNotify.NewGrafanaAlertmanager("orgID", orgID, amcfg, peer, l, alertingNotify.NewGrafanaAlertmanagerMetrics(m.Registerer))
 	if err != nil {
 		return nil, err
@@ -148,6 +182,15 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 
 		// TODO: Preferably, logic around autogen would be outside of the specific alertmanager implementation so that remote alertmanager will get it for free.
 		withAutogen: withAutogen,
+
+		clock:           clock.New(),
+		limitMetrics:    m.AlertmanagerNotificationLimitMetrics,
+		limiters:        make(map[string]*notificationLimiter),
+		digests:         make(map[string]*digestBuffer),
+		restoredDigests: restoredDigests,
+
+		healthMetrics:  m.AlertmanagerIntegrationHealthMetrics,
+		healthCheckers: make(map[string]*healthChecker),
 	}
 
 	return am, nil
@@ -162,6 +205,24 @@ func (am *alertmanager) Ready() bool {
 
 func (am *alertmanager) StopAndWait() {
 	am.Base.StopAndWait()
+
+	am.limitersMtx.Lock()
+	for _, l := range am.limiters {
+		l.stop()
+	}
+	am.limitersMtx.Unlock()
+
+	am.digestsMtx.Lock()
+	for _, d := range am.digests {
+		d.stop()
+	}
+	am.digestsMtx.Unlock()
+
+	am.healthCheckersMtx.Lock()
+	for _, h := range am.healthCheckers {
+		h.stop()
+	}
+	am.healthCheckersMtx.Unlock()
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration to the database and applies it to the Alertmanager.
@@ -333,6 +394,10 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 	}
 
 	am.logger.Info("Applying new configuration to Alertmanager", "configHash", fmt.Sprintf("%x", configHash))
+	// Fallback receivers must be known before the receivers of the configuration are built.
+	if err := am.setRouteFallbacks(cfg); err != nil {
+		return false, err
+	}
 	err = am.Base.ApplyConfig(AlertingConfiguration{
 		rawAlertmanagerConfig:    rawConfig,
 		configHash:               configHash,
@@ -345,13 +410,73 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 		receiverIntegrationsFunc: am.buildReceiverIntegrations,
 	})
 	if err != nil {
+		// The integrations of the configuration are not used, so they must not be checked.
+		am.takePendingHealthChecks()
 		return false, err
 	}
 
+	am.pruneNotificationLimiters(cfg)
+	am.pruneDigestBuffers(cfg)
+	am.pruneHealthCheckers(cfg)
+	am.startHealthChecks()
 	am.updateConfigMetrics(cfg)
 	return true, nil
 }
 
+// validateConfig builds the templates, the fallback receivers and the integrations of the receivers of the
+// configuration as applyConfig does, without applying it, and returns the reasons why it cannot be applied.
+func (am *alertmanager) validateConfig(cfg *apimodels.PostableUserConfig) []error {
+	// The integrations are built by an Alertmanager that is discarded, so that the notification limiters, digest
+	// buffers and health checks of the applied configuration are not changed.
+	scratch := &alertmanager{
+		Base:                am.Base,
+		logger:              am.logger,
+		Settings:            am.Settings,
+		Store:               am.Store,
+		NotificationService: am.NotificationService,
+		orgID:               am.orgID,
+		decryptFn:           am.decryptFn,
+		clock:               am.clock,
+		limitMetrics:        am.limitMetrics,
+		limiters:            make(map[string]*notificationLimiter),
+		digests:             make(map[string]*digestBuffer),
+		healthMetrics:       am.healthMetrics,
+		healthCheckers:      make(map[string]*healthChecker),
+	}
+	defer func() {
+		scratch.pruneDigestBuffers(&apimodels.PostableUserConfig{})
+		scratch.pruneNotificationLimiters(&apimodels.PostableUserConfig{})
+		scratch.takePendingHealthChecks()
+	}()
+
+	var errs []error
+	if err := scratch.setRouteFallbacks(cfg); err != nil {
+		errs = append(errs, err)
+	}
+
+	seen := make(map[string]struct{})
+	tmpls := make([]string, 0, len(cfg.TemplateFiles))
+	for _, def := range ToTemplateDefinitions(cfg) {
+		if _, ok := seen[def.Name]; ok {
+			continue
+		}
+		tmpls = append(tmpls, def.Template)
+		seen[def.Name] = struct{}{}
+	}
+	tmpl, err := am.Base.TemplateFromContent(tmpls)
+	if err != nil {
+		// The integrations cannot be built without templates.
+		return append(errs, fmt.Errorf("invalid templates: %w", err))
+	}
+
+	for _, receiver := range PostableApiAlertingConfigToApiReceivers(cfg.AlertmanagerConfig) {
+		if _, err := scratch.buildReceiverIntegrations(receiver, tmpl); err != nil {
+			errs = append(errs, fmt.Errorf("invalid receiver %q: %w", receiver.Name, err))
+		}
+	}
+	return errs
+}
+
 // applyAndMarkConfig applies a configuration and marks it as applied if no errors occur.
 func (am *alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig) error {
 	configChanged, err := am.applyConfig(cfg)
@@ -399,7 +524,23 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	return integrations, nil
+	if receiver.Name == "" {
+		// Integrations built to send test notifications do not belong to a receiver of the configuration.
+		// They are not subject to notification limits, digests, acknowledgements, health checks nor fallbacks.
+		return integrations, nil
+	}
+	if err := am.registerHealthChecks(receiver, integrations); err != nil {
+		return nil, err
+	}
+	integrations, err = am.withNotificationLimits(receiver, integrations)
+	if err != nil {
+		return nil, err
+	}
+	integrations, err = am.withDigests(receiver, integrations, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return am.withFallback(receiver, withAcknowledgements(receiver, integrations)), nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
@@ -162,7 +162,8 @@ func (moa *MultiOrgAlertmanager) gettableUserConfigFromAMConfigString(ctx contex
 	result := definitions.GettableUserConfig{
 		TemplateFiles: cfg.TemplateFiles,
 		AlertmanagerConfig: definitions.GettableApiAlertingConfig{
-			Config: cfg.AlertmanagerConfig.Config,
+			Config:                 cfg.AlertmanagerConfig.Config,
+			RouteFallbackReceivers: cfg.RouteFallbackReceivers,
 		},
 	}
 	for _, recv := range cfg.AlertmanagerConfig.Receivers {
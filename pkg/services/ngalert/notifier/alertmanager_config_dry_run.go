@@ -0,0 +1,312 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mohae/deepcopy"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// DryRunAlertmanagerConfiguration validates the configuration as SaveAndApplyAlertmanagerConfiguration would, and
+// compares it with the latest configuration of the organization. Nothing is persisted nor applied.
+// Validation errors are reported in the result; the returned error is only set if the validation could not be done.
+func (moa *MultiOrgAlertmanager) DryRunAlertmanagerConfiguration(ctx context.Context, org int64, cfg definitions.PostableUserConfig) (definitions.AlertingConfigDryRunResult, error) {
+	var current *definitions.PostableUserConfig
+	amConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, org)
+	if err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return definitions.AlertingConfigDryRunResult{}, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	if amConfig != nil {
+		current, err = Load([]byte(amConfig.AlertmanagerConfiguration))
+		if err != nil {
+			// The new configuration replaces an invalid one, so everything it contains is new.
+			moa.logger.Warn("Last known alertmanager configuration was invalid", "org", org, "error", err)
+			current = nil
+		}
+	}
+
+	newCfg, ok := deepcopy.Copy(&cfg).(*definitions.PostableUserConfig)
+	if !ok {
+		return definitions.AlertingConfigDryRunResult{}, errors.New("failed to copy config")
+	}
+	RemoveAutogenConfigIfExists(newCfg.AlertmanagerConfig.Route)
+
+	result := definitions.AlertingConfigDryRunResult{
+		Diff:     diffAlertingConfigs(current, newCfg),
+		Warnings: routeWarnings(newCfg.AlertmanagerConfig.Route, newCfg.AlertmanagerConfig.Receivers),
+	}
+	for _, err := range moa.validateAlertmanagerConfiguration(ctx, org, newCfg) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+// configValidator is implemented by the Alertmanagers that can validate a configuration without applying it.
+type configValidator interface {
+	validateConfig(cfg *definitions.PostableUserConfig) []error
+}
+
+// validateAlertmanagerConfiguration returns the reasons why the configuration cannot be applied, following the steps
+// of SaveAndApplyAlertmanagerConfiguration. The routes and time intervals of the configuration are validated when
+// it is decoded, like the configuration that is saved. The configuration is changed in place.
+func (moa *MultiOrgAlertmanager) validateAlertmanagerConfiguration(ctx context.Context, org int64, cfg *definitions.PostableUserConfig) []error {
+	if len(cfg.AlertmanagerConfig.InhibitRules) > 0 {
+		return []error{errors.New("inhibition rules are not supported")}
+	}
+	if err := moa.Crypto.ProcessSecureSettings(ctx, org, cfg.AlertmanagerConfig.Receivers); err != nil {
+		return []error{fmt.Errorf("failed to post process Alertmanager configuration: %w", err)}
+	}
+	if err := assignReceiverConfigsUIDs(cfg.AlertmanagerConfig.Receivers); err != nil {
+		return []error{fmt.Errorf("failed to assign missing uids: %w", err)}
+	}
+
+	am, err := moa.AlertmanagerFor(org)
+	if err != nil && !errors.Is(err, ErrAlertmanagerNotReady) {
+		return []error{err}
+	}
+	var errs []error
+	if moa.featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting) {
+		if err := AddAutogenConfig(ctx, moa.logger, moa.configStore, org, &cfg.AlertmanagerConfig, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if v, ok := am.(configValidator); ok {
+		errs = append(errs, v.validateConfig(cfg)...)
+	}
+	return errs
+}
+
+// routeWarnings returns the problems of the routing tree that do not prevent the configuration from being applied:
+// routes that notify undefined receivers, and routes that can never match an alert.
+func routeWarnings(root *definitions.Route, receivers []*definitions.PostableApiReceiver) []string {
+	if root == nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(receivers))
+	for _, r := range receivers {
+		names[r.Name] = struct{}{}
+	}
+
+	var warnings []string
+	var walk func(path string, r *config.Route, inherited labels.Matchers)
+	walk = func(path string, r *config.Route, inherited labels.Matchers) {
+		if r.Receiver != "" {
+			if _, ok := names[r.Receiver]; !ok {
+				warnings = append(warnings, fmt.Sprintf("route %s uses receiver %q, which does not exist", path, r.Receiver))
+			}
+		}
+		matchers := append(append(labels.Matchers{}, inherited...), routeMatchers(r)...)
+		if conflict := conflictingMatchers(matchers); conflict != "" {
+			// The routes below it can never match either, so they are not reported.
+			warnings = append(warnings, fmt.Sprintf("route %s can never match: %s", path, conflict))
+			return
+		}
+		catchAll := -1
+		for i, child := range r.Routes {
+			childPath := routePath(path, i)
+			if catchAll >= 0 {
+				warnings = append(warnings, fmt.Sprintf("route %s can never match: route %s before it matches all alerts and does not continue", childPath, routePath(path, catchAll)))
+				continue
+			}
+			walk(childPath, child, matchers)
+			if len(routeMatchers(child)) == 0 && !child.Continue {
+				catchAll = i
+			}
+		}
+	}
+	walk("/", root.AsAMRoute(), nil)
+	return warnings
+}
+
+// routeMatchers returns the matchers of the route, including the deprecated match and match_re.
+func routeMatchers(r *config.Route) labels.Matchers {
+	matchers := make(labels.Matchers, 0, len(r.Match)+len(r.MatchRE)+len(r.Matchers))
+	for name, value := range r.Match {
+		if m, err := labels.NewMatcher(labels.MatchEqual, name, value); err == nil {
+			matchers = append(matchers, m)
+		}
+	}
+	for name, value := range r.MatchRE {
+		if m, err := labels.NewMatcher(labels.MatchRegexp, string(name), value.String()); err == nil {
+			matchers = append(matchers, m)
+		}
+	}
+	return append(matchers, r.Matchers...)
+}
+
+// conflictingMatchers describes why no label set can satisfy all matchers, or returns an empty string if one can.
+// Only the matchers of labels that must equal a value are checked.
+func conflictingMatchers(matchers labels.Matchers) string {
+	required := make(map[string]*labels.Matcher)
+	for _, m := range matchers {
+		if m.Type == labels.MatchEqual && m.Value != "" {
+			if _, ok := required[m.Name]; !ok {
+				required[m.Name] = m
+			}
+		}
+	}
+	for _, m := range matchers {
+		eq, ok := required[m.Name]
+		if !ok || m == eq {
+			continue
+		}
+		if !m.Matches(eq.Value) {
+			return fmt.Sprintf("matchers %s and %s conflict", eq, m)
+		}
+	}
+	return ""
+}
+
+func routePath(parent string, idx int) string {
+	return strings.TrimSuffix(parent, "/") + "/" + strconv.Itoa(idx)
+}
+
+func walkRoutes(r *definitions.Route, path string, fn func(path string, r *definitions.Route)) {
+	if r == nil {
+		return
+	}
+	fn(path, r)
+	for i, child := range r.Routes {
+		walkRoutes(child, routePath(path, i), fn)
+	}
+}
+
+// diffAlertingConfigs compares the receivers, routes, time intervals and templates of two configurations.
+// The current configuration can be nil, in which case everything in the next configuration is added.
+func diffAlertingConfigs(current, next *definitions.PostableUserConfig) definitions.AlertingConfigDiff {
+	if current == nil {
+		current = &definitions.PostableUserConfig{}
+	}
+	nextReceivers := receiversByName(next)
+	markUpdatedSecureSettings(nextReceivers, next)
+	return definitions.AlertingConfigDiff{
+		Receivers:     diffResources(receiversByName(current), nextReceivers),
+		Routes:        diffResources(routesByPath(current.AlertmanagerConfig.Route), routesByPath(next.AlertmanagerConfig.Route)),
+		TimeIntervals: diffResources(timeIntervalsByName(current.AlertmanagerConfig), timeIntervalsByName(next.AlertmanagerConfig)),
+		Templates:     diffResources(templatesByName(current), templatesByName(next)),
+	}
+}
+
+func diffResources(current, next map[string]any) definitions.ConfigResourceDiff {
+	var diff definitions.ConfigResourceDiff
+	for name, n := range next {
+		c, ok := current[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+		} else if !reflect.DeepEqual(c, n) {
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+	for name := range current {
+		if _, ok := next[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// normalized returns the JSON representation of v as generic values, so that values that encode to the same JSON
+// object are equal regardless of the order of their keys.
+func normalized(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err.Error()
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return string(b)
+	}
+	return out
+}
+
+type comparableIntegration struct {
+	UID                   string
+	Name                  string
+	Type                  string
+	DisableResolveMessage bool
+	Settings              any
+	// UpdatedSecureSettings are the secure settings set in the configuration. The stored secure settings are
+	// encrypted so their values cannot be compared, hence any secure setting that is set is considered changed.
+	UpdatedSecureSettings []string
+}
+
+func receiversByName(cfg *definitions.PostableUserConfig) map[string]any {
+	result := make(map[string]any, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		integrations := make([]comparableIntegration, 0, len(r.GrafanaManagedReceivers))
+		for _, gr := range r.GrafanaManagedReceivers {
+			integrations = append(integrations, comparableIntegration{
+				UID:                   gr.UID,
+				Name:                  gr.Name,
+				Type:                  gr.Type,
+				DisableResolveMessage: gr.DisableResolveMessage,
+				Settings:              normalized(gr.Settings),
+			})
+		}
+		result[r.Name] = integrations
+	}
+	return result
+}
+
+// markUpdatedSecureSettings records the secure settings set in the receivers of the next configuration,
+// before they are processed.
+func markUpdatedSecureSettings(receivers map[string]any, cfg *definitions.PostableUserConfig) {
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		integrations, ok := receivers[r.Name].([]comparableIntegration)
+		if !ok {
+			continue
+		}
+		for i, gr := range r.GrafanaManagedReceivers {
+			for key := range gr.SecureSettings {
+				integrations[i].UpdatedSecureSettings = append(integrations[i].UpdatedSecureSettings, key)
+			}
+			sort.Strings(integrations[i].UpdatedSecureSettings)
+		}
+	}
+}
+
+func routesByPath(root *definitions.Route) map[string]any {
+	result := make(map[string]any)
+	walkRoutes(root, "/", func(path string, r *definitions.Route) {
+		node := *r
+		node.Routes = nil
+		node.Provenance = ""
+		result[path] = normalized(node)
+	})
+	return result
+}
+
+func timeIntervalsByName(cfg definitions.PostableApiAlertingConfig) map[string]any {
+	result := make(map[string]any, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		result[ti.Name] = normalized(ti.TimeIntervals)
+	}
+	for _, ti := range cfg.TimeIntervals {
+		result[ti.Name] = normalized(ti.TimeIntervals)
+	}
+	return result
+}
+
+func templatesByName(cfg *definitions.PostableUserConfig) map[string]any {
+	result := make(map[string]any, len(cfg.TemplateFiles))
+	for name, content := range cfg.TemplateFiles {
+		result[name] = content
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestRouteWarnings(t *testing.T) {
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "default",
+				"routes": [
+					{"receiver": "team-a", "object_matchers": [["team", "=", "a"]], "routes": [
+						{"receiver": "team-a", "object_matchers": [["team", "=~", "b|c"]]},
+						{"receiver": "team-a", "object_matchers": [["severity", "=", "critical"]]}
+					]},
+					{"receiver": "default", "continue": true},
+					{"receiver": "default"},
+					{"receiver": "team-a", "object_matchers": [["team", "=", "b"]]}
+				]
+			},
+			"receivers": [{"name": "default"}, {"name": "team-a"}]
+		}
+	}`))
+	require.NoError(t, err)
+
+	warnings := routeWarnings(cfg.AlertmanagerConfig.Route, cfg.AlertmanagerConfig.Receivers)
+	require.Equal(t, []string{
+		`route /0/0 can never match: matchers team="a" and team=~"b|c" conflict`,
+		`route /3 can never match: route /2 before it matches all alerts and does not continue`,
+	}, warnings)
+
+	t.Run("undefined receivers are reported", func(t *testing.T) {
+		warnings := routeWarnings(cfg.AlertmanagerConfig.Route, cfg.AlertmanagerConfig.Receivers[:1])
+		require.Contains(t, warnings, `route /0 uses receiver "team-a", which does not exist`)
+	})
+}
+
+func TestDiffAlertingConfigs(t *testing.T) {
+	current, err := Load([]byte(`{
+		"template_files": {"a": "a", "b": "b"},
+		"alertmanager_config": {
+			"route": {"receiver": "email", "routes": [{"receiver": "email", "object_matchers": [["team", "=", "a"]]}]},
+			"mute_time_intervals": [{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}],
+			"receivers": [
+				{"name": "email", "grafana_managed_receiver_configs": [{"uid": "1", "name": "email", "type": "email", "settings": {"addresses": "a@example.com", "singleEmail": true}}]},
+				{"name": "slack", "grafana_managed_receiver_configs": [{"uid": "2", "name": "slack", "type": "slack", "settings": {"recipient": "#alerts"}, "secureSettings": {"token": "encrypted"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	next, err := Load([]byte(`{
+		"template_files": {"a": "a", "b": "changed"},
+		"alertmanager_config": {
+			"route": {"receiver": "email", "routes": [{"receiver": "email", "object_matchers": [["team", "=", "a"]]}, {"receiver": "slack"}]},
+			"time_intervals": [{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}],
+			"receivers": [
+				{"name": "email", "grafana_managed_receiver_configs": [{"uid": "1", "name": "email", "type": "email", "settings": {"singleEmail": true, "addresses": "a@example.com"}}]},
+				{"name": "slack", "grafana_managed_receiver_configs": [{"uid": "2", "name": "slack", "type": "slack", "settings": {"recipient": "#alerts"}, "secureSettings": {"token": "new"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	require.Equal(t, definitions.AlertingConfigDiff{
+		Receivers: definitions.ConfigResourceDiff{Changed: []string{"slack"}},
+		Routes:    definitions.ConfigResourceDiff{Added: []string{"/1"}},
+		Templates: definitions.ConfigResourceDiff{Changed: []string{"b"}},
+	}, diffAlertingConfigs(current, next))
+
+	t.Run("everything is added if there is no current config", func(t *testing.T) {
+		diff := diffAlertingConfigs(nil, next)
+		require.Equal(t, []string{"email", "slack"}, diff.Receivers.Added)
+		require.Equal(t, []string{"/", "/0", "/1"}, diff.Routes.Added)
+		require.Equal(t, []string{"weekends"}, diff.TimeIntervals.Added)
+		require.Equal(t, []string{"a", "b"}, diff.Templates.Added)
+	})
+}
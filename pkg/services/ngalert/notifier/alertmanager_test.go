@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
@@ -59,3 +60,133 @@ func TestAlertmanager_newAlertmanager(t *testing.T) {
 	am := setupAMTest(t)
 	require.False(t, am.Ready())
 }
+
+func TestAlertmanager_applyConfigHealthChecks(t *testing.T) {
+	am := setupAMTest(t)
+	am.Settings.UnifiedAlerting.ContactPointHealthChecksEnabled = true
+	t.Cleanup(func() { am.pruneHealthCheckers(&apimodels.PostableUserConfig{}) })
+
+	config := func(secondReceiverSettings string) *apimodels.PostableUserConfig {
+		cfg, err := Load([]byte(`{
+			"alertmanager_config": {
+				"route": {"receiver": "checked"},
+				"receivers": [
+					{
+						"name": "checked",
+						"grafana_managed_receiver_configs": [{
+							"uid": "checked-uid",
+							"name": "checked",
+							"type": "webhook",
+							"settings": {"url": "http://localhost/webhook", "healthCheck": {"interval": "15m"}}
+						}]
+					},
+					{
+						"name": "other",
+						"grafana_managed_receiver_configs": [{
+							"uid": "other-uid",
+							"name": "other",
+							"type": "webhook",
+							"settings": ` + secondReceiverSettings + `
+						}]
+					}
+				]
+			}
+		}`))
+		require.NoError(t, err)
+		return cfg
+	}
+	healthCheckers := func() []string {
+		am.healthCheckersMtx.Lock()
+		defer am.healthCheckersMtx.Unlock()
+		uids := make([]string, 0, len(am.healthCheckers))
+		for uid := range am.healthCheckers {
+			uids = append(uids, uid)
+		}
+		return uids
+	}
+
+	t.Run("health checks are not started when the configuration fails to apply", func(t *testing.T) {
+		_, err := am.applyConfig(config(`{}`))
+		require.Error(t, err)
+		require.Empty(t, healthCheckers())
+		require.Empty(t, am.pendingHealthChecks)
+	})
+
+	t.Run("health checks are started when the configuration is applied", func(t *testing.T) {
+		_, err := am.applyConfig(config(`{"url": "http://localhost/other"}`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"checked-uid"}, healthCheckers())
+		require.Empty(t, am.pendingHealthChecks)
+	})
+
+	t.Run("health checks are not started when they are disabled", func(t *testing.T) {
+		am.Settings.UnifiedAlerting.ContactPointHealthChecksEnabled = false
+		t.Cleanup(func() { am.Settings.UnifiedAlerting.ContactPointHealthChecksEnabled = true })
+		_, err := am.applyConfig(config(`{"url": "http://localhost/disabled"}`))
+		require.NoError(t, err)
+		require.Empty(t, healthCheckers())
+		require.Empty(t, am.pendingHealthChecks)
+	})
+}
+
+func TestAlertmanager_validateConfig(t *testing.T) {
+	am := setupAMTest(t)
+
+	cfg, err := Load([]byte(`{
+		"template_files": {"a": "{{ define \"broken\" }}{{ .Missing"},
+		"alertmanager_config": {
+			"route": {"receiver": "digest"},
+			"receivers": [
+				{
+					"name": "digest",
+					"grafana_managed_receiver_configs": [{
+						"uid": "digest-uid",
+						"name": "digest",
+						"type": "webhook",
+						"settings": {"url": "http://localhost/webhook", "digest": {"schedule": "@hourly"}}
+					}]
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	errs := am.validateConfig(cfg)
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "invalid templates")
+
+	cfg, err = Load([]byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "digest"},
+			"receivers": [
+				{
+					"name": "digest",
+					"grafana_managed_receiver_configs": [{
+						"uid": "digest-uid",
+						"name": "digest",
+						"type": "webhook",
+						"settings": {"url": "http://localhost/webhook", "digest": {"schedule": "@hourly"}, "healthCheck": {"interval": "15m"}}
+					}]
+				},
+				{
+					"name": "broken",
+					"grafana_managed_receiver_configs": [{
+						"uid": "broken-uid",
+						"name": "broken",
+						"type": "webhook",
+						"settings": {"url": "http://localhost/webhook", "notificationLimits": {"maxNotifications": -1}}
+					}]
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	errs = am.validateConfig(cfg)
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], `invalid receiver "broken"`)
+
+	// The running configuration is not changed.
+	require.Empty(t, am.digests)
+	require.Empty(t, am.limiters)
+	require.Empty(t, am.healthCheckers)
+	require.Empty(t, am.pendingHealthChecks)
+}
@@ -0,0 +1,513 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	// digestSettingsKey is the key of the integration settings that enables the digest mode of the integration.
+	digestSettingsKey = "digest"
+
+	// ScheduledDigestAlertName is the alertname of the synthetic alert that carries a scheduled digest.
+	ScheduledDigestAlertName = "AlertDigest"
+
+	// maxDigestHistory is the maximum number of state changes kept for each alert of a digest.
+	maxDigestHistory = 100
+
+	// digestSendTimeout is the timeout for sending a scheduled digest.
+	digestSendTimeout = time.Minute
+
+	DefaultDigestTitle = `{{ len .Alerts }} alerts for {{ .Receiver }} ({{ .NumFiring }} firing, {{ .NumResolved }} resolved)`
+
+	DefaultDigestMessage = `Alerts from {{ .From.Format "2006-01-02 15:04:05 MST" }} to {{ .To.Format "2006-01-02 15:04:05 MST" }}
+{{ range .Alerts }}
+[{{ .Status | toUpper }}] {{ .Labels.alertname }}{{ if .Annotations.summary }}: {{ .Annotations.summary }}{{ end }}
+Labels: {{ range .Labels.SortedPairs }}{{ .Name }}={{ .Value }} {{ end }}
+{{ range .History }}  - {{ .State }} at {{ .At.Format "2006-01-02 15:04:05 MST" }}
+{{ end }}{{ end }}`
+)
+
+// DigestSettings configure an integration to buffer its alerts and send them as a single summary on a schedule,
+// instead of notifying in real time. They are read from the "digest" key of the integration settings.
+type DigestSettings struct {
+	// Schedule is a cron expression, such as "0 * * * *" or "@daily", that defines when digests are sent.
+	Schedule string `json:"schedule"`
+	// Title is the template of the summary of the digest. DefaultDigestTitle is used if it is empty.
+	Title string `json:"title,omitempty"`
+	// Message is the template of the body of the digest. DefaultDigestMessage is used if it is empty.
+	Message string `json:"message,omitempty"`
+}
+
+// DigestData is the data the templates of a digest are executed with.
+type DigestData struct {
+	// Receiver is the name of the receiver of the digest.
+	Receiver string
+	// From and To are the bounds of the period of the digest.
+	From time.Time
+	To   time.Time
+	// Alerts are all alerts notified during the period, in the order they started.
+	Alerts      []DigestAlert
+	NumFiring   int
+	NumResolved int
+}
+
+// DigestAlert is an alert of a digest, along with the state changes notified for it during the period.
+type DigestAlert struct {
+	Status       string
+	Labels       alertingTemplates.KV
+	Annotations  alertingTemplates.KV
+	StartsAt     time.Time
+	EndsAt       time.Time
+	GeneratorURL string
+	Fingerprint  string
+	History      []DigestStateChange
+}
+
+// DigestStateChange is a change of state of an alert during the period of a digest.
+type DigestStateChange struct {
+	State string
+	At    time.Time
+}
+
+// parseDigestSettings reads the digest settings from the settings of an integration.
+// It returns nil if the integration is not in digest mode.
+func parseDigestSettings(settings json.RawMessage) (*DigestSettings, cron.Schedule, error) {
+	if len(settings) == 0 {
+		return nil, nil, nil
+	}
+	var s map[string]json.RawMessage
+	if err := json.Unmarshal(settings, &s); err != nil {
+		return nil, nil, err
+	}
+	raw, ok := s[digestSettingsKey]
+	if !ok || string(raw) == "null" {
+		return nil, nil, nil
+	}
+	var digest DigestSettings
+	if err := json.Unmarshal(raw, &digest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", digestSettingsKey, err)
+	}
+	if digest.Schedule == "" {
+		return nil, nil, errors.New("schedule is required")
+	}
+	schedule, err := cron.ParseStandard(digest.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %w", digest.Schedule, err)
+	}
+	if digest.Title == "" {
+		digest.Title = DefaultDigestTitle
+	}
+	if digest.Message == "" {
+		digest.Message = DefaultDigestMessage
+	}
+	return &digest, schedule, nil
+}
+
+type digestEntry struct {
+	alert   *types.Alert
+	history []DigestStateChange
+}
+
+// digestBuffer collects the alerts notified to an integration in digest mode, and sends them as a single summary
+// through the integration on the configured schedule. Like notification limiters, it is kept across configuration
+// reloads for as long as the integration exists and is in digest mode. The buffer is saved by the Alertmanager
+// each time it changes, see digestState.
+type digestBuffer struct {
+	receiver    string
+	integration string
+	clock       clock.Clock
+	logger      log.Logger
+	// onChange is called without the lock held each time alerts are added to the buffer or the buffer is flushed.
+	onChange func()
+
+	mtx         sync.Mutex
+	settings    DigestSettings
+	schedule    cron.Schedule
+	tmpl        *alertingTemplates.Template
+	next        alertingNotify.Notifier
+	periodStart time.Time
+	alerts      map[model.Fingerprint]*digestEntry
+	timer       *clock.Timer
+
+	// sendCtx is the context of the last notification, which the digest is sent with. It is nil if no alerts
+	// were notified since the buffer was restored.
+	sendCtx context.Context
+}
+
+func newDigestBuffer(receiver, integration string, c clock.Clock, l log.Logger) *digestBuffer {
+	return &digestBuffer{
+		receiver:    receiver,
+		integration: integration,
+		clock:       c,
+		logger:      l,
+		periodStart: c.Now(),
+		alerts:      make(map[model.Fingerprint]*digestEntry),
+	}
+}
+
+// update sets the settings of the buffer and the integration digests are sent through, and reschedules the next
+// digest if the schedule changed.
+func (d *digestBuffer) update(receiver string, settings DigestSettings, schedule cron.Schedule, tmpl *alertingTemplates.Template, next alertingNotify.Notifier) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.receiver = receiver
+	d.tmpl = tmpl
+	d.next = next
+	if d.timer != nil && d.settings.Schedule == settings.Schedule {
+		d.settings = settings
+		return
+	}
+	d.settings = settings
+	d.schedule = schedule
+	d.scheduleLocked(d.clock.Now())
+}
+
+func (d *digestBuffer) scheduleLocked(now time.Time) {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = d.clock.AfterFunc(d.schedule.Next(now).Sub(now), d.flush)
+}
+
+func (d *digestBuffer) stop() {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+}
+
+// add records the alerts in the buffer. A state change is added to the history of an alert each time it is notified
+// with a different state than the last one.
+func (d *digestBuffer) add(ctx context.Context, alerts ...*types.Alert) {
+	d.addLocked(ctx, alerts...)
+	if d.onChange != nil {
+		d.onChange()
+	}
+}
+
+func (d *digestBuffer) addLocked(ctx context.Context, alerts ...*types.Alert) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	now := d.clock.Now()
+	for _, a := range alerts {
+		fp := a.Fingerprint()
+		entry, ok := d.alerts[fp]
+		if !ok {
+			entry = &digestEntry{}
+			d.alerts[fp] = entry
+		}
+		entry.alert = a
+
+		change := DigestStateChange{State: string(model.AlertFiring), At: a.StartsAt}
+		if a.ResolvedAt(now) {
+			change = DigestStateChange{State: string(model.AlertResolved), At: a.EndsAt}
+		}
+		if n := len(entry.history); n > 0 && entry.history[n-1].State == change.State {
+			continue
+		}
+		entry.history = append(entry.history, change)
+		if len(entry.history) > maxDigestHistory {
+			entry.history = entry.history[len(entry.history)-maxDigestHistory:]
+		}
+	}
+	d.sendCtx = context.WithoutCancel(ctx)
+}
+
+// flush sends the buffered alerts as a digest and starts a new period.
+func (d *digestBuffer) flush() {
+	d.mtx.Lock()
+	now := d.clock.Now()
+	data := d.dataLocked(now)
+	entries := d.alerts
+	settings, tmpl, next := d.settings, d.tmpl, d.next
+	ctx, receiver := d.sendCtx, d.receiver
+	d.alerts = make(map[model.Fingerprint]*digestEntry)
+	d.periodStart = now
+	d.sendCtx = nil
+	if d.timer != nil {
+		d.scheduleLocked(now)
+	}
+	d.mtx.Unlock()
+
+	if len(entries) == 0 || next == nil {
+		return
+	}
+	if d.onChange != nil {
+		d.onChange()
+	}
+
+	alert, err := newScheduledDigestAlert(now, data, settings, tmpl)
+	if err != nil {
+		d.logger.Error("Failed to render digest, falling back to the default templates", "receiver", d.receiver, "integration", d.integration, "error", err)
+		alert, err = newScheduledDigestAlert(now, data, DigestSettings{Title: DefaultDigestTitle, Message: DefaultDigestMessage}, tmpl)
+		if err != nil {
+			d.logger.Error("Failed to render digest", "receiver", d.receiver, "integration", d.integration, "error", err)
+			return
+		}
+	}
+
+	if ctx == nil {
+		// The alerts were restored after a restart, so the digest is sent like health checks.
+		ctx = notify.WithGroupKey(context.Background(), fmt.Sprintf("%s-%s-%d", receiver, alert.Labels.Fingerprint(), now.Unix()))
+		ctx = notify.WithGroupLabels(ctx, alert.Labels)
+		ctx = notify.WithReceiverName(ctx, receiver)
+		ctx = notify.WithNow(ctx, now)
+	}
+	ctx, cancel := context.WithTimeout(ctx, digestSendTimeout)
+	defer cancel()
+	if _, err := next.Notify(ctx, alert); err != nil {
+		d.logger.Error("Failed to send digest", "receiver", d.receiver, "integration", d.integration, "alerts", len(entries), "error", err)
+	}
+}
+
+func (d *digestBuffer) dataLocked(now time.Time) DigestData {
+	data := DigestData{
+		Receiver: d.receiver,
+		From:     d.periodStart,
+		To:       now,
+		Alerts:   make([]DigestAlert, 0, len(d.alerts)),
+	}
+	for fp, entry := range d.alerts {
+		status := string(entry.alert.StatusAt(now))
+		if status == string(model.AlertFiring) {
+			data.NumFiring++
+		} else {
+			data.NumResolved++
+		}
+		data.Alerts = append(data.Alerts, DigestAlert{
+			Status:       status,
+			Labels:       labelSetToKV(entry.alert.Labels),
+			Annotations:  labelSetToKV(entry.alert.Annotations),
+			StartsAt:     entry.alert.StartsAt,
+			EndsAt:       entry.alert.EndsAt,
+			GeneratorURL: entry.alert.GeneratorURL,
+			Fingerprint:  fp.String(),
+			History:      append([]DigestStateChange(nil), entry.history...),
+		})
+	}
+	sort.Slice(data.Alerts, func(i, j int) bool {
+		if !data.Alerts[i].StartsAt.Equal(data.Alerts[j].StartsAt) {
+			return data.Alerts[i].StartsAt.Before(data.Alerts[j].StartsAt)
+		}
+		return data.Alerts[i].Fingerprint < data.Alerts[j].Fingerprint
+	})
+	return data
+}
+
+// snapshot returns the state of the buffer to save.
+func (d *digestBuffer) snapshot() persistedDigest {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	p := persistedDigest{PeriodStart: d.periodStart, Alerts: make([]persistedDigestAlert, 0, len(d.alerts))}
+	for _, entry := range d.alerts {
+		p.Alerts = append(p.Alerts, persistedDigestAlert{Alert: *entry.alert, History: append([]DigestStateChange(nil), entry.history...)})
+	}
+	return p
+}
+
+// restore adds the alerts of a saved state to the buffer. The period of the digest starts when the saved period started.
+func (d *digestBuffer) restore(p persistedDigest) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if p.PeriodStart.Before(d.periodStart) {
+		d.periodStart = p.PeriodStart
+	}
+	for _, a := range p.Alerts {
+		alert := a.Alert
+		d.alerts[alert.Fingerprint()] = &digestEntry{alert: &alert, history: a.History}
+	}
+}
+
+// digestState is the saved state of the digest buffers of an Alertmanager, keyed by integration UID, so that alerts
+// buffered for a digest are not lost when Grafana restarts. It only holds the buffers that have alerts. In high
+// availability mode, the instances share the saved state: the instance that notifies the integration, which is the
+// one that buffers its alerts, saves it, and an instance that restarts restores it.
+type digestState map[string]persistedDigest
+
+type persistedDigest struct {
+	PeriodStart time.Time              `json:"periodStart"`
+	Alerts      []persistedDigestAlert `json:"alerts"`
+}
+
+type persistedDigestAlert struct {
+	Alert   types.Alert         `json:"alert"`
+	History []DigestStateChange `json:"history"`
+}
+
+func (s digestState) MarshalBinary() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func labelSetToKV(ls model.LabelSet) alertingTemplates.KV {
+	kv := make(alertingTemplates.KV, len(ls))
+	for k, v := range ls {
+		kv[string(k)] = string(v)
+	}
+	return kv
+}
+
+// newScheduledDigestAlert creates the alert that carries a digest. Its summary and description are the rendered
+// title and message of the digest, and it is firing as long as one of the alerts of the digest is firing.
+func newScheduledDigestAlert(now time.Time, data DigestData, settings DigestSettings, tmpl *alertingTemplates.Template) (*types.Alert, error) {
+	title, err := tmpl.ExecuteTextString(settings.Title, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render title: %w", err)
+	}
+	message, err := tmpl.ExecuteTextString(settings.Message, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render message: %w", err)
+	}
+
+	var endsAt time.Time
+	if data.NumFiring == 0 {
+		endsAt = now
+	}
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{
+				model.AlertNameLabel: ScheduledDigestAlertName,
+				"receiver":           model.LabelValue(data.Receiver),
+			},
+			Annotations: model.LabelSet{
+				"summary":     model.LabelValue(title),
+				"description": model.LabelValue(message),
+			},
+			StartsAt: data.From,
+			EndsAt:   endsAt,
+		},
+		UpdatedAt: now,
+	}, nil
+}
+
+// digestNotifier buffers the alerts of an integration in digest mode instead of notifying them.
+type digestNotifier struct {
+	buffer *digestBuffer
+}
+
+func (n *digestNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	n.buffer.add(ctx, alerts...)
+	return false, nil
+}
+
+// withDigests wraps the integrations of the receiver that are in digest mode.
+func (am *alertmanager) withDigests(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		cfg := integrationConfigFor(receiver, integration.Name(), integration.Index())
+		if cfg == nil || cfg.UID == "" {
+			result = append(result, integration)
+			continue
+		}
+		settings, schedule, err := parseDigestSettings(cfg.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid digest settings of integration %q (UID: %s) of receiver %q: %w", cfg.Type, cfg.UID, receiver.Name, err)
+		}
+		if settings == nil {
+			result = append(result, integration)
+			continue
+		}
+		// Render the templates with empty data to report errors when the configuration is applied rather than
+		// when the digest is sent.
+		if _, err := newScheduledDigestAlert(am.clock.Now(), DigestData{}, *settings, tmpl); err != nil {
+			return nil, fmt.Errorf("invalid digest settings of integration %q (UID: %s) of receiver %q: %w", cfg.Type, cfg.UID, receiver.Name, err)
+		}
+		n := &digestNotifier{buffer: am.digestBufferFor(receiver.Name, cfg, *settings, schedule, tmpl, integration)}
+		result = append(result, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiver.Name))
+	}
+	return result, nil
+}
+
+func (am *alertmanager) digestBufferFor(receiver string, cfg *alertingNotify.GrafanaIntegrationConfig, settings DigestSettings, schedule cron.Schedule, tmpl *alertingTemplates.Template, next alertingNotify.Notifier) *digestBuffer {
+	am.digestsMtx.Lock()
+	defer am.digestsMtx.Unlock()
+	d, ok := am.digests[cfg.UID]
+	if !ok {
+		d = newDigestBuffer(receiver, cfg.Type, am.clock, am.logger)
+		d.onChange = am.saveDigests
+		if p, ok := am.restoredDigests[cfg.UID]; ok {
+			d.restore(p)
+			delete(am.restoredDigests, cfg.UID)
+		}
+		am.digests[cfg.UID] = d
+	}
+	d.update(receiver, settings, schedule, tmpl, next)
+	return d
+}
+
+// saveDigests saves the alerts of the digest buffers, so that they are restored when the Alertmanager is created again.
+func (am *alertmanager) saveDigests() {
+	if am.stateStore == nil {
+		return
+	}
+	// Snapshots are taken and saved one at a time, so that an older snapshot never overwrites a newer one.
+	am.digestsSaveMtx.Lock()
+	defer am.digestsSaveMtx.Unlock()
+
+	am.digestsMtx.Lock()
+	state := make(digestState, len(am.digests))
+	for uid, d := range am.digests {
+		if p := d.snapshot(); len(p.Alerts) > 0 {
+			state[uid] = p
+		}
+	}
+	am.digestsMtx.Unlock()
+
+	if _, err := am.stateStore.SaveDigests(context.Background(), state); err != nil {
+		am.logger.Error("Failed to save the digest buffers", "error", err)
+	}
+}
+
+// restoreDigests reads the saved alerts of the digest buffers. They are added to the buffers of the integrations when
+// the configuration is applied.
+func restoreDigests(content string) (digestState, error) {
+	state := digestState{}
+	if content == "" {
+		return state, nil
+	}
+	if err := json.Unmarshal([]byte(content), &state); err != nil {
+		return digestState{}, err
+	}
+	return state, nil
+}
+
+// pruneDigestBuffers removes the buffers of the integrations that no longer exist or are no longer in digest mode.
+// Alerts buffered or restored for them are discarded.
+func (am *alertmanager) pruneDigestBuffers(cfg *apimodels.PostableUserConfig) {
+	keep := make(map[string]struct{})
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			if settings, _, err := parseDigestSettings(json.RawMessage(gr.Settings)); err == nil && settings != nil {
+				keep[gr.UID] = struct{}{}
+			}
+		}
+	}
+
+	am.digestsMtx.Lock()
+	defer am.digestsMtx.Unlock()
+	for uid, d := range am.digests {
+		if _, ok := keep[uid]; !ok {
+			d.stop()
+			delete(am.digests, uid)
+		}
+	}
+	am.restoredDigests = nil
+}
@@ -0,0 +1,215 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestParseDigestSettings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings string
+		expected *DigestSettings
+		err      string
+	}{
+		{
+			name:     "no digest",
+			settings: `{"url": "http://localhost"}`,
+		},
+		{
+			name:     "default templates",
+			settings: `{"digest": {"schedule": "@hourly"}}`,
+			expected: &DigestSettings{Schedule: "@hourly", Title: DefaultDigestTitle, Message: DefaultDigestMessage},
+		},
+		{
+			name:     "custom templates",
+			settings: `{"digest": {"schedule": "0 9 * * 1-5", "title": "Daily digest", "message": "{{ len .Alerts }}"}}`,
+			expected: &DigestSettings{Schedule: "0 9 * * 1-5", Title: "Daily digest", Message: "{{ len .Alerts }}"},
+		},
+		{
+			name:     "missing schedule",
+			settings: `{"digest": {}}`,
+			err:      "schedule is required",
+		},
+		{
+			name:     "invalid schedule",
+			settings: `{"digest": {"schedule": "every hour"}}`,
+			err:      "invalid schedule",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, schedule, err := parseDigestSettings([]byte(tc.settings))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, settings)
+			if tc.expected != nil {
+				require.NotNil(t, schedule)
+			}
+		})
+	}
+}
+
+func TestDigestNotifier(t *testing.T) {
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+	tmpl := alertingTemplates.ForTests(t)
+	settings, schedule, err := parseDigestSettings([]byte(`{"digest": {"schedule": "@hourly"}}`))
+	require.NoError(t, err)
+
+	next := &recordingNotifier{}
+	buffer := newDigestBuffer("low-severity", "slack", mockClock, log.NewNopLogger())
+	buffer.update("low-severity", *settings, schedule, tmpl, next)
+	t.Cleanup(buffer.stop)
+	n := &digestNotifier{buffer: buffer}
+
+	firing := newTestAlert("disk-full", mockClock.Now().Add(-time.Minute))
+	firing.Annotations = model.LabelSet{"summary": "Disk is almost full"}
+	flapping := newTestAlert("high-latency", mockClock.Now())
+
+	retry, err := n.Notify(context.Background(), firing, flapping)
+	require.NoError(t, err)
+	require.False(t, retry)
+
+	mockClock.Add(10 * time.Minute)
+	resolved := *flapping
+	resolved.EndsAt = mockClock.Now()
+	_, err = n.Notify(context.Background(), &resolved)
+	require.NoError(t, err)
+
+	// Notifying the same state again does not change the history.
+	mockClock.Add(time.Minute)
+	_, err = n.Notify(context.Background(), firing)
+	require.NoError(t, err)
+	require.Empty(t, next.Calls())
+
+	// The digest is sent at the top of the hour.
+	mockClock.Add(19 * time.Minute)
+	require.Eventually(t, func() bool { return len(next.Calls()) == 1 }, time.Second, 10*time.Millisecond)
+
+	digest := next.Calls()[0]
+	require.Len(t, digest, 1)
+	assert.Equal(t, model.LabelValue(ScheduledDigestAlertName), digest[0].Labels[model.AlertNameLabel])
+	assert.Equal(t, model.LabelValue("low-severity"), digest[0].Labels["receiver"])
+	assert.False(t, digest[0].ResolvedAt(mockClock.Now()))
+	assert.Equal(t, "2 alerts for low-severity (1 firing, 1 resolved)", string(digest[0].Annotations["summary"]))
+
+	description := string(digest[0].Annotations["description"])
+	assert.Contains(t, description, "Alerts from 2024-05-01 10:30:00 UTC to 2024-05-01 11:00:00 UTC")
+	assert.Contains(t, description, "[FIRING] disk-full: Disk is almost full")
+	assert.Contains(t, description, "[RESOLVED] high-latency")
+	assert.Contains(t, description, "  - firing at 2024-05-01 10:30:00 UTC\n  - resolved at 2024-05-01 10:40:00 UTC")
+
+	t.Run("no digest is sent for an empty period", func(t *testing.T) {
+		mockClock.Add(time.Hour)
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, next.Calls(), 1)
+	})
+}
+
+func TestNewScheduledDigestAlert(t *testing.T) {
+	now := time.Now()
+	tmpl := alertingTemplates.ForTests(t)
+	data := DigestData{
+		Receiver: "team-a",
+		From:     now.Add(-time.Hour),
+		To:       now,
+		Alerts: []DigestAlert{
+			{Status: "resolved", Labels: alertingTemplates.KV{"alertname": "a"}},
+			{Status: "resolved", Labels: alertingTemplates.KV{"alertname": "b"}},
+		},
+		NumResolved: 2,
+	}
+
+	t.Run("custom templates are executed with the digest data", func(t *testing.T) {
+		settings := DigestSettings{
+			Title:   `{{ .Receiver | toUpper }}`,
+			Message: `{{ range .Alerts }}{{ .Labels.alertname }}{{ end }}`,
+		}
+		alert, err := newScheduledDigestAlert(now, data, settings, tmpl)
+		require.NoError(t, err)
+		assert.Equal(t, model.LabelValue("TEAM-A"), alert.Annotations["summary"])
+		assert.Equal(t, model.LabelValue("ab"), alert.Annotations["description"])
+		assert.True(t, alert.ResolvedAt(now))
+		assert.Equal(t, data.From, alert.StartsAt)
+	})
+
+	t.Run("invalid templates return an error", func(t *testing.T) {
+		_, err := newScheduledDigestAlert(now, data, DigestSettings{Title: `{{ .Missing }}`, Message: "x"}, tmpl)
+		require.ErrorContains(t, err, "failed to render title")
+	})
+}
+
+func TestDigestBuffer_HistoryIsBounded(t *testing.T) {
+	mockClock := clock.NewMock()
+	buffer := newDigestBuffer("r", "slack", mockClock, log.NewNopLogger())
+	a := newTestAlert("flapping", mockClock.Now())
+	for i := 0; i < maxDigestHistory+10; i++ {
+		mockClock.Add(time.Minute)
+		next := *a
+		next.StartsAt = mockClock.Now()
+		if i%2 == 1 {
+			next.EndsAt = mockClock.Now()
+		}
+		buffer.add(context.Background(), []*types.Alert{&next}...)
+	}
+	require.Len(t, buffer.alerts[a.Fingerprint()].history, maxDigestHistory)
+}
+
+func TestDigestBuffer_Restore(t *testing.T) {
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+	tmpl := alertingTemplates.ForTests(t)
+	settings, schedule, err := parseDigestSettings([]byte(`{"digest": {"schedule": "@hourly"}}`))
+	require.NoError(t, err)
+	fs := NewFileStore(1, fakes.NewFakeKVStore(t))
+
+	// The buffer is saved each time alerts are added.
+	am := &alertmanager{clock: mockClock, logger: log.NewNopLogger(), stateStore: fs, digests: make(map[string]*digestBuffer)}
+	cfg := &alertingNotify.GrafanaIntegrationConfig{UID: "uid", Type: "slack"}
+	buffer := am.digestBufferFor("low-severity", cfg, *settings, schedule, tmpl, &recordingNotifier{})
+	buffer.add(context.Background(), newTestAlert("disk-full", mockClock.Now()))
+	buffer.stop()
+
+	// The saved alerts are added to the buffer of the same integration after a restart, and sent with the next digest.
+	content, err := fs.GetDigests(context.Background())
+	require.NoError(t, err)
+	restored, err := restoreDigests(content)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+
+	mockClock.Add(10 * time.Minute)
+	next := &recordingNotifier{}
+	am = &alertmanager{clock: mockClock, logger: log.NewNopLogger(), stateStore: fs, digests: make(map[string]*digestBuffer), restoredDigests: restored}
+	buffer = am.digestBufferFor("low-severity", cfg, *settings, schedule, tmpl, next)
+	t.Cleanup(buffer.stop)
+
+	mockClock.Add(20 * time.Minute)
+	require.Eventually(t, func() bool { return len(next.Calls()) == 1 }, time.Second, 10*time.Millisecond)
+	digest := next.Calls()[0]
+	assert.Equal(t, "1 alerts for low-severity (1 firing, 0 resolved)", string(digest[0].Annotations["summary"]))
+	assert.Contains(t, string(digest[0].Annotations["description"]), "Alerts from 2024-05-01 10:30:00 UTC to 2024-05-01 11:00:00 UTC")
+
+	// Once sent, the alerts are no longer saved.
+	require.Eventually(t, func() bool {
+		content, err := fs.GetDigests(context.Background())
+		require.NoError(t, err)
+		return content == "{}"
+	}, time.Second, 10*time.Millisecond)
+}
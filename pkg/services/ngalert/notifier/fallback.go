@@ -0,0 +1,304 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+// fallbackNotifyTimeout is the timeout for re-delivering a notification through a fallback receiver.
+const fallbackNotifyTimeout = time.Minute
+
+type fallbackNotificationKey struct{}
+
+// withFallbackNotification marks the context of a notification re-delivered through a fallback receiver,
+// so that it is not re-delivered again if the fallback receiver fails too.
+func withFallbackNotification(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fallbackNotificationKey{}, true)
+}
+
+func isFallbackNotification(ctx context.Context) bool {
+	v, ok := ctx.Value(fallbackNotificationKey{}).(bool)
+	return ok && v
+}
+
+// fallbackNotifier records the result of each notification of an integration in the fallback tracker of its receiver.
+type fallbackNotifier struct {
+	tracker     *fallbackTracker
+	integration string
+	next        alertingNotify.Notifier
+}
+
+func (n *fallbackNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	retry, err := n.next.Notify(ctx, alerts...)
+	if !isFallbackNotification(ctx) {
+		n.tracker.record(ctx, n.integration, err, alerts)
+	}
+	return retry, err
+}
+
+// routeFallbackKey identifies the routes of a receiver that have a given route key.
+type routeFallbackKey struct {
+	receiver string
+	routeKey string
+}
+
+// routeFallbacks are the fallback receivers of the routes of a configuration.
+type routeFallbacks struct {
+	// byRoute are the fallback receivers of the routes, including the ones inherited from parent routes, keyed by
+	// the receiver and the key of the route.
+	byRoute map[routeFallbackKey]string
+	// receivers are the receivers of the routes that have a fallback receiver.
+	receivers map[string]struct{}
+}
+
+// newRouteFallbacks resolves the fallback receivers of the routes of the configuration. The keys of the routes are
+// computed like the dispatcher of the Alertmanager does, so that they match the group keys of notifications.
+// Sibling routes with the same matchers have the same key, so routes with the same receiver and key must
+// have the same fallback receiver.
+func newRouteFallbacks(cfg *apimodels.PostableUserConfig) (routeFallbacks, error) {
+	f := routeFallbacks{byRoute: make(map[routeFallbackKey]string), receivers: make(map[string]struct{})}
+	if len(cfg.RouteFallbackReceivers) == 0 {
+		return f, nil
+	}
+	if err := apimodels.ValidateRouteFallbackReceivers(cfg.AlertmanagerConfig.Route, cfg.RouteFallbackReceivers, cfg.AlertmanagerConfig.Receivers); err != nil {
+		return f, err
+	}
+
+	seen := make(map[routeFallbackKey]string)
+	var walk func(r *dispatch.Route, path, fallback string) error
+	walk = func(r *dispatch.Route, path, fallback string) error {
+		if explicit, ok := cfg.RouteFallbackReceivers[path]; ok {
+			fallback = explicit
+		}
+		// An inherited fallback receiver does not apply to the routes that notify it.
+		routeFallback := fallback
+		if routeFallback == r.RouteOpts.Receiver {
+			routeFallback = ""
+		}
+		key := routeFallbackKey{receiver: r.RouteOpts.Receiver, routeKey: r.Key()}
+		if other, ok := seen[key]; ok && other != routeFallback {
+			return fmt.Errorf("routes %s with receiver %q have the same matchers but different fallback receivers", key.routeKey, key.receiver)
+		}
+		seen[key] = routeFallback
+		if routeFallback != "" {
+			f.byRoute[key] = routeFallback
+			f.receivers[r.RouteOpts.Receiver] = struct{}{}
+		}
+		for i, child := range r.Routes {
+			if err := walk(child, apimodels.RoutePath(path, i), fallback); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil), "", ""); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// fallbackFor returns the fallback receiver of the route of a notification of the receiver, given the group key of
+// the notification which is made of the key of its route and of the labels of the group. Route keys may contain
+// colons, so the longest route key that the group key starts with is the one of the route.
+func (f routeFallbacks) fallbackFor(receiver, groupKey string) string {
+	var route, fallback string
+	for key, fb := range f.byRoute {
+		if key.receiver == receiver && len(key.routeKey) > len(route) && strings.HasPrefix(groupKey, key.routeKey+":") {
+			route, fallback = key.routeKey, fb
+		}
+	}
+	return fallback
+}
+
+// fallbackFlush holds the results of the integrations of a receiver for a single flush of an aggregation group.
+type fallbackFlush struct {
+	ctx      context.Context
+	groupKey string
+	results  map[string]error
+	alerts   map[model.Fingerprint]*types.Alert
+}
+
+// fallbackTracker collects the results of the integrations of a receiver for each flush of an aggregation group.
+// The integrations of a flush are retried until they succeed or the flush times out, so the results are evaluated
+// once the context of the flush is done. If every integration of the receiver failed, the alerts of the flush are
+// re-delivered through the fallback receiver of the route of the aggregation group, if it has one.
+type fallbackTracker struct {
+	receiver        string
+	fallbackFor     func(groupKey string) string
+	numIntegrations int
+	deliver         func(ctx context.Context, receiver string, alerts ...*types.Alert) error
+	metrics         *metrics.AlertmanagerIntegrationHealthMetrics
+	logger          log.Logger
+
+	mtx     sync.Mutex
+	flushes map[string]*fallbackFlush
+}
+
+func newFallbackTracker(receiver string, fallbackFor func(string) string, numIntegrations int, deliver func(context.Context, string, ...*types.Alert) error, m *metrics.AlertmanagerIntegrationHealthMetrics, l log.Logger) *fallbackTracker {
+	return &fallbackTracker{
+		receiver:        receiver,
+		fallbackFor:     fallbackFor,
+		numIntegrations: numIntegrations,
+		deliver:         deliver,
+		metrics:         m,
+		logger:          l,
+		flushes:         make(map[string]*fallbackFlush),
+	}
+}
+
+// record stores the result of the last attempt of an integration in the flush the context belongs to.
+func (t *fallbackTracker) record(ctx context.Context, integration string, err error, alerts []*types.Alert) {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return
+	}
+	key := groupKey
+	if now, ok := notify.Now(ctx); ok {
+		key = fmt.Sprintf("%s@%d", key, now.UnixNano())
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	f, ok := t.flushes[key]
+	if !ok {
+		f = &fallbackFlush{
+			ctx:      ctx,
+			groupKey: groupKey,
+			results:  make(map[string]error, t.numIntegrations),
+			alerts:   make(map[model.Fingerprint]*types.Alert, len(alerts)),
+		}
+		t.flushes[key] = f
+		context.AfterFunc(ctx, func() { t.complete(key) })
+	}
+	f.results[integration] = err
+	for _, a := range alerts {
+		f.alerts[a.Fingerprint()] = a
+	}
+}
+
+// complete re-delivers the alerts of the flush through the fallback receiver of its route if every integration failed.
+func (t *fallbackTracker) complete(key string) {
+	t.mtx.Lock()
+	f, ok := t.flushes[key]
+	delete(t.flushes, key)
+	t.mtx.Unlock()
+	if !ok || len(f.results) < t.numIntegrations {
+		return
+	}
+	for _, err := range f.results {
+		if err == nil {
+			return
+		}
+	}
+	fallback := t.fallbackFor(f.groupKey)
+	if fallback == "" {
+		return
+	}
+
+	alerts := make([]*types.Alert, 0, len(f.alerts))
+	for _, a := range f.alerts {
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Fingerprint() < alerts[j].Fingerprint()
+	})
+
+	ctx, cancel := context.WithTimeout(withFallbackNotification(context.WithoutCancel(f.ctx)), fallbackNotifyTimeout)
+	defer cancel()
+	t.metrics.FallbackNotifications.WithLabelValues(t.receiver, fallback).Inc()
+	if err := t.deliver(ctx, fallback, alerts...); err != nil {
+		t.metrics.FallbackNotificationFailures.WithLabelValues(t.receiver, fallback).Inc()
+		t.logger.Error("Failed to re-deliver notification through the fallback receiver", "receiver", t.receiver, "fallback", fallback, "alerts", len(alerts), "error", err)
+		return
+	}
+	t.logger.Info("All integrations of the receiver failed, notification was re-delivered through the fallback receiver", "receiver", t.receiver, "fallback", fallback, "alerts", len(alerts))
+}
+
+// withFallback wraps the integrations of the receiver so that notifications of the routes that have a fallback
+// receiver are re-delivered through it when all of them fail. It must wrap all other notifiers, so that notifications
+// that are suppressed, collapsed into digests or acknowledged are not considered failed.
+func (am *alertmanager) withFallback(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	fallbacks := am.getRouteFallbacks()
+	if _, ok := fallbacks.receivers[receiver.Name]; !ok || len(integrations) == 0 {
+		return integrations
+	}
+	fallbackFor := func(groupKey string) string {
+		return fallbacks.fallbackFor(receiver.Name, groupKey)
+	}
+	tracker := newFallbackTracker(receiver.Name, fallbackFor, len(integrations), am.notifyReceiver, am.healthMetrics, am.logger)
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		n := &fallbackNotifier{tracker: tracker, integration: fmt.Sprintf("%s[%d]", integration.Name(), integration.Index()), next: integration}
+		result = append(result, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiver.Name))
+	}
+	return result
+}
+
+// notifyReceiver sends the alerts through all integrations of the receiver of the running configuration.
+// Notifications sent this way are not recorded in the notification log.
+func (am *alertmanager) notifyReceiver(ctx context.Context, name string, alerts ...*types.Alert) error {
+	var receiver *alertingNotify.NotifyReceiver
+	for _, r := range am.Base.GetReceivers() {
+		if r.Name() == name {
+			receiver = r
+			break
+		}
+	}
+	if receiver == nil {
+		return fmt.Errorf("receiver %q does not exist", name)
+	}
+
+	ctx = notify.WithReceiverName(ctx, name)
+	var errs []error
+	for _, integration := range receiver.Integrations() {
+		toSend := alerts
+		if !integration.SendResolved() {
+			toSend = make([]*types.Alert, 0, len(alerts))
+			for _, a := range alerts {
+				if !a.Resolved() {
+					toSend = append(toSend, a)
+				}
+			}
+		}
+		if len(toSend) == 0 {
+			continue
+		}
+		if _, err := integration.Notify(ctx, toSend...); err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: %w", integration.Name(), integration.Index(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (am *alertmanager) getRouteFallbacks() routeFallbacks {
+	am.fallbacksMtx.RLock()
+	defer am.fallbacksMtx.RUnlock()
+	return am.fallbacks
+}
+
+// setRouteFallbacks sets the fallback receivers of the routes of the configuration that is about to be applied.
+func (am *alertmanager) setRouteFallbacks(cfg *apimodels.PostableUserConfig) error {
+	fallbacks, err := newRouteFallbacks(cfg)
+	if err != nil {
+		return err
+	}
+	am.fallbacksMtx.Lock()
+	defer am.fallbacksMtx.Unlock()
+	am.fallbacks = fallbacks
+	return nil
+}
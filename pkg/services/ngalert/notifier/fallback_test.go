@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type fallbackDelivery struct {
+	receiver string
+	alerts   []*types.Alert
+}
+
+func TestFallbackTracker(t *testing.T) {
+	var (
+		mtx        sync.Mutex
+		deliveries []fallbackDelivery
+	)
+	deliver := func(ctx context.Context, receiver string, alerts ...*types.Alert) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		assert.True(t, isFallbackNotification(ctx))
+		deliveries = append(deliveries, fallbackDelivery{receiver: receiver, alerts: alerts})
+		return nil
+	}
+	delivered := func() []fallbackDelivery {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return deliveries
+	}
+
+	m := metrics.NewAlertmanagerIntegrationHealthMetrics(prometheus.NewRegistry())
+	fallbacks := routeFallbacks{byRoute: map[routeFallbackKey]string{{receiver: "on-call", routeKey: "{}"}: "backup"}}
+	fallbackFor := func(groupKey string) string { return fallbacks.fallbackFor("on-call", groupKey) }
+	tracker := newFallbackTracker("on-call", fallbackFor, 2, deliver, m, log.NewNopLogger())
+	failing := &recordingNotifier{err: errors.New("service unavailable")}
+	succeeding := &recordingNotifier{}
+
+	alert := newTestAlert("disk-full", time.Now())
+	flush := func(integrations ...*fallbackNotifier) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = notify.WithGroupKey(ctx, "{}:{alertname=\"disk-full\"}")
+		ctx = notify.WithNow(ctx, time.Now())
+		for _, n := range integrations {
+			_, _ = n.Notify(ctx, alert)
+		}
+		cancel()
+	}
+
+	t.Run("alerts are re-delivered when all integrations fail", func(t *testing.T) {
+		flush(
+			&fallbackNotifier{tracker: tracker, integration: "slack[0]", next: failing},
+			&fallbackNotifier{tracker: tracker, integration: "email[0]", next: failing},
+		)
+		require.Eventually(t, func() bool { return len(delivered()) == 1 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, fallbackDelivery{receiver: "backup", alerts: []*types.Alert{alert}}, delivered()[0])
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.FallbackNotifications.WithLabelValues("on-call", "backup")))
+	})
+
+	t.Run("alerts are not re-delivered when an integration succeeds", func(t *testing.T) {
+		flush(
+			&fallbackNotifier{tracker: tracker, integration: "slack[0]", next: failing},
+			&fallbackNotifier{tracker: tracker, integration: "email[0]", next: succeeding},
+		)
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, delivered(), 1)
+	})
+
+	t.Run("alerts are not re-delivered when an integration did not notify", func(t *testing.T) {
+		flush(&fallbackNotifier{tracker: tracker, integration: "slack[0]", next: failing})
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, delivered(), 1)
+	})
+
+	t.Run("only the last attempt of an integration counts", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = notify.WithGroupKey(ctx, "{}:{alertname=\"disk-full\"}")
+		ctx = notify.WithNow(ctx, time.Now())
+		slack := &fallbackNotifier{tracker: tracker, integration: "slack[0]", next: failing}
+		_, _ = slack.Notify(ctx, alert)
+		_, _ = (&fallbackNotifier{tracker: tracker, integration: "email[0]", next: failing}).Notify(ctx, alert)
+		slack.next = succeeding
+		_, _ = slack.Notify(ctx, alert)
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, delivered(), 1)
+	})
+
+	t.Run("alerts of routes without a fallback receiver are not re-delivered", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = notify.WithGroupKey(ctx, "{}/{team=\"ops\"}:{alertname=\"disk-full\"}")
+		_, _ = (&fallbackNotifier{tracker: tracker, integration: "slack[0]", next: failing}).Notify(ctx, alert)
+		_, _ = (&fallbackNotifier{tracker: tracker, integration: "email[0]", next: failing}).Notify(ctx, alert)
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, delivered(), 1)
+	})
+
+	t.Run("notifications re-delivered through a fallback are not tracked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(withFallbackNotification(context.Background()))
+		ctx = notify.WithGroupKey(ctx, "{}:{alertname=\"disk-full\"}")
+		_, _ = (&fallbackNotifier{tracker: tracker, integration: "slack[0]", next: failing}).Notify(ctx, alert)
+		_, _ = (&fallbackNotifier{tracker: tracker, integration: "email[0]", next: failing}).Notify(ctx, alert)
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, delivered(), 1)
+	})
+}
+
+func TestNewRouteFallbacks(t *testing.T) {
+	config := func(fallbacks map[string]string) *apimodels.PostableUserConfig {
+		return &apimodels.PostableUserConfig{
+			AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+				Config: apimodels.Config{
+					Route: &apimodels.Route{
+						Receiver: "default",
+						Routes: []*apimodels.Route{
+							{
+								Receiver:       "on-call",
+								ObjectMatchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "team", Value: "ops"}},
+								Routes: []*apimodels.Route{
+									{ObjectMatchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "severity", Value: "critical"}}},
+									{Receiver: "backup", ObjectMatchers: apimodels.ObjectMatchers{{Type: labels.MatchEqual, Name: "severity", Value: "info"}}},
+								},
+							},
+						},
+					},
+				},
+				Receivers: []*apimodels.PostableApiReceiver{
+					{Receiver: config.Receiver{Name: "default"}},
+					{Receiver: config.Receiver{Name: "on-call"}},
+					{Receiver: config.Receiver{Name: "backup"}},
+				},
+			},
+			RouteFallbackReceivers: fallbacks,
+		}
+	}
+
+	t.Run("fallback receivers are inherited by child routes", func(t *testing.T) {
+		fallbacks, err := newRouteFallbacks(config(map[string]string{"0": "backup"}))
+		require.NoError(t, err)
+		require.Equal(t, map[routeFallbackKey]string{
+			{receiver: "on-call", routeKey: `{}/{team="ops"}`}:                       "backup",
+			{receiver: "on-call", routeKey: `{}/{team="ops"}/{severity="critical"}`}: "backup",
+		}, fallbacks.byRoute)
+		require.Equal(t, map[string]struct{}{"on-call": {}}, fallbacks.receivers)
+
+		assert.Equal(t, "backup", fallbacks.fallbackFor("on-call", `{}/{team="ops"}/{severity="critical"}:{alertname="disk-full"}`))
+		assert.Equal(t, "backup", fallbacks.fallbackFor("on-call", `{}/{team="ops"}:{alertname="disk-full"}`))
+		assert.Equal(t, "", fallbacks.fallbackFor("backup", `{}/{team="ops"}/{severity="info"}:{alertname="disk-full"}`))
+		assert.Equal(t, "", fallbacks.fallbackFor("default", `{}:{alertname="disk-full"}`))
+	})
+
+	t.Run("fallback receivers of child routes override the one of their parent", func(t *testing.T) {
+		fallbacks, err := newRouteFallbacks(config(map[string]string{"": "backup", "0.0": "default"}))
+		require.NoError(t, err)
+		require.Equal(t, map[routeFallbackKey]string{
+			{receiver: "default", routeKey: `{}`}:                                    "backup",
+			{receiver: "on-call", routeKey: `{}/{team="ops"}`}:                       "backup",
+			{receiver: "on-call", routeKey: `{}/{team="ops"}/{severity="critical"}`}: "default",
+		}, fallbacks.byRoute)
+	})
+
+	t.Run("fallback receiver of an unknown route", func(t *testing.T) {
+		_, err := newRouteFallbacks(config(map[string]string{"1": "backup"}))
+		require.EqualError(t, err, `fallback receiver "backup" is set on route "1", which does not exist`)
+	})
+
+	t.Run("receiver of the route is its fallback receiver", func(t *testing.T) {
+		_, err := newRouteFallbacks(config(map[string]string{"0": "on-call"}))
+		require.EqualError(t, err, `receiver "on-call" of route "0" cannot be its fallback receiver`)
+	})
+
+	t.Run("sibling routes with the same matchers and receiver have different fallback receivers", func(t *testing.T) {
+		cfg := config(map[string]string{"0": "backup"})
+		sibling := *cfg.AlertmanagerConfig.Route.Routes[0]
+		sibling.Routes = nil
+		cfg.AlertmanagerConfig.Route.Routes = append(cfg.AlertmanagerConfig.Route.Routes, &sibling)
+		_, err := newRouteFallbacks(cfg)
+		require.EqualError(t, err, `routes {}/{team="ops"} with receiver "on-call" have the same matchers but different fallback receivers`)
+
+		cfg.RouteFallbackReceivers["1"] = "backup"
+		_, err = newRouteFallbacks(cfg)
+		require.NoError(t, err)
+	})
+}
@@ -15,6 +15,7 @@ const (
 	KVNamespace             = "alertmanager"
 	NotificationLogFilename = "notifications"
 	SilencesFilename        = "silences"
+	DigestsFilename         = "digests"
 )
 
 // FileStore is in charge of persisting the alertmanager files to the database.
@@ -64,6 +65,11 @@ func (fileStore *FileStore) contentFor(ctx context.Context, filename string) (st
 	return string(bytes), err
 }
 
+// GetDigests returns the saved alerts of the digest buffers from kvstore.
+func (fileStore *FileStore) GetDigests(ctx context.Context) (string, error) {
+	return fileStore.contentFor(ctx, DigestsFilename)
+}
+
 // SaveSilences saves the silences to the database and returns the size of the unencoded state.
 func (fileStore *FileStore) SaveSilences(ctx context.Context, st alertingNotify.State) (int64, error) {
 	return fileStore.persist(ctx, SilencesFilename, st)
@@ -74,6 +80,11 @@ func (fileStore *FileStore) SaveNotificationLog(ctx context.Context, st alerting
 	return fileStore.persist(ctx, NotificationLogFilename, st)
 }
 
+// SaveDigests saves the alerts of the digest buffers to the database and returns the size of the unencoded state.
+func (fileStore *FileStore) SaveDigests(ctx context.Context, st alertingNotify.State) (int64, error) {
+	return fileStore.persist(ctx, DigestsFilename, st)
+}
+
 // persist takes care of persisting the binary representation of internal state to the database as a base64 encoded string.
 func (fileStore *FileStore) persist(ctx context.Context, filename string, st alertingNotify.State) (int64, error) {
 	var size int64
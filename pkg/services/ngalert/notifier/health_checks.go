@@ -0,0 +1,297 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+const (
+	// healthCheckSettingsKey is the key of the integration settings that enables its periodic health checks.
+	healthCheckSettingsKey = "healthCheck"
+
+	// HealthCheckAlertName is the alertname of the synthetic alert sent by health checks.
+	HealthCheckAlertName = "ContactPointHealthCheck"
+
+	// HealthCheckLabel is the label that marks the synthetic alert of health checks, so that the receiving
+	// side can filter it out.
+	HealthCheckLabel = "__grafana_health_check__"
+
+	// minHealthCheckInterval is the minimum interval between two health checks of an integration,
+	// so that health checks do not flood the contact point.
+	minHealthCheckInterval = time.Minute
+
+	// healthCheckTimeout is the timeout for sending the synthetic alert of a health check.
+	healthCheckTimeout = 30 * time.Second
+)
+
+// HealthCheckSettings configure the periodic health checks of an integration. Health checks are disabled unless
+// they are enabled in the unified alerting settings and the "healthCheck" key of the integration settings is set. Each health check sends a synthetic alert through the
+// integration, which is resolved so that it does not page anyone.
+type HealthCheckSettings struct {
+	// Interval is the interval between two health checks.
+	Interval model.Duration `json:"interval"`
+}
+
+// parseHealthCheckSettings reads the health check settings from the settings of an integration.
+// It returns nil if health checks are not enabled.
+func parseHealthCheckSettings(settings json.RawMessage) (*HealthCheckSettings, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	var s map[string]json.RawMessage
+	if err := json.Unmarshal(settings, &s); err != nil {
+		return nil, err
+	}
+	raw, ok := s[healthCheckSettingsKey]
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+	var hc HealthCheckSettings
+	if err := json.Unmarshal(raw, &hc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", healthCheckSettingsKey, err)
+	}
+	if hc.Interval == 0 {
+		return nil, errors.New("interval is required")
+	}
+	if time.Duration(hc.Interval) < minHealthCheckInterval {
+		return nil, fmt.Errorf("interval must be at least %s", minHealthCheckInterval)
+	}
+	return &hc, nil
+}
+
+// healthChecker periodically sends a synthetic alert through an integration and records whether it succeeded.
+// Like notification limiters, it is kept across configuration reloads for as long as the integration exists and
+// has health checks enabled, so that its status is not lost.
+type healthChecker struct {
+	integration string
+	clock       clock.Clock
+	metrics     *metrics.AlertmanagerIntegrationHealthMetrics
+	logger      log.Logger
+
+	mtx      sync.Mutex
+	receiver string
+	interval time.Duration
+	notifier alertingNotify.Notifier
+	timer    *clock.Timer
+	status   *apimodels.IntegrationHealthCheckStatus
+}
+
+func newHealthChecker(integration string, c clock.Clock, m *metrics.AlertmanagerIntegrationHealthMetrics, l log.Logger) *healthChecker {
+	return &healthChecker{
+		integration: integration,
+		clock:       c,
+		metrics:     m,
+		logger:      l,
+	}
+}
+
+// update sets the integration to check, and reschedules the next health check if the interval changed.
+func (h *healthChecker) update(receiver string, interval time.Duration, n alertingNotify.Notifier) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.receiver = receiver
+	h.notifier = n
+	if h.timer != nil && h.interval == interval {
+		return
+	}
+	h.interval = interval
+	h.scheduleLocked()
+}
+
+func (h *healthChecker) scheduleLocked() {
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	h.timer = h.clock.AfterFunc(h.interval, h.run)
+}
+
+func (h *healthChecker) stop() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+}
+
+// run checks the integration and schedules the next health check, unless the checker was stopped in the meantime.
+func (h *healthChecker) run() {
+	h.check(context.Background())
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.timer != nil {
+		h.scheduleLocked()
+	}
+}
+
+// check sends the synthetic alert through the integration and records the result.
+func (h *healthChecker) check(ctx context.Context) {
+	h.mtx.Lock()
+	receiver, n := h.receiver, h.notifier
+	h.mtx.Unlock()
+	if n == nil {
+		return
+	}
+
+	now := h.clock.Now()
+	alert := newHealthCheckAlert(receiver, now)
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	ctx = notify.WithGroupKey(ctx, fmt.Sprintf("%s-%s-%d", receiver, alert.Labels.Fingerprint(), now.Unix()))
+	ctx = notify.WithGroupLabels(ctx, alert.Labels)
+	ctx = notify.WithReceiverName(ctx, receiver)
+	ctx = notify.WithNow(ctx, now)
+	_, err := n.Notify(ctx, alert)
+
+	h.metrics.HealthChecks.WithLabelValues(receiver, h.integration).Inc()
+	if err != nil {
+		h.metrics.HealthCheckFailures.WithLabelValues(receiver, h.integration).Inc()
+		h.logger.Warn("Health check of integration failed", "receiver", receiver, "integration", h.integration, "error", err)
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	status := &apimodels.IntegrationHealthCheckStatus{LastCheckAt: now, Healthy: err == nil}
+	if err != nil {
+		status.Error = err.Error()
+		status.ConsecutiveFailures = 1
+		if h.status != nil {
+			status.ConsecutiveFailures += h.status.ConsecutiveFailures
+		}
+	}
+	h.status = status
+}
+
+// Status returns the result of the last health check, or nil if the integration has not been checked yet.
+func (h *healthChecker) Status() *apimodels.IntegrationHealthCheckStatus {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.status == nil {
+		return nil
+	}
+	status := *h.status
+	return &status
+}
+
+// newHealthCheckAlert creates the synthetic alert sent by health checks. Unlike the alert of test notifications,
+// it is resolved, so that contact points which page on firing alerts are not paged every interval.
+func newHealthCheckAlert(receiver string, now time.Time) *types.Alert {
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{
+				model.AlertNameLabel: HealthCheckAlertName,
+				HealthCheckLabel:     "true",
+				"receiver":           model.LabelValue(receiver),
+			},
+			Annotations: model.LabelSet{
+				"summary":     "Health check of the contact point",
+				"description": "This is a resolved test notification sent by Grafana to check the contact point. No action is required.",
+			},
+			StartsAt: now.Add(-time.Second),
+			EndsAt:   now,
+		},
+		UpdatedAt: now,
+	}
+}
+
+// pendingHealthCheck is the health check of an integration of a configuration that is being applied.
+type pendingHealthCheck struct {
+	cfg         *alertingNotify.GrafanaIntegrationConfig
+	receiver    string
+	interval    time.Duration
+	integration *alertingNotify.Integration
+}
+
+// registerHealthChecks validates the health check settings of the integrations of the receiver, and keeps the health
+// checks of the integrations that have them enabled until the configuration is applied. Health checks are sent through
+// the integrations directly, so they are not subject to notification limits, digests nor acknowledgements. Health checks
+// reach the contact points, so they are started only if they are enabled in the unified alerting settings.
+func (am *alertmanager) registerHealthChecks(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) error {
+	pending := make([]pendingHealthCheck, 0, len(integrations))
+	for _, integration := range integrations {
+		cfg := integrationConfigFor(receiver, integration.Name(), integration.Index())
+		if cfg == nil || cfg.UID == "" {
+			continue
+		}
+		settings, err := parseHealthCheckSettings(cfg.Settings)
+		if err != nil {
+			return fmt.Errorf("invalid health check settings of integration %q (UID: %s) of receiver %q: %w", cfg.Type, cfg.UID, receiver.Name, err)
+		}
+		if settings == nil || !am.Settings.UnifiedAlerting.ContactPointHealthChecksEnabled {
+			continue
+		}
+		pending = append(pending, pendingHealthCheck{cfg: cfg, receiver: receiver.Name, interval: time.Duration(settings.Interval), integration: integration})
+	}
+
+	am.healthCheckersMtx.Lock()
+	defer am.healthCheckersMtx.Unlock()
+	am.pendingHealthChecks = append(am.pendingHealthChecks, pending...)
+	return nil
+}
+
+// startHealthChecks schedules the health checks registered while the configuration was applied. It must be called
+// only once the configuration is applied, so that integrations of a configuration that failed to apply are not checked.
+func (am *alertmanager) startHealthChecks() {
+	for _, p := range am.takePendingHealthChecks() {
+		am.healthCheckerFor(p.cfg).update(p.receiver, p.interval, p.integration)
+	}
+}
+
+// takePendingHealthChecks returns the health checks registered while the configuration was applied, and forgets them.
+func (am *alertmanager) takePendingHealthChecks() []pendingHealthCheck {
+	am.healthCheckersMtx.Lock()
+	defer am.healthCheckersMtx.Unlock()
+	pending := am.pendingHealthChecks
+	am.pendingHealthChecks = nil
+	return pending
+}
+
+func (am *alertmanager) healthCheckerFor(cfg *alertingNotify.GrafanaIntegrationConfig) *healthChecker {
+	am.healthCheckersMtx.Lock()
+	defer am.healthCheckersMtx.Unlock()
+	h, ok := am.healthCheckers[cfg.UID]
+	if !ok {
+		h = newHealthChecker(cfg.Type, am.clock, am.healthMetrics, am.logger)
+		am.healthCheckers[cfg.UID] = h
+	}
+	return h
+}
+
+// pruneHealthCheckers stops the health checks of the integrations that no longer exist or no longer have them enabled,
+// and all of them if health checks are disabled.
+func (am *alertmanager) pruneHealthCheckers(cfg *apimodels.PostableUserConfig) {
+	keep := make(map[string]struct{})
+	if am.Settings.UnifiedAlerting.ContactPointHealthChecksEnabled {
+		for _, r := range cfg.AlertmanagerConfig.Receivers {
+			for _, gr := range r.GrafanaManagedReceivers {
+				if settings, err := parseHealthCheckSettings(json.RawMessage(gr.Settings)); err == nil && settings != nil {
+					keep[gr.UID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	am.healthCheckersMtx.Lock()
+	defer am.healthCheckersMtx.Unlock()
+	for uid, h := range am.healthCheckers {
+		if _, ok := keep[uid]; !ok {
+			h.stop()
+			delete(am.healthCheckers, uid)
+		}
+	}
+}
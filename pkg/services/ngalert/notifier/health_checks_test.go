@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+func TestParseHealthCheckSettings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings string
+		expected *HealthCheckSettings
+		err      string
+	}{
+		{
+			name:     "no health check",
+			settings: `{"url": "http://localhost"}`,
+		},
+		{
+			name:     "interval",
+			settings: `{"healthCheck": {"interval": "15m"}}`,
+			expected: &HealthCheckSettings{Interval: model.Duration(15 * time.Minute)},
+		},
+		{
+			name:     "missing interval",
+			settings: `{"healthCheck": {}}`,
+			err:      "interval is required",
+		},
+		{
+			name:     "interval is too short",
+			settings: `{"healthCheck": {"interval": "10s"}}`,
+			err:      "interval must be at least 1m0s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, err := parseHealthCheckSettings([]byte(tc.settings))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, settings)
+		})
+	}
+}
+
+func TestHealthChecker(t *testing.T) {
+	mockClock := clock.NewMock()
+	m := metrics.NewAlertmanagerIntegrationHealthMetrics(prometheus.NewRegistry())
+	next := &recordingNotifier{}
+	h := newHealthChecker("slack", mockClock, m, log.NewNopLogger())
+	h.update("on-call", 15*time.Minute, next)
+	t.Cleanup(h.stop)
+
+	require.Nil(t, h.Status())
+
+	mockClock.Add(15 * time.Minute)
+	require.Eventually(t, func() bool { return h.Status() != nil }, time.Second, 10*time.Millisecond)
+	status := h.Status()
+	assert.True(t, status.Healthy)
+	assert.Equal(t, mockClock.Now(), status.LastCheckAt)
+
+	calls := next.Calls()
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0], 1)
+	assert.Equal(t, model.LabelValue(HealthCheckAlertName), calls[0][0].Labels[model.AlertNameLabel])
+	assert.Equal(t, model.LabelValue("on-call"), calls[0][0].Labels["receiver"])
+	assert.Equal(t, model.LabelValue("true"), calls[0][0].Labels[HealthCheckLabel])
+	assert.True(t, calls[0][0].ResolvedAt(mockClock.Now()), "health checks must not send firing alerts")
+
+	t.Run("failures are recorded", func(t *testing.T) {
+		next.mtx.Lock()
+		next.err = errors.New("connection refused")
+		next.mtx.Unlock()
+
+		h.check(context.Background())
+		h.check(context.Background())
+		status := h.Status()
+		assert.False(t, status.Healthy)
+		assert.Equal(t, "connection refused", status.Error)
+		assert.Equal(t, 2, status.ConsecutiveFailures)
+
+		assert.Equal(t, 3.0, testutil.ToFloat64(m.HealthChecks.WithLabelValues("on-call", "slack")))
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.HealthCheckFailures.WithLabelValues("on-call", "slack")))
+	})
+
+	t.Run("no health checks after stop", func(t *testing.T) {
+		h.stop()
+		n := len(next.Calls())
+		mockClock.Add(time.Hour)
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, next.Calls(), n)
+	})
+}
@@ -400,6 +400,26 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (Alertmanager, err
 	return orgAM, nil
 }
 
+// integrationStatusReporter is implemented by the Alertmanagers that keep track of the runtime status of their integrations.
+type integrationStatusReporter interface {
+	IntegrationStatuses() map[string]apimodels.IntegrationStatus
+}
+
+// GetIntegrationStatuses returns the runtime status of the integrations of the organization's Alertmanager, keyed by
+// integration UID. It returns an empty result if the Alertmanager does not keep track of it.
+func (moa *MultiOrgAlertmanager) GetIntegrationStatuses(_ context.Context, orgID int64) (map[string]apimodels.IntegrationStatus, error) {
+	am, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	reporter, ok := am.(integrationStatusReporter)
+	if !ok {
+		return nil, nil
+	}
+	return reporter.IntegrationStatuses(), nil
+}
+
 // CreateSilence creates a silence in the Alertmanager for the organization provided, returning the silence ID. It will
 // also persist the silence state to the kvstore immediately after creating the silence.
 func (moa *MultiOrgAlertmanager) CreateSilence(ctx context.Context, orgID int64, ps *alertingNotify.PostableSilence) (string, error) {
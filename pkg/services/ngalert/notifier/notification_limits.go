@@ -0,0 +1,467 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+const (
+	// notificationLimitsSettingsKey is the key of the integration settings that holds its notification limits.
+	notificationLimitsSettingsKey = "notificationLimits"
+
+	// DigestAlertName is the alertname of the synthetic alert that summarizes the alerts collapsed into a digest.
+	DigestAlertName = "NotificationDigest"
+
+	// maxDigestLines is the maximum number of collapsed alerts listed in the description of a digest.
+	maxDigestLines = 20
+
+	// digestFlushTimeout is the timeout for sending a digest once the rate limit window allows it.
+	digestFlushTimeout = 30 * time.Second
+
+	suppressReasonRateLimit           = "rate_limit"
+	suppressReasonMaxAlertsPerMessage = "max_alerts_per_message"
+)
+
+// NotificationLimits are the limits applied to the notifications of a single integration.
+// They are read from the "notificationLimits" key of the integration settings.
+type NotificationLimits struct {
+	// MaxNotifications is the maximum number of notifications sent within Window. Zero means no limit.
+	MaxNotifications int `json:"maxNotifications,omitempty"`
+	// Window is the sliding window MaxNotifications applies to.
+	Window model.Duration `json:"window,omitempty"`
+	// MaxAlertsPerMessage is the maximum number of alerts sent in a single notification. Zero means no limit.
+	MaxAlertsPerMessage int `json:"maxAlertsPerMessage,omitempty"`
+}
+
+func (l NotificationLimits) enabled() bool {
+	return l.MaxNotifications > 0 || l.MaxAlertsPerMessage > 0
+}
+
+// Validate checks that the limits are consistent.
+func (l NotificationLimits) Validate() error {
+	if l.MaxNotifications < 0 {
+		return errors.New("maxNotifications must not be negative")
+	}
+	if l.MaxAlertsPerMessage < 0 {
+		return errors.New("maxAlertsPerMessage must not be negative")
+	}
+	if l.Window < 0 {
+		return errors.New("window must not be negative")
+	}
+	if l.MaxNotifications > 0 && l.Window == 0 {
+		return errors.New("window is required when maxNotifications is set")
+	}
+	return nil
+}
+
+// parseNotificationLimits reads the notification limits from the settings of an integration.
+// It returns nil if no limits are configured.
+func parseNotificationLimits(settings json.RawMessage) (*NotificationLimits, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	var s map[string]json.RawMessage
+	if err := json.Unmarshal(settings, &s); err != nil {
+		return nil, err
+	}
+	raw, ok := s[notificationLimitsSettingsKey]
+	if !ok {
+		return nil, nil
+	}
+	var limits NotificationLimits
+	if err := json.Unmarshal(raw, &limits); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", notificationLimitsSettingsKey, err)
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+	if !limits.enabled() {
+		return nil, nil
+	}
+	return &limits, nil
+}
+
+// notificationLimiter enforces the notification limits of an integration. Notifications that exceed the rate limit
+// are buffered and sent as a single digest once the window allows it, and alerts that exceed the per-message limit
+// are collapsed into a digest alert. Its state is kept across configuration reloads for as long as the integration
+// exists and has limits configured.
+type notificationLimiter struct {
+	receiver    string
+	integration string
+	clock       clock.Clock
+	metrics     *metrics.AlertmanagerNotificationLimitMetrics
+	logger      log.Logger
+
+	mtx     sync.Mutex
+	limits  NotificationLimits
+	sent    []time.Time
+	pending map[model.Fingerprint]*types.Alert
+	status  apimodels.IntegrationStatus
+
+	// flushCtx and flushTo are the context and the notifier of the last suppressed notification.
+	// The digest of pending alerts is sent with them once the rate limit window allows it.
+	flushCtx   context.Context
+	flushTo    alertingNotify.Notifier
+	flushTimer *clock.Timer
+}
+
+func newNotificationLimiter(receiver, integration string, limits NotificationLimits, c clock.Clock, m *metrics.AlertmanagerNotificationLimitMetrics, l log.Logger) *notificationLimiter {
+	return &notificationLimiter{
+		receiver:    receiver,
+		integration: integration,
+		clock:       c,
+		metrics:     m,
+		logger:      l,
+		limits:      limits,
+		pending:     make(map[model.Fingerprint]*types.Alert),
+	}
+}
+
+// setLimits updates the limits of the limiter, keeping the notifications already sent in the current window.
+func (l *notificationLimiter) setLimits(receiver string, limits NotificationLimits) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.receiver = receiver
+	l.limits = limits
+}
+
+// Status returns a snapshot of the status of the limiter.
+func (l *notificationLimiter) Status() apimodels.IntegrationStatus {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	s := l.status
+	s.PendingDigestAlerts = len(l.pending)
+	return s
+}
+
+// stop cancels the pending digest, if any.
+func (l *notificationLimiter) stop() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.flushTimer != nil {
+		l.flushTimer.Stop()
+		l.flushTimer = nil
+	}
+}
+
+// notify sends the alerts through next if the limits allow it, otherwise the alerts are buffered for the next digest.
+func (l *notificationLimiter) notify(ctx context.Context, next alertingNotify.Notifier, alerts ...*types.Alert) (bool, error) {
+	l.mtx.Lock()
+	now := l.clock.Now()
+	if !l.allowLocked(now) {
+		l.suppressLocked(ctx, next, now, alerts)
+		l.mtx.Unlock()
+		return false, nil
+	}
+	pending := l.takePendingLocked()
+	limits := l.limits
+	l.mtx.Unlock()
+
+	retry, err := next.Notify(ctx, l.compose(now, limits, alerts, pending)...)
+	if err != nil && len(pending) > 0 {
+		// Keep the collapsed alerts so they are not lost if the notification is not retried.
+		l.mtx.Lock()
+		for _, a := range pending {
+			if _, ok := l.pending[a.Fingerprint()]; !ok {
+				l.pending[a.Fingerprint()] = a
+			}
+		}
+		l.mtx.Unlock()
+	}
+	return retry, err
+}
+
+// allowLocked records a notification at the given time and returns true if it is within the rate limit.
+func (l *notificationLimiter) allowLocked(now time.Time) bool {
+	if l.limits.MaxNotifications <= 0 {
+		return true
+	}
+	windowStart := now.Add(-time.Duration(l.limits.Window))
+	i := 0
+	for i < len(l.sent) && !l.sent[i].After(windowStart) {
+		i++
+	}
+	l.sent = l.sent[i:]
+	if len(l.sent) >= l.limits.MaxNotifications {
+		return false
+	}
+	l.sent = append(l.sent, now)
+	return true
+}
+
+func (l *notificationLimiter) suppressLocked(ctx context.Context, next alertingNotify.Notifier, now time.Time, alerts []*types.Alert) {
+	for _, a := range alerts {
+		l.pending[a.Fingerprint()] = a
+	}
+	l.status.SuppressedNotifications++
+	l.status.SuppressedAlerts += int64(len(alerts))
+	l.status.LastSuppressedAt = &now
+	if l.metrics != nil {
+		l.metrics.SuppressedNotifications.WithLabelValues(l.receiver, l.integration).Inc()
+		l.metrics.SuppressedAlerts.WithLabelValues(l.receiver, l.integration, suppressReasonRateLimit).Add(float64(len(alerts)))
+	}
+	l.flushCtx = context.WithoutCancel(ctx)
+	l.flushTo = next
+	l.scheduleFlushLocked(now)
+}
+
+func (l *notificationLimiter) scheduleFlushLocked(now time.Time) {
+	if l.flushTimer != nil || len(l.sent) == 0 {
+		return
+	}
+	wait := l.sent[0].Add(time.Duration(l.limits.Window)).Sub(now)
+	l.flushTimer = l.clock.AfterFunc(wait, l.flush)
+}
+
+// flush sends the pending alerts as a digest if the rate limit allows it, otherwise it tries again later.
+func (l *notificationLimiter) flush() {
+	l.mtx.Lock()
+	l.flushTimer = nil
+	if len(l.pending) == 0 || l.flushTo == nil {
+		l.mtx.Unlock()
+		return
+	}
+	now := l.clock.Now()
+	if !l.allowLocked(now) {
+		l.scheduleFlushLocked(now)
+		l.mtx.Unlock()
+		return
+	}
+	pending := l.takePendingLocked()
+	ctx, next, limits := l.flushCtx, l.flushTo, l.limits
+	l.flushCtx, l.flushTo = nil, nil
+	l.mtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, digestFlushTimeout)
+	defer cancel()
+	if _, err := next.Notify(ctx, l.compose(now, limits, nil, pending)...); err != nil {
+		l.logger.Error("Failed to send notification digest", "receiver", l.receiver, "integration", l.integration, "alerts", len(pending), "error", err)
+	}
+}
+
+func (l *notificationLimiter) takePendingLocked() []*types.Alert {
+	if len(l.pending) == 0 {
+		return nil
+	}
+	result := make([]*types.Alert, 0, len(l.pending))
+	for _, a := range l.pending {
+		result = append(result, a)
+	}
+	l.pending = make(map[model.Fingerprint]*types.Alert)
+	return result
+}
+
+// compose builds the alerts of a notification. Pending alerts that are not part of the notification, and alerts over
+// the per-message limit, are replaced by a single digest alert.
+func (l *notificationLimiter) compose(now time.Time, limits NotificationLimits, alerts []*types.Alert, pending []*types.Alert) []*types.Alert {
+	inMessage := make(map[model.Fingerprint]struct{}, len(alerts))
+	for _, a := range alerts {
+		inMessage[a.Fingerprint()] = struct{}{}
+	}
+	collapsed := make([]*types.Alert, 0, len(pending))
+	for _, a := range pending {
+		if _, ok := inMessage[a.Fingerprint()]; !ok {
+			collapsed = append(collapsed, a)
+		}
+	}
+
+	if limit := limits.MaxAlertsPerMessage; limit > 0 {
+		size := len(alerts)
+		if len(collapsed) > 0 {
+			size++
+		}
+		if size > limit {
+			keep := limit - 1
+			overflow := alerts[keep:]
+			alerts = alerts[:keep:keep]
+			collapsed = append(collapsed, overflow...)
+			l.mtx.Lock()
+			l.status.SuppressedAlerts += int64(len(overflow))
+			l.mtx.Unlock()
+			if l.metrics != nil {
+				l.metrics.SuppressedAlerts.WithLabelValues(l.receiver, l.integration, suppressReasonMaxAlertsPerMessage).Add(float64(len(overflow)))
+			}
+		}
+	}
+
+	if len(collapsed) == 0 {
+		return alerts
+	}
+	l.mtx.Lock()
+	l.status.DigestsSent++
+	l.mtx.Unlock()
+	if l.metrics != nil {
+		l.metrics.DigestNotifications.WithLabelValues(l.receiver, l.integration).Inc()
+	}
+	return append(alerts, newDigestAlert(now, collapsed))
+}
+
+// newDigestAlert creates an alert that summarizes the collapsed alerts. It carries the labels that are common to all
+// collapsed alerts, and it is firing as long as at least one of them is firing.
+func newDigestAlert(now time.Time, collapsed []*types.Alert) *types.Alert {
+	sort.Slice(collapsed, func(i, j int) bool {
+		if !collapsed[i].StartsAt.Equal(collapsed[j].StartsAt) {
+			return collapsed[i].StartsAt.Before(collapsed[j].StartsAt)
+		}
+		return collapsed[i].Labels.String() < collapsed[j].Labels.String()
+	})
+
+	labels := collapsed[0].Labels.Clone()
+	var firing int
+	startsAt, endsAt := collapsed[0].StartsAt, time.Time{}
+	var desc strings.Builder
+	for i, a := range collapsed {
+		for name, value := range labels {
+			if a.Labels[name] != value {
+				delete(labels, name)
+			}
+		}
+		status := "RESOLVED"
+		if !a.ResolvedAt(now) {
+			status = "FIRING"
+			firing++
+		} else if a.EndsAt.After(endsAt) {
+			endsAt = a.EndsAt
+		}
+		if i < maxDigestLines {
+			fmt.Fprintf(&desc, "[%s] %s\n", status, a.Labels.String())
+		}
+	}
+	if len(collapsed) > maxDigestLines {
+		fmt.Fprintf(&desc, "... and %d more\n", len(collapsed)-maxDigestLines)
+	}
+	if firing > 0 {
+		endsAt = time.Time{}
+	}
+	labels[model.AlertNameLabel] = DigestAlertName
+
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: labels,
+			Annotations: model.LabelSet{
+				"summary": model.LabelValue(fmt.Sprintf("%d alerts (%d firing, %d resolved) were collapsed into this digest because the notification limits of the contact point were exceeded",
+					len(collapsed), firing, len(collapsed)-firing)),
+				"description": model.LabelValue(desc.String()),
+			},
+			StartsAt: startsAt,
+			EndsAt:   endsAt,
+		},
+		UpdatedAt: now,
+	}
+}
+
+// limitedNotifier is a notifier that applies the notification limits of an integration before delegating to it.
+type limitedNotifier struct {
+	limiter *notificationLimiter
+	next    alertingNotify.Notifier
+}
+
+func (n *limitedNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	return n.limiter.notify(ctx, n.next, alerts...)
+}
+
+// withNotificationLimits wraps the integrations of the receiver that have notification limits configured.
+func (am *alertmanager) withNotificationLimits(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) ([]*alertingNotify.Integration, error) {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		cfg := integrationConfigFor(receiver, integration.Name(), integration.Index())
+		if cfg == nil || cfg.UID == "" {
+			result = append(result, integration)
+			continue
+		}
+		limits, err := parseNotificationLimits(cfg.Settings)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification limits of integration %q (UID: %s) of receiver %q: %w", cfg.Type, cfg.UID, receiver.Name, err)
+		}
+		if limits == nil {
+			result = append(result, integration)
+			continue
+		}
+		limited := &limitedNotifier{limiter: am.notificationLimiterFor(receiver.Name, cfg, *limits), next: integration}
+		result = append(result, alertingNotify.NewIntegration(limited, integration, integration.Name(), integration.Index(), receiver.Name))
+	}
+	return result, nil
+}
+
+// integrationConfigFor returns the configuration of the idx-th integration of the given type in the receiver.
+func integrationConfigFor(receiver *alertingNotify.APIReceiver, integrationType string, idx int) *alertingNotify.GrafanaIntegrationConfig {
+	i := 0
+	for _, cfg := range receiver.Integrations {
+		if !strings.EqualFold(cfg.Type, integrationType) {
+			continue
+		}
+		if i == idx {
+			return cfg
+		}
+		i++
+	}
+	return nil
+}
+
+func (am *alertmanager) notificationLimiterFor(receiver string, cfg *alertingNotify.GrafanaIntegrationConfig, limits NotificationLimits) *notificationLimiter {
+	am.limitersMtx.Lock()
+	defer am.limitersMtx.Unlock()
+	if l, ok := am.limiters[cfg.UID]; ok {
+		l.setLimits(receiver, limits)
+		return l
+	}
+	l := newNotificationLimiter(receiver, cfg.Type, limits, am.clock, am.limitMetrics, am.logger)
+	am.limiters[cfg.UID] = l
+	return l
+}
+
+// pruneNotificationLimiters removes the limiters of the integrations that no longer exist or no longer have limits.
+func (am *alertmanager) pruneNotificationLimiters(cfg *apimodels.PostableUserConfig) {
+	keep := make(map[string]struct{})
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			if limits, err := parseNotificationLimits(json.RawMessage(gr.Settings)); err == nil && limits != nil {
+				keep[gr.UID] = struct{}{}
+			}
+		}
+	}
+
+	am.limitersMtx.Lock()
+	defer am.limitersMtx.Unlock()
+	for uid, l := range am.limiters {
+		if _, ok := keep[uid]; !ok {
+			l.stop()
+			delete(am.limiters, uid)
+		}
+	}
+}
+
+// IntegrationStatuses returns the status of the integrations that have notification limits or health checks,
+// keyed by integration UID.
+func (am *alertmanager) IntegrationStatuses() map[string]apimodels.IntegrationStatus {
+	am.limitersMtx.Lock()
+	result := make(map[string]apimodels.IntegrationStatus, len(am.limiters))
+	for uid, l := range am.limiters {
+		result[uid] = l.Status()
+	}
+	am.limitersMtx.Unlock()
+
+	am.healthCheckersMtx.Lock()
+	defer am.healthCheckersMtx.Unlock()
+	for uid, h := range am.healthCheckers {
+		status := result[uid]
+		status.HealthCheck = h.Status()
+		result[uid] = status
+	}
+	return result
+}
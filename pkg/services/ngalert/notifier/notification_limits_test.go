@@ -0,0 +1,252 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type recordingNotifier struct {
+	mtx   sync.Mutex
+	calls [][]*types.Alert
+	err   error
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, alerts ...*types.Alert) (bool, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.calls = append(n.calls, alerts)
+	return n.err != nil, n.err
+}
+
+func (n *recordingNotifier) Calls() [][]*types.Alert {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.calls
+}
+
+func newTestAlert(name string, startsAt time.Time) *types.Alert {
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{model.AlertNameLabel: model.LabelValue(name), "team": "a"},
+			StartsAt: startsAt,
+		},
+	}
+}
+
+func TestParseNotificationLimits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings string
+		expected *NotificationLimits
+		err      string
+	}{
+		{
+			name:     "no settings",
+			settings: ``,
+		},
+		{
+			name:     "no limits",
+			settings: `{"url": "http://localhost"}`,
+		},
+		{
+			name:     "empty limits",
+			settings: `{"notificationLimits": {}}`,
+		},
+		{
+			name:     "valid limits",
+			settings: `{"url": "http://localhost", "notificationLimits": {"maxNotifications": 10, "window": "5m", "maxAlertsPerMessage": 20}}`,
+			expected: &NotificationLimits{MaxNotifications: 10, Window: model.Duration(5 * time.Minute), MaxAlertsPerMessage: 20},
+		},
+		{
+			name:     "max notifications without window",
+			settings: `{"notificationLimits": {"maxNotifications": 10}}`,
+			err:      "window is required when maxNotifications is set",
+		},
+		{
+			name:     "negative max alerts per message",
+			settings: `{"notificationLimits": {"maxAlertsPerMessage": -1}}`,
+			err:      "maxAlertsPerMessage must not be negative",
+		},
+		{
+			name:     "invalid window",
+			settings: `{"notificationLimits": {"maxNotifications": 1, "window": "soon"}}`,
+			err:      "failed to parse notificationLimits",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limits, err := parseNotificationLimits([]byte(tc.settings))
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, limits)
+		})
+	}
+}
+
+func TestNotificationLimiter_RateLimit(t *testing.T) {
+	mockClock := clock.NewMock()
+	m := metrics.NewAlertmanagerNotificationLimitMetrics(prometheus.NewRegistry())
+	limits := NotificationLimits{MaxNotifications: 2, Window: model.Duration(time.Minute)}
+	limiter := newNotificationLimiter("team-a", "slack", limits, mockClock, m, log.NewNopLogger())
+	next := &recordingNotifier{}
+	n := &limitedNotifier{limiter: limiter, next: next}
+
+	for i := 0; i < 2; i++ {
+		retry, err := n.Notify(context.Background(), newTestAlert(fmt.Sprintf("alert-%d", i), mockClock.Now()))
+		require.NoError(t, err)
+		require.False(t, retry)
+	}
+	require.Len(t, next.Calls(), 2)
+
+	t.Run("notifications over the limit are suppressed", func(t *testing.T) {
+		retry, err := n.Notify(context.Background(), newTestAlert("alert-2", mockClock.Now()), newTestAlert("alert-3", mockClock.Now()))
+		require.NoError(t, err)
+		require.False(t, retry)
+		require.Len(t, next.Calls(), 2)
+
+		status := limiter.Status()
+		assert.EqualValues(t, 1, status.SuppressedNotifications)
+		assert.EqualValues(t, 2, status.SuppressedAlerts)
+		assert.Equal(t, 2, status.PendingDigestAlerts)
+		assert.EqualValues(t, 0, status.DigestsSent)
+		require.NotNil(t, status.LastSuppressedAt)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.SuppressedNotifications.WithLabelValues("team-a", "slack")))
+		assert.Equal(t, 2.0, testutil.ToFloat64(m.SuppressedAlerts.WithLabelValues("team-a", "slack", suppressReasonRateLimit)))
+	})
+
+	t.Run("suppressed alerts are sent as a digest when the window allows it", func(t *testing.T) {
+		mockClock.Add(time.Minute)
+
+		require.Eventually(t, func() bool { return len(next.Calls()) == 3 }, time.Second, 10*time.Millisecond)
+		digest := next.Calls()[2]
+		require.Len(t, digest, 1)
+		assert.Equal(t, model.LabelValue(DigestAlertName), digest[0].Labels[model.AlertNameLabel])
+		assert.Equal(t, model.LabelValue("a"), digest[0].Labels["team"])
+		assert.Contains(t, string(digest[0].Annotations["summary"]), "2 alerts (2 firing, 0 resolved)")
+		assert.Contains(t, string(digest[0].Annotations["description"]), "alert-3")
+
+		status := limiter.Status()
+		assert.Equal(t, 0, status.PendingDigestAlerts)
+		assert.EqualValues(t, 1, status.DigestsSent)
+		assert.Equal(t, 1.0, testutil.ToFloat64(m.DigestNotifications.WithLabelValues("team-a", "slack")))
+	})
+}
+
+func TestNotificationLimiter_PendingAlertsAreSentWithNextNotification(t *testing.T) {
+	mockClock := clock.NewMock()
+	limits := NotificationLimits{MaxNotifications: 1, Window: model.Duration(time.Minute)}
+	limiter := newNotificationLimiter("team-a", "slack", limits, mockClock, nil, log.NewNopLogger())
+	next := &recordingNotifier{}
+	n := &limitedNotifier{limiter: limiter, next: next}
+
+	_, err := n.Notify(context.Background(), newTestAlert("alert-0", mockClock.Now()))
+	require.NoError(t, err)
+	_, err = n.Notify(context.Background(), newTestAlert("alert-1", mockClock.Now()))
+	require.NoError(t, err)
+	require.Len(t, next.Calls(), 1)
+
+	// Sending before the digest is flushed includes the pending alerts as a digest alert.
+	limiter.mtx.Lock()
+	limiter.sent = nil
+	limiter.mtx.Unlock()
+	_, err = n.Notify(context.Background(), newTestAlert("alert-2", mockClock.Now()))
+	require.NoError(t, err)
+
+	calls := next.Calls()
+	require.Len(t, calls, 2)
+	require.Len(t, calls[1], 2)
+	assert.Equal(t, model.LabelValue("alert-2"), calls[1][0].Labels[model.AlertNameLabel])
+	assert.Equal(t, model.LabelValue(DigestAlertName), calls[1][1].Labels[model.AlertNameLabel])
+	assert.Contains(t, string(calls[1][1].Annotations["description"]), "alert-1")
+	assert.Equal(t, 0, limiter.Status().PendingDigestAlerts)
+
+	t.Run("pending alerts are kept if the notification fails", func(t *testing.T) {
+		limiter.mtx.Lock()
+		limiter.sent = nil
+		limiter.pending[newTestAlert("alert-3", mockClock.Now()).Fingerprint()] = newTestAlert("alert-3", mockClock.Now())
+		limiter.mtx.Unlock()
+		next.err = fmt.Errorf("failed")
+
+		_, err := n.Notify(context.Background(), newTestAlert("alert-4", mockClock.Now()))
+		require.Error(t, err)
+		assert.Equal(t, 1, limiter.Status().PendingDigestAlerts)
+	})
+}
+
+func TestNotificationLimiter_MaxAlertsPerMessage(t *testing.T) {
+	mockClock := clock.NewMock()
+	m := metrics.NewAlertmanagerNotificationLimitMetrics(prometheus.NewRegistry())
+	limiter := newNotificationLimiter("team-a", "webhook", NotificationLimits{MaxAlertsPerMessage: 3}, mockClock, m, log.NewNopLogger())
+	next := &recordingNotifier{}
+	n := &limitedNotifier{limiter: limiter, next: next}
+
+	resolved := newTestAlert("alert-4", mockClock.Now().Add(-time.Hour))
+	resolved.EndsAt = mockClock.Now().Add(-time.Minute)
+	alerts := []*types.Alert{
+		newTestAlert("alert-0", mockClock.Now()),
+		newTestAlert("alert-1", mockClock.Now()),
+		newTestAlert("alert-2", mockClock.Now()),
+		newTestAlert("alert-3", mockClock.Now()),
+		resolved,
+	}
+	_, err := n.Notify(context.Background(), alerts...)
+	require.NoError(t, err)
+
+	calls := next.Calls()
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0], 3)
+	assert.Equal(t, alerts[0], calls[0][0])
+	assert.Equal(t, alerts[1], calls[0][1])
+	digest := calls[0][2]
+	assert.Equal(t, model.LabelValue(DigestAlertName), digest.Labels[model.AlertNameLabel])
+	assert.Contains(t, string(digest.Annotations["summary"]), "3 alerts (2 firing, 1 resolved)")
+	assert.False(t, digest.ResolvedAt(mockClock.Now()))
+
+	status := limiter.Status()
+	assert.EqualValues(t, 0, status.SuppressedNotifications)
+	assert.EqualValues(t, 3, status.SuppressedAlerts)
+	assert.EqualValues(t, 1, status.DigestsSent)
+	assert.Equal(t, 3.0, testutil.ToFloat64(m.SuppressedAlerts.WithLabelValues("team-a", "webhook", suppressReasonMaxAlertsPerMessage)))
+}
+
+func TestNewDigestAlert(t *testing.T) {
+	now := time.Now()
+	t.Run("digest is resolved when all collapsed alerts are resolved", func(t *testing.T) {
+		a1 := newTestAlert("alert-1", now.Add(-2*time.Hour))
+		a1.EndsAt = now.Add(-time.Hour)
+		a2 := newTestAlert("alert-2", now.Add(-time.Hour))
+		a2.EndsAt = now.Add(-time.Minute)
+
+		digest := newDigestAlert(now, []*types.Alert{a2, a1})
+		assert.True(t, digest.ResolvedAt(now))
+		assert.Equal(t, a1.StartsAt, digest.StartsAt)
+		assert.Equal(t, a2.EndsAt, digest.EndsAt)
+	})
+
+	t.Run("description is truncated", func(t *testing.T) {
+		collapsed := make([]*types.Alert, 0, maxDigestLines+5)
+		for i := 0; i < maxDigestLines+5; i++ {
+			collapsed = append(collapsed, newTestAlert(fmt.Sprintf("alert-%d", i), now))
+		}
+		digest := newDigestAlert(now, collapsed)
+		assert.Contains(t, string(digest.Annotations["description"]), "... and 5 more")
+	})
+}
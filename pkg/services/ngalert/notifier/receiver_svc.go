@@ -29,6 +29,7 @@ type ReceiverService struct {
 	cfgStore          configStore
 	encryptionService secrets.Service
 	xact              transactionManager
+	statusProvider    integrationStatusProvider
 	log               log.Logger
 }
 
@@ -45,12 +46,17 @@ type transactionManager interface {
 	InTransaction(ctx context.Context, work func(ctx context.Context) error) error
 }
 
+type integrationStatusProvider interface {
+	GetIntegrationStatuses(ctx context.Context, orgID int64) (map[string]definitions.IntegrationStatus, error)
+}
+
 func NewReceiverService(
 	ac accesscontrol.AccessControl,
 	cfgStore configStore,
 	provisioningStore provisoningStore,
 	encryptionService secrets.Service,
 	xact transactionManager,
+	statusProvider integrationStatusProvider,
 	log log.Logger,
 ) *ReceiverService {
 	return &ReceiverService{
@@ -59,6 +65,7 @@ func NewReceiverService(
 		cfgStore:          cfgStore,
 		encryptionService: encryptionService,
 		xact:              xact,
+		statusProvider:    statusProvider,
 		log:               log,
 	}
 }
@@ -114,7 +121,12 @@ func (rs *ReceiverService) GetReceiver(ctx context.Context, q models.GetReceiver
 			}
 			decryptFn := rs.decryptOrRedact(ctx, decrypt, q.Name, "")
 
-			return PostableToGettableApiReceiver(r, provenances, decryptFn, false)
+			res, err := PostableToGettableApiReceiver(r, provenances, decryptFn, false)
+			if err != nil {
+				return definitions.GettableApiReceiver{}, err
+			}
+			rs.setIntegrationStatuses(res, rs.getIntegrationStatuses(ctx, q.OrgID))
+			return res, nil
 		}
 	}
 
@@ -150,6 +162,8 @@ func (rs *ReceiverService) GetReceivers(ctx context.Context, q models.GetReceive
 		return nil, err
 	}
 
+	statuses := rs.getIntegrationStatuses(ctx, q.OrgID)
+
 	var output []definitions.GettableApiReceiver
 	for i := q.Offset; i < len(cfg.AlertmanagerConfig.Receivers); i++ {
 		r := cfg.AlertmanagerConfig.Receivers[i]
@@ -169,6 +183,7 @@ func (rs *ReceiverService) GetReceivers(ctx context.Context, q models.GetReceive
 		if err != nil {
 			return nil, err
 		}
+		rs.setIntegrationStatuses(res, statuses)
 
 		output = append(output, res)
 		// stop if we have reached the limit or we have found all the requested receivers
@@ -180,6 +195,28 @@ func (rs *ReceiverService) GetReceivers(ctx context.Context, q models.GetReceive
 	return output, nil
 }
 
+// getIntegrationStatuses returns the runtime status of the integrations of the organization, keyed by integration UID.
+// The status is informational, so failing to get it is logged and does not fail the request.
+func (rs *ReceiverService) getIntegrationStatuses(ctx context.Context, orgID int64) map[string]definitions.IntegrationStatus {
+	if rs.statusProvider == nil {
+		return nil
+	}
+	statuses, err := rs.statusProvider.GetIntegrationStatuses(ctx, orgID)
+	if err != nil {
+		rs.log.Debug("Failed to get the status of integrations", "org", orgID, "error", err)
+		return nil
+	}
+	return statuses
+}
+
+func (rs *ReceiverService) setIntegrationStatuses(r definitions.GettableApiReceiver, statuses map[string]definitions.IntegrationStatus) {
+	for _, gr := range r.GrafanaManagedReceivers {
+		if status, ok := statuses[gr.UID]; ok {
+			gr.Status = &status
+		}
+	}
+}
+
 func (rs *ReceiverService) decryptOrRedact(ctx context.Context, decrypt bool, name, fallback string) func(value string) string {
 	return func(value string) string {
 		if !decrypt {
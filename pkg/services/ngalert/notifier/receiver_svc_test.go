@@ -67,6 +67,30 @@ func TestReceiverService_GetReceivers(t *testing.T) {
 		require.Len(t, Receivers, 1)
 		require.Equal(t, "slack receiver", Receivers[0].Name)
 	})
+
+	t.Run("service includes the status of integrations", func(t *testing.T) {
+		sut := createReceiverServiceSut(t, secretsService)
+		sut.statusProvider = fakeIntegrationStatusProvider{
+			"UID2": {SuppressedNotifications: 3, SuppressedAlerts: 10, DigestsSent: 1},
+		}
+
+		Receivers, err := sut.GetReceivers(context.Background(), multiQ(1), nil)
+		require.NoError(t, err)
+		require.Len(t, Receivers, 2)
+		require.Nil(t, Receivers[0].GrafanaManagedReceivers[0].Status)
+		require.Equal(t, &definitions.IntegrationStatus{SuppressedNotifications: 3, SuppressedAlerts: 10, DigestsSent: 1}, Receivers[1].GrafanaManagedReceivers[0].Status)
+
+		Receiver, err := sut.GetReceiver(context.Background(), singleQ(1, "slack receiver"), nil)
+		require.NoError(t, err)
+		require.NotNil(t, Receiver.GrafanaManagedReceivers[0].Status)
+		require.EqualValues(t, 3, Receiver.GrafanaManagedReceivers[0].Status.SuppressedNotifications)
+	})
+}
+
+type fakeIntegrationStatusProvider map[string]definitions.IntegrationStatus
+
+func (f fakeIntegrationStatusProvider) GetIntegrationStatuses(_ context.Context, _ int64) (map[string]definitions.IntegrationStatus, error) {
+	return f, nil
 }
 
 func TestReceiverService_DecryptRedact(t *testing.T) {
@@ -177,6 +201,7 @@ func createReceiverServiceSut(t *testing.T, encryptSvc secrets.Service) *Receive
 		store,
 		encryptSvc,
 		xact,
+		nil,
 		log.NewNopLogger(),
 	}
 }
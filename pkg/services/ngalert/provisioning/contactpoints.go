@@ -337,7 +337,7 @@ func (ecp *ContactPointService) DeleteContactPoint(ctx context.Context, orgID in
 			}
 		}
 	}
-	if fullRemoval && isContactPointInUse(name, []*apimodels.Route{revision.cfg.AlertmanagerConfig.Route}) {
+	if fullRemoval && (isContactPointInUse(name, []*apimodels.Route{revision.cfg.AlertmanagerConfig.Route}) || isFallbackReceiver(name, revision.cfg.RouteFallbackReceivers)) {
 		return ErrContactPointReferenced
 	}
 
@@ -382,6 +382,16 @@ func isContactPointInUse(name string, routes []*apimodels.Route) bool {
 	return false
 }
 
+// isFallbackReceiver returns true if the contact point is the fallback receiver of a route.
+func isFallbackReceiver(name string, fallbacks map[string]string) bool {
+	for _, fallback := range fallbacks {
+		if fallback == name {
+			return true
+		}
+	}
+	return false
+}
+
 // decryptValueOrRedacted returns a function that decodes a string from Base64 and then decrypts using secrets.Service.
 // If argument 'decrypt' is false, then returns definitions.RedactedValue regardless of the decrypted value.
 // Otherwise, it returns the decoded and decrypted value. The function returns empty string in the case of errors, which are logged
@@ -443,6 +453,11 @@ groupLoop:
 				// Firstly, if we're the only receiver in the group, simply rename the group to match. Done!
 				if len(receiverGroup.GrafanaManagedReceivers) == 1 {
 					replaceReferences(receiverGroup.Name, target.Name, cfg.AlertmanagerConfig.Route)
+					for path, fallback := range cfg.RouteFallbackReceivers {
+						if fallback == receiverGroup.Name {
+							cfg.RouteFallbackReceivers[path] = target.Name
+						}
+					}
 					receiverGroup.Name = target.Name
 					receiverGroup.GrafanaManagedReceivers[i] = target
 					renamedReceiver = receiverGroup.Name
@@ -267,6 +267,7 @@ func TestContactPointServiceDecryptRedact(t *testing.T) {
 			ecp.provenanceStore,
 			ecp.encryptionService,
 			ecp.xact,
+			nil,
 			log.NewNopLogger(),
 		)
 	}
@@ -365,6 +366,7 @@ func createContactPointServiceSut(t *testing.T, secretService secrets.Service) *
 		provisioningStore,
 		secretService,
 		xact,
+		nil,
 		log.NewNopLogger(),
 	)
 
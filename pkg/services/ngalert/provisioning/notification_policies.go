@@ -86,6 +86,8 @@ func (nps *NotificationPolicyService) UpdatePolicyTree(ctx context.Context, orgI
 	}
 
 	revision.cfg.AlertmanagerConfig.Config.Route = &tree
+	// Fallback receivers are keyed by the position of their routes, which the new tree does not keep.
+	revision.cfg.RouteFallbackReceivers = nil
 
 	return nps.xact.InTransaction(ctx, func(ctx context.Context) error {
 		if err := nps.configStore.Save(ctx, revision, orgID); err != nil {
@@ -108,6 +110,7 @@ func (nps *NotificationPolicyService) ResetPolicyTree(ctx context.Context, orgID
 		return definitions.Route{}, err
 	}
 	revision.cfg.AlertmanagerConfig.Config.Route = route
+	revision.cfg.RouteFallbackReceivers = nil
 	err = nps.ensureDefaultReceiverExists(revision.cfg, defaultCfg)
 	if err != nil {
 		return definitions.Route{}, err
@@ -3,6 +3,7 @@ package state
 import (
 	"context"
 	"errors"
+	"maps"
 	"math"
 	"net/url"
 	"strings"
@@ -281,7 +282,13 @@ func (c *cache) set(entry *State) {
 	if _, ok := c.states[entry.OrgID][entry.AlertRuleUID]; !ok {
 		c.states[entry.OrgID][entry.AlertRuleUID] = &ruleStates{states: make(map[string]*State)}
 	}
-	c.states[entry.OrgID][entry.AlertRuleUID].states[entry.CacheID] = entry
+	states := c.states[entry.OrgID][entry.AlertRuleUID].states
+	// The state is replaced by setAcknowledgement when it's acknowledged while being evaluated.
+	// Keep the acknowledgement unless the evaluation resolved the state.
+	if existing, ok := states[entry.CacheID]; ok && existing != entry && isFiring(entry.State) {
+		entry.Acknowledgement = existing.Acknowledgement
+	}
+	states[entry.CacheID] = entry
 }
 
 func (c *cache) get(orgID int64, alertRuleUID, stateId string) *State {
@@ -298,6 +305,39 @@ func (c *cache) get(orgID int64, alertRuleUID, stateId string) *State {
 	return nil
 }
 
+// setAcknowledgement sets the acknowledgement of the state of the rule that has the given labels, or removes it if ack is nil.
+// Internal labels are ignored when looking up the state. Only firing states can be acknowledged.
+func (c *cache) setAcknowledgement(orgID int64, alertRuleUID string, lbs data.Labels, ack *ngModels.AlertInstanceAcknowledgement) (*State, error) {
+	want := map[string]string(lbs.Copy())
+	ngModels.WithoutInternalLabels()(want)
+
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	ruleStates, ok := c.states[orgID][alertRuleUID]
+	if !ok {
+		return nil, ngModels.ErrAlertInstanceNotFound.Errorf("alert rule %s has no alert instances", alertRuleUID)
+	}
+	for id, s := range ruleStates.states {
+		if !maps.Equal(s.GetLabels(ngModels.WithoutInternalLabels()), want) {
+			continue
+		}
+		if ack != nil && !isFiring(s.State) {
+			return nil, ngModels.ErrAlertInstanceNotFiring.Errorf("alert instance is in state %s", s.State)
+		}
+		// Evaluations change the state without holding the lock, so it's replaced by a copy instead of being changed in place.
+		acknowledged := *s
+		acknowledged.Acknowledgement = ack
+		ruleStates.states[id] = &acknowledged
+		result := acknowledged
+		return &result, nil
+	}
+	return nil, ngModels.ErrAlertInstanceNotFound.Errorf("alert rule %s has no alert instance with labels %s", alertRuleUID, lbs.String())
+}
+
+func isFiring(state eval.State) bool {
+	return state == eval.Alerting || state == eval.NoData || state == eval.Error
+}
+
 func (c *cache) getAll(orgID int64, skipNormalState bool) []*State {
 	var states []*State
 	c.mtxStates.RLock()
@@ -381,6 +421,7 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					CurrentStateSince: v2.StartsAt,
 					CurrentStateEnd:   v2.EndsAt,
 					ResultFingerprint: v2.ResultFingerprint.String(),
+					Acknowledgement:   v2.Acknowledgement,
 				})
 			}
 		}
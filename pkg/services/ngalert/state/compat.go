@@ -30,6 +30,7 @@ const (
 // StateToPostableAlert converts a state to a model that is accepted by Alertmanager. Annotations and Labels are copied from the state.
 // - if state has at least one result, a new label '__value_string__' is added to the label set
 // - the alert's GeneratorURL is constructed to point to the alert detail view
+// - if the state is acknowledged, the acknowledgement is added to the annotations so that it can be used in notification templates
 // - if evaluation state is either NoData or Error, the resulting set of labels is changed:
 //   - original alert name (label: model.AlertNameLabel) is backed up to OriginalAlertName
 //   - label model.AlertNameLabel is overwritten to either NoDataAlertName or ErrorAlertName
@@ -61,6 +62,17 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 		nA[alertingModels.OrgIDAnnotation] = strconv.FormatInt(alertState.OrgID, 10)
 	}
 
+	if ack := alertState.Acknowledgement; ack != nil {
+		nA[ngModels.AcknowledgedByAnnotation] = ack.By
+		nA[ngModels.AcknowledgedAtAnnotation] = ack.At.UTC().Format(time.RFC3339)
+		if ack.Note != "" {
+			nA[ngModels.AcknowledgementNoteAnnotation] = ack.Note
+		}
+		if ack.SuppressRepeatNotifications {
+			nA[ngModels.SuppressRepeatNotificationsAnnotation] = "true"
+		}
+	}
+
 	var urlStr string
 	if uid := nL[alertingModels.RuleUIDLabel]; len(uid) > 0 && appURL != nil {
 		u := *appURL
@@ -158,6 +158,21 @@ func Test_StateToPostableAlert(t *testing.T) {
 				require.Equal(t, alertState.StateReason, result.Annotations[ngModels.StateReasonAnnotation])
 			})
 
+			t.Run("should add acknowledgement annotations if acknowledged", func(t *testing.T) {
+				alertState := randomTransition(eval.Normal, tc.state)
+				alertState.Acknowledgement = &ngModels.AlertInstanceAcknowledgement{
+					By:                          "admin",
+					At:                          time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+					Note:                        "looking into it",
+					SuppressRepeatNotifications: true,
+				}
+				result := StateToPostableAlert(alertState, appURL)
+				require.Equal(t, "admin", result.Annotations[ngModels.AcknowledgedByAnnotation])
+				require.Equal(t, "2024-05-01T10:00:00Z", result.Annotations[ngModels.AcknowledgedAtAnnotation])
+				require.Equal(t, "looking into it", result.Annotations[ngModels.AcknowledgementNoteAnnotation])
+				require.Equal(t, "true", result.Annotations[ngModels.SuppressRepeatNotificationsAnnotation])
+			})
+
 			switch tc.state {
 			case eval.NoData:
 				t.Run("should keep existing labels and change name", func(t *testing.T) {
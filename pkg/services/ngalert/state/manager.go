@@ -210,6 +210,7 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				LastEvaluationTime:   entry.LastEvalTime,
 				Annotations:          ruleForEntry.Annotations,
 				ResultFingerprint:    resultFp,
+				Acknowledgement:      entry.Acknowledgement,
 			}
 			statesCount++
 		}
@@ -466,6 +467,38 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
 }
 
+// AcknowledgeState records that a user has taken ownership of the firing alert instance of the rule that has the given labels.
+// If ack is nil, the acknowledgement is removed. The state is saved immediately so that the acknowledgement survives restarts.
+func (st *Manager) AcknowledgeState(ctx context.Context, orgID int64, alertRuleUID string, lbs data.Labels, ack *ngModels.AlertInstanceAcknowledgement) (*State, error) {
+	s, err := st.cache.setAcknowledgement(orgID, alertRuleUID, lbs, ack)
+	if err != nil {
+		return nil, err
+	}
+	if st.instanceStore == nil {
+		return s, nil
+	}
+
+	key, err := s.GetAlertInstanceKey()
+	if err != nil {
+		return nil, err
+	}
+	err = st.instanceStore.SaveAlertInstance(ctx, ngModels.AlertInstance{
+		AlertInstanceKey:  key,
+		Labels:            ngModels.InstanceLabels(s.Labels),
+		CurrentState:      ngModels.InstanceStateType(s.State.String()),
+		CurrentReason:     s.StateReason,
+		LastEvalTime:      s.LastEvaluationTime,
+		CurrentStateSince: s.StartsAt,
+		CurrentStateEnd:   s.EndsAt,
+		ResultFingerprint: s.ResultFingerprint.String(),
+		Acknowledgement:   s.Acknowledgement,
+	})
+	if err != nil {
+		st.log.FromContext(ctx).Error("Failed to save alert state", "ruleUID", alertRuleUID, "labels", s.Labels.String(), "error", err)
+	}
+	return s, nil
+}
+
 func (st *Manager) Put(states []*State) {
 	for _, s := range states {
 		st.cache.set(s)
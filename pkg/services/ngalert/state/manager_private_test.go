@@ -12,6 +12,7 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	alertingModels "github.com/grafana/alerting/models"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -4000,3 +4001,81 @@ func setCacheID(s *State) *State {
 	s.CacheID = id
 	return s
 }
+
+func TestAcknowledgeState(t *testing.T) {
+	instanceStore := &FakeInstanceStore{}
+	st := NewManager(ManagerCfg{
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.NewNopLogger(),
+		InstanceStore: instanceStore,
+		Clock:         clock.NewMock(),
+	}, NewNoopPersister())
+
+	firing := &State{
+		OrgID:        1,
+		AlertRuleUID: "rule",
+		CacheID:      "firing",
+		State:        eval.Alerting,
+		Labels:       data.Labels{"instance": "a", alertingModels.RuleUIDLabel: "rule"},
+	}
+	pending := &State{
+		OrgID:        1,
+		AlertRuleUID: "rule",
+		CacheID:      "pending",
+		State:        eval.Pending,
+		Labels:       data.Labels{"instance": "b", alertingModels.RuleUIDLabel: "rule"},
+	}
+	st.Put([]*State{firing, pending})
+
+	ack := &ngmodels.AlertInstanceAcknowledgement{By: "admin", At: time.Now(), Note: "on it", SuppressRepeatNotifications: true}
+
+	t.Run("firing state is acknowledged and saved", func(t *testing.T) {
+		s, err := st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "a"}, ack)
+		require.NoError(t, err)
+		require.Equal(t, ack, s.Acknowledgement)
+		require.Equal(t, ack, st.Get(1, "rule", "firing").Acknowledgement)
+
+		ops := instanceStore.RecordedOps()
+		require.Len(t, ops, 1)
+		saved, ok := ops[0].(ngmodels.AlertInstance)
+		require.True(t, ok)
+		require.Equal(t, ack, saved.Acknowledgement)
+	})
+
+	t.Run("pending state cannot be acknowledged", func(t *testing.T) {
+		_, err := st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "b"}, ack)
+		require.ErrorIs(t, err, ngmodels.ErrAlertInstanceNotFiring)
+	})
+
+	t.Run("unknown state is not found", func(t *testing.T) {
+		_, err := st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "c"}, ack)
+		require.ErrorIs(t, err, ngmodels.ErrAlertInstanceNotFound)
+		_, err = st.AcknowledgeState(context.Background(), 2, "rule", data.Labels{"instance": "a"}, ack)
+		require.ErrorIs(t, err, ngmodels.ErrAlertInstanceNotFound)
+	})
+
+	t.Run("acknowledgement is removed", func(t *testing.T) {
+		s, err := st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "a"}, nil)
+		require.NoError(t, err)
+		require.Nil(t, s.Acknowledgement)
+	})
+
+	t.Run("acknowledgement is removed when the state is resolved", func(t *testing.T) {
+		s, err := st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "a"}, ack)
+		require.NoError(t, err)
+		s.Resolve("", time.Now())
+		require.Nil(t, s.Acknowledgement)
+	})
+
+	t.Run("acknowledgement is kept when a state acknowledged during its evaluation is saved", func(t *testing.T) {
+		_, err := st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "a"}, nil)
+		require.NoError(t, err)
+		evaluated := st.Get(1, "rule", "firing")
+		_, err = st.AcknowledgeState(context.Background(), 1, "rule", data.Labels{"instance": "a"}, ack)
+		require.NoError(t, err)
+		require.Nil(t, evaluated.Acknowledgement, "the state being evaluated must not be changed")
+
+		st.Put([]*State{evaluated})
+		require.Equal(t, ack, st.Get(1, "rule", "firing").Acknowledgement)
+	})
+}
@@ -93,6 +93,7 @@ func (a *SyncStatePersister) saveAlertStates(ctx context.Context, states ...Stat
 			LastEvalTime:      s.LastEvaluationTime,
 			CurrentStateSince: s.StartsAt,
 			CurrentStateEnd:   s.EndsAt,
+			Acknowledgement:   s.Acknowledgement,
 		}
 
 		err = a.store.SaveAlertInstance(ctx, instance)
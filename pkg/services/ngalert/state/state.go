@@ -65,6 +65,10 @@ type State struct {
 	// conditions.
 	Values map[string]float64
 
+	// Acknowledgement is set when a user has taken ownership of the firing alert instance.
+	// It is removed when the state changes to Normal.
+	Acknowledgement *models.AlertInstanceAcknowledgement
+
 	StartsAt             time.Time
 	EndsAt               time.Time
 	LastSentAt           time.Time
@@ -132,6 +136,7 @@ func (a *State) SetNormal(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.Acknowledgement = nil
 }
 
 // Resolve sets the State to Normal. It updates the StateReason, the end time, and sets Resolved to true.
@@ -140,6 +145,7 @@ func (a *State) Resolve(reason string, endsAt time.Time) {
 	a.StateReason = reason
 	a.Resolved = true
 	a.EndsAt = endsAt
+	a.Acknowledgement = nil
 }
 
 // Maintain updates the end time using the most recent evaluation.
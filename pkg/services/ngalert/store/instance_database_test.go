@@ -369,6 +369,29 @@ func TestIntegrationFullSync(t *testing.T) {
 			}
 		}
 	})
+	t.Run("Should keep the acknowledgement on sync", func(t *testing.T) {
+		acknowledged := generateTestAlertInstance(orgID, "acknowledged")
+		acknowledged.Acknowledgement = &models.AlertInstanceAcknowledgement{
+			By:                          "user",
+			At:                          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			Note:                        "looking into it",
+			SuppressRepeatNotifications: true,
+		}
+		err := dbstore.FullSync(ctx, []models.AlertInstance{acknowledged})
+		require.NoError(t, err)
+
+		res, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{
+			RuleOrgID: orgID,
+			RuleUID:   "acknowledged",
+		})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.NotNil(t, res[0].Acknowledgement)
+		require.Equal(t, acknowledged.Acknowledgement.By, res[0].Acknowledgement.By)
+		require.True(t, acknowledged.Acknowledgement.At.Equal(res[0].Acknowledgement.At))
+		require.Equal(t, acknowledged.Acknowledgement.Note, res[0].Acknowledgement.Note)
+		require.True(t, res[0].Acknowledgement.SuppressRepeatNotifications)
+	})
 }
 
 func generateTestAlertInstance(orgID int64, ruleID string) models.AlertInstance {
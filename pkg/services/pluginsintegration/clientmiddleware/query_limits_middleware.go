@@ -0,0 +1,74 @@
+package clientmiddleware
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// NewQueryLimitsMiddleware creates a new plugins.ClientMiddleware that will replace query results
+// which exceed the limits of series, data points or bytes by errors. The limits of series and points
+// are sent to the plugin in headers, so that it can stop reading results which exceed them, and the
+// limit of bytes limits the size of the responses of data sources to core plugins.
+func NewQueryLimitsMiddleware(cfg *setting.Cfg) plugins.ClientMiddleware {
+	return plugins.ClientMiddlewareFunc(func(next plugins.Client) plugins.Client {
+		return &QueryLimitsMiddleware{
+			next: next,
+			cfg:  cfg,
+		}
+	})
+}
+
+type QueryLimitsMiddleware struct {
+	next plugins.Client
+	cfg  *setting.Cfg
+}
+
+func (m *QueryLimitsMiddleware) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	if req == nil {
+		return m.next.QueryData(ctx, req)
+	}
+
+	limits := querylimits.ForRequest(m.cfg, req.PluginContext)
+	querylimits.SetHeaders(limits, req)
+	if limits.MaxResponseBytes > 0 {
+		ctx = httpclient.WithContextualMiddleware(ctx, httpclient.ResponseLimitMiddleware(limits.MaxResponseBytes))
+	}
+
+	resp, err := m.next.QueryData(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	querylimits.Apply(limits, resp, req.PluginContext.PluginID, querylimits.SourceDataSource)
+	return resp, nil
+}
+
+func (m *QueryLimitsMiddleware) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return m.next.CallResource(ctx, req, sender)
+}
+
+func (m *QueryLimitsMiddleware) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return m.next.CheckHealth(ctx, req)
+}
+
+func (m *QueryLimitsMiddleware) CollectMetrics(ctx context.Context, req *backend.CollectMetricsRequest) (*backend.CollectMetricsResult, error) {
+	return m.next.CollectMetrics(ctx, req)
+}
+
+func (m *QueryLimitsMiddleware) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	return m.next.SubscribeStream(ctx, req)
+}
+
+func (m *QueryLimitsMiddleware) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return m.next.PublishStream(ctx, req)
+}
+
+func (m *QueryLimitsMiddleware) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	return m.next.RunStream(ctx, req, sender)
+}
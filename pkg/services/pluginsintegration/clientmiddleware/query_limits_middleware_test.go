@@ -0,0 +1,87 @@
+package clientmiddleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/plugins/manager/client/clienttest"
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryLimitsMiddleware(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryLimits = setting.QueryLimitsSettings{QueryLimits: setting.QueryLimits{MaxSeries: 1, MaxResponseBytes: 10}}
+
+	t.Run("Should replace results which exceed the limits by errors", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewQueryLimitsMiddleware(cfg)))
+		cdt.TestClient.QueryDataFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			resp := backend.NewQueryDataResponse()
+			resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{
+				data.NewFrame("", data.NewField("value", nil, []float64{1})),
+				data.NewFrame("", data.NewField("value", nil, []float64{2})),
+			}}
+			return resp, nil
+		}
+
+		resp, err := cdt.Decorator.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{PluginID: "prometheus"},
+		})
+		require.NoError(t, err)
+		require.EqualError(t, resp.Responses["A"].Error, "query result of 2 series exceeds the limit of 1 series")
+	})
+
+	t.Run("Should send the limits of series and points to the plugin", func(t *testing.T) {
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewQueryLimitsMiddleware(cfg)))
+		cdt.TestClient.QueryDataFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			resp := backend.NewQueryDataResponse()
+			err := querylimits.NewLimiter(querylimits.LimitsFromHeaders(req.Headers)).Add(2, 2)
+			resp.Responses["A"] = backend.DataResponse{Error: err}
+			return resp, nil
+		}
+
+		resp, err := cdt.Decorator.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{PluginID: "prometheus"},
+			Headers:       map[string]string{querylimits.HeaderMaxSeries: "100"},
+		})
+		require.NoError(t, err)
+		require.EqualError(t, resp.Responses["A"].Error, "query result exceeds the limit of 1 series")
+	})
+
+	t.Run("Should limit the size of responses of data sources", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+		}))
+		t.Cleanup(server.Close)
+
+		cdt := clienttest.NewClientDecoratorTest(t, clienttest.WithMiddlewares(NewQueryLimitsMiddleware(cfg)))
+		cdt.TestClient.QueryDataFunc = func(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+			client, err := httpclient.New(httpclient.Options{Middlewares: []httpclient.Middleware{httpclient.ContextualMiddleware()}})
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			res, err := client.Do(httpReq)
+			require.NoError(t, err)
+			defer func() { _ = res.Body.Close() }()
+
+			resp := backend.NewQueryDataResponse()
+			_, err = io.ReadAll(res.Body)
+			resp.Responses["A"] = backend.DataResponse{Error: err}
+			return resp, nil
+		}
+
+		resp, err := cdt.Decorator.QueryData(context.Background(), &backend.QueryDataRequest{})
+		require.NoError(t, err)
+		require.EqualError(t, resp.Responses["A"].Error, "query result exceeds the limit of 10 bytes")
+	})
+}
@@ -178,6 +178,7 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 		clientmiddleware.NewOAuthTokenMiddleware(oAuthTokenService),
 		clientmiddleware.NewCookiesMiddleware(skipCookiesNames),
 		clientmiddleware.NewResourceResponseMiddleware(),
+		clientmiddleware.NewQueryLimitsMiddleware(cfg),
 		clientmiddleware.NewCachingMiddlewareWithFeatureManager(cachingService, features),
 	)
 
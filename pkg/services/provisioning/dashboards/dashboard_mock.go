@@ -1,6 +1,10 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -67,3 +71,14 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// GetProvisionerStatus not implemented for mocks
+func (dpm *ProvisionerMock) GetProvisionerStatus() []ProvisionerStatus {
+	return []ProvisionerStatus{}
+}
+
+// SaveDashboardToSource not implemented for mocks
+func (dpm *ProvisionerMock) SaveDashboardToSource(ctx context.Context, provisioning *dashboards.DashboardProvisioning,
+	dash *dashboards.Dashboard, author SourceAuthor) (bool, error) {
+	return false, nil
+}
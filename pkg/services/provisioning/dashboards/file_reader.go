@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,9 +39,15 @@ type FileReader struct {
 	FoldersFromFilesStructure    bool
 	folderService                folder.Service
 
+	// git is the repository the dashboards are synced from, for readers of type git.
+	git *gitRepository
+
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+	lastWalk                time.Time
+	fileErrors              map[string]string
+	walkErrors              map[string]string
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -92,7 +99,17 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	if fr.git != nil {
+		if err := fr.git.sync(ctx); err != nil {
+			return err
+		}
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
+	fr.mux.Lock()
+	fr.walkErrors = map[string]string{}
+	fr.mux.Unlock()
+
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
 		return err
@@ -125,9 +142,67 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 	defer fr.mux.Unlock()
 
 	fr.usageTracker = usageTracker
+	fr.fileErrors = fr.walkErrors
+	fr.lastWalk = time.Now()
 	return nil
 }
 
+// recordFileError keeps the error of the dashboard file for the status of the reader.
+func (fr *FileReader) recordFileError(path string, err error) {
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+
+	if fr.walkErrors != nil {
+		fr.walkErrors[path] = err.Error()
+	}
+}
+
+// status returns the status of the last walk of the reader.
+func (fr *FileReader) status() ProvisionerStatus {
+	resolvedPath := fr.resolvedPath()
+
+	fr.mux.RLock()
+	status := ProvisionerStatus{
+		Name:       fr.Cfg.Name,
+		Type:       fr.Cfg.Type,
+		Path:       resolvedPath,
+		LastSynced: fr.lastWalk,
+		Errors:     make([]FileError, 0, len(fr.fileErrors)),
+	}
+	for path, err := range fr.fileErrors {
+		if rel, relErr := filepath.Rel(resolvedPath, path); relErr == nil {
+			path = rel
+		}
+		status.Errors = append(status.Errors, FileError{File: path, Error: err})
+	}
+	fr.mux.RUnlock()
+
+	sort.Slice(status.Errors, func(i, j int) bool { return status.Errors[i].File < status.Errors[j].File })
+	if fr.git != nil {
+		fr.git.status(&status)
+	}
+	return status
+}
+
+// saveToSource writes the dashboard saved from the UI back to its file, for readers that support it.
+// It returns false if the reader does not write dashboards back.
+func (fr *FileReader) saveToSource(ctx context.Context, externalID string, data *simplejson.Json, author SourceAuthor) (bool, error) {
+	if fr.git == nil || !fr.git.commitOnSave || !fr.Cfg.AllowUIUpdates {
+		return false, nil
+	}
+	rel, err := filepath.Rel(fr.resolvedPath(), externalID)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false, fmt.Errorf("dashboard file %s is not provisioned by %s", externalID, fr.Cfg.Name)
+	}
+	message := fmt.Sprintf("Update %s", data.Get("title").MustString())
+	commit, err := fr.git.commitDashboard(ctx, rel, data, author, message)
+	if err != nil {
+		return false, err
+	}
+	fr.log.Info("Committed dashboard saved from the UI", "file", rel, "commit", commit)
+	return true, nil
+}
+
 func (fr *FileReader) changeWritePermissions(restrict bool) {
 	fr.mux.Lock()
 	defer fr.mux.Unlock()
@@ -155,6 +230,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, folderUID, fileInfo, dashboardRefs)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
+			fr.recordFileError(path, err)
 			continue
 		}
 
@@ -184,6 +260,7 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Cont
 		usageTracker.track(provisioningMetadata)
 		if err != nil {
 			fr.log.Error("failed to save dashboard", "file", path, "error", err)
+			fr.recordFileError(path, err)
 		}
 	}
 	return nil
@@ -237,6 +314,7 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 	jsonFile, err := fr.readDashboardFromFile(path, resolvedFileInfo.ModTime(), folderID, folderUID)
 	if err != nil {
 		fr.log.Error("failed to load dashboard from ", "file", path, "error", err)
+		fr.recordFileError(path, err)
 		return provisioningMetadata, nil
 	}
 
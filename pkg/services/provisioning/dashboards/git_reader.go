@@ -0,0 +1,352 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// gitRepository is a local git repository that dashboards are provisioned from. The dashboards of the
+// last commit of the branch are written to a directory in the data path of Grafana, which is then read
+// like any other provisioning directory. Changes in the working tree that are not
+// committed are not provisioned. The working tree of the repository is never modified, so the
+// repository can be bare, and dashboards are only committed back to branches that are not checked out.
+type gitRepository struct {
+	repository string
+	branch     string
+	// subPath is the directory of the dashboards in the repository, in slash-separated form.
+	subPath string
+	// syncPath is the directory the dashboards of the last synced commit are written to.
+	syncPath     string
+	commitOnSave bool
+	log          log.Logger
+
+	mux    sync.Mutex
+	commit string
+	synced time.Time
+	err    error
+	// commitErr is the error of the last commit of a dashboard saved from the UI.
+	commitErr error
+}
+
+// NewDashboardGitReader returns a reader of the dashboards of a local git repository, which are synced
+// to a directory in dataPath. The repository is read with the git binary, which must be installed.
+func NewDashboardGitReader(cfg *config, dataPath string, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	repository, ok := cfg.Options["repository"].(string)
+	if !ok || repository == "" {
+		return nil, fmt.Errorf("failed to load dashboards, repository param is not a string")
+	}
+	branch, _ := cfg.Options["branch"].(string)
+	subPath, _ := cfg.Options["path"].(string)
+	commitOnSave, _ := cfg.Options["commitOnSave"].(bool)
+
+	// Dashboards are mapped to folders by directory, unless disabled explicitly.
+	foldersFromFilesStructure := true
+	if v, ok := cfg.Options["foldersFromFilesStructure"].(bool); ok {
+		foldersFromFilesStructure = v
+	}
+	if foldersFromFilesStructure && cfg.Folder != "" && cfg.FolderUID != "" {
+		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("failed to load dashboards, the git binary is required: %w", err)
+	}
+	if _, err := runGit(context.Background(), repository, nil, nil, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("failed to open git repository %s: %w", repository, err)
+	}
+	// The sync directory is replaced on every sync, so the name of the provider must not escape it.
+	syncDir := url.PathEscape(cfg.Name)
+	if syncDir == "." || syncDir == ".." {
+		return nil, fmt.Errorf("failed to load dashboards, invalid provider name %q", cfg.Name)
+	}
+
+	repo := &gitRepository{
+		repository:   repository,
+		branch:       branch,
+		subPath:      strings.Trim(filepath.ToSlash(filepath.Clean(subPath)), "/."),
+		syncPath:     filepath.Join(dataPath, "provisioning", "git", syncDir),
+		commitOnSave: commitOnSave,
+		log:          log,
+	}
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         repo.syncPath,
+		log:                          log,
+		dashboardProvisioningService: service,
+		dashboardStore:               dashboardStore,
+		folderService:                folderService,
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		usageTracker:                 newUsageTracker(),
+		git:                          repo,
+	}, nil
+}
+
+// sync writes the dashboards of the last commit of the branch to the sync directory, if the commit
+// changed since the last sync.
+func (g *gitRepository) sync(ctx context.Context) error {
+	commit, err := g.resolveCommit(ctx)
+	if err == nil {
+		err = g.checkout(ctx, commit)
+	}
+
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.err = err
+	if err != nil {
+		return err
+	}
+	if commit != g.commit {
+		g.log.Info("Synced dashboards from git", "commit", commit)
+	}
+	g.commit = commit
+	g.synced = time.Now()
+	return nil
+}
+
+func (g *gitRepository) resolveCommit(ctx context.Context) (string, error) {
+	ref := g.branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commit, err := runGit(ctx, g.repository, nil, nil, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+func (g *gitRepository) checkout(ctx context.Context, commit string) error {
+	g.mux.Lock()
+	upToDate := commit == g.commit
+	g.mux.Unlock()
+	if _, err := os.Stat(g.syncPath); err == nil && upToDate {
+		return nil
+	}
+
+	args := []string{"ls-tree", "-r", "-z", commit}
+	if g.subPath != "" {
+		args = append(args, "--", g.subPath)
+	}
+	out, err := runGit(ctx, g.repository, nil, nil, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list files of commit %s: %w", commit, err)
+	}
+
+	// The dashboards are written to a new directory, which then replaces the sync directory, so that
+	// the directory never holds the dashboards of two commits.
+	if err := os.MkdirAll(filepath.Dir(g.syncPath), 0o750); err != nil {
+		return err
+	}
+	tmpPath, err := os.MkdirTemp(filepath.Dir(g.syncPath), "."+filepath.Base(g.syncPath))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmpPath) }()
+
+	for _, entry := range strings.Split(out, "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		meta, file, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 || fields[1] != "blob" || !strings.HasSuffix(file, ".json") {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(file, g.subPath), "/")
+		if rel == "" || strings.HasPrefix(rel, "../") {
+			continue
+		}
+
+		content, err := runGitRaw(ctx, g.repository, nil, nil, "cat-file", "blob", fields[2])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		target := filepath.Join(tmpPath, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, content, 0o600); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(g.syncPath); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, g.syncPath)
+}
+
+// commitDashboard commits the dashboard to the file, relative to the dashboards directory of the
+// repository, on top of the branch, and returns the commit.
+func (g *gitRepository) commitDashboard(ctx context.Context, rel string, data *simplejson.Json, author SourceAuthor, message string) (string, error) {
+	commit, err := g.writeCommit(ctx, rel, data, author, message)
+	g.mux.Lock()
+	g.commitErr = err
+	g.mux.Unlock()
+	return commit, err
+}
+
+func (g *gitRepository) writeCommit(ctx context.Context, rel string, data *simplejson.Json, author SourceAuthor, message string) (string, error) {
+	repoPath := path.Join(g.subPath, filepath.ToSlash(rel))
+
+	var err error
+	branch := g.branch
+	if branch == "" {
+		branch, err = runGit(ctx, g.repository, nil, nil, "symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return "", fmt.Errorf("dashboards can only be committed to a branch: %w", err)
+		}
+	}
+	ref := "refs/heads/" + branch
+	if err := g.checkNotCheckedOut(ctx, ref); err != nil {
+		return "", err
+	}
+	parent, err := runGit(ctx, g.repository, nil, nil, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+	}
+
+	// Files in the repository hold dashboards without the database specific fields.
+	dashboard, err := data.Encode()
+	if err != nil {
+		return "", err
+	}
+	content, err := simplejson.NewJson(dashboard)
+	if err != nil {
+		return "", err
+	}
+	content.Del("id")
+	content.Del("version")
+	encoded, err := content.EncodePretty()
+	if err != nil {
+		return "", err
+	}
+
+	blob, err := runGit(ctx, g.repository, nil, append(encoded, '\n'), "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+
+	// The tree is built in a temporary index, so that the index of the repository is left untouched.
+	index, err := os.CreateTemp("", "grafana-git-index")
+	if err != nil {
+		return "", err
+	}
+	_ = index.Close()
+	_ = os.Remove(index.Name())
+	defer func() { _ = os.Remove(index.Name()) }()
+	indexEnv := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	if _, err := runGit(ctx, g.repository, indexEnv, nil, "read-tree", parent); err != nil {
+		return "", err
+	}
+	if _, err := runGit(ctx, g.repository, indexEnv, nil, "update-index", "--add", "--cacheinfo", "100644,"+blob+","+repoPath); err != nil {
+		return "", err
+	}
+	tree, err := runGit(ctx, g.repository, indexEnv, nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	parentTree, err := runGit(ctx, g.repository, nil, nil, "rev-parse", parent+"^{tree}")
+	if err != nil {
+		return "", err
+	}
+	if tree == parentTree {
+		return parent, nil
+	}
+
+	authorEnv := []string{
+		"GIT_AUTHOR_NAME=" + author.Name, "GIT_AUTHOR_EMAIL=" + author.Email,
+		"GIT_COMMITTER_NAME=" + author.Name, "GIT_COMMITTER_EMAIL=" + author.Email,
+	}
+	commit, err := runGit(ctx, g.repository, authorEnv, nil, "commit-tree", tree, "-p", parent, "-m", message)
+	if err != nil {
+		return "", err
+	}
+	// The update fails if the branch moved since it was resolved.
+	if _, err := runGit(ctx, g.repository, nil, nil, "update-ref", "-m", "grafana: "+message, ref, commit, parent); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// checkNotCheckedOut returns an error if the branch is checked out in a working tree of the repository.
+// Commits are written without a working tree, so the working tree and the index would still hold the
+// previous dashboard, and the next commit made in the working tree would revert the change.
+func (g *gitRepository) checkNotCheckedOut(ctx context.Context, ref string) error {
+	out, err := runGit(ctx, g.repository, nil, nil, "worktree", "list", "--porcelain")
+	if err != nil {
+		return err
+	}
+	// Working trees are separated by empty lines, with a worktree, a HEAD and a branch line each.
+	for _, entry := range strings.Split(out, "\n\n") {
+		var worktree string
+		for _, line := range strings.Split(entry, "\n") {
+			if p, ok := strings.CutPrefix(line, "worktree "); ok {
+				worktree = p
+			}
+			if line == "branch "+ref {
+				return fmt.Errorf("branch %s is checked out in %s, dashboards can only be committed to a bare repository or to a branch that is not checked out",
+					strings.TrimPrefix(ref, "refs/heads/"), worktree)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *gitRepository) status(status *ProvisionerStatus) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	status.Commit = g.commit
+	status.Branch = g.branch
+	if g.err != nil {
+		status.SyncError = g.err.Error()
+	}
+	if g.commitErr != nil {
+		status.CommitError = g.commitErr.Error()
+	}
+}
+
+func runGit(ctx context.Context, dir string, env []string, stdin []byte, args ...string) (string, error) {
+	out, err := runGitRaw(ctx, dir, env, stdin, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+func runGitRaw(ctx context.Context, dir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	// #nosec G204 -- the arguments are not passed through a shell
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,129 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	ctx := context.Background()
+	repo := t.TempDir()
+	git := func(t *testing.T, args ...string) string {
+		t.Helper()
+		out, err := runGit(ctx, repo, []string{
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		}, nil, args...)
+		require.NoError(t, err)
+		return out
+	}
+	writeFile := func(t *testing.T, name, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Join(repo, filepath.Dir(name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0o600))
+	}
+
+	git(t, "init", "-q", "-b", "main")
+	writeFile(t, "dashboards/team/one.json", `{"uid": "one", "title": "One"}`)
+	writeFile(t, "dashboards/two.json", `{"uid": "two", "title": "Two"}`)
+	writeFile(t, "dashboards/broken.json", `{"uid": `)
+	writeFile(t, "README.md", "not a dashboard")
+	git(t, "add", "-A")
+	git(t, "commit", "-q", "-m", "Add dashboards")
+	head := git(t, "rev-parse", "HEAD")
+
+	cfg := &config{
+		Name:           configName,
+		Type:           "git",
+		OrgID:          1,
+		AllowUIUpdates: true,
+		Options: map[string]any{
+			"repository":   repo,
+			"branch":       "main",
+			"path":         "dashboards",
+			"commitOnSave": true,
+		},
+	}
+
+	fakeService := &dashboards.FakeDashboardProvisioning{}
+	defer fakeService.AssertExpectations(t)
+	fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(nil, nil).Once()
+	fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).Return(&folder.Folder{}, nil).Once()
+	fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{}, nil).Times(2)
+
+	reader, err := NewDashboardGitReader(cfg, t.TempDir(), log.New("test-logger"), nil, &fakeDashboardStore{}, nil)
+	require.NoError(t, err)
+	reader.dashboardProvisioningService = fakeService
+
+	require.NoError(t, reader.walkDisk(ctx))
+
+	t.Run("status reports the synced commit and broken files", func(t *testing.T) {
+		status := reader.status()
+		assert.Equal(t, configName, status.Name)
+		assert.Equal(t, "main", status.Branch)
+		assert.Equal(t, head, status.Commit)
+		assert.Empty(t, status.SyncError)
+		assert.False(t, status.LastSynced.IsZero())
+		require.Len(t, status.Errors, 1)
+		assert.Equal(t, "broken.json", status.Errors[0].File)
+
+		_, err := os.Stat(filepath.Join(reader.resolvedPath(), "README.md"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("dashboards are not committed to a branch that is checked out", func(t *testing.T) {
+		data := simplejson.NewFromAny(map[string]any{"uid": "one", "title": "One renamed"})
+		_, err := reader.saveToSource(ctx, filepath.Join(reader.resolvedPath(), "team", "one.json"), data, SourceAuthor{Name: "Editor"})
+		require.ErrorContains(t, err, "branch main is checked out")
+		assert.Equal(t, head, git(t, "rev-parse", "main"))
+		assert.Equal(t, err.Error(), reader.status().CommitError)
+
+		// Switch the working tree to another branch, so that main can be committed to.
+		git(t, "checkout", "-q", "-b", "work")
+	})
+
+	t.Run("dashboards saved from the UI are committed to the branch", func(t *testing.T) {
+		data := simplejson.NewFromAny(map[string]any{"id": 3, "version": 2, "uid": "one", "title": "One renamed"})
+		saved, err := reader.saveToSource(ctx, filepath.Join(reader.resolvedPath(), "team", "one.json"), data, SourceAuthor{Name: "Editor", Email: "editor@example.com"})
+		require.NoError(t, err)
+		require.True(t, saved)
+
+		assert.Equal(t, head, git(t, "rev-parse", "main^"))
+		assert.Equal(t, "Editor <editor@example.com>", git(t, "log", "-1", "--format=%an <%ae>", "main"))
+		assert.Empty(t, reader.status().CommitError)
+
+		committed, err := simplejson.NewJson([]byte(git(t, "show", "main:dashboards/team/one.json")))
+		require.NoError(t, err)
+		assert.Equal(t, "One renamed", committed.Get("title").MustString())
+		_, hasID := committed.CheckGet("id")
+		assert.False(t, hasID)
+
+		// Saving the same dashboard again does not create an empty commit.
+		commit := git(t, "rev-parse", "main")
+		_, err = reader.saveToSource(ctx, filepath.Join(reader.resolvedPath(), "team", "one.json"), data, SourceAuthor{Name: "Editor", Email: "editor@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, commit, git(t, "rev-parse", "main"))
+	})
+
+	t.Run("dashboards outside of the provisioner are not committed", func(t *testing.T) {
+		data := simplejson.NewFromAny(map[string]any{"uid": "other"})
+		_, err := reader.saveToSource(ctx, filepath.Join(repo, "other.json"), data, SourceAuthor{Name: "Editor"})
+		require.Error(t, err)
+	})
+}
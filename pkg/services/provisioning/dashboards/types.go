@@ -36,6 +36,34 @@ type configV0 struct {
 	AllowUIUpdates        bool           `json:"allowUiUpdates" yaml:"allowUiUpdates"`
 }
 
+// ProvisionerStatus is the status of the last sync of a dashboard provider.
+type ProvisionerStatus struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Path string `json:"path"`
+	// Branch and Commit are the branch and the last synced commit of git providers.
+	Branch     string    `json:"branch,omitempty"`
+	Commit     string    `json:"commit,omitempty"`
+	LastSynced time.Time `json:"lastSynced"`
+	// SyncError is the error of the last sync of git providers.
+	SyncError string `json:"syncError,omitempty"`
+	// CommitError is the error of the last commit of a dashboard saved from the UI to git providers.
+	CommitError string `json:"commitError,omitempty"`
+	// Errors are the dashboard files that could not be read or saved in the last sync.
+	Errors []FileError `json:"errors"`
+}
+
+type FileError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// SourceAuthor is the author of the changes of dashboards written back to their provider.
+type SourceAuthor struct {
+	Name  string
+	Email string
+}
+
 type configVersion struct {
 	APIVersion int64 `json:"apiVersion" yaml:"apiVersion"`
 }
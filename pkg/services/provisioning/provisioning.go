@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/correlations"
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
@@ -85,7 +86,7 @@ func ProvideService(
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.Cfg.DataPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -102,6 +103,8 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	GetDashboardProvisionerStatus() []dashboards.ProvisionerStatus
+	SaveDashboardToSource(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning, dash *dashboardservice.Dashboard, user identity.Requester) (bool, error)
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -270,7 +273,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		notifier.NewCachedNotificationSettingsValidationService(&st),
 		alertingauthz.NewRuleService(ps.ac),
 	)
-	receiverSvc := notifier.NewReceiverService(ps.ac, &st, st, ps.secretService, ps.SQLStore, ps.log)
+	receiverSvc := notifier.NewReceiverService(ps.ac, &st, st, ps.secretService, ps.SQLStore, nil, ps.log)
 	contactPointService := provisioning.NewContactPointService(&st, ps.secretService,
 		st, ps.SQLStore, receiverSvc, ps.log, &st)
 	notificationPolicyService := provisioning.NewNotificationPolicyService(&st,
@@ -298,6 +301,20 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *ProvisioningServiceImpl) GetDashboardProvisionerStatus() []dashboards.ProvisionerStatus {
+	return ps.dashboardProvisioner.GetProvisionerStatus()
+}
+
+// SaveDashboardToSource writes a provisioned dashboard saved from the UI back to its provider, with the user as author.
+func (ps *ProvisioningServiceImpl) SaveDashboardToSource(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning,
+	dash *dashboardservice.Dashboard, user identity.Requester) (bool, error) {
+	author := dashboards.SourceAuthor{Name: user.GetDisplayName(), Email: user.GetEmail()}
+	if author.Name == "" {
+		author.Name = user.GetLogin()
+	}
+	return ps.dashboardProvisioner.SaveDashboardToSource(ctx, provisioning, dash, author)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,6 +1,12 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -10,6 +16,7 @@ type Calls struct {
 	ProvisionAlerting                   []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	SaveDashboardToSource               []any
 	Run                                 []any
 }
 
@@ -21,6 +28,7 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	SaveDashboardToSourceFunc               func(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning, dash *dashboardservice.Dashboard, user identity.Requester) (bool, error)
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -83,6 +91,19 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) GetDashboardProvisionerStatus() []dashboards.ProvisionerStatus {
+	return []dashboards.ProvisionerStatus{}
+}
+
+func (mock *ProvisioningServiceMock) SaveDashboardToSource(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning,
+	dash *dashboardservice.Dashboard, user identity.Requester) (bool, error) {
+	mock.Calls.SaveDashboardToSource = append(mock.Calls.SaveDashboardToSource, provisioning)
+	if mock.SaveDashboardToSourceFunc != nil {
+		return mock.SaveDashboardToSourceFunc(ctx, provisioning, dash, user)
+	}
+	return false, nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
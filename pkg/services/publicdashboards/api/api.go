@@ -32,6 +32,7 @@ type Api struct {
 	license       licensing.Licensing
 	log           log.Logger
 	routeRegister routing.RouteRegister
+	rateLimiter   *QueryRateLimiter
 }
 
 func ProvideApi(
@@ -52,6 +53,7 @@ func ProvideApi(
 		license:                license,
 		log:                    log.New("publicdashboards.api"),
 		routeRegister:          rr,
+		rateLimiter:            NewQueryRateLimiter(cfg),
 	}
 
 	// register endpoints if the feature is enabled
@@ -75,7 +77,7 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Group("/api/public/dashboards/:accessToken", func(apiRoute routing.RouteRegister) {
 		apiRoute.Get("/", routing.Wrap(api.ViewPublicDashboard))
 		apiRoute.Get("/annotations", routing.Wrap(api.GetPublicAnnotations))
-		apiRoute.Post("/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+		apiRoute.Post("/panels/:panelId/query", RateLimitQueries(api.rateLimiter), routing.Wrap(api.QueryPublicDashboard))
 	}, api.Middleware.HandleApi)
 
 	// Auth endpoints
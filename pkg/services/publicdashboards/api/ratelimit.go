@@ -0,0 +1,107 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// rateLimiterIdleTimeout is how long the limiters of a public dashboard or viewer are kept without queries
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// QueryRateLimiter limits the queries of public dashboards, for all viewers of a public dashboard and for
+// each viewer IP address of a public dashboard.
+type QueryRateLimiter struct {
+	dashboardLimit rate.Limit
+	ipLimit        rate.Limit
+	burst          int
+	now            func() time.Time
+
+	mu          sync.Mutex
+	dashboards  map[string]*limiterEntry
+	ips         map[string]*limiterEntry
+	lastCleanup time.Time
+}
+
+func NewQueryRateLimiter(cfg *setting.Cfg) *QueryRateLimiter {
+	burst := cfg.PublicDashboardsQueryRateLimitBurst
+	if burst < 1 {
+		burst = 1
+	}
+	return &QueryRateLimiter{
+		dashboardLimit: rate.Limit(cfg.PublicDashboardsQueryRateLimit),
+		ipLimit:        rate.Limit(cfg.PublicDashboardsQueryRateLimitPerIP),
+		burst:          burst,
+		now:            time.Now,
+		dashboards:     make(map[string]*limiterEntry),
+		ips:            make(map[string]*limiterEntry),
+	}
+}
+
+// Allow returns whether a query of the public dashboard from the IP address is allowed, and the limit that
+// was reached otherwise.
+func (l *QueryRateLimiter) Allow(accessToken, ip string) (bool, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.cleanup(now)
+
+	if l.ipLimit > 0 && !l.limiter(l.ips, accessToken+"/"+ip, l.ipLimit, now).AllowN(now, 1) {
+		return false, QueryRateLimitIP
+	}
+	if l.dashboardLimit > 0 && !l.limiter(l.dashboards, accessToken, l.dashboardLimit, now).AllowN(now, 1) {
+		return false, QueryRateLimitDashboard
+	}
+	return true, ""
+}
+
+func (l *QueryRateLimiter) limiter(limiters map[string]*limiterEntry, key string, limit rate.Limit, now time.Time) *rate.Limiter {
+	entry, ok := limiters[key]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(limit, l.burst)}
+		limiters[key] = entry
+	}
+	entry.lastSeen = now
+	return entry.limiter
+}
+
+// cleanup removes the limiters that have not been used for a while, so that the limiters of viewers don't
+// accumulate.
+func (l *QueryRateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < time.Minute {
+		return
+	}
+	l.lastCleanup = now
+	for _, limiters := range []map[string]*limiterEntry{l.dashboards, l.ips} {
+		for key, entry := range limiters {
+			if now.Sub(entry.lastSeen) > rateLimiterIdleTimeout {
+				delete(limiters, key)
+			}
+		}
+	}
+}
+
+// RateLimitQueries Middleware to reject the queries of a public dashboard above the rate limits
+func RateLimitQueries(limiter *QueryRateLimiter) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken := web.Params(c.Req)[":accessToken"]
+		if ok, limit := limiter.Allow(accessToken, c.RemoteAddr()); !ok {
+			metrics.MPublicDashboardQueryRateLimited.WithLabelValues(limit).Inc()
+			c.Resp.Header().Set("Retry-After", "1")
+			c.WriteErr(ErrQueryRateLimited.Errorf("RateLimitQueries: %s rate limit reached", limit))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryRateLimiter(t *testing.T) {
+	now := time.Now()
+	newLimiter := func(perDashboard, perIP float64) *QueryRateLimiter {
+		cfg := setting.NewCfg()
+		cfg.PublicDashboardsQueryRateLimit = perDashboard
+		cfg.PublicDashboardsQueryRateLimitPerIP = perIP
+		cfg.PublicDashboardsQueryRateLimitBurst = 2
+		limiter := NewQueryRateLimiter(cfg)
+		limiter.now = func() time.Time { return now }
+		return limiter
+	}
+
+	t.Run("allows all queries without limits", func(t *testing.T) {
+		limiter := newLimiter(0, 0)
+		for i := 0; i < 100; i++ {
+			ok, _ := limiter.Allow("token", "10.0.0.1")
+			require.True(t, ok)
+		}
+	})
+
+	t.Run("limits queries per ip of a public dashboard", func(t *testing.T) {
+		limiter := newLimiter(0, 1)
+		for i := 0; i < 2; i++ {
+			ok, _ := limiter.Allow("token", "10.0.0.1")
+			require.True(t, ok)
+		}
+		ok, limit := limiter.Allow("token", "10.0.0.1")
+		require.False(t, ok)
+		assert.Equal(t, models.QueryRateLimitIP, limit)
+
+		// other viewers and other public dashboards are not limited
+		ok, _ = limiter.Allow("token", "10.0.0.2")
+		assert.True(t, ok)
+		ok, _ = limiter.Allow("other-token", "10.0.0.1")
+		assert.True(t, ok)
+
+		now = now.Add(time.Second)
+		ok, _ = limiter.Allow("token", "10.0.0.1")
+		assert.True(t, ok)
+	})
+
+	t.Run("limits queries of all viewers of a public dashboard", func(t *testing.T) {
+		limiter := newLimiter(1, 0)
+		ok, _ := limiter.Allow("token", "10.0.0.1")
+		require.True(t, ok)
+		ok, _ = limiter.Allow("token", "10.0.0.2")
+		require.True(t, ok)
+		ok, limit := limiter.Allow("token", "10.0.0.3")
+		require.False(t, ok)
+		assert.Equal(t, models.QueryRateLimitDashboard, limit)
+	})
+
+	t.Run("removes idle limiters", func(t *testing.T) {
+		limiter := newLimiter(1, 1)
+		limiter.Allow("token", "10.0.0.1")
+		require.Len(t, limiter.ips, 1)
+
+		now = now.Add(rateLimiterIdleTimeout + time.Minute)
+		limiter.Allow("other-token", "10.0.0.2")
+		assert.Len(t, limiter.ips, 1)
+		assert.Len(t, limiter.dashboards, 1)
+	})
+}
+
+func TestRateLimitQueries(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.PublicDashboardsQueryRateLimitPerIP = 1
+	cfg.PublicDashboardsQueryRateLimitBurst = 1
+	mw := RateLimitQueries(NewQueryRateLimiter(cfg))
+	params := map[string]string{":accessToken": validAccessToken}
+	path := "/api/public/dashboards/" + validAccessToken + "/panels/1/query"
+
+	_, resp := runMw(t, nil, "POST", path, params, mw)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	_, resp = runMw(t, &contextmodel.ReqContext{Logger: log.New("test")}, "POST", path, params, mw)
+	require.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("Retry-After"))
+}
@@ -585,16 +585,18 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.EqualValues(t, affectedRows, 1)
 
 		updatedPublicDashboard := PublicDashboard{
-			Uid:                  pdUid,
-			DashboardUid:         savedDashboard.UID,
-			OrgId:                savedDashboard.OrgID,
-			IsEnabled:            false,
-			AnnotationsEnabled:   true,
-			TimeSelectionEnabled: true,
-			Share:                EmailShareType,
-			TimeSettings:         &TimeSettings{From: "now-8", To: "now"},
-			UpdatedAt:            time.Now().UTC().Round(time.Second),
-			UpdatedBy:            8,
+			Uid:                   pdUid,
+			DashboardUid:          savedDashboard.UID,
+			OrgId:                 savedDashboard.OrgID,
+			IsEnabled:             false,
+			AnnotationsEnabled:    true,
+			TimeSelectionEnabled:  true,
+			Share:                 EmailShareType,
+			TimeSettings:          &TimeSettings{From: "now-8", To: "now"},
+			UpdatedAt:             time.Now().UTC().Round(time.Second),
+			UpdatedBy:             8,
+			TimeSelectionMaxRange: "7d",
+			TemplateVariables:     TemplateVariables{{Name: "env", Values: []string{"prod", "dev"}}},
 		}
 
 		// update initial record
@@ -614,6 +616,8 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.Equal(t, updatedPublicDashboard.AnnotationsEnabled, pdRetrieved.AnnotationsEnabled)
 		assert.Equal(t, updatedPublicDashboard.TimeSelectionEnabled, pdRetrieved.TimeSelectionEnabled)
 		assert.Equal(t, updatedPublicDashboard.Share, pdRetrieved.Share)
+		assert.Equal(t, updatedPublicDashboard.TimeSelectionMaxRange, pdRetrieved.TimeSelectionMaxRange)
+		assert.Equal(t, updatedPublicDashboard.TemplateVariables, pdRetrieved.TemplateVariables)
 
 		// not updated dashboard shouldn't have changed
 		pdNotUpdatedRetrieved, err := publicdashboardStore.FindByDashboardUid(context.Background(), anotherSavedDashboard.OrgID, anotherSavedDashboard.UID)
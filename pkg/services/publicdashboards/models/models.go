@@ -26,6 +26,8 @@ func (e PublicDashboardErr) Error() string {
 const (
 	QuerySuccess                                  = "success"
 	QueryFailure                                  = "failure"
+	QueryRateLimitDashboard                       = "dashboard"
+	QueryRateLimitIP                              = "ip"
 	EmailShareType                      ShareType = "email"
 	PublicShareType                     ShareType = "public"
 	FeaturePublicDashboardsEmailSharing           = "publicDashboardsEmailSharing"
@@ -33,6 +35,7 @@ const (
 
 var (
 	QueryResultStatuses = []string{QuerySuccess, QueryFailure}
+	QueryRateLimits     = []string{QueryRateLimitDashboard, QueryRateLimitIP}
 	ValidShareTypes     = []ShareType{EmailShareType, PublicShareType}
 )
 
@@ -50,19 +53,55 @@ type PublicDashboard struct {
 	//config fields
 	TimeSettings         *TimeSettings `json:"-" xorm:"time_settings"`
 	TimeSelectionEnabled bool          `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
-	IsEnabled            bool          `json:"isEnabled" xorm:"is_enabled"`
-	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
-	Share                ShareType     `json:"share" xorm:"share"`
-	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// TimeSelectionMaxRange is the longest time range viewers can select, such as 7d. Empty means unbounded.
+	TimeSelectionMaxRange string            `json:"timeSelectionMaxRange,omitempty" xorm:"time_selection_max_range"`
+	TemplateVariables     TemplateVariables `json:"templateVariables" xorm:"template_variables"`
+	IsEnabled             bool              `json:"isEnabled" xorm:"is_enabled"`
+	AnnotationsEnabled    bool              `json:"annotationsEnabled" xorm:"annotations_enabled"`
+	Share                 ShareType         `json:"share" xorm:"share"`
+	Recipients            []EmailDTO        `json:"recipients,omitempty" xorm:"-"`
 }
 
 type PublicDashboardDTO struct {
-	Uid                  string    `json:"uid"`
-	AccessToken          string    `json:"accessToken"`
-	TimeSelectionEnabled *bool     `json:"timeSelectionEnabled"`
-	IsEnabled            *bool     `json:"isEnabled"`
-	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
-	Share                ShareType `json:"share"`
+	Uid                   string            `json:"uid"`
+	AccessToken           string            `json:"accessToken"`
+	TimeSelectionEnabled  *bool             `json:"timeSelectionEnabled"`
+	TimeSelectionMaxRange *string           `json:"timeSelectionMaxRange"`
+	TemplateVariables     TemplateVariables `json:"templateVariables"`
+	IsEnabled             *bool             `json:"isEnabled"`
+	AnnotationsEnabled    *bool             `json:"annotationsEnabled"`
+	Share                 ShareType         `json:"share"`
+}
+
+// TemplateVariable is a template variable of the dashboard that viewers of the public dashboard can change,
+// and the values they can select.
+type TemplateVariable struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+type TemplateVariables []TemplateVariable
+
+// Find returns the template variable with the name, or nil if viewers can't change it.
+func (tv TemplateVariables) Find(name string) *TemplateVariable {
+	for i := range tv {
+		if tv[i].Name == name {
+			return &tv[i]
+		}
+	}
+	return nil
+}
+
+func (tv *TemplateVariables) FromDB(data []byte) error {
+	if len(data) == 0 {
+		*tv = nil
+		return nil
+	}
+	return json.Unmarshal(data, tv)
+}
+
+func (tv TemplateVariables) ToDB() ([]byte, error) {
+	return json.Marshal(tv)
 }
 
 type EmailDTO struct {
@@ -154,6 +193,8 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables are the values of the template variables selected by the viewer, by name
+	Variables map[string][]string
 }
 
 type AnnotationsQueryDTO struct {
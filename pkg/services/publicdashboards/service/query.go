@@ -163,6 +163,12 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 		return dtos.MetricRequest{}, models.ErrPanelNotFound.Errorf("buildMetricRequest: public dashboard panel not found")
 	}
 
+	// replace the template variables viewers can change with the selected values
+	variables := templateVariableValues(dashboard.Data, publicDashboard.TemplateVariables, reqDTO.Variables)
+	for i := range queries {
+		interpolateTemplateVariables(queries[i], variables)
+	}
+
 	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
 
 	// determine safe resolution to query data at
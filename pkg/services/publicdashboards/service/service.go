@@ -104,6 +104,7 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
 
 	sanitizeData(dash.Data)
+	sanitizeTemplateVariables(dash.Data, pubdash.TemplateVariables)
 
 	return &dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}, nil
 }
@@ -197,7 +198,12 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	// ensure dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateTemplateVariables(dto.PublicDashboard.TemplateVariables, dash.Data)
 	if err != nil {
 		return nil, err
 	}
@@ -254,7 +260,12 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	}
 
 	// validate dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateTemplateVariables(dto.PublicDashboard.TemplateVariables, dash.Data)
 	if err != nil {
 		return nil, err
 	}
@@ -503,22 +514,29 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		share = PublicShareType
 	}
 
+	timeSelectionMaxRange := ""
+	if dto.PublicDashboard.TimeSelectionMaxRange != nil {
+		timeSelectionMaxRange = *dto.PublicDashboard.TimeSelectionMaxRange
+	}
+
 	now := time.Now()
 
 	return &PublicDashboard{
-		Uid:                  uid,
-		DashboardUid:         dto.DashboardUid,
-		OrgId:                dto.OrgID,
-		IsEnabled:            isEnabled,
-		AnnotationsEnabled:   annotationsEnabled,
-		TimeSelectionEnabled: timeSelectionEnabled,
-		TimeSettings:         &TimeSettings{},
-		Share:                share,
-		CreatedBy:            dto.UserId,
-		CreatedAt:            now,
-		UpdatedBy:            dto.UserId,
-		UpdatedAt:            now,
-		AccessToken:          accessToken,
+		Uid:                   uid,
+		DashboardUid:          dto.DashboardUid,
+		OrgId:                 dto.OrgID,
+		IsEnabled:             isEnabled,
+		AnnotationsEnabled:    annotationsEnabled,
+		TimeSelectionEnabled:  timeSelectionEnabled,
+		TimeSelectionMaxRange: timeSelectionMaxRange,
+		TemplateVariables:     dto.PublicDashboard.TemplateVariables,
+		TimeSettings:          &TimeSettings{},
+		Share:                 share,
+		CreatedBy:             dto.UserId,
+		CreatedAt:             now,
+		UpdatedBy:             dto.UserId,
+		UpdatedAt:             now,
+		AccessToken:           accessToken,
 	}, nil
 }
 
@@ -533,15 +551,28 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	timeSelectionMaxRange := pd.TimeSelectionMaxRange
+	if pubdashDTO.TimeSelectionMaxRange != nil {
+		timeSelectionMaxRange = *pubdashDTO.TimeSelectionMaxRange
+	}
+
+	// template variables are only updated when they are part of the request, an empty list removes them all
+	templateVariables := pd.TemplateVariables
+	if pubdashDTO.TemplateVariables != nil {
+		templateVariables = pubdashDTO.TemplateVariables
+	}
+
 	return &PublicDashboard{
-		Uid:                  pd.Uid,
-		IsEnabled:            isEnabled,
-		AnnotationsEnabled:   annotationsEnabled,
-		TimeSelectionEnabled: timeSelectionEnabled,
-		TimeSettings:         pd.TimeSettings,
-		Share:                share,
-		UpdatedBy:            dto.UserId,
-		UpdatedAt:            time.Now(),
+		Uid:                   pd.Uid,
+		IsEnabled:             isEnabled,
+		AnnotationsEnabled:    annotationsEnabled,
+		TimeSelectionEnabled:  timeSelectionEnabled,
+		TimeSelectionMaxRange: timeSelectionMaxRange,
+		TemplateVariables:     templateVariables,
+		TimeSettings:          pd.TimeSettings,
+		Share:                 share,
+		UpdatedBy:             dto.UserId,
+		UpdatedAt:             time.Now(),
 	}
 }
 
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// variableRegex matches $var, ${var}, ${var:format} and [[var]] or [[var:format]], like the frontend does
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::(\w+))?\}`)
+
+// templateVariableValues returns the values of the template variables viewers can change, by name. Values
+// selected by the viewer take precedence over the current values of the dashboard. Selected values are
+// expected to be validated against the allowed values already.
+func templateVariableValues(dashboardData *simplejson.Json, allowed models.TemplateVariables, selected map[string][]string) map[string][]string {
+	values := make(map[string][]string, len(allowed))
+	for _, v := range dashboardData.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		if allowed.Find(name) == nil {
+			continue
+		}
+		if s, ok := selected[name]; ok && len(s) > 0 {
+			values[name] = s
+			continue
+		}
+
+		current := variable.GetPath("current", "value")
+		if s, err := current.String(); err == nil {
+			values[name] = []string{s}
+		} else {
+			values[name] = current.MustStringArray()
+		}
+	}
+	return values
+}
+
+// interpolateTemplateVariables replaces the template variables in all strings of the query, except its
+// datasource and ref ID. Variables without a value are left unchanged.
+func interpolateTemplateVariables(query *simplejson.Json, values map[string][]string) {
+	if len(values) == 0 {
+		return
+	}
+	for key, value := range query.MustMap() {
+		if key == "datasource" || key == "refId" {
+			continue
+		}
+		query.Set(key, interpolateValue(value, values))
+	}
+}
+
+func interpolateValue(value any, values map[string][]string) any {
+	switch v := value.(type) {
+	case string:
+		return interpolateString(v, values)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = interpolateValue(item, values)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = interpolateValue(item, values)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func interpolateString(s string, values map[string][]string) string {
+	if !strings.ContainsAny(s, "$[") {
+		return s
+	}
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[5]
+		}
+
+		value, ok := values[name]
+		if !ok {
+			return match
+		}
+		return formatVariableValue(value, format)
+	})
+}
+
+// formatVariableValue formats the values of a variable with one of the formats of the frontend. Multiple
+// values default to the glob format.
+func formatVariableValue(values []string, format string) string {
+	switch format {
+	case "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, len(values))
+		for i, v := range values {
+			escaped[i] = regexp.QuoteMeta(v)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quoteValues(values, "'")
+	case "doublequote":
+		return quoteValues(values, `"`)
+	case "raw":
+		return strings.Join(values, ",")
+	}
+
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+func quoteValues(values []string, quote string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote + strings.ReplaceAll(v, quote, `\`+quote) + quote
+	}
+	return strings.Join(quoted, ",")
+}
+
+// sanitizeTemplateVariables hides the template variables viewers can't change, and replaces the options of
+// the others with their allowed values, so that the definitions of the variables are not exposed.
+func sanitizeTemplateVariables(data *simplejson.Json, allowed models.TemplateVariables) {
+	for _, v := range data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		variable.Del("query")
+		variable.Del("definition")
+		variable.Del("datasource")
+		variable.Del("regex")
+
+		allowedVariable := allowed.Find(variable.Get("name").MustString())
+		if allowedVariable == nil {
+			// 2 hides the variable and its label
+			variable.Set("hide", 2)
+			variable.Set("options", []any{})
+			continue
+		}
+
+		options := make([]any, 0, len(allowedVariable.Values))
+		query := make([]string, 0, len(allowedVariable.Values))
+		for _, value := range allowedVariable.Values {
+			options = append(options, map[string]any{"text": value, "value": value, "selected": false})
+			query = append(query, strings.ReplaceAll(value, ",", `\,`))
+		}
+		variable.Set("type", "custom")
+		variable.Set("query", strings.Join(query, ","))
+		variable.Set("options", options)
+		variable.Set("includeAll", false)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const dashboardWithVariablesJSON = `{
+	"templating": {
+		"list": [
+			{"name": "env", "type": "query", "query": "label_values(env)", "datasource": {"uid": "prom"},
+			 "current": {"text": "prod", "value": "prod"}},
+			{"name": "host", "type": "query", "query": "label_values(host)",
+			 "current": {"text": ["a", "b"], "value": ["a", "b"]}},
+			{"name": "secret", "type": "textbox", "query": "hidden",
+			 "current": {"text": "s", "value": "s"}}
+		]
+	}
+}`
+
+func TestInterpolateTemplateVariables(t *testing.T) {
+	dashboardData, err := simplejson.NewJson([]byte(dashboardWithVariablesJSON))
+	require.NoError(t, err)
+	allowed := TemplateVariables{{Name: "env", Values: []string{"prod", "dev"}}, {Name: "host", Values: []string{"a", "b", "c"}}}
+
+	t.Run("uses the values selected by the viewer and defaults to the current values", func(t *testing.T) {
+		values := templateVariableValues(dashboardData, allowed, map[string][]string{"env": {"dev"}})
+		assert.Equal(t, map[string][]string{"env": {"dev"}, "host": {"a", "b"}}, values)
+	})
+
+	t.Run("replaces the variables viewers can change in the query", func(t *testing.T) {
+		query := simplejson.NewFromAny(map[string]any{
+			"refId":      "$env",
+			"datasource": map[string]any{"uid": "$env"},
+			"expr":       `up{env="$env", host=~"${host:regex}", secret="$secret"}`,
+			"rawSql":     "SELECT * FROM t WHERE host IN (${host:singlequote}) AND env = '[[env]]' AND i > $__interval",
+			"filters":    []any{map[string]any{"value": "${host:csv}"}},
+		})
+
+		interpolateTemplateVariables(query, map[string][]string{"env": {"dev"}, "host": {"a", "b"}})
+
+		assert.Equal(t, "$env", query.Get("refId").MustString())
+		assert.Equal(t, "$env", query.GetPath("datasource", "uid").MustString())
+		assert.Equal(t, `up{env="dev", host=~"(a|b)", secret="$secret"}`, query.Get("expr").MustString())
+		assert.Equal(t, "SELECT * FROM t WHERE host IN ('a','b') AND env = 'dev' AND i > $__interval", query.Get("rawSql").MustString())
+		assert.Equal(t, "a,b", simplejson.NewFromAny(query.Get("filters").MustArray()[0]).Get("value").MustString())
+	})
+
+	t.Run("formats multiple values as glob by default", func(t *testing.T) {
+		assert.Equal(t, "{a,b}", interpolateString("$host", map[string][]string{"host": {"a", "b"}}))
+	})
+}
+
+func TestSanitizeTemplateVariables(t *testing.T) {
+	dashboardData, err := simplejson.NewJson([]byte(dashboardWithVariablesJSON))
+	require.NoError(t, err)
+
+	sanitizeTemplateVariables(dashboardData, TemplateVariables{{Name: "env", Values: []string{"prod", "dev"}}})
+
+	variables := dashboardData.GetPath("templating", "list").MustArray()
+	env := simplejson.NewFromAny(variables[0])
+	assert.Equal(t, "custom", env.Get("type").MustString())
+	assert.Equal(t, "prod,dev", env.Get("query").MustString())
+	assert.Len(t, env.Get("options").MustArray(), 2)
+	_, hasDatasource := env.CheckGet("datasource")
+	assert.False(t, hasDatasource)
+
+	for _, v := range variables[1:] {
+		variable := simplejson.NewFromAny(v)
+		assert.Equal(t, 2, variable.Get("hide").MustInt())
+		_, hasQuery := variable.CheckGet("query")
+		assert.False(t, hasQuery)
+	}
+}
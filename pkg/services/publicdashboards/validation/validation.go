@@ -2,6 +2,8 @@ package validation
 
 import (
 	"github.com/google/uuid"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
@@ -13,6 +15,37 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if maxRange := dto.PublicDashboard.TimeSelectionMaxRange; maxRange != nil && *maxRange != "" {
+		if d, err := gtime.ParseDuration(*maxRange); err != nil || d <= 0 {
+			return ErrInvalidMaxTimeRange.Errorf("ValidateSavePublicDashboard: invalid max time range %q", *maxRange)
+		}
+	}
+
+	seen := make(map[string]bool, len(dto.PublicDashboard.TemplateVariables))
+	for _, variable := range dto.PublicDashboard.TemplateVariables {
+		if variable.Name == "" || seen[variable.Name] {
+			return ErrInvalidTemplateVariable.Errorf("ValidateSavePublicDashboard: template variable names must be unique and not empty")
+		}
+		if len(variable.Values) == 0 {
+			return ErrInvalidTemplateVariable.Errorf("ValidateSavePublicDashboard: template variable %s has no allowed values", variable.Name)
+		}
+		seen[variable.Name] = true
+	}
+
+	return nil
+}
+
+// ValidateTemplateVariables asserts that the template variables viewers can change exist in the dashboard
+func ValidateTemplateVariables(variables TemplateVariables, dashboardData *simplejson.Json) error {
+	names := make(map[string]bool)
+	for _, v := range dashboardData.GetPath("templating", "list").MustArray() {
+		names[simplejson.NewFromAny(v).Get("name").MustString()] = true
+	}
+	for _, variable := range variables {
+		if !names[variable.Name] {
+			return ErrInvalidTemplateVariable.Errorf("ValidateTemplateVariables: dashboard has no template variable %s", variable.Name)
+		}
+	}
 	return nil
 }
 
@@ -28,19 +61,47 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 	if pd.TimeSelectionEnabled {
 		timeRange := legacydata.NewDataTimeRange(req.TimeRange.From, req.TimeRange.To)
 
-		_, err := timeRange.ParseFrom()
+		from, err := timeRange.ParseFrom()
 		if err != nil {
 			return ErrInvalidTimeRange.Errorf("ValidateQueryPublicDashboardRequest: time range from is invalid")
 		}
-		_, err = timeRange.ParseTo()
+		to, err := timeRange.ParseTo()
 		if err != nil {
 			return ErrInvalidTimeRange.Errorf("ValidateQueryPublicDashboardRequest: time range to is invalid")
 		}
+
+		if pd.TimeSelectionMaxRange != "" {
+			maxRange, err := gtime.ParseDuration(pd.TimeSelectionMaxRange)
+			if err == nil && to.Sub(from) > maxRange {
+				return ErrTimeRangeExceedsMax.Errorf("ValidateQueryPublicDashboardRequest: time range exceeds %s", pd.TimeSelectionMaxRange)
+			}
+		}
+	}
+
+	for name, values := range req.Variables {
+		variable := pd.TemplateVariables.Find(name)
+		if variable == nil {
+			return ErrInvalidTemplateVariable.Errorf("ValidateQueryPublicDashboardRequest: template variable %s can't be changed", name)
+		}
+		for _, value := range values {
+			if !isAllowedValue(variable.Values, value) {
+				return ErrInvalidTemplateVariable.Errorf("ValidateQueryPublicDashboardRequest: value of template variable %s is not allowed", name)
+			}
+		}
 	}
 
 	return nil
 }
 
+func isAllowedValue(allowed []string, value string) bool {
+	for _, v := range allowed {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // IsValidAccessToken asserts that an accessToken is a valid uuid
 func IsValidAccessToken(token string) bool {
 	_, err := uuid.Parse(token)
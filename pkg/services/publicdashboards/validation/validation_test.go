@@ -3,6 +3,7 @@ package validation
 import (
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +23,41 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when max time range is invalid", func(t *testing.T) {
+		maxRange := "a week"
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{TimeSelectionMaxRange: &maxRange}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidMaxTimeRange)
+	})
+
+	t.Run("Returns error when template variable has no allowed values", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{
+			TemplateVariables: TemplateVariables{{Name: "env"}},
+		}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidTemplateVariable)
+	})
+
+	t.Run("Returns error when template variable is duplicated", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{
+			TemplateVariables: TemplateVariables{{Name: "env", Values: []string{"prod"}}, {Name: "env", Values: []string{"dev"}}},
+		}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidTemplateVariable)
+	})
+}
+
+func TestValidateTemplateVariables(t *testing.T) {
+	dashboardData := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{"list": []any{map[string]any{"name": "env"}}},
+	})
+
+	require.NoError(t, ValidateTemplateVariables(TemplateVariables{{Name: "env", Values: []string{"prod"}}}, dashboardData))
+	require.ErrorIs(t, ValidateTemplateVariables(TemplateVariables{{Name: "region", Values: []string{"eu"}}}, dashboardData), ErrInvalidTemplateVariable)
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
@@ -114,6 +150,74 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns no error when time range is within the max time range",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					TimeRange: TimeRangeDTO{
+						From: "now-6d",
+						To:   "now",
+					},
+				},
+				pd: &PublicDashboard{
+					TimeSelectionEnabled:  true,
+					TimeSelectionMaxRange: "7d",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when time range exceeds the max time range",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					TimeRange: TimeRangeDTO{
+						From: "now-30d",
+						To:   "now",
+					},
+				},
+				pd: &PublicDashboard{
+					TimeSelectionEnabled:  true,
+					TimeSelectionMaxRange: "7d",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns no error when template variable values are allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"env": {"prod", "dev"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{{Name: "env", Values: []string{"prod", "dev"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when template variable value is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"env": {"staging"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{{Name: "env", Values: []string{"prod", "dev"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns validation error when template variable can't be changed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"host": {"a"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{{Name: "env", Values: []string{"prod"}}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Returns validation error when time range from or to is blank",
 			args: args{
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -236,6 +237,9 @@ func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Reque
 	if err != nil {
 		return nil, fmt.Errorf("expression request error: %w", err)
 	}
+
+	// results of data source queries are limited by the plugin client, the results of expressions are limited here
+	querylimits.Apply(s.cfg.QueryLimits.ForOrg(exprReq.OrgId), qdr, expr.DatasourceType, querylimits.SourceExpression)
 	return qdr, nil
 }
 
@@ -0,0 +1,356 @@
+// Package querylimits limits the number of series, data points and bytes of query results, so that
+// large results fail with an error instead of exhausting the memory of the server.
+//
+// The limits of series and points are sent to plugins in headers of the request. The Prometheus, Loki,
+// Elasticsearch and InfluxDB plugins count the series and points of a result while they read the response
+// of the data source, and stop once the result exceeds the limits. The limit of bytes stops reading HTTP
+// responses of data sources to core plugins which are too large. All limits are checked again on the
+// result returned by the plugin, for the plugins and results which are not limited while they are read.
+package querylimits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	LimitSeries        = "series"
+	LimitPoints        = "points"
+	LimitResponseBytes = "response_bytes"
+
+	// SourceDataSource and SourceExpression are the sources of results in metrics
+	SourceDataSource = "datasource"
+	SourceExpression = "expression"
+
+	// HeaderMaxSeries and HeaderMaxPoints are the headers of query requests to plugins with the limits
+	// of series and points of the results. They are not forwarded to data sources.
+	HeaderMaxSeries = "QueryLimitsMaxSeries"
+	HeaderMaxPoints = "QueryLimitsMaxPoints"
+)
+
+var exceededTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "query_limits",
+		Name:      "exceeded_total",
+		Help:      "A counter for query results which were replaced by an error because they exceeded a limit",
+	},
+	[]string{"limit", "plugin_id", "source"},
+)
+
+// ExceededError is the error of a query result which exceeds a limit.
+type ExceededError struct {
+	Limit  string
+	Max    int64
+	Actual int64
+}
+
+func (e *ExceededError) Error() string {
+	unit := e.Limit
+	if e.Limit == LimitResponseBytes {
+		unit = "bytes"
+	}
+	if e.Actual == 0 {
+		return fmt.Sprintf("query result exceeds the limit of %d %s", e.Max, unit)
+	}
+	return fmt.Sprintf("query result of %d %s exceeds the limit of %d %s", e.Actual, unit, e.Max, unit)
+}
+
+// QueryLimit returns the exceeded limit.
+func (e *ExceededError) QueryLimit() (string, int64) {
+	return e.Limit, e.Max
+}
+
+// limitError is an error of a plugin which stopped reading a result because it exceeded a limit.
+// Plugins which can't depend on this package, like the Prometheus library, return their own errors
+// with the same method as ExceededError.
+type limitError interface {
+	error
+	QueryLimit() (limit string, max int64)
+}
+
+type dataSourceSettings struct {
+	QueryLimits struct {
+		MaxSeries        int64 `json:"maxSeries"`
+		MaxPoints        int64 `json:"maxPoints"`
+		MaxResponseBytes int64 `json:"maxResponseBytes"`
+	} `json:"queryLimits"`
+}
+
+// ForRequest returns the limits of the queries of a request, which are the limits of the organization
+// lowered by the limits in the settings of the data source.
+func ForRequest(cfg *setting.Cfg, pCtx backend.PluginContext) setting.QueryLimits {
+	limits := cfg.QueryLimits.ForOrg(pCtx.OrgID)
+
+	ds := pCtx.DataSourceInstanceSettings
+	if ds == nil || len(ds.JSONData) == 0 {
+		return limits
+	}
+	var settings dataSourceSettings
+	if err := json.Unmarshal(ds.JSONData, &settings); err != nil {
+		return limits
+	}
+
+	limits.MaxSeries = lower(limits.MaxSeries, settings.QueryLimits.MaxSeries)
+	limits.MaxPoints = lower(limits.MaxPoints, settings.QueryLimits.MaxPoints)
+	limits.MaxResponseBytes = lower(limits.MaxResponseBytes, settings.QueryLimits.MaxResponseBytes)
+	return limits
+}
+
+// SetHeaders sets the headers of the limits of series and points of a request, or removes them if
+// there are no limits, so that the headers of the request can't raise the limits.
+func SetHeaders(limits setting.QueryLimits, req *backend.QueryDataRequest) {
+	if req.Headers == nil {
+		req.Headers = map[string]string{}
+	}
+	setHeader(req.Headers, HeaderMaxSeries, limits.MaxSeries)
+	setHeader(req.Headers, HeaderMaxPoints, limits.MaxPoints)
+}
+
+func setHeader(headers map[string]string, name string, limit int64) {
+	if limit > 0 {
+		headers[name] = strconv.FormatInt(limit, 10)
+	} else {
+		delete(headers, name)
+	}
+}
+
+// LimitsFromHeaders returns the limits of series and points in the headers of a request, zero means no limit.
+func LimitsFromHeaders(headers map[string]string) (maxSeries, maxPoints int64) {
+	maxSeries, _ = strconv.ParseInt(headers[HeaderMaxSeries], 10, 64)
+	maxPoints, _ = strconv.ParseInt(headers[HeaderMaxPoints], 10, 64)
+	return maxSeries, maxPoints
+}
+
+// Limiter counts the series and points of a result while a plugin reads it, and returns an error once
+// they exceed the limits of the request. Plugins must not count more series or points than Check counts
+// for the result they return, so that they only stop reading results which would exceed the limits
+// anyway. A nil Limiter has no limits.
+type Limiter struct {
+	maxSeries, maxPoints int64
+	series, points       int64
+}
+
+// NewLimiter returns a limiter for limits of series and points, or nil if there are none. A limiter
+// counts a single result, so queries which are read in parallel need their own limiters.
+func NewLimiter(maxSeries, maxPoints int64) *Limiter {
+	if maxSeries <= 0 && maxPoints <= 0 {
+		return nil
+	}
+	return &Limiter{maxSeries: maxSeries, maxPoints: maxPoints}
+}
+
+// Add counts series and points, and returns an *ExceededError if the result exceeds a limit.
+func (l *Limiter) Add(series, points int64) error {
+	if l == nil {
+		return nil
+	}
+	l.series += series
+	l.points += points
+	if l.maxSeries > 0 && l.series > l.maxSeries {
+		return &ExceededError{Limit: LimitSeries, Max: l.maxSeries}
+	}
+	if l.maxPoints > 0 && l.points > l.maxPoints {
+		return &ExceededError{Limit: LimitPoints, Max: l.maxPoints}
+	}
+	return nil
+}
+
+// lower returns the lowest limit, where zero means no limit.
+func lower(limit, dsLimit int64) int64 {
+	if dsLimit <= 0 || (limit > 0 && limit < dsLimit) {
+		return limit
+	}
+	return dsLimit
+}
+
+// Apply replaces the results of the response which exceed the limits by errors.
+func Apply(limits setting.QueryLimits, resp *backend.QueryDataResponse, pluginID, source string) {
+	if resp == nil || limits == (setting.QueryLimits{}) {
+		return
+	}
+
+	for refID, res := range resp.Responses {
+		err := Check(limits, res)
+		if err == nil {
+			continue
+		}
+		exceededTotal.WithLabelValues(err.Limit, pluginID, source).Inc()
+		resp.Responses[refID] = backend.DataResponse{
+			Error:       err,
+			Status:      backend.StatusBadRequest,
+			ErrorSource: backend.ErrorSourceDownstream,
+		}
+	}
+}
+
+// Check returns an error if a result exceeds the limits. Results which failed because the plugin
+// stopped reading them at a limit, or because a response of the data source was larger than the limit
+// of bytes, also exceed the limits.
+func Check(limits setting.QueryLimits, res backend.DataResponse) *ExceededError {
+	if res.Error != nil {
+		var limitErr limitError
+		if errors.As(res.Error, &limitErr) {
+			limit, max := limitErr.QueryLimit()
+			return &ExceededError{Limit: limit, Max: max}
+		}
+		if limits.MaxResponseBytes > 0 && isResponseTooLarge(res.Error) {
+			return &ExceededError{Limit: LimitResponseBytes, Max: limits.MaxResponseBytes}
+		}
+		return nil
+	}
+
+	if limits.MaxSeries > 0 {
+		if series := countSeries(res.Frames); series > limits.MaxSeries {
+			return &ExceededError{Limit: LimitSeries, Max: limits.MaxSeries, Actual: series}
+		}
+	}
+	if limits.MaxPoints > 0 {
+		if points := countPoints(res.Frames); points > limits.MaxPoints {
+			return &ExceededError{Limit: LimitPoints, Max: limits.MaxPoints, Actual: points}
+		}
+	}
+	if limits.MaxResponseBytes > 0 {
+		if size := estimateSize(res.Frames); size > limits.MaxResponseBytes {
+			return &ExceededError{Limit: LimitResponseBytes, Max: limits.MaxResponseBytes, Actual: size}
+		}
+	}
+	return nil
+}
+
+// isResponseTooLarge returns true for errors of responses larger than the limit of the http client,
+// including errors which plugins returned as text.
+func isResponseTooLarge(err error) bool {
+	return errors.Is(err, httpclient.ErrResponseBodyTooLarge) || strings.Contains(err.Error(), httpclient.ErrResponseBodyTooLarge.Error())
+}
+
+// countSeries counts the value fields of wide and multi frames, and the distinct label values of
+// the value fields of long frames. Other frames are one series.
+func countSeries(frames data.Frames) int64 {
+	var series int64
+	for _, frame := range frames {
+		schema := frame.TimeSeriesSchema()
+		switch schema.Type {
+		case data.TimeSeriesTypeWide:
+			series += int64(len(schema.ValueIndices))
+		case data.TimeSeriesTypeLong:
+			seen := map[string]struct{}{}
+			for row := 0; row < frame.Rows(); row++ {
+				key := make([]string, 0, len(schema.FactorIndices))
+				for _, i := range schema.FactorIndices {
+					if v, ok := frame.Fields[i].ConcreteAt(row); ok {
+						key = append(key, fmt.Sprint(v))
+					} else {
+						key = append(key, "")
+					}
+				}
+				seen[strings.Join(key, "\x00")] = struct{}{}
+			}
+			series += int64(len(seen) * len(schema.ValueIndices))
+		default:
+			if len(frame.Fields) > 0 {
+				series++
+			}
+		}
+	}
+	return series
+}
+
+// countPoints counts the values of the value fields of time series frames, and the values of all
+// fields except time fields of other frames.
+func countPoints(frames data.Frames) int64 {
+	var points int64
+	for _, frame := range frames {
+		if schema := frame.TimeSeriesSchema(); schema.Type != data.TimeSeriesTypeNot {
+			points += int64(frame.Rows() * len(schema.ValueIndices))
+			continue
+		}
+		for _, field := range frame.Fields {
+			if field.Type().Time() {
+				continue
+			}
+			points += int64(field.Len())
+		}
+	}
+	return points
+}
+
+// estimateSize estimates the memory used by the values of frames.
+func estimateSize(frames data.Frames) int64 {
+	var size int64
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			size += fieldSize(field)
+		}
+	}
+	return size
+}
+
+const (
+	pointerSize = int64(unsafe.Sizeof(uintptr(0)))
+	stringSize  = int64(unsafe.Sizeof(""))
+	sliceSize   = int64(unsafe.Sizeof([]byte(nil)))
+	timeSize    = int64(unsafe.Sizeof(time.Time{}))
+)
+
+// fieldSize estimates the memory used by the values of a field. The values of nullable fields are
+// pointers, to values which are only allocated if they are not null.
+func fieldSize(field *data.Field) int64 {
+	length := int64(field.Len())
+	fixedSize := valueSize(field.Type().NonNullableType())
+	if !field.Nullable() && fixedSize > 0 {
+		return length * fixedSize
+	}
+
+	var size int64
+	if field.Nullable() {
+		size = length * pointerSize
+	}
+	for i := 0; i < field.Len(); i++ {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			size += stringSize + int64(len(v))
+		case json.RawMessage:
+			size += sliceSize + int64(len(v))
+		default:
+			size += fixedSize
+		}
+	}
+	return size
+}
+
+// valueSize returns the size of the values of a non-nullable field type, or zero for strings and JSON,
+// whose size depends on the value.
+func valueSize(fieldType data.FieldType) int64 {
+	switch fieldType {
+	case data.FieldTypeString, data.FieldTypeJSON:
+		return 0
+	case data.FieldTypeTime:
+		return timeSize
+	case data.FieldTypeInt8, data.FieldTypeUint8, data.FieldTypeBool:
+		return 1
+	case data.FieldTypeInt16, data.FieldTypeUint16:
+		return 2
+	case data.FieldTypeInt32, data.FieldTypeUint32, data.FieldTypeFloat32:
+		return 4
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,196 @@
+package querylimits
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestForRequest(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryLimits = setting.QueryLimitsSettings{
+		QueryLimits: setting.QueryLimits{MaxSeries: 1000, MaxPoints: 100000},
+		Orgs:        map[int64]setting.QueryLimits{2: {MaxSeries: 10}},
+	}
+
+	t.Run("limits of the organization", func(t *testing.T) {
+		require.Equal(t, setting.QueryLimits{MaxSeries: 1000, MaxPoints: 100000}, ForRequest(cfg, backend.PluginContext{OrgID: 1}))
+		require.Equal(t, setting.QueryLimits{MaxSeries: 10}, ForRequest(cfg, backend.PluginContext{OrgID: 2}))
+	})
+
+	t.Run("data sources can only lower the limits", func(t *testing.T) {
+		limits := ForRequest(cfg, backend.PluginContext{
+			OrgID: 1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				JSONData: []byte(`{"queryLimits": {"maxSeries": 5000, "maxPoints": 500, "maxResponseBytes": 1024}}`),
+			},
+		})
+		require.Equal(t, setting.QueryLimits{MaxSeries: 1000, MaxPoints: 500, MaxResponseBytes: 1024}, limits)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	wide := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{now, now.Add(time.Minute)}),
+		data.NewField("a", nil, []float64{1, 2}),
+		data.NewField("b", nil, []float64{3, 4}),
+	)
+	long := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{now, now, now.Add(time.Minute), now.Add(time.Minute)}),
+		data.NewField("host", nil, []string{"a", "b", "a", "b"}),
+		data.NewField("value", nil, []float64{1, 2, 3, 4}),
+	)
+	table := data.NewFrame("", data.NewField("message", nil, []string{"first line", "second line"}))
+
+	tests := []struct {
+		name   string
+		limits setting.QueryLimits
+		frames data.Frames
+		err    string
+	}{
+		{
+			name:   "series of wide frames",
+			limits: setting.QueryLimits{MaxSeries: 1},
+			frames: data.Frames{wide},
+			err:    "query result of 2 series exceeds the limit of 1 series",
+		},
+		{
+			name:   "series of long frames",
+			limits: setting.QueryLimits{MaxSeries: 3},
+			frames: data.Frames{long, wide},
+			err:    "query result of 4 series exceeds the limit of 3 series",
+		},
+		{
+			name:   "tables are one series",
+			limits: setting.QueryLimits{MaxSeries: 1},
+			frames: data.Frames{table},
+		},
+		{
+			name:   "points",
+			limits: setting.QueryLimits{MaxPoints: 7},
+			frames: data.Frames{wide, long},
+			err:    "query result of 8 points exceeds the limit of 7 points",
+		},
+		{
+			name:   "bytes",
+			limits: setting.QueryLimits{MaxResponseBytes: 50},
+			frames: data.Frames{table},
+			err:    "query result of 53 bytes exceeds the limit of 50 bytes",
+		},
+		{
+			name:   "within the limits",
+			limits: setting.QueryLimits{MaxSeries: 4, MaxPoints: 8, MaxResponseBytes: 300},
+			frames: data.Frames{wide, long},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Check(test.limits, backend.DataResponse{Frames: test.frames})
+			if test.err == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.EqualError(t, err, test.err)
+		})
+	}
+
+	t.Run("responses of data sources larger than the limit", func(t *testing.T) {
+		res := backend.DataResponse{Error: fmt.Errorf("failed to read response: %w", httpclient.ErrResponseBodyTooLarge)}
+		require.EqualError(t, Check(setting.QueryLimits{MaxResponseBytes: 1024}, res), "query result exceeds the limit of 1024 bytes")
+		require.Nil(t, Check(setting.QueryLimits{MaxSeries: 1}, res))
+	})
+
+	t.Run("results which plugins stopped reading at a limit", func(t *testing.T) {
+		res := backend.DataResponse{Error: fmt.Errorf("failed to parse response: %w", &pluginLimitError{limit: LimitPoints, max: 100})}
+		require.Equal(t, &ExceededError{Limit: LimitPoints, Max: 100}, Check(setting.QueryLimits{MaxPoints: 100}, res))
+		require.EqualError(t, Check(setting.QueryLimits{}, res), "query result exceeds the limit of 100 points")
+	})
+}
+
+// pluginLimitError is a limit error of a plugin which doesn't depend on this package.
+type pluginLimitError struct {
+	limit string
+	max   int64
+}
+
+func (e *pluginLimitError) Error() string {
+	return "limit exceeded"
+}
+
+func (e *pluginLimitError) QueryLimit() (string, int64) {
+	return e.limit, e.max
+}
+
+func TestEstimateSize(t *testing.T) {
+	now := time.Now()
+	value := 1.5
+	text := "text"
+
+	tests := []struct {
+		name  string
+		field *data.Field
+		size  int64
+	}{
+		{name: "numbers", field: data.NewField("", nil, []float64{1, 2}), size: 16},
+		{name: "small numbers", field: data.NewField("", nil, []int8{1, 2}), size: 2},
+		{name: "times", field: data.NewField("", nil, []time.Time{now, now}), size: 2 * timeSize},
+		{name: "strings", field: data.NewField("", nil, []string{"a", "bc"}), size: 2*stringSize + 3},
+		{name: "nullable numbers", field: data.NewField("", nil, []*float64{&value, nil}), size: 2*pointerSize + 8},
+		{name: "nullable times", field: data.NewField("", nil, []*time.Time{&now, nil}), size: 2*pointerSize + timeSize},
+		{name: "nullable strings", field: data.NewField("", nil, []*string{&text, nil}), size: 2*pointerSize + stringSize + 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.size, estimateSize(data.Frames{data.NewFrame("", test.field)}))
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	t.Run("limits of the headers", func(t *testing.T) {
+		req := &backend.QueryDataRequest{Headers: map[string]string{HeaderMaxPoints: "1000000"}}
+		SetHeaders(setting.QueryLimits{MaxSeries: 2}, req)
+		require.Equal(t, map[string]string{HeaderMaxSeries: "2"}, req.Headers)
+
+		limiter := NewLimiter(LimitsFromHeaders(req.Headers))
+		require.NoError(t, limiter.Add(2, 100))
+		require.EqualError(t, limiter.Add(1, 0), "query result exceeds the limit of 2 series")
+	})
+
+	t.Run("points", func(t *testing.T) {
+		limiter := NewLimiter(0, 10)
+		require.NoError(t, limiter.Add(1, 10))
+		err := limiter.Add(0, 1)
+		require.Equal(t, &ExceededError{Limit: LimitPoints, Max: 10}, err)
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		limiter := NewLimiter(LimitsFromHeaders(map[string]string{}))
+		require.Nil(t, limiter)
+		require.NoError(t, limiter.Add(1000, 1000000))
+	})
+}
+
+func TestApply(t *testing.T) {
+	resp := backend.NewQueryDataResponse()
+	resp.Responses["A"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1, 2, 3}))}}
+	resp.Responses["B"] = backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))}}
+
+	Apply(setting.QueryLimits{MaxPoints: 2}, resp, "prometheus", SourceDataSource)
+
+	require.EqualError(t, resp.Responses["A"].Error, "query result of 3 points exceeds the limit of 2 points")
+	require.Equal(t, backend.StatusBadRequest, resp.Responses["A"].Status)
+	require.Empty(t, resp.Responses["A"].Frames)
+	require.NoError(t, resp.Responses["B"].Error)
+	require.Len(t, resp.Responses["B"].Frames, 1)
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -13,6 +15,7 @@ import (
 var ErrTimeout = errors.New("timeout error - you can set timeout in seconds with &timeout url parameter")
 var ErrConcurrentLimitReached = errors.New("rendering concurrent limit reached")
 var ErrRenderUnavailable = errors.New("rendering plugin not available")
+var ErrNativeRenderUnsupported = errors.New("not supported by the native renderer")
 var ErrServerTimeout = errutil.NewBase(errutil.StatusUnknown, "rendering.serverTimeout", errutil.WithPublicMessage("error trying to connect to image-renderer service"))
 
 type RenderType string
@@ -21,6 +24,7 @@ const (
 	RenderCSV RenderType = "csv"
 	RenderPNG RenderType = "png"
 	RenderPDF RenderType = "pdf"
+	RenderSVG RenderType = "svg"
 )
 
 type TimeoutOpts struct {
@@ -94,6 +98,35 @@ type RenderCSVResult struct {
 	FileName string
 }
 
+// PanelDataQuery identifies the panel drawn by the native renderer, and the time range and variables of its queries.
+type PanelDataQuery struct {
+	DashboardUID string
+	PanelID      int64
+	From         string
+	To           string
+	Variables    map[string][]string
+}
+
+// PanelData is the data the native renderer draws a panel from.
+type PanelData struct {
+	// Type is the plugin ID of the panel, e.g. timeseries.
+	Type  string
+	Title string
+	// Options are the options of the panel, and FieldConfig the defaults of its field config.
+	Options     map[string]any
+	FieldConfig map[string]any
+	From        time.Time
+	To          time.Time
+	Frames      data.Frames
+	// Errors are the errors of the queries of the panel that failed.
+	Errors []error
+}
+
+// PanelDataProvider executes the queries of a panel for the native renderer, with the permissions of the rendering user.
+type PanelDataProvider interface {
+	GetPanelData(ctx context.Context, authOpts AuthOpts, query PanelDataQuery) (*PanelData, error)
+}
+
 type renderFunc func(ctx context.Context, renderType RenderType, renderKey string, options Opts) (*RenderResult, error)
 type renderCSVFunc func(ctx context.Context, renderKey string, options CSVOpts) (*RenderCSVResult, error)
 type sanitizeFunc func(ctx context.Context, req *SanitizeSVGRequest) (*SanitizeSVGResponse, error)
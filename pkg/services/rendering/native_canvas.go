@@ -0,0 +1,226 @@
+package rendering
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+type textAlign int
+
+const (
+	alignLeft textAlign = iota
+	alignCenter
+	alignRight
+)
+
+type point struct {
+	x, y float64
+}
+
+// canvas is the drawing surface of the native renderer. Coordinates are in pixels from the top left corner,
+// and angles in radians, clockwise from the positive x axis.
+type canvas interface {
+	fillRect(x, y, w, h float64, c color.RGBA)
+	polyline(points []point, width float64, c color.RGBA)
+	// arc draws a ring segment of the thickness inside the circle of radius r.
+	arc(cx, cy, r, thickness, start, end float64, c color.RGBA)
+	// text draws the text with its top at y, and its horizontal position at x depending on the alignment.
+	// The size is the height of the glyphs in pixels.
+	text(x, y float64, s string, size float64, c color.RGBA, align textAlign)
+	encode(w io.Writer) error
+}
+
+// rasterCanvas draws into an image, encoded as PNG. Drawing is scaled by the device scale factor.
+type rasterCanvas struct {
+	img   *image.RGBA
+	scale float64
+}
+
+func newRasterCanvas(width, height int, scale float64) *rasterCanvas {
+	return &rasterCanvas{
+		img:   image.NewRGBA(image.Rect(0, 0, int(math.Ceil(float64(width)*scale)), int(math.Ceil(float64(height)*scale)))),
+		scale: scale,
+	}
+}
+
+func (c *rasterCanvas) fillRect(x, y, w, h float64, col color.RGBA) {
+	x0, y0 := int(math.Round(x*c.scale)), int(math.Round(y*c.scale))
+	x1, y1 := int(math.Round((x+w)*c.scale)), int(math.Round((y+h)*c.scale))
+	r := image.Rect(x0, y0, x1, y1).Intersect(c.img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			c.img.SetRGBA(px, py, col)
+		}
+	}
+}
+
+func (c *rasterCanvas) polyline(points []point, width float64, col color.RGBA) {
+	radius := math.Max(width*c.scale/2, 0.5)
+	for i := 1; i < len(points); i++ {
+		c.line(points[i-1], points[i], radius, col)
+	}
+	if len(points) == 1 {
+		c.disc(points[0].x*c.scale, points[0].y*c.scale, radius, col)
+	}
+}
+
+// line draws a segment by stamping discs along it.
+func (c *rasterCanvas) line(a, b point, radius float64, col color.RGBA) {
+	ax, ay, bx, by := a.x*c.scale, a.y*c.scale, b.x*c.scale, b.y*c.scale
+	steps := int(math.Ceil(math.Hypot(bx-ax, by-ay) * 2))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		c.disc(ax+(bx-ax)*t, ay+(by-ay)*t, radius, col)
+	}
+}
+
+func (c *rasterCanvas) disc(cx, cy, radius float64, col color.RGBA) {
+	r := image.Rect(int(math.Floor(cx-radius)), int(math.Floor(cy-radius)), int(math.Ceil(cx+radius))+1, int(math.Ceil(cy+radius))+1).Intersect(c.img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			if math.Hypot(float64(px)+0.5-cx, float64(py)+0.5-cy) <= radius {
+				c.img.SetRGBA(px, py, col)
+			}
+		}
+	}
+}
+
+func (c *rasterCanvas) arc(cx, cy, r, thickness, start, end float64, col color.RGBA) {
+	cx, cy, r, thickness = cx*c.scale, cy*c.scale, r*c.scale, thickness*c.scale
+	bounds := image.Rect(int(cx-r)-1, int(cy-r)-1, int(cx+r)+2, int(cy+r)+2).Intersect(c.img.Bounds())
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy
+			d := math.Hypot(dx, dy)
+			if d > r || d < r-thickness {
+				continue
+			}
+			if angleBetween(math.Atan2(dy, dx), start, end) {
+				c.img.SetRGBA(px, py, col)
+			}
+		}
+	}
+}
+
+// angleBetween returns whether the angle is on the clockwise arc from start to end.
+func angleBetween(angle, start, end float64) bool {
+	sweep := end - start
+	if sweep >= 2*math.Pi {
+		return true
+	}
+	d := math.Mod(angle-start, 2*math.Pi)
+	if d < 0 {
+		d += 2 * math.Pi
+	}
+	return d <= sweep
+}
+
+func (c *rasterCanvas) text(x, y float64, s string, size float64, col color.RGBA, align textAlign) {
+	scale := glyphScale(size * c.scale)
+	pixel := float64(scale) / c.scale
+	switch align {
+	case alignCenter:
+		x -= float64(textWidth(s, scale)) / c.scale / 2
+	case alignRight:
+		x -= float64(textWidth(s, scale)) / c.scale
+	}
+	for _, r := range s {
+		g := glyphFor(r)
+		for row := 0; row < glyphHeight; row++ {
+			for column := 0; column < glyphWidth; column++ {
+				if g[row]&(1<<(glyphWidth-1-column)) != 0 {
+					c.fillRect(x+float64(column)*pixel, y+float64(row)*pixel, pixel, pixel, col)
+				}
+			}
+		}
+		x += float64(glyphWidth+glyphSpacing) * pixel
+	}
+}
+
+func (c *rasterCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+// glyphScale returns the integer scale of the bitmap font for text of the size.
+func glyphScale(size float64) int {
+	return int(math.Max(1, math.Round(size/glyphHeight)))
+}
+
+// measureText returns the width of the text of the size, as drawn by the raster canvas.
+func measureText(s string, size float64) float64 {
+	return float64(textWidth(s, glyphScale(size)))
+}
+
+// svgCanvas draws SVG elements.
+type svgCanvas struct {
+	width, height int
+	sb            strings.Builder
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (c *svgCanvas) fillRect(x, y, w, h float64, col color.RGBA) {
+	fmt.Fprintf(&c.sb, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", svgNum(x), svgNum(y), svgNum(w), svgNum(h), svgColor(col))
+}
+
+func (c *svgCanvas) polyline(points []point, width float64, col color.RGBA) {
+	if len(points) == 0 {
+		return
+	}
+	coords := make([]string, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, svgNum(p.x)+","+svgNum(p.y))
+	}
+	fmt.Fprintf(&c.sb, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round" stroke-linecap="round"/>`+"\n", strings.Join(coords, " "), svgColor(col), svgNum(width))
+}
+
+func (c *svgCanvas) arc(cx, cy, r, thickness, start, end float64, col color.RGBA) {
+	if end-start >= 2*math.Pi {
+		end = start + 2*math.Pi - 0.0001
+	}
+	mid := r - thickness/2
+	large := 0
+	if end-start > math.Pi {
+		large = 1
+	}
+	x0, y0 := cx+mid*math.Cos(start), cy+mid*math.Sin(start)
+	x1, y1 := cx+mid*math.Cos(end), cy+mid*math.Sin(end)
+	fmt.Fprintf(&c.sb, `<path d="M %s %s A %s %s 0 %d 1 %s %s" fill="none" stroke="%s" stroke-width="%s"/>`+"\n",
+		svgNum(x0), svgNum(y0), svgNum(mid), svgNum(mid), large, svgNum(x1), svgNum(y1), svgColor(col), svgNum(thickness))
+}
+
+func (c *svgCanvas) text(x, y float64, s string, size float64, col color.RGBA, align textAlign) {
+	anchor := "start"
+	switch align {
+	case alignCenter:
+		anchor = "middle"
+	case alignRight:
+		anchor = "end"
+	}
+	fmt.Fprintf(&c.sb, `<text x="%s" y="%s" font-family="Inter, Helvetica, Arial, sans-serif" font-size="%s" dominant-baseline="hanging" text-anchor="%s" fill="%s">%s</text>`+"\n",
+		svgNum(x), svgNum(y), svgNum(size*1.3), anchor, svgColor(col), html.EscapeString(s))
+}
+
+func (c *svgCanvas) encode(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n%s</svg>\n", c.width, c.height, c.width, c.height, c.sb.String())
+	return err
+}
+
+func svgNum(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,162 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The native renderer is the fallback used when neither the image renderer plugin nor a remote renderer is
+// available, e.g. in deployments that cannot run Chromium. It does not replace them: it draws only the timeseries,
+// stat, gauge, bar gauge and bar chart panels, with the common options, from the results of the panel queries,
+// and everything else is left to the image renderer.
+
+// renderNatively draws the panel of the path from its query results, without a browser.
+// Only dashboard and solo panel paths with a panel ID are supported.
+func (rs *RenderingService) renderNatively(ctx context.Context, renderType RenderType, _ string, opts Opts) (*RenderResult, error) {
+	if renderType == RenderPDF || opts.Encoding == "pdf" {
+		return nil, fmt.Errorf("pdf rendering is %w", ErrNativeRenderUnsupported)
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, getRequestTimeout(opts.TimeoutOpts))
+		defer cancel()
+	}
+
+	query, tz, err := parseNativeRenderPath(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if tz == "" {
+		tz = opts.Timezone
+	}
+	location, err := nativeLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+
+	panelData, err := rs.panelData.GetPanelData(ctx, opts.AuthOpts, query)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		rs.log.Info("Rendering timed out")
+		return nil, ErrTimeout
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = rs.Cfg.RendererDefaultImageWidth
+	}
+	if height <= 0 {
+		height = rs.Cfg.RendererDefaultImageHeight
+	}
+
+	var c canvas
+	if renderType == RenderSVG || opts.Encoding == "svg" {
+		renderType = RenderSVG
+		c = newSVGCanvas(width, height)
+	} else {
+		c = newRasterCanvas(width, height, opts.DeviceScaleFactor)
+	}
+
+	panel := &nativePanel{
+		data:     panelData,
+		theme:    themeFor(opts.Theme),
+		cfg:      parseFieldConfig(panelData.FieldConfig, themeFor(opts.Theme)),
+		location: location,
+		width:    float64(width),
+		height:   float64(height),
+	}
+	if err := panel.draw(c); err != nil {
+		return nil, err
+	}
+
+	filePath, err := rs.getNewFilePath(renderType)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- the file path is generated by getNewFilePath
+	f, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			rs.log.Warn("Failed to close file", "path", filePath, "err", err)
+		}
+	}()
+	if err := c.encode(f); err != nil {
+		return nil, err
+	}
+
+	return &RenderResult{FilePath: filePath}, nil
+}
+
+// parseNativeRenderPath returns the panel query and the timezone of a d-solo/{uid}/{slug} or d/{uid}/{slug} path.
+func parseNativeRenderPath(path string) (PanelDataQuery, string, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return PanelDataQuery{}, "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) > 0 && segments[0] == "render" {
+		segments = segments[1:]
+	}
+	if len(segments) < 2 || (segments[0] != "d-solo" && segments[0] != "d") || segments[1] == "" {
+		return PanelDataQuery{}, "", fmt.Errorf("path %q is %w", u.Path, ErrNativeRenderUnsupported)
+	}
+
+	params := u.Query()
+	panelID := params.Get("panelId")
+	if panelID == "" {
+		panelID = params.Get("viewPanel")
+	}
+	id, err := strconv.ParseInt(panelID, 10, 64)
+	if err != nil {
+		return PanelDataQuery{}, "", fmt.Errorf("path without a panel ID is %w", ErrNativeRenderUnsupported)
+	}
+
+	query := PanelDataQuery{
+		DashboardUID: segments[1],
+		PanelID:      id,
+		From:         params.Get("from"),
+		To:           params.Get("to"),
+		Variables:    map[string][]string{},
+	}
+	for key, values := range params {
+		if name, ok := strings.CutPrefix(key, "var-"); ok {
+			query.Variables[name] = values
+		}
+	}
+
+	return query, params.Get("tz"), nil
+}
+
+// nativeLocation returns the location of a timezone name or a UTC offset like UTC+02:00.
+func nativeLocation(tz string) (*time.Location, error) {
+	switch tz {
+	case "", "browser", "utc", "UTC":
+		return time.UTC, nil
+	}
+	if offset, ok := strings.CutPrefix(tz, "UTC"); ok {
+		t, err := time.Parse("-07:00", offset)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q", tz)
+		}
+		_, seconds := t.Zone()
+		return time.FixedZone(tz, seconds), nil
+	}
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return location, nil
+}
@@ -0,0 +1,262 @@
+package rendering
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakePanelDataProvider struct {
+	data    *PanelData
+	err     error
+	queries []PanelDataQuery
+}
+
+func (f *fakePanelDataProvider) GetPanelData(_ context.Context, _ AuthOpts, query PanelDataQuery) (*PanelData, error) {
+	f.queries = append(f.queries, query)
+	return f.data, f.err
+}
+
+type fakeRenderKeyProvider struct{}
+
+func (fakeRenderKeyProvider) get(context.Context, AuthOpts) (string, error) { return "", nil }
+
+func (fakeRenderKeyProvider) afterRequest(context.Context, AuthOpts, string) {}
+
+func (fakeRenderKeyProvider) Dispose(context.Context) {}
+
+func newNativeRenderingService(t *testing.T, provider PanelDataProvider) *RenderingService {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.ImagesDir = t.TempDir()
+	cfg.RendererDefaultImageWidth = 1000
+	cfg.RendererDefaultImageHeight = 500
+	cfg.RendererNativeEnabled = true
+	rs := &RenderingService{
+		Cfg:                   cfg,
+		log:                   log.New("test"),
+		RendererPluginManager: &dummyPluginManager{},
+		nativeAvailable:       true,
+		panelData:             provider,
+	}
+	rs.renderAction = rs.renderNatively
+	return rs
+}
+
+func TestParseNativeRenderPath(t *testing.T) {
+	t.Run("solo panel path", func(t *testing.T) {
+		query, tz, err := parseNativeRenderPath("d-solo/abc/my-dash?orgId=1&panelId=5&from=now-1h&to=now&var-job=api&var-job=web&tz=Europe%2FStockholm")
+		require.NoError(t, err)
+		assert.Equal(t, PanelDataQuery{
+			DashboardUID: "abc",
+			PanelID:      5,
+			From:         "now-1h",
+			To:           "now",
+			Variables:    map[string][]string{"job": {"api", "web"}},
+		}, query)
+		assert.Equal(t, "Europe/Stockholm", tz)
+	})
+
+	t.Run("render path with view panel", func(t *testing.T) {
+		query, _, err := parseNativeRenderPath("render/d/abc/my-dash?viewPanel=3")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", query.DashboardUID)
+		assert.EqualValues(t, 3, query.PanelID)
+	})
+
+	t.Run("dashboard path without panel is not supported", func(t *testing.T) {
+		_, _, err := parseNativeRenderPath("d/abc/my-dash?orgId=1")
+		require.ErrorIs(t, err, ErrNativeRenderUnsupported)
+	})
+
+	t.Run("other paths are not supported", func(t *testing.T) {
+		_, _, err := parseNativeRenderPath("explore?panelId=1")
+		require.ErrorIs(t, err, ErrNativeRenderUnsupported)
+	})
+}
+
+func TestNativeLocation(t *testing.T) {
+	loc, err := nativeLocation("UTC+02:00")
+	require.NoError(t, err)
+	_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone()
+	assert.Equal(t, 2*60*60, offset)
+
+	loc, err = nativeLocation("")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	_, err = nativeLocation("Nowhere/Nothing")
+	assert.Error(t, err)
+}
+
+func TestRenderNatively(t *testing.T) {
+	to := time.Date(2024, 1, 23, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-time.Hour)
+	times := make([]time.Time, 0, 60)
+	values := make([]*float64, 0, 60)
+	for i := 0; i < 60; i++ {
+		times = append(times, from.Add(time.Duration(i)*time.Minute))
+		v := math.Sin(float64(i) / 10)
+		values = append(values, &v)
+	}
+	values[30] = nil
+	frames := data.Frames{data.NewFrame("cpu",
+		data.NewField("time", nil, times),
+		data.NewField("value", data.Labels{"host": "a"}, values),
+	)}
+
+	t.Run("draws a time series panel as PNG", func(t *testing.T) {
+		provider := &fakePanelDataProvider{data: &PanelData{Type: "timeseries", Title: "CPU", From: from, To: to, Frames: frames}}
+		rs := newNativeRenderingService(t, provider)
+		opts := Opts{Width: 400, Height: 200, DeviceScaleFactor: 2, Path: "d-solo/abc/slug?orgId=1&panelId=2&from=1&to=2", ConcurrentLimit: 1}
+
+		result, err := rs.Render(context.Background(), RenderPNG, opts, fakeRenderKeyProvider{})
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(result.FilePath, ".png"))
+		require.Len(t, provider.queries, 1)
+		assert.EqualValues(t, 2, provider.queries[0].PanelID)
+
+		f, err := os.Open(result.FilePath)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		img, err := png.Decode(f)
+		require.NoError(t, err)
+		assert.Equal(t, 800, img.Bounds().Dx())
+		assert.Equal(t, 400, img.Bounds().Dy())
+
+		// The first series is drawn in the first color of the palette.
+		found := false
+		for y := 0; y < img.Bounds().Dy() && !found; y++ {
+			for x := 0; x < img.Bounds().Dx() && !found; x++ {
+				r, g, b, _ := img.At(x, y).RGBA()
+				found = uint8(r>>8) == nativePalette[0].R && uint8(g>>8) == nativePalette[0].G && uint8(b>>8) == nativePalette[0].B
+			}
+		}
+		assert.True(t, found, "series line should be drawn")
+	})
+
+	t.Run("draws a stat panel as SVG", func(t *testing.T) {
+		provider := &fakePanelDataProvider{data: &PanelData{
+			Type:        "stat",
+			Title:       "Usage",
+			FieldConfig: map[string]any{"unit": "percent", "decimals": float64(1)},
+			From:        from,
+			To:          to,
+			Frames:      frames,
+		}}
+		rs := newNativeRenderingService(t, provider)
+		opts := Opts{Path: "d-solo/abc/slug?panelId=2", Theme: models.ThemeLight, ConcurrentLimit: 1}
+
+		result, err := rs.Render(context.Background(), RenderSVG, opts, fakeRenderKeyProvider{})
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(result.FilePath, ".svg"))
+		content, err := os.ReadFile(result.FilePath)
+		require.NoError(t, err)
+		svg := string(content)
+		assert.Contains(t, svg, `width="1000" height="500"`)
+		assert.Contains(t, svg, ">Usage</text>")
+		assert.Contains(t, svg, ">-0.4%</text>", "last non null value should be shown")
+		assert.Contains(t, svg, `fill="#ffffff"`, "light theme background")
+	})
+
+	t.Run("unsupported panel types return an error", func(t *testing.T) {
+		provider := &fakePanelDataProvider{data: &PanelData{Type: "geomap", From: from, To: to, Frames: frames}}
+		rs := newNativeRenderingService(t, provider)
+
+		_, err := rs.Render(context.Background(), RenderPNG, Opts{Path: "d-solo/abc/slug?panelId=2", ConcurrentLimit: 1}, fakeRenderKeyProvider{})
+		require.ErrorIs(t, err, ErrNativeRenderUnsupported)
+	})
+
+	t.Run("errors of the provider are returned", func(t *testing.T) {
+		provider := &fakePanelDataProvider{err: errors.New("dashboard not found")}
+		rs := newNativeRenderingService(t, provider)
+
+		_, err := rs.Render(context.Background(), RenderPNG, Opts{Path: "d-solo/abc/slug?panelId=2", ConcurrentLimit: 1}, fakeRenderKeyProvider{})
+		require.EqualError(t, err, "dashboard not found")
+	})
+}
+
+func TestNativeRendererAvailability(t *testing.T) {
+	t.Run("is available when enabled with a panel data provider", func(t *testing.T) {
+		rs := newNativeRenderingService(t, &fakePanelDataProvider{})
+		assert.True(t, rs.IsAvailable(context.Background()))
+	})
+
+	t.Run("is used by Run when no other renderer is available", func(t *testing.T) {
+		rs := newNativeRenderingService(t, &fakePanelDataProvider{})
+		rs.renderAction = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.NoError(t, rs.Run(ctx))
+		}()
+		cancel()
+		<-done
+
+		require.NotNil(t, rs.renderCSVAction)
+		_, err := rs.renderCSVAction(context.Background(), "", CSVOpts{})
+		assert.ErrorIs(t, err, ErrNativeRenderUnsupported)
+	})
+}
+
+func TestFormatValue(t *testing.T) {
+	one := 1
+	testCases := []struct {
+		cfg      nativeFieldConfig
+		value    float64
+		expected string
+	}{
+		{cfg: nativeFieldConfig{}, value: 42, expected: "42"},
+		{cfg: nativeFieldConfig{}, value: 1500, expected: "1.5 K"},
+		{cfg: nativeFieldConfig{}, value: 0.0123, expected: "0.012"},
+		{cfg: nativeFieldConfig{unit: "percent", decimals: &one}, value: 12.345, expected: "12.3%"},
+		{cfg: nativeFieldConfig{unit: "percentunit"}, value: 0.5, expected: "50%"},
+		{cfg: nativeFieldConfig{unit: "bytes"}, value: 2048, expected: "2KiB"},
+		{cfg: nativeFieldConfig{unit: "ms"}, value: 1500, expected: "1.5 s"},
+		{cfg: nativeFieldConfig{unit: "req/s"}, value: 3, expected: "3 req/s"},
+		{cfg: nativeFieldConfig{}, value: math.NaN(), expected: "No data"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.cfg.formatValue(tc.value))
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	c, ok := parseColor("dark-red")
+	require.True(t, ok)
+	assert.Equal(t, nativeNamedColors["red"], c)
+
+	c, ok = parseColor("#f0a")
+	require.True(t, ok)
+	assert.Equal(t, rgb(0xff, 0x00, 0xaa), c)
+
+	c, ok = parseColor("rgba(1, 2, 3, 0.5)")
+	require.True(t, ok)
+	assert.Equal(t, rgb(1, 2, 3), c)
+
+	_, ok = parseColor("nope")
+	assert.False(t, ok)
+}
+
+func TestSVGCanvasEscapesText(t *testing.T) {
+	c := newSVGCanvas(10, 10)
+	c.text(0, 0, "<script>", 10, rgb(0, 0, 0), alignLeft)
+	var buf bytes.Buffer
+	require.NoError(t, c.encode(&buf))
+	assert.NotContains(t, buf.String(), "<script>")
+}
@@ -0,0 +1,862 @@
+package rendering
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	nativePadding   = 8.0
+	nativeTitleSize = 14.0
+	nativeLabelSize = 11.0
+)
+
+type nativeTheme struct {
+	background color.RGBA
+	text       color.RGBA
+	grid       color.RGBA
+}
+
+var (
+	nativeDarkTheme  = nativeTheme{background: rgb(0x18, 0x1b, 0x1f), text: rgb(0xcc, 0xcc, 0xdc), grid: rgb(0x2c, 0x30, 0x35)}
+	nativeLightTheme = nativeTheme{background: rgb(0xff, 0xff, 0xff), text: rgb(0x24, 0x29, 0x2e), grid: rgb(0xe4, 0xe6, 0xea)}
+
+	// nativePalette is the classic palette of series colors of the frontend.
+	nativePalette = []color.RGBA{
+		rgb(0x73, 0xbf, 0x69), rgb(0xf2, 0xcc, 0x0c), rgb(0x8a, 0xb8, 0xff), rgb(0xff, 0x78, 0x0a),
+		rgb(0xf2, 0x49, 0x5c), rgb(0x57, 0x94, 0xf2), rgb(0xb8, 0x77, 0xd9), rgb(0x70, 0x5d, 0xa0),
+		rgb(0x37, 0x87, 0x2d), rgb(0xfa, 0xde, 0x2a), rgb(0x44, 0x7e, 0xbc), rgb(0xc1, 0x5c, 0x17),
+	}
+
+	// nativeNamedColors are the base named colors of the frontend. Their shades are mapped to the base color.
+	nativeNamedColors = map[string]color.RGBA{
+		"green":  rgb(0x73, 0xbf, 0x69),
+		"red":    rgb(0xf2, 0x49, 0x5c),
+		"yellow": rgb(0xfa, 0xde, 0x2a),
+		"orange": rgb(0xff, 0x98, 0x30),
+		"blue":   rgb(0x57, 0x94, 0xf2),
+		"purple": rgb(0xb8, 0x77, 0xd9),
+		"white":  rgb(0xff, 0xff, 0xff),
+		"black":  rgb(0x00, 0x00, 0x00),
+		"gray":   rgb(0x80, 0x80, 0x80),
+		"grey":   rgb(0x80, 0x80, 0x80),
+	}
+)
+
+func rgb(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}
+}
+
+func themeFor(theme models.Theme) nativeTheme {
+	if theme == models.ThemeLight {
+		return nativeLightTheme
+	}
+	return nativeDarkTheme
+}
+
+// parseColor parses the named, hex and rgb colors of the frontend.
+func parseColor(s string) (color.RGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, prefix := range []string{"super-light-", "light-", "semi-dark-", "dark-"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	if c, ok := nativeNamedColors[s]; ok {
+		return c, true
+	}
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 8 {
+			hex = hex[:6]
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return color.RGBA{}, false
+		}
+		return rgb(uint8(v>>16), uint8(v>>8), uint8(v)), true
+	}
+	if strings.HasPrefix(s, "rgb") {
+		start, end := strings.Index(s, "("), strings.Index(s, ")")
+		if start < 0 || end < start {
+			return color.RGBA{}, false
+		}
+		parts := strings.Split(s[start+1:end], ",")
+		if len(parts) < 3 {
+			return color.RGBA{}, false
+		}
+		var c [3]uint8
+		for i := range c {
+			v, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+			if err != nil {
+				return color.RGBA{}, false
+			}
+			c[i] = uint8(v)
+		}
+		return rgb(c[0], c[1], c[2]), true
+	}
+	return color.RGBA{}, false
+}
+
+// nativeSeries is a numeric field of a frame, with the time field of the frame if it has one.
+type nativeSeries struct {
+	name   string
+	times  []time.Time
+	values []float64 // NaN for null values
+	color  color.RGBA
+}
+
+// seriesFromFrames returns the numeric fields of the frames as series.
+func seriesFromFrames(frames data.Frames) []nativeSeries {
+	var result []nativeSeries
+	for _, frame := range frames {
+		var timeField *data.Field
+		for _, f := range frame.Fields {
+			if f.Type().Time() {
+				timeField = f
+				break
+			}
+		}
+		numeric := 0
+		for _, f := range frame.Fields {
+			if f.Type().Numeric() {
+				numeric++
+			}
+		}
+		for _, f := range frame.Fields {
+			if !f.Type().Numeric() {
+				continue
+			}
+			s := nativeSeries{name: seriesName(frame, f, numeric), values: make([]float64, f.Len())}
+			for i := range s.values {
+				v, err := f.NullableFloatAt(i)
+				if err != nil || v == nil {
+					s.values[i] = math.NaN()
+					continue
+				}
+				s.values[i] = *v
+			}
+			if timeField != nil {
+				s.times = make([]time.Time, timeField.Len())
+				for i := range s.times {
+					if t, ok := timeField.ConcreteAt(i); ok {
+						s.times[i], _ = t.(time.Time)
+					}
+				}
+			}
+			if f.Config != nil && f.Config.Color != nil {
+				if c, ok := parseColor(fmt.Sprint(f.Config.Color["fixedColor"])); ok {
+					s.color = c
+				}
+			}
+			result = append(result, s)
+		}
+	}
+	for i := range result {
+		if result[i].color.A == 0 {
+			result[i].color = nativePalette[i%len(nativePalette)]
+		}
+	}
+	return result
+}
+
+// seriesName returns the display name of the field, like the frontend does for the common cases.
+func seriesName(frame *data.Frame, f *data.Field, numericFields int) string {
+	if f.Config != nil {
+		if f.Config.DisplayNameFromDS != "" {
+			return f.Config.DisplayNameFromDS
+		}
+		if f.Config.DisplayName != "" {
+			return f.Config.DisplayName
+		}
+	}
+	if len(f.Labels) > 0 {
+		keys := make([]string, 0, len(f.Labels))
+		for k := range f.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", k, f.Labels[k]))
+		}
+		name := f.Name
+		if name == "Value" || name == "value" {
+			name = ""
+		}
+		return name + "{" + strings.Join(pairs, ", ") + "}"
+	}
+	if numericFields == 1 && frame.Name != "" {
+		return frame.Name
+	}
+	return f.Name
+}
+
+// reduce calculates the value of the series shown by the stat, gauge and bar panels.
+func reduce(values []float64, calc string) float64 {
+	result := math.NaN()
+	count := 0
+	for i, v := range values {
+		if math.IsNaN(v) {
+			if calc == "last" && i == len(values)-1 {
+				return v
+			}
+			continue
+		}
+		count++
+		switch calc {
+		case "first", "firstNotNull":
+			if count == 1 {
+				return v
+			}
+		case "min":
+			if count == 1 || v < result {
+				result = v
+			}
+		case "max":
+			if count == 1 || v > result {
+				result = v
+			}
+		case "sum", "mean":
+			if count == 1 {
+				result = 0
+			}
+			result += v
+		case "count":
+			result = float64(count)
+		default: // lastNotNull, last
+			result = v
+		}
+	}
+	if calc == "mean" && count > 0 {
+		result /= float64(count)
+	}
+	return result
+}
+
+type nativeFieldConfig struct {
+	unit       string
+	decimals   *int
+	min, max   *float64
+	thresholds []nativeThreshold
+}
+
+type nativeThreshold struct {
+	value float64 // -Inf for the base threshold
+	color color.RGBA
+}
+
+func parseFieldConfig(defaults map[string]any, theme nativeTheme) nativeFieldConfig {
+	cfg := nativeFieldConfig{}
+	cfg.unit, _ = defaults["unit"].(string)
+	if v, ok := defaults["decimals"].(float64); ok {
+		d := int(v)
+		cfg.decimals = &d
+	}
+	if v, ok := defaults["min"].(float64); ok {
+		cfg.min = &v
+	}
+	if v, ok := defaults["max"].(float64); ok {
+		cfg.max = &v
+	}
+	thresholds, _ := defaults["thresholds"].(map[string]any)
+	steps, _ := thresholds["steps"].([]any)
+	for _, s := range steps {
+		step, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		t := nativeThreshold{value: math.Inf(-1), color: nativeNamedColors["green"]}
+		if v, ok := step["value"].(float64); ok {
+			t.value = v
+		}
+		if name, ok := step["color"].(string); ok {
+			if name == "text" {
+				t.color = theme.text
+			} else if c, ok := parseColor(name); ok {
+				t.color = c
+			}
+		}
+		cfg.thresholds = append(cfg.thresholds, t)
+	}
+	sort.SliceStable(cfg.thresholds, func(i, j int) bool { return cfg.thresholds[i].value < cfg.thresholds[j].value })
+	return cfg
+}
+
+// thresholdColor returns the color of the highest threshold the value reaches, or the fallback without thresholds.
+func (cfg nativeFieldConfig) thresholdColor(v float64, fallback color.RGBA) color.RGBA {
+	result := fallback
+	for _, t := range cfg.thresholds {
+		if v >= t.value {
+			result = t.color
+		}
+	}
+	return result
+}
+
+// formatValue formats the value with the unit of the field config, like the common units of the frontend.
+func (cfg nativeFieldConfig) formatValue(v float64) string {
+	if math.IsNaN(v) {
+		return "No data"
+	}
+	switch cfg.unit {
+	case "percent":
+		return cfg.formatNumber(v) + "%"
+	case "percentunit":
+		return cfg.formatNumber(v*100) + "%"
+	case "bytes", "decbytes":
+		base, units := 1024.0, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+		if cfg.unit == "decbytes" {
+			base, units = 1000, []string{"B", "kB", "MB", "GB", "TB", "PB"}
+		}
+		return cfg.scaled(v, base, units)
+	case "s", "ms":
+		d := time.Duration(v * float64(time.Second))
+		if cfg.unit == "ms" {
+			d = time.Duration(v * float64(time.Millisecond))
+		}
+		return cfg.formatDuration(d)
+	case "", "none", "short":
+		return cfg.scaled(v, 1000, []string{"", " K", " Mil", " Bil", " Tri"})
+	default:
+		if suffix, ok := strings.CutPrefix(cfg.unit, "suffix:"); ok {
+			return cfg.formatNumber(v) + suffix
+		}
+		if prefix, ok := strings.CutPrefix(cfg.unit, "prefix:"); ok {
+			return prefix + cfg.formatNumber(v)
+		}
+		return cfg.formatNumber(v) + " " + cfg.unit
+	}
+}
+
+func (cfg nativeFieldConfig) scaled(v, base float64, units []string) string {
+	i := 0
+	for math.Abs(v) >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	return cfg.formatNumber(v) + units[i]
+}
+
+func (cfg nativeFieldConfig) formatDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < time.Millisecond:
+		return cfg.formatNumber(float64(d)/float64(time.Microsecond)) + " µs"
+	case abs < time.Second:
+		return cfg.formatNumber(float64(d)/float64(time.Millisecond)) + " ms"
+	case abs < time.Minute:
+		return cfg.formatNumber(d.Seconds()) + " s"
+	case abs < time.Hour:
+		return cfg.formatNumber(d.Minutes()) + " min"
+	case abs < 24*time.Hour:
+		return cfg.formatNumber(d.Hours()) + " hour"
+	default:
+		return cfg.formatNumber(d.Hours()/24) + " day"
+	}
+}
+
+func (cfg nativeFieldConfig) formatNumber(v float64) string {
+	decimals := 0
+	if cfg.decimals != nil {
+		decimals = *cfg.decimals
+	} else if abs := math.Abs(v); abs > 0 && abs < 1 {
+		decimals = int(math.Min(6, math.Ceil(-math.Log10(abs))+1))
+	} else if abs < 100 && v != math.Trunc(v) {
+		decimals = 2
+	} else if abs < 1000 && v != math.Trunc(v) {
+		decimals = 1
+	}
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if cfg.decimals == nil && strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// nativePanel holds what is needed to draw a panel.
+type nativePanel struct {
+	data     *PanelData
+	theme    nativeTheme
+	cfg      nativeFieldConfig
+	location *time.Location
+	width    float64
+	height   float64
+}
+
+// draw draws the panel on the canvas.
+func (p *nativePanel) draw(c canvas) error {
+	c.fillRect(0, 0, p.width, p.height, p.theme.background)
+	top := nativePadding
+	if p.data.Title != "" {
+		c.text(nativePadding, top, p.data.Title, nativeTitleSize, p.theme.text, alignLeft)
+		top += nativeTitleSize + nativePadding
+	}
+	x, y, w, h := nativePadding, top, p.width-2*nativePadding, p.height-top-nativePadding
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	series := seriesFromFrames(p.data.Frames)
+	if len(series) == 0 {
+		message := "No data"
+		if len(p.data.Errors) > 0 {
+			message = p.data.Errors[0].Error()
+		}
+		c.text(x+w/2, y+h/2-nativeLabelSize, message, nativeLabelSize, p.theme.text, alignCenter)
+		return nil
+	}
+
+	switch p.data.Type {
+	case "timeseries", "graph", "trend":
+		p.drawTimeSeries(c, series, x, y, w, h)
+	case "stat", "singlestat":
+		p.drawStat(c, series, x, y, w, h)
+	case "gauge":
+		p.drawGauge(c, series, x, y, w, h)
+	case "bargauge":
+		p.drawBarGauge(c, series, x, y, w, h)
+	case "barchart":
+		p.drawBarChart(c, series, x, y, w, h)
+	default:
+		return fmt.Errorf("panel type %q is %w", p.data.Type, ErrNativeRenderUnsupported)
+	}
+	return nil
+}
+
+func (p *nativePanel) option(path ...string) any {
+	var v any = p.data.Options
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// calc returns the reducer of the stat, gauge and bar panels.
+func (p *nativePanel) calc() string {
+	if calcs, ok := p.option("reduceOptions", "calcs").([]any); ok && len(calcs) > 0 {
+		if calc, ok := calcs[0].(string); ok {
+			return calc
+		}
+	}
+	return "lastNotNull"
+}
+
+// valueRange returns the range of the values, or the min and max of the field config.
+func (p *nativePanel) valueRange(series []nativeSeries, includeZero bool) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, v := range s.values {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+	if math.IsInf(lo, 1) {
+		lo, hi = 0, 1
+	}
+	if includeZero {
+		lo, hi = math.Min(lo, 0), math.Max(hi, 0)
+	}
+	if p.cfg.min != nil {
+		lo = *p.cfg.min
+	}
+	if p.cfg.max != nil {
+		hi = *p.cfg.max
+	}
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+func (p *nativePanel) drawTimeSeries(c canvas, series []nativeSeries, x, y, w, h float64) {
+	// Legend
+	if show, ok := p.option("legend", "showLegend").(bool); !ok || show {
+		if mode, _ := p.option("legend", "displayMode").(string); mode != "hidden" {
+			h -= p.drawLegend(c, series, x, y+h, w)
+		}
+	}
+
+	lo, hi := p.valueRange(series, false)
+	ticks := niceTicks(lo, hi, int(math.Max(2, h/40)))
+	lo, hi = math.Min(lo, ticks[0]), math.Max(hi, ticks[len(ticks)-1])
+	labels := make([]string, len(ticks))
+	axisWidth := 0.0
+	for i, t := range ticks {
+		labels[i] = p.cfg.formatValue(t)
+		axisWidth = math.Max(axisWidth, measureText(labels[i], nativeLabelSize))
+	}
+	plotX, plotW := x+axisWidth+nativePadding, w-axisWidth-nativePadding
+	plotH := h - nativeLabelSize - nativePadding
+	if plotW <= 0 || plotH <= 0 {
+		return
+	}
+	toY := func(v float64) float64 { return y + plotH - (v-lo)/(hi-lo)*plotH }
+
+	for i, t := range ticks {
+		ty := toY(t)
+		c.fillRect(plotX, ty, plotW, 1, p.theme.grid)
+		c.text(plotX-nativePadding, ty-nativeLabelSize/2, labels[i], nativeLabelSize, p.theme.text, alignRight)
+	}
+
+	from, to := p.data.From, p.data.To
+	span := to.Sub(from)
+	if span <= 0 {
+		return
+	}
+	toX := func(t time.Time) float64 { return plotX + float64(t.Sub(from))/float64(span)*plotW }
+	step := niceTimeStep(span, int(math.Max(2, plotW/100)))
+	layout := timeLayout(span)
+	for t := from.In(p.location).Truncate(step); !t.After(to); t = t.Add(step) {
+		if t.Before(from) {
+			continue
+		}
+		tx := toX(t)
+		c.fillRect(tx, y, 1, plotH, p.theme.grid)
+		c.text(tx, y+plotH+nativePadding/2, t.In(p.location).Format(layout), nativeLabelSize, p.theme.text, alignCenter)
+	}
+
+	lineWidth := 1.0
+	custom, _ := p.data.FieldConfig["custom"].(map[string]any)
+	if v, ok := custom["lineWidth"].(float64); ok && v > 0 {
+		lineWidth = v
+	}
+	for _, s := range series {
+		if s.times == nil {
+			continue
+		}
+		var points []point
+		for i, v := range s.values {
+			t := s.times[i]
+			if math.IsNaN(v) || t.Before(from) || t.After(to) {
+				// Null values break the line.
+				c.polyline(points, lineWidth, s.color)
+				points = points[:0]
+				continue
+			}
+			points = append(points, point{x: toX(t), y: math.Max(y, math.Min(y+plotH, toY(v)))})
+		}
+		c.polyline(points, lineWidth, s.color)
+	}
+}
+
+// drawLegend draws the legend at the bottom of the area, and returns its height. At most 3 lines are drawn.
+func (p *nativePanel) drawLegend(c canvas, series []nativeSeries, x, bottom, w float64) float64 {
+	const maxLines = 3
+	lineHeight := nativeLabelSize + nativePadding/2
+	type entry struct {
+		x, line float64
+		s       nativeSeries
+	}
+	var entries []entry
+	cx, line := 0.0, 0.0
+	for _, s := range series {
+		width := nativeLabelSize + nativePadding/2 + measureText(s.name, nativeLabelSize)
+		if cx > 0 && cx+width > w {
+			cx, line = 0, line+1
+		}
+		if line >= maxLines {
+			break
+		}
+		entries = append(entries, entry{x: cx, line: line, s: s})
+		cx += width + 2*nativePadding
+	}
+	height := (line+1)*lineHeight + nativePadding
+	if line >= maxLines {
+		height = maxLines*lineHeight + nativePadding
+	}
+	top := bottom - height + nativePadding
+	for _, e := range entries {
+		ey := top + e.line*lineHeight
+		c.fillRect(x+e.x, ey+nativeLabelSize/2-1, nativeLabelSize, 3, e.s.color)
+		c.text(x+e.x+nativeLabelSize+nativePadding/2, ey, e.s.name, nativeLabelSize, p.theme.text, alignLeft)
+	}
+	return height
+}
+
+// reduced is a reduced value of a series, shown by the stat, gauge and bar panels.
+type reduced struct {
+	name  string
+	value float64
+	color color.RGBA
+}
+
+func (p *nativePanel) reduceSeries(series []nativeSeries) []reduced {
+	calc := p.calc()
+	result := make([]reduced, 0, len(series))
+	for _, s := range series {
+		v := reduce(s.values, calc)
+		result = append(result, reduced{name: s.name, value: v, color: p.cfg.thresholdColor(v, s.color)})
+	}
+	return result
+}
+
+// grid splits the area in cells for n values, in the orientation of the panel.
+func (p *nativePanel) grid(n int, x, y, w, h float64) []struct{ x, y, w, h float64 } {
+	cols, rows := n, 1
+	if orientation, _ := p.option("orientation").(string); orientation == "vertical" || (orientation != "horizontal" && h > w) {
+		cols, rows = 1, n
+	}
+	cells := make([]struct{ x, y, w, h float64 }, 0, n)
+	cw, ch := w/float64(cols), h/float64(rows)
+	for i := 0; i < n; i++ {
+		cells = append(cells, struct{ x, y, w, h float64 }{x + float64(i%cols)*cw, y + float64(i/cols)*ch, cw, ch})
+	}
+	return cells
+}
+
+// fitText returns the largest text size for which the text fits in the box, capped to the max size.
+func fitText(s string, w, h, maxSize float64) float64 {
+	size := math.Min(h, maxSize)
+	if width := measureText(s, glyphHeight); width > 0 {
+		size = math.Min(size, w/width*glyphHeight)
+	}
+	return math.Max(size, glyphHeight)
+}
+
+func (p *nativePanel) drawStat(c canvas, series []nativeSeries, x, y, w, h float64) {
+	values := p.reduceSeries(series)
+	colorMode, _ := p.option("colorMode").(string)
+	for i, cell := range p.grid(len(values), x, y, w, h) {
+		v := values[i]
+		textColor := v.color
+		if colorMode == "background" || colorMode == "background_solid" {
+			c.fillRect(cell.x+1, cell.y+1, cell.w-2, cell.h-2, v.color)
+			textColor = rgb(0xff, 0xff, 0xff)
+		} else if colorMode == "none" {
+			textColor = p.theme.text
+		}
+		top := cell.y
+		if len(values) > 1 {
+			c.text(cell.x+cell.w/2, top+nativePadding/2, v.name, nativeLabelSize, textColor, alignCenter)
+			top += nativeLabelSize + nativePadding
+		}
+		text := p.cfg.formatValue(v.value)
+		size := fitText(text, cell.w*0.8, (cell.y+cell.h-top)*0.6, 120)
+		c.text(cell.x+cell.w/2, top+(cell.y+cell.h-top-size)/2, text, size, textColor, alignCenter)
+	}
+}
+
+const (
+	gaugeStart = 5 * math.Pi / 6
+	gaugeSweep = 4 * math.Pi / 3
+)
+
+func (p *nativePanel) drawGauge(c canvas, series []nativeSeries, x, y, w, h float64) {
+	values := p.reduceSeries(series)
+	lo, hi := p.valueRange(series, true)
+	for i, cell := range p.grid(len(values), x, y, w, h) {
+		v := values[i]
+		top, ch := cell.y, cell.h
+		if len(values) > 1 {
+			c.text(cell.x+cell.w/2, top, v.name, nativeLabelSize, p.theme.text, alignCenter)
+			top, ch = top+nativeLabelSize+nativePadding, ch-nativeLabelSize-nativePadding
+		}
+		r := math.Min(cell.w/2, ch/1.6) - nativePadding
+		if r <= 0 {
+			continue
+		}
+		cx, cy := cell.x+cell.w/2, top+r+nativePadding
+		thickness := r / 5
+		toAngle := func(v float64) float64 {
+			return gaugeStart + math.Max(0, math.Min(1, (v-lo)/(hi-lo)))*gaugeSweep
+		}
+
+		// Threshold bands outside of the gauge
+		for j, t := range p.cfg.thresholds {
+			end := hi
+			if j+1 < len(p.cfg.thresholds) {
+				end = p.cfg.thresholds[j+1].value
+			}
+			c.arc(cx, cy, r, thickness/4, toAngle(t.value), toAngle(end), t.color)
+		}
+		inner := r - thickness/4 - 2
+		c.arc(cx, cy, inner, thickness, gaugeStart, gaugeStart+gaugeSweep, p.theme.grid)
+		if !math.IsNaN(v.value) {
+			c.arc(cx, cy, inner, thickness, gaugeStart, toAngle(v.value), v.color)
+		}
+
+		text := p.cfg.formatValue(v.value)
+		size := fitText(text, (inner-thickness)*1.4, inner*0.5, 80)
+		c.text(cx, cy-size/2, text, size, v.color, alignCenter)
+	}
+}
+
+func (p *nativePanel) drawBarGauge(c canvas, series []nativeSeries, x, y, w, h float64) {
+	values := p.reduceSeries(series)
+	lo, hi := p.valueRange(series, true)
+	rowH := math.Min(h/float64(len(values)), 60)
+	nameW := 0.0
+	for _, v := range values {
+		nameW = math.Max(nameW, measureText(v.name, nativeLabelSize))
+	}
+	nameW = math.Min(nameW, w/3)
+	valueW := 0.0
+	for _, v := range values {
+		valueW = math.Max(valueW, measureText(p.cfg.formatValue(v.value), nativeLabelSize))
+	}
+	barX, barW := x+nameW+nativePadding, w-nameW-valueW-2*nativePadding
+	if barW <= 0 {
+		return
+	}
+	for i, v := range values {
+		ry := y + float64(i)*rowH
+		c.text(x, ry+rowH/2-nativeLabelSize/2, v.name, nativeLabelSize, p.theme.text, alignLeft)
+		c.fillRect(barX, ry+2, barW, rowH-4, p.theme.grid)
+		if !math.IsNaN(v.value) {
+			fraction := math.Max(0, math.Min(1, (v.value-lo)/(hi-lo)))
+			c.fillRect(barX, ry+2, barW*fraction, rowH-4, v.color)
+		}
+		c.text(x+w, ry+rowH/2-nativeLabelSize/2, p.cfg.formatValue(v.value), nativeLabelSize, v.color, alignRight)
+	}
+}
+
+// drawBarChart draws a bar for each value of the numeric fields, grouped by the values of the first string field
+// of the frame. Without a string field, a bar is drawn for the reduced value of each series.
+func (p *nativePanel) drawBarChart(c canvas, series []nativeSeries, x, y, w, h float64) {
+	var categories []string
+	for _, frame := range p.data.Frames {
+		for _, f := range frame.Fields {
+			if f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString {
+				for i := 0; i < f.Len(); i++ {
+					v, _ := f.ConcreteAt(i)
+					s, _ := v.(string)
+					categories = append(categories, s)
+				}
+				break
+			}
+		}
+		if categories != nil {
+			series = seriesFromFrames(data.Frames{frame})
+			break
+		}
+	}
+	if categories == nil {
+		categories = []string{""}
+		for i, v := range p.reduceSeries(series) {
+			series[i].values = []float64{v.value}
+		}
+	}
+
+	lo, hi := p.valueRange(series, true)
+	ticks := niceTicks(lo, hi, int(math.Max(2, h/40)))
+	lo, hi = math.Min(lo, ticks[0]), math.Max(hi, ticks[len(ticks)-1])
+	axisWidth := 0.0
+	for _, t := range ticks {
+		axisWidth = math.Max(axisWidth, measureText(p.cfg.formatValue(t), nativeLabelSize))
+	}
+	plotX, plotW := x+axisWidth+nativePadding, w-axisWidth-nativePadding
+	plotH := h - nativeLabelSize - nativePadding
+	if plotW <= 0 || plotH <= 0 {
+		return
+	}
+	toY := func(v float64) float64 { return y + plotH - (v-lo)/(hi-lo)*plotH }
+	for _, t := range ticks {
+		c.fillRect(plotX, toY(t), plotW, 1, p.theme.grid)
+		c.text(plotX-nativePadding, toY(t)-nativeLabelSize/2, p.cfg.formatValue(t), nativeLabelSize, p.theme.text, alignRight)
+	}
+
+	groupW := plotW / float64(len(categories))
+	barW := groupW * 0.8 / float64(len(series))
+	zero := toY(math.Max(lo, math.Min(hi, 0)))
+	for i, category := range categories {
+		gx := plotX + float64(i)*groupW + groupW*0.1
+		for j, s := range series {
+			if i >= len(s.values) || math.IsNaN(s.values[i]) {
+				continue
+			}
+			vy := toY(s.values[i])
+			c.fillRect(gx+float64(j)*barW, math.Min(vy, zero), math.Max(barW-1, 1), math.Abs(zero-vy), p.cfg.thresholdColor(s.values[i], s.color))
+		}
+		if category == "" && len(series) == 1 {
+			category = series[0].name
+		}
+		c.text(gx+groupW*0.4, y+plotH+nativePadding/2, category, nativeLabelSize, p.theme.text, alignCenter)
+	}
+}
+
+// niceTicks returns about n round values that cover the range.
+func niceTicks(lo, hi float64, n int) []float64 {
+	step := niceNumber((hi-lo)/float64(n), true)
+	if step <= 0 || math.IsNaN(step) || math.IsInf(step, 0) {
+		return []float64{lo, hi}
+	}
+	start := math.Floor(lo/step) * step
+	end := math.Ceil(hi/step) * step
+	var ticks []float64
+	for v := start; v <= end+step/2; v += step {
+		// Avoid -0 and floating point noise in labels.
+		ticks = append(ticks, math.Round(v/step)*step+0)
+	}
+	return ticks
+}
+
+func niceNumber(v float64, round bool) float64 {
+	if v <= 0 {
+		return 0
+	}
+	exp := math.Floor(math.Log10(v))
+	f := v / math.Pow(10, exp)
+	var nice float64
+	switch {
+	case round && f < 1.5, !round && f <= 1:
+		nice = 1
+	case round && f < 3, !round && f <= 2:
+		nice = 2
+	case round && f < 7, !round && f <= 5:
+		nice = 5
+	default:
+		nice = 10
+	}
+	return nice * math.Pow(10, exp)
+}
+
+var nativeTimeSteps = []time.Duration{
+	time.Second, 5 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour, 365 * 24 * time.Hour,
+}
+
+// niceTimeStep returns the smallest round step that splits the span in at most n intervals.
+func niceTimeStep(span time.Duration, n int) time.Duration {
+	for _, step := range nativeTimeSteps {
+		if span/step <= time.Duration(n) {
+			return step
+		}
+	}
+	return nativeTimeSteps[len(nativeTimeSteps)-1]
+}
+
+func timeLayout(span time.Duration) string {
+	switch {
+	case span <= time.Hour:
+		return "15:04:05"
+	case span <= 24*time.Hour:
+		return "15:04"
+	case span <= 7*24*time.Hour:
+		return "01/02 15:04"
+	case span <= 365*24*time.Hour:
+		return "01/02"
+	default:
+		return "2006-01"
+	}
+}
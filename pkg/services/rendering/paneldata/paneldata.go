@@ -0,0 +1,96 @@
+package paneldata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/panelquery"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var _ rendering.PanelDataProvider = (*Service)(nil)
+
+// Service executes the queries of dashboard panels for the native renderer.
+type Service struct {
+	dashboardService dashboards.DashboardService
+	queryService     query.Service
+	acService        accesscontrol.Service
+	userService      user.Service
+}
+
+func ProvideService(dashboardService dashboards.DashboardService, queryService query.Service, acService accesscontrol.Service, userService user.Service) *Service {
+	return &Service{
+		dashboardService: dashboardService,
+		queryService:     queryService,
+		acService:        acService,
+		userService:      userService,
+	}
+}
+
+// GetPanelData queries the panel as the user of the render request, who must be able to view the dashboard.
+func (s *Service) GetPanelData(ctx context.Context, authOpts rendering.AuthOpts, q rendering.PanelDataQuery) (*rendering.PanelData, error) {
+	u, err := s.signedInUser(ctx, authOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := guardian.NewByUID(ctx, q.DashboardUID, u.OrgID, u)
+	if err != nil {
+		return nil, err
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return nil, dashboards.ErrDashboardNotFound
+	}
+
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: q.DashboardUID, OrgID: u.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := panelquery.Query(ctx, u, dash.Data, q.PanelID, q.From, q.To, q.Variables, s.queryService, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	panelData := &rendering.PanelData{
+		From:   result.From,
+		To:     result.To,
+		Frames: result.Frames,
+		Errors: result.Errors,
+	}
+	panelData.Type, _ = result.Panel["type"].(string)
+	panelData.Title, _ = result.Panel["title"].(string)
+	panelData.Options, _ = result.Panel["options"].(map[string]any)
+	if fieldConfig, ok := result.Panel["fieldConfig"].(map[string]any); ok {
+		panelData.FieldConfig, _ = fieldConfig["defaults"].(map[string]any)
+	}
+	return panelData, nil
+}
+
+// signedInUser returns the user of the render request with its permissions. Render requests without a user,
+// e.g. of alert screenshots, are made with the org role only.
+func (s *Service) signedInUser(ctx context.Context, authOpts rendering.AuthOpts) (*user.SignedInUser, error) {
+	var u *user.SignedInUser
+	if authOpts.UserID > 0 {
+		var err error
+		u, err = s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: authOpts.UserID, OrgID: authOpts.OrgID})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		u = &user.SignedInUser{OrgID: authOpts.OrgID, OrgRole: authOpts.OrgRole}
+	}
+
+	permissions, err := s.acService.GetUserPermissions(ctx, u, accesscontrol.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions of the render user: %w", err)
+	}
+	u.Permissions = map[int64]map[string][]string{u.OrgID: accesscontrol.GroupScopesByAction(permissions)}
+	return u, nil
+}
@@ -39,6 +39,8 @@ type RenderingService struct {
 	versionMutex      sync.RWMutex
 	capabilities      []Capability
 	pluginAvailable   bool
+	nativeAvailable   bool
+	panelData         PanelDataProvider
 
 	perRequestRenderKeyProvider renderKeyProvider
 	Cfg                         *setting.Cfg
@@ -57,7 +59,7 @@ type Plugin interface {
 	Version() string
 }
 
-func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remoteCache *remotecache.RemoteCache, rm PluginManager) (*RenderingService, error) {
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remoteCache *remotecache.RemoteCache, rm PluginManager, panelData PanelDataProvider) (*RenderingService, error) {
 	folders := []string{
 		cfg.ImagesDir,
 		cfg.CSVsDir,
@@ -141,6 +143,8 @@ func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, remot
 		domain:                domain,
 		sanitizeURL:           sanitizeURL,
 		pluginAvailable:       exists,
+		nativeAvailable:       cfg.RendererNativeEnabled && panelData != nil,
+		panelData:             panelData,
 	}
 
 	gob.Register(&RenderUser{})
@@ -202,6 +206,21 @@ func (rs *RenderingService) Run(ctx context.Context) error {
 		return nil
 	}
 
+	if rs.nativeAvailable {
+		rs.log = rs.log.New("renderer", "native")
+		rs.log.Info("Backend rendering via native renderer")
+		rs.renderAction = rs.renderNatively
+		rs.renderCSVAction = func(context.Context, string, CSVOpts) (*RenderCSVResult, error) {
+			return nil, fmt.Errorf("csv rendering is %w", ErrNativeRenderUnsupported)
+		}
+		rs.sanitizeSVGAction = func(context.Context, *SanitizeSVGRequest) (*SanitizeSVGResponse, error) {
+			return nil, fmt.Errorf("svg sanitization is %w", ErrNativeRenderUnsupported)
+		}
+		<-ctx.Done()
+
+		return nil
+	}
+
 	rs.log.Debug("No image renderer found/installed. " +
 		"For image rendering support please install the grafana-image-renderer plugin. " +
 		"Read more at https://grafana.com/docs/grafana/latest/administration/image_rendering/")
@@ -215,7 +234,7 @@ func (rs *RenderingService) remoteAvailable() bool {
 }
 
 func (rs *RenderingService) IsAvailable(ctx context.Context) bool {
-	return rs.remoteAvailable() || rs.pluginAvailable
+	return rs.remoteAvailable() || rs.pluginAvailable || rs.nativeAvailable
 }
 
 func (rs *RenderingService) Version() string {
@@ -398,6 +417,9 @@ func (rs *RenderingService) getNewFilePath(rt RenderType) (string, error) {
 	case RenderPDF:
 		ext = "pdf"
 		folder = rs.Cfg.PDFsDir
+	case RenderSVG:
+		ext = "svg"
+		folder = rs.Cfg.ImagesDir
 	default:
 		ext = "png"
 		folder = rs.Cfg.ImagesDir
@@ -0,0 +1,356 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ReportService) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/reports", func(reports routing.RouteRegister) {
+		reports.Get("/", middleware.ReqSignedIn, routing.Wrap(s.listHandler))
+		reports.Post("/", middleware.ReqEditorRole, routing.Wrap(s.createHandler))
+		reports.Get("/:uid", middleware.ReqSignedIn, routing.Wrap(s.getHandler))
+		reports.Put("/:uid", middleware.ReqEditorRole, routing.Wrap(s.updateHandler))
+		reports.Delete("/:uid", middleware.ReqEditorRole, routing.Wrap(s.deleteHandler))
+		reports.Post("/:uid/send", middleware.ReqEditorRole, routing.Wrap(s.sendHandler))
+		reports.Get("/:uid/history", middleware.ReqSignedIn, routing.Wrap(s.historyHandler))
+	})
+}
+
+// swagger:route GET /reports reports listReports
+//
+// Get all reports of the current organization on dashboards the user can view.
+//
+// Responses:
+// 200: listReportsResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *ReportService) listHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.ListReports(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get reports", err)
+	}
+	access := make(map[string]reportAccess)
+	result := make([]*Report, 0, len(reports))
+	for _, report := range reports {
+		a, ok := access[report.DashboardUID]
+		if !ok {
+			if a, err = s.getReportAccess(c, report.DashboardUID); err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+			}
+			access[report.DashboardUID] = a
+		}
+		if !a.canView {
+			continue
+		}
+		result = append(result, a.redactReport(c, report))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /reports reports createReport
+//
+// Create a report.
+//
+// Creates a report that renders a dashboard on a schedule, and delivers it by email or webhook.
+// The report is rendered with the permissions of the user who created it.
+//
+// Responses:
+// 200: getReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ReportService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if resp := s.checkDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.CreatedBy = c.SignedInUser.UserID
+
+	report, err := s.CreateReport(c.Req.Context(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /reports/{report_uid} reports getReport
+//
+// Get a report by UID.
+//
+// The webhook URLs of the report are only returned to its creator and to users who can edit the dashboard.
+//
+// Responses:
+// 200: getReportResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) getHandler(c *contextmodel.ReqContext) response.Response {
+	report, access, resp := s.getReportWithAccess(c, false)
+	if resp != nil {
+		return resp
+	}
+	return response.JSON(http.StatusOK, access.redactReport(c, report))
+}
+
+// swagger:route PUT /reports/{report_uid} reports updateReport
+//
+// Update a report.
+//
+// Only the creator of the report and organization admins can update it.
+//
+// Responses:
+// 200: getReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if _, _, resp := s.getReportWithAccess(c, true); resp != nil {
+		return resp
+	}
+	if resp := s.checkDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	report, err := s.UpdateReport(c.Req.Context(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update report", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route DELETE /reports/{report_uid} reports deleteReport
+//
+// Delete a report and its history.
+//
+// Only the creator of the report and organization admins can delete it.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	report, _, resp := s.getReportWithAccess(c, true)
+	if resp != nil {
+		return resp
+	}
+	if err := s.DeleteReport(c.Req.Context(), report.OrgID, report.UID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete report", err)
+	}
+	return response.Success("Report deleted")
+}
+
+// swagger:route POST /reports/{report_uid}/send reports sendReport
+//
+// Send a report now.
+//
+// Renders and delivers the report immediately, regardless of its schedule. The run is added to the history of the report.
+// Only the creator of the report and organization admins can send it.
+//
+// Responses:
+// 200: getReportRunResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) sendHandler(c *contextmodel.ReqContext) response.Response {
+	report, _, resp := s.getReportWithAccess(c, true)
+	if resp != nil {
+		return resp
+	}
+	ctx, cancel := context.WithTimeout(c.Req.Context(), sendTimeout)
+	defer cancel()
+	run, err := s.SendReport(ctx, report, TriggerManual)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to send report", err)
+	}
+	return response.JSON(http.StatusOK, run)
+}
+
+// swagger:route GET /reports/{report_uid}/history reports getReportHistory
+//
+// Get the delivery history of a report, latest first.
+//
+// Responses:
+// 200: getReportHistoryResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportService) historyHandler(c *contextmodel.ReqContext) response.Response {
+	report, access, resp := s.getReportWithAccess(c, false)
+	if resp != nil {
+		return resp
+	}
+	runs, err := s.GetReportHistory(c.Req.Context(), report.OrgID, report.UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report history", err)
+	}
+	return response.JSON(http.StatusOK, access.redactRuns(c, report, runs))
+}
+
+// reportAccess is the access of a user to the dashboard of a report.
+type reportAccess struct {
+	canView bool
+	canEdit bool
+}
+
+// getReportAccess returns the access of the user to the dashboard. A dashboard that does not exist
+// cannot be viewed by anyone.
+func (s *ReportService) getReportAccess(c *contextmodel.ReqContext, dashboardUID string) (reportAccess, error) {
+	g, err := guardian.NewByUID(c.Req.Context(), dashboardUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return reportAccess{}, nil
+		}
+		return reportAccess{}, err
+	}
+	canView, err := g.CanView()
+	if err != nil || !canView {
+		return reportAccess{}, err
+	}
+	canEdit, err := g.CanSave()
+	if err != nil {
+		return reportAccess{}, err
+	}
+	return reportAccess{canView: true, canEdit: canEdit}, nil
+}
+
+// getReportWithAccess returns the report in the path of the request and the access of the user to it.
+// Reports on dashboards the user cannot view are not found. If manage is true, the user must also
+// be the creator of the report or an organization admin.
+func (s *ReportService) getReportWithAccess(c *contextmodel.ReqContext, manage bool) (*Report, reportAccess, response.Response) {
+	report, err := s.GetReport(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return nil, reportAccess{}, response.ErrOrFallback(http.StatusInternalServerError, "Failed to get report", err)
+	}
+	access, err := s.getReportAccess(c, report.DashboardUID)
+	if err != nil {
+		return nil, reportAccess{}, response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if !access.canView {
+		return nil, reportAccess{}, response.ErrOrFallback(http.StatusNotFound, "Report not found", ErrReportNotFound.Errorf("report %s not found", report.UID))
+	}
+	if manage && !isReportOwner(c, report) && c.SignedInUser.GetOrgRole() != org.RoleAdmin {
+		return nil, reportAccess{}, response.Error(http.StatusForbidden, "Only the creator of the report and organization admins can manage it", nil)
+	}
+	return report, access, nil
+}
+
+func isReportOwner(c *contextmodel.ReqContext, report *Report) bool {
+	return c.SignedInUser.UserID != 0 && report.CreatedBy == c.SignedInUser.UserID
+}
+
+// redactReport removes the webhook URLs from the report, unless the user created it or can edit the dashboard.
+// Webhook URLs often contain credentials.
+func (a reportAccess) redactReport(c *contextmodel.ReqContext, report *Report) *Report {
+	if a.canEdit || isReportOwner(c, report) || len(report.Recipients.Webhooks) == 0 {
+		return report
+	}
+	redacted := *report
+	redacted.Recipients.Webhooks = nil
+	return &redacted
+}
+
+// redactRuns removes the webhook URLs from the deliveries of the runs, like redactReport.
+func (a reportAccess) redactRuns(c *contextmodel.ReqContext, report *Report, runs []*Run) []*Run {
+	if a.canEdit || isReportOwner(c, report) {
+		return runs
+	}
+	for _, run := range runs {
+		for i := range run.Deliveries {
+			if run.Deliveries[i].Type == "webhook" {
+				run.Deliveries[i].Recipient = ""
+			}
+		}
+	}
+	return runs
+}
+
+// checkDashboardAccess returns an error response if the dashboard of the report does not exist,
+// or the user cannot view it.
+func (s *ReportService) checkDashboardAccess(c *contextmodel.ReqContext, dashboardUID string) response.Response {
+	if dashboardUID == "" {
+		return response.ErrOrFallback(http.StatusBadRequest, "Invalid report", errInvalidReport("dashboard UID is required"))
+	}
+	g, err := guardian.NewByUID(c.Req.Context(), dashboardUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to check dashboard permissions", err)
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard", err)
+	}
+	return nil
+}
+
+// swagger:parameters getReport updateReport deleteReport sendReport getReportHistory
+type ReportByUID struct {
+	// in:path
+	// required:true
+	UID string `json:"report_uid"`
+}
+
+// swagger:parameters createReport
+type CreateReportParams struct {
+	// in:body
+	// required:true
+	Body CreateReportCommand `json:"body"`
+}
+
+// swagger:parameters updateReport
+type UpdateReportParams struct {
+	// in:body
+	// required:true
+	Body CreateReportCommand `json:"body"`
+}
+
+// swagger:response listReportsResponse
+type ListReportsResponse struct {
+	// in: body
+	Body []*Report `json:"body"`
+}
+
+// swagger:response getReportResponse
+type GetReportResponse struct {
+	// in: body
+	Body *Report `json:"body"`
+}
+
+// swagger:response getReportRunResponse
+type GetReportRunResponse struct {
+	// in: body
+	Body *Run `json:"body"`
+}
+
+// swagger:response getReportHistoryResponse
+type GetReportHistoryResponse struct {
+	// in: body
+	Body []*Run `json:"body"`
+}
@@ -0,0 +1,104 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func (s *ReportService) insertReport(ctx context.Context, report *Report) error {
+	return s.store.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.Insert(report)
+		return err
+	})
+}
+
+func (s *ReportService) updateReport(ctx context.Context, report *Report) error {
+	return s.store.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.ID(report.ID).AllCols().Update(report)
+		return err
+	})
+}
+
+func (s *ReportService) getReport(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	report := &Report{}
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		exists, err := session.Where("org_id = ? AND uid = ?", orgID, uid).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound.Errorf("report %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) listReports(ctx context.Context, orgID int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Where("org_id = ?", orgID).Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+// listEnabledReports returns the enabled reports of all orgs.
+func (s *ReportService) listEnabledReports(ctx context.Context) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Where("enabled = ?", true).Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *ReportService) deleteReport(ctx context.Context, orgID int64, uid string) error {
+	return s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		report := &Report{}
+		exists, err := session.Where("org_id = ? AND uid = ?", orgID, uid).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound.Errorf("report %s not found", uid)
+		}
+		if _, err := session.Exec("DELETE FROM report_run WHERE report_id = ?", report.ID); err != nil {
+			return err
+		}
+		_, err = session.Exec("DELETE FROM report WHERE id = ?", report.ID)
+		return err
+	})
+}
+
+// insertRun stores the run, and deletes the oldest runs of the report beyond the history limit.
+func (s *ReportService) insertRun(ctx context.Context, run *Run) error {
+	return s.store.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		if _, err := session.Insert(run); err != nil {
+			return err
+		}
+		if s.cfg.ReportsHistoryLimit <= 0 {
+			return nil
+		}
+		var ids []int64
+		if err := session.Table("report_run").Cols("id").Where("report_id = ?", run.ReportID).
+			Desc("started").Desc("id").Limit(1000, s.cfg.ReportsHistoryLimit).Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		_, err := session.In("id", ids).Delete(&Run{})
+		return err
+	})
+}
+
+func (s *ReportService) listRuns(ctx context.Context, reportID int64) ([]*Run, error) {
+	runs := make([]*Run, 0)
+	err := s.store.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Where("report_id = ?", reportID).Desc("started").Desc("id").Find(&runs)
+	})
+	return runs, err
+}
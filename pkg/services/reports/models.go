@@ -0,0 +1,131 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrReportNotFound = errutil.NotFound("reports.not-found", errutil.WithPublicMessage("Report not found"))
+	ErrReportInvalid  = errutil.BadRequest("reports.invalid").MustTemplate("invalid report: {{ .Public.Reason }}", errutil.WithPublic("Invalid report: {{ .Public.Reason }}"))
+	ErrReportNoPanels = errutil.BadRequest("reports.no-panels", errutil.WithPublicMessage("The dashboard has no panels to report"))
+)
+
+func errInvalidReport(reason string) error {
+	return ErrReportInvalid.Build(errutil.TemplateData{Public: map[string]any{"Reason": reason}})
+}
+
+// Format is the format of the files of a report.
+type Format string
+
+const (
+	// FormatPNG is an image of each panel of the dashboard.
+	FormatPNG Format = "png"
+	// FormatCSV is the data of each panel of the dashboard.
+	FormatCSV Format = "csv"
+	// FormatPDF is a multi-page document of the whole dashboard.
+	FormatPDF Format = "pdf"
+)
+
+// RunState is the state of a run of a report.
+type RunState string
+
+const (
+	RunStateSuccess RunState = "success"
+	// RunStatePartial is the state of a run in which some, but not all, recipients received the report.
+	RunStatePartial RunState = "partial"
+	RunStateFailed  RunState = "failed"
+)
+
+// Trigger is what started a run of a report.
+type Trigger string
+
+const (
+	TriggerSchedule Trigger = "schedule"
+	TriggerManual   Trigger = "manual"
+)
+
+// Recipients are the email addresses and the webhook URLs a report is delivered to.
+type Recipients struct {
+	Emails   []string `json:"emails,omitempty"`
+	Webhooks []string `json:"webhooks,omitempty"`
+}
+
+// Report is the model for scheduled dashboard reports
+type Report struct {
+	ID           int64               `json:"-" xorm:"pk autoincr 'id'"`
+	UID          string              `json:"uid" xorm:"uid"`
+	OrgID        int64               `json:"orgId" xorm:"org_id"`
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid" xorm:"dashboard_uid"`
+	From         string              `json:"from" xorm:"time_from"`
+	To           string              `json:"to" xorm:"time_to"`
+	Timezone     string              `json:"timezone,omitempty"`
+	Variables    map[string][]string `json:"variables,omitempty" xorm:"jsonb variables"`
+	Schedule     string              `json:"schedule"`
+	Format       Format              `json:"format"`
+	Recipients   Recipients          `json:"recipients" xorm:"jsonb recipients"`
+	Enabled      bool                `json:"enabled"`
+	CreatedBy    int64               `json:"createdBy"`
+	Created      time.Time           `json:"created"`
+	Updated      time.Time           `json:"updated"`
+}
+
+// Delivery is the result of the delivery of a report to one recipient.
+type Delivery struct {
+	// Type is either email or webhook.
+	Type      string `json:"type"`
+	Recipient string `json:"recipient"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Run is the model for the delivery history of reports
+type Run struct {
+	ID         int64      `json:"id" xorm:"pk autoincr 'id'"`
+	ReportID   int64      `json:"-" xorm:"report_id"`
+	OrgID      int64      `json:"-" xorm:"org_id"`
+	Trigger    Trigger    `json:"trigger" xorm:"triggered_by"`
+	State      RunState   `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	Deliveries []Delivery `json:"deliveries" xorm:"jsonb deliveries"`
+	Started    time.Time  `json:"started"`
+	Finished   time.Time  `json:"finished"`
+}
+
+func (r Run) TableName() string {
+	return "report_run"
+}
+
+// CreateReportCommand is the command for creating a report
+// swagger:model
+type CreateReportCommand struct {
+	// Name of the report. Used as the subject of the emails.
+	Name string `json:"name" binding:"Required"`
+	// UID of the dashboard to report.
+	DashboardUID string `json:"dashboardUid" binding:"Required"`
+	// From and To are the time range of the report, e.g. now-7d and now. Defaults to the time range of the dashboard.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Timezone of the report, e.g. Europe/Stockholm. Defaults to the timezone of the server.
+	Timezone string `json:"timezone"`
+	// Variables overrides the current values of the variables of the dashboard.
+	Variables map[string][]string `json:"variables"`
+	// Schedule is a cron expression, e.g. 0 8 * * 1 for every Monday at 08:00.
+	Schedule string `json:"schedule" binding:"Required"`
+	// Format is one of png, csv and pdf.
+	Format     Format     `json:"format" binding:"Required"`
+	Recipients Recipients `json:"recipients"`
+	Enabled    bool       `json:"enabled"`
+
+	OrgID     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+}
+
+// UpdateReportCommand is the command for updating a report
+// swagger:model
+type UpdateReportCommand struct {
+	CreateReportCommand
+
+	UID string `json:"-"`
+}
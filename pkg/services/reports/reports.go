@@ -0,0 +1,190 @@
+package reports
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service interface {
+	CreateReport(ctx context.Context, cmd CreateReportCommand) (*Report, error)
+	UpdateReport(ctx context.Context, cmd UpdateReportCommand) (*Report, error)
+	GetReport(ctx context.Context, orgID int64, uid string) (*Report, error)
+	ListReports(ctx context.Context, orgID int64) ([]*Report, error)
+	DeleteReport(ctx context.Context, orgID int64, uid string) error
+	// SendReport renders and delivers the report now, and stores the run in the history of the report.
+	SendReport(ctx context.Context, report *Report, trigger Trigger) (*Run, error)
+	GetReportHistory(ctx context.Context, orgID int64, uid string) ([]*Run, error)
+}
+
+type ReportService struct {
+	cfg                 *setting.Cfg
+	store               db.DB
+	log                 log.Logger
+	dashboardService    dashboards.DashboardService
+	renderService       rendering.Service
+	notificationService notifications.Service
+	userService         user.Service
+	serverLock          *serverlock.ServerLockService
+	now                 func() time.Time
+}
+
+var _ Service = (*ReportService)(nil)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, dashboardService dashboards.DashboardService,
+	renderService rendering.Service, notificationService notifications.Service, userService user.Service,
+	serverLock *serverlock.ServerLockService) *ReportService {
+	s := &ReportService{
+		cfg:                 cfg,
+		store:               sqlStore,
+		log:                 log.New("reports"),
+		dashboardService:    dashboardService,
+		renderService:       renderService,
+		notificationService: notificationService,
+		userService:         userService,
+		serverLock:          serverLock,
+		now:                 time.Now,
+	}
+
+	// Register routes only when reporting is enabled
+	if cfg.ReportsEnabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+
+	return s
+}
+
+func (s *ReportService) IsDisabled() bool {
+	return !s.cfg.ReportsEnabled
+}
+
+func (s *ReportService) CreateReport(ctx context.Context, cmd CreateReportCommand) (*Report, error) {
+	if err := validateReport(cmd); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	report := &Report{
+		UID:       util.GenerateShortUID(),
+		OrgID:     cmd.OrgID,
+		CreatedBy: cmd.CreatedBy,
+		Created:   now,
+	}
+	setReport(report, cmd, now)
+	if err := s.insertReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) UpdateReport(ctx context.Context, cmd UpdateReportCommand) (*Report, error) {
+	if err := validateReport(cmd.CreateReportCommand); err != nil {
+		return nil, err
+	}
+	report, err := s.GetReport(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	setReport(report, cmd.CreateReportCommand, s.now())
+	if err := s.updateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ReportService) GetReport(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	return s.getReport(ctx, orgID, uid)
+}
+
+func (s *ReportService) ListReports(ctx context.Context, orgID int64) ([]*Report, error) {
+	return s.listReports(ctx, orgID)
+}
+
+func (s *ReportService) DeleteReport(ctx context.Context, orgID int64, uid string) error {
+	return s.deleteReport(ctx, orgID, uid)
+}
+
+func (s *ReportService) GetReportHistory(ctx context.Context, orgID int64, uid string) ([]*Run, error) {
+	report, err := s.GetReport(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.listRuns(ctx, report.ID)
+}
+
+func setReport(report *Report, cmd CreateReportCommand, now time.Time) {
+	report.Name = cmd.Name
+	report.DashboardUID = cmd.DashboardUID
+	report.From = cmd.From
+	report.To = cmd.To
+	report.Timezone = cmd.Timezone
+	report.Variables = cmd.Variables
+	report.Schedule = cmd.Schedule
+	report.Format = cmd.Format
+	report.Recipients = cmd.Recipients
+	report.Enabled = cmd.Enabled
+	report.Updated = now
+}
+
+func validateReport(cmd CreateReportCommand) error {
+	if cmd.Name == "" {
+		return errInvalidReport("name is required")
+	}
+	if cmd.DashboardUID == "" {
+		return errInvalidReport("dashboard UID is required")
+	}
+	if (cmd.From == "") != (cmd.To == "") {
+		return errInvalidReport("both from and to, or neither, are required")
+	}
+	if _, err := cron.ParseStandard(cmd.Schedule); err != nil {
+		return errInvalidReport(fmt.Sprintf("invalid schedule: %s", err))
+	}
+	if cmd.Timezone != "" {
+		if _, err := time.LoadLocation(cmd.Timezone); err != nil {
+			return errInvalidReport(fmt.Sprintf("invalid timezone %q", cmd.Timezone))
+		}
+	}
+	switch cmd.Format {
+	case FormatPNG, FormatCSV, FormatPDF:
+	default:
+		return errInvalidReport("format must be one of png, csv and pdf")
+	}
+	if len(cmd.Recipients.Emails) == 0 && len(cmd.Recipients.Webhooks) == 0 {
+		return errInvalidReport("at least one email address or webhook is required")
+	}
+	for _, email := range cmd.Recipients.Emails {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return errInvalidReport(fmt.Sprintf("invalid email address %q", email))
+		}
+	}
+	for _, webhook := range cmd.Recipients.Webhooks {
+		u, err := url.Parse(webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errInvalidReport(fmt.Sprintf("invalid webhook URL %q", webhook))
+		}
+	}
+	return nil
+}
+
+// schedule returns the schedule of the report in its timezone.
+func (r *Report) schedule() (cron.Schedule, error) {
+	spec := r.Schedule
+	if r.Timezone != "" {
+		spec = "CRON_TZ=" + r.Timezone + " " + spec
+	}
+	return cron.ParseStandard(spec)
+}
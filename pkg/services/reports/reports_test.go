@@ -0,0 +1,413 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeSession struct {
+	rendering.Session
+}
+
+func (fakeSession) Dispose(context.Context) {}
+
+type testContext struct {
+	service       *ReportService
+	render        *rendering.MockService
+	dashboards    *dashboards.FakeDashboardService
+	notifications *notifications.NotificationServiceMock
+}
+
+func setupTestService(t *testing.T) *testContext {
+	t.Helper()
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.ReportsEnabled = true
+	cfg.ReportsHistoryLimit = 3
+
+	ctrl := gomock.NewController(t)
+	tc := &testContext{
+		render:        rendering.NewMockService(ctrl),
+		dashboards:    dashboards.NewFakeDashboardService(t),
+		notifications: notifications.MockNotificationService(),
+	}
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedSignedInUser = &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleEditor}
+
+	tc.service = &ReportService{
+		cfg:                 cfg,
+		store:               sqlStore,
+		log:                 log.NewNopLogger(),
+		dashboardService:    tc.dashboards,
+		renderService:       tc.render,
+		notificationService: tc.notifications,
+		userService:         userService,
+		serverLock:          serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest()),
+		now:                 time.Now,
+	}
+	return tc
+}
+
+func validCommand() CreateReportCommand {
+	return CreateReportCommand{
+		Name:         "Weekly",
+		DashboardUID: "dash",
+		From:         "now-7d",
+		To:           "now",
+		Schedule:     "0 8 * * 1",
+		Format:       FormatPNG,
+		Recipients:   Recipients{Emails: []string{"ops@example.com"}},
+		Enabled:      true,
+		OrgID:        1,
+		CreatedBy:    1,
+	}
+}
+
+func TestValidateReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cmd *CreateReportCommand)
+		valid  bool
+	}{
+		{name: "valid", modify: func(cmd *CreateReportCommand) {}, valid: true},
+		{name: "webhook only", modify: func(cmd *CreateReportCommand) {
+			cmd.Recipients = Recipients{Webhooks: []string{"https://example.com/hook"}}
+		}, valid: true},
+		{name: "missing name", modify: func(cmd *CreateReportCommand) { cmd.Name = "" }},
+		{name: "from without to", modify: func(cmd *CreateReportCommand) { cmd.To = "" }},
+		{name: "invalid schedule", modify: func(cmd *CreateReportCommand) { cmd.Schedule = "every monday" }},
+		{name: "invalid timezone", modify: func(cmd *CreateReportCommand) { cmd.Timezone = "Mars/Olympus" }},
+		{name: "invalid format", modify: func(cmd *CreateReportCommand) { cmd.Format = "xlsx" }},
+		{name: "no recipients", modify: func(cmd *CreateReportCommand) { cmd.Recipients = Recipients{} }},
+		{name: "invalid email", modify: func(cmd *CreateReportCommand) { cmd.Recipients.Emails = []string{"ops"} }},
+		{name: "invalid webhook", modify: func(cmd *CreateReportCommand) { cmd.Recipients.Webhooks = []string{"ftp://example.com"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := validCommand()
+			tt.modify(&cmd)
+			err := validateReport(cmd)
+			if tt.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrReportInvalid)
+			var grafanaErr errutil.Error
+			require.ErrorAs(t, err, &grafanaErr)
+			assert.Contains(t, grafanaErr.Public().Message, "Invalid report: ")
+		})
+	}
+}
+
+func TestReportSchedule(t *testing.T) {
+	report := &Report{Schedule: "0 8 * * *", Timezone: "Europe/Paris"}
+	schedule, err := report.schedule()
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestIntegrationReports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	tc := setupTestService(t)
+	s := tc.service
+	ctx := context.Background()
+
+	report, err := s.CreateReport(ctx, validCommand())
+	require.NoError(t, err)
+	require.NotEmpty(t, report.UID)
+
+	t.Run("get and list reports", func(t *testing.T) {
+		got, err := s.GetReport(ctx, 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, report.Name, got.Name)
+		assert.Equal(t, []string{"ops@example.com"}, got.Recipients.Emails)
+
+		_, err = s.GetReport(ctx, 2, report.UID)
+		require.ErrorIs(t, err, ErrReportNotFound)
+
+		list, err := s.ListReports(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+	})
+
+	t.Run("update report", func(t *testing.T) {
+		cmd := UpdateReportCommand{CreateReportCommand: validCommand(), UID: report.UID}
+		cmd.Name = "Daily"
+		cmd.Schedule = "0 8 * * *"
+		cmd.Variables = map[string][]string{"host": {"a", "b"}}
+		updated, err := s.UpdateReport(ctx, cmd)
+		require.NoError(t, err)
+		assert.Equal(t, report.ID, updated.ID)
+
+		got, err := s.GetReport(ctx, 1, report.UID)
+		require.NoError(t, err)
+		assert.Equal(t, "Daily", got.Name)
+		assert.Equal(t, []string{"a", "b"}, got.Variables["host"])
+	})
+
+	t.Run("history is pruned to the limit", func(t *testing.T) {
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			started := start.Add(time.Duration(i) * time.Minute)
+			err := s.insertRun(ctx, &Run{ReportID: report.ID, OrgID: 1, Trigger: TriggerManual, State: RunStateSuccess, Started: started, Finished: started})
+			require.NoError(t, err)
+		}
+		runs, err := s.GetReportHistory(ctx, 1, report.UID)
+		require.NoError(t, err)
+		require.Len(t, runs, 3)
+		assert.True(t, runs[0].Started.After(runs[2].Started))
+	})
+
+	t.Run("delete report", func(t *testing.T) {
+		require.NoError(t, s.DeleteReport(ctx, 1, report.UID))
+		_, err := s.GetReport(ctx, 1, report.UID)
+		require.ErrorIs(t, err, ErrReportNotFound)
+		require.ErrorIs(t, s.DeleteReport(ctx, 1, report.UID), ErrReportNotFound)
+	})
+}
+
+func TestIntegrationSendReport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	tc := setupTestService(t)
+	s := tc.service
+	ctx := context.Background()
+
+	rendered := filepath.Join(t.TempDir(), "panel.png")
+	require.NoError(t, os.WriteFile(rendered, []byte("png"), 0600))
+
+	dashboard := &dashboards.Dashboard{UID: "dash", Slug: "ops", Title: "Ops", OrgID: 1, Data: simplejson.NewFromAny(map[string]any{
+		"panels": []any{
+			map[string]any{"id": 1, "type": "timeseries"},
+			map[string]any{"id": 2, "type": "row", "collapsed": true, "panels": []any{
+				map[string]any{"id": 3, "type": "stat"},
+			}},
+		},
+	})}
+	tc.dashboards.On("GetDashboard", mock.Anything, mock.Anything).Return(dashboard, nil)
+	tc.render.EXPECT().IsAvailable(gomock.Any()).Return(true).AnyTimes()
+	tc.render.EXPECT().CreateRenderingSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeSession{}, nil).AnyTimes()
+	var paths []string
+	tc.render.EXPECT().Render(gomock.Any(), rendering.RenderPNG, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ rendering.RenderType, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+			paths = append(paths, opts.Path)
+			return &rendering.RenderResult{FilePath: rendered}, nil
+		}).AnyTimes()
+
+	cmd := validCommand()
+	cmd.Recipients = Recipients{
+		Emails:   []string{"ops@example.com", "bad@example.com"},
+		Webhooks: []string{"https://example.com/hook"},
+	}
+	report, err := s.CreateReport(ctx, cmd)
+	require.NoError(t, err)
+
+	t.Run("renders each panel and records the deliveries", func(t *testing.T) {
+		tc.notifications.EmailHandlerSync = func(_ context.Context, cmd *notifications.SendEmailCommandSync) error {
+			if cmd.To[0] == "bad@example.com" {
+				return errors.New("mailbox unavailable")
+			}
+			return nil
+		}
+
+		run, err := s.SendReport(ctx, report, TriggerManual)
+		require.NoError(t, err)
+		assert.Equal(t, RunStatePartial, run.State)
+		require.Len(t, run.Deliveries, 3)
+		assert.Equal(t, "mailbox unavailable", run.Deliveries[1].Error)
+
+		require.Len(t, paths, 2)
+		assert.Contains(t, paths[0], "d-solo/dash/ops?")
+		assert.Contains(t, paths[0], "panelId=1")
+		assert.Contains(t, paths[1], "panelId=3")
+		assert.Contains(t, paths[0], "from=now-7d")
+
+		assert.Len(t, tc.notifications.EmailSync.AttachedFiles, 2)
+		assert.Equal(t, "report", tc.notifications.EmailSync.Template)
+		assert.Contains(t, tc.notifications.Webhook.Body, `"name":"ops-panel-3.png"`)
+
+		runs, err := s.GetReportHistory(ctx, 1, report.UID)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, TriggerManual, runs[0].Trigger)
+	})
+
+	t.Run("sends due reports once", func(t *testing.T) {
+		tc.notifications.EmailHandlerSync = nil
+		schedule, err := report.schedule()
+		require.NoError(t, err)
+		due := schedule.Next(time.Now())
+
+		s.sendDueReports(ctx, due.Add(-time.Minute), due)
+		s.sendDueReports(ctx, due.Add(-time.Minute), due)
+		require.Eventually(t, func() bool {
+			runs, err := s.GetReportHistory(ctx, 1, report.UID)
+			return err == nil && len(runs) == 2 && runs[0].Trigger == TriggerSchedule
+		}, 5*time.Second, 50*time.Millisecond)
+
+		// The next minute is not due.
+		s.sendDueReports(ctx, due, due.Add(time.Minute))
+		time.Sleep(100 * time.Millisecond)
+		runs, err := s.GetReportHistory(ctx, 1, report.UID)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, RunStateSuccess, runs[0].State)
+	})
+
+	t.Run("fails when the renderer is not available", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		render := rendering.NewMockService(ctrl)
+		render.EXPECT().IsAvailable(gomock.Any()).Return(false)
+		s.renderService = render
+		t.Cleanup(func() { s.renderService = tc.render })
+
+		run, err := s.SendReport(ctx, report, TriggerManual)
+		require.NoError(t, err)
+		assert.Equal(t, RunStateFailed, run.State)
+		assert.Equal(t, rendering.ErrRenderUnavailable.Error(), run.Error)
+		assert.Empty(t, run.Deliveries)
+	})
+}
+
+func TestRunState(t *testing.T) {
+	assert.Equal(t, RunStateSuccess, runState(nil))
+	assert.Equal(t, RunStateSuccess, runState([]Delivery{{Type: "email"}}))
+	assert.Equal(t, RunStatePartial, runState([]Delivery{{Type: "email"}, {Type: "webhook", Error: "timeout"}}))
+	assert.Equal(t, RunStateFailed, runState([]Delivery{{Type: "webhook", Error: "timeout"}}))
+}
+
+func TestIntegrationReportsAPIAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	tc := setupTestService(t)
+	s := tc.service
+	ctx := context.Background()
+
+	origNewByUID := guardian.NewByUID
+	t.Cleanup(func() { guardian.NewByUID = origNewByUID })
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{
+		CanViewUIDs: []string{"dash", "edit"},
+		CanSaveUIDs: []string{"edit"},
+	})
+
+	cmd := validCommand()
+	cmd.Recipients.Webhooks = []string{"https://example.com/hook?token=secret"}
+	viewable, err := s.CreateReport(ctx, cmd)
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, s.insertRun(ctx, &Run{ReportID: viewable.ID, OrgID: 1, Trigger: TriggerManual, State: RunStateSuccess, Started: now, Finished: now, Deliveries: []Delivery{
+		{Type: "email", Recipient: "ops@example.com"},
+		{Type: "webhook", Recipient: "https://example.com/hook?token=secret"},
+	}}))
+	cmd.DashboardUID = "edit"
+	editable, err := s.CreateReport(ctx, cmd)
+	require.NoError(t, err)
+	cmd.DashboardUID = "hidden"
+	hidden, err := s.CreateReport(ctx, cmd)
+	require.NoError(t, err)
+
+	request := func(uid string, usr *user.SignedInUser) *contextmodel.ReqContext {
+		req := web.SetURLParams(httptest.NewRequest(http.MethodGet, "/api/reports/"+uid, nil), map[string]string{":uid": uid})
+		return &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: usr}
+	}
+	viewer := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer}
+	owner := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleEditor}
+	admin := &user.SignedInUser{UserID: 3, OrgID: 1, OrgRole: org.RoleAdmin}
+
+	t.Run("list only returns reports on dashboards the user can view", func(t *testing.T) {
+		resp := s.listHandler(request("", viewer))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var reports []*Report
+		require.NoError(t, json.Unmarshal(resp.Body(), &reports))
+		require.Len(t, reports, 2)
+		for _, r := range reports {
+			assert.NotEqual(t, hidden.UID, r.UID)
+			if r.UID == editable.UID {
+				assert.Equal(t, cmd.Recipients.Webhooks, r.Recipients.Webhooks)
+			} else {
+				assert.Empty(t, r.Recipients.Webhooks)
+				assert.Equal(t, cmd.Recipients.Emails, r.Recipients.Emails)
+			}
+		}
+	})
+
+	t.Run("get hides reports on dashboards the user cannot view", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, s.getHandler(request(hidden.UID, owner)).Status())
+		assert.Equal(t, http.StatusNotFound, s.historyHandler(request(hidden.UID, owner)).Status())
+	})
+
+	t.Run("webhook URLs are only returned to the creator and dashboard editors", func(t *testing.T) {
+		resp := s.getHandler(request(viewable.UID, viewer))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var report Report
+		require.NoError(t, json.Unmarshal(resp.Body(), &report))
+		assert.Empty(t, report.Recipients.Webhooks)
+
+		resp = s.historyHandler(request(viewable.UID, viewer))
+		require.Equal(t, http.StatusOK, resp.Status())
+		var runs []*Run
+		require.NoError(t, json.Unmarshal(resp.Body(), &runs))
+		require.Len(t, runs, 1)
+		assert.Equal(t, []Delivery{{Type: "email", Recipient: "ops@example.com"}, {Type: "webhook"}}, runs[0].Deliveries)
+
+		resp = s.getHandler(request(viewable.UID, owner))
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NoError(t, json.Unmarshal(resp.Body(), &report))
+		assert.Equal(t, cmd.Recipients.Webhooks, report.Recipients.Webhooks)
+
+		got, err := s.GetReport(ctx, 1, viewable.UID)
+		require.NoError(t, err)
+		assert.Equal(t, cmd.Recipients.Webhooks, got.Recipients.Webhooks)
+	})
+
+	t.Run("only the creator and admins can delete and send", func(t *testing.T) {
+		editor := &user.SignedInUser{UserID: 4, OrgID: 1, OrgRole: org.RoleEditor}
+		assert.Equal(t, http.StatusForbidden, s.deleteHandler(request(viewable.UID, editor)).Status())
+		assert.Equal(t, http.StatusForbidden, s.sendHandler(request(viewable.UID, editor)).Status())
+		assert.Equal(t, http.StatusNotFound, s.deleteHandler(request(hidden.UID, admin)).Status())
+
+		assert.Equal(t, http.StatusOK, s.deleteHandler(request(editable.UID, owner)).Status())
+		assert.Equal(t, http.StatusOK, s.deleteHandler(request(viewable.UID, admin)).Status())
+	})
+}
@@ -0,0 +1,69 @@
+package reports
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// schedulerInterval is the resolution of report schedules.
+	schedulerInterval = time.Minute
+	// sendTimeout bounds the rendering and delivery of a report.
+	sendTimeout = 10 * time.Minute
+)
+
+// Run sends the enabled reports that are due, once a minute. Each run of a report is sent by
+// a single instance of Grafana, the one that gets the server lock of the report.
+func (s *ReportService) Run(ctx context.Context) error {
+	last := s.now().Truncate(schedulerInterval)
+	for {
+		next := last.Add(schedulerInterval)
+		// Ticks are aligned to the minute, so that all instances check the schedules at about the same time.
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-timer.C:
+			s.sendDueReports(ctx, last, next)
+			last = next
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// sendDueReports sends the reports that are scheduled in the interval (from, to].
+func (s *ReportService) sendDueReports(ctx context.Context, from, to time.Time) {
+	reports, err := s.listEnabledReports(ctx)
+	if err != nil {
+		s.log.Error("Failed to list reports", "error", err)
+		return
+	}
+
+	for _, report := range reports {
+		schedule, err := report.schedule()
+		if err != nil {
+			s.log.Warn("Skipping report with invalid schedule", "report", report.UID, "orgId", report.OrgID, "error", err)
+			continue
+		}
+		due := schedule.Next(from)
+		if due.After(to) {
+			continue
+		}
+
+		// The lock is held until half way to the following run, so that instances with a later tick
+		// don't send the same run again.
+		maxInterval := schedule.Next(due).Sub(due) / 2
+		go func(report *Report) {
+			err := s.serverLock.LockAndExecute(ctx, "send report "+report.UID, maxInterval, func(ctx context.Context) {
+				ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+				defer cancel()
+				if _, err := s.SendReport(ctx, report, TriggerSchedule); err != nil {
+					s.log.Error("Failed to send report", "report", report.UID, "orgId", report.OrgID, "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock report", "report", report.UID, "orgId", report.OrgID, "error", err)
+			}
+		}(report)
+	}
+}
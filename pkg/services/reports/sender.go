@@ -0,0 +1,315 @@
+package reports
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// renderTimeout is the timeout of the rendering of each file of a report.
+const renderTimeout = time.Minute
+
+// reportFile is a rendered file of a report.
+type reportFile struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// webhookPayload is the body of the requests to the webhooks of a report.
+type webhookPayload struct {
+	UID          string              `json:"uid"`
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	DashboardURL string              `json:"dashboardUrl"`
+	From         string              `json:"from,omitempty"`
+	To           string              `json:"to,omitempty"`
+	Variables    map[string][]string `json:"variables,omitempty"`
+	Files        []webhookFile       `json:"files"`
+}
+
+type webhookFile struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	// Content is the base64 encoded content of the file.
+	Content string `json:"content"`
+}
+
+func (s *ReportService) SendReport(ctx context.Context, report *Report, trigger Trigger) (*Run, error) {
+	run := &Run{
+		ReportID:   report.ID,
+		OrgID:      report.OrgID,
+		Trigger:    trigger,
+		Started:    s.now(),
+		Deliveries: []Delivery{},
+	}
+	logger := s.log.FromContext(ctx).New("report", report.UID, "orgId", report.OrgID, "trigger", trigger)
+
+	dashboard, files, err := s.renderReport(ctx, report)
+	if err != nil {
+		logger.Error("Failed to render report", "error", err)
+		run.State = RunStateFailed
+		run.Error = err.Error()
+	} else {
+		run.Deliveries = s.deliverReport(ctx, report, dashboard, files)
+		run.State = runState(run.Deliveries)
+		logger.Info("Sent report", "state", run.State, "files", len(files))
+	}
+	run.Finished = s.now()
+
+	if err := s.insertRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to store report run: %w", err)
+	}
+	return run, nil
+}
+
+func runState(deliveries []Delivery) RunState {
+	failed := 0
+	for _, d := range deliveries {
+		if d.Error != "" {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return RunStateSuccess
+	case failed < len(deliveries):
+		return RunStatePartial
+	default:
+		return RunStateFailed
+	}
+}
+
+// renderReport renders the files of the report as the user who created it.
+func (s *ReportService) renderReport(ctx context.Context, report *Report) (*dashboards.Dashboard, []reportFile, error) {
+	if !s.renderService.IsAvailable(ctx) {
+		return nil, nil, rendering.ErrRenderUnavailable
+	}
+
+	u, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: report.CreatedBy, OrgID: report.OrgID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the creator of the report: %w", err)
+	}
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: report.DashboardUID, OrgID: report.OrgID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authOpts := rendering.AuthOpts{OrgID: report.OrgID, UserID: u.UserID, OrgRole: u.OrgRole}
+	session, err := s.renderService.CreateRenderingSession(ctx, authOpts, rendering.SessionOpts{Expiry: sendTimeout, RefreshExpiryOnEachRequest: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer session.Dispose(ctx)
+
+	params := reportParams(report)
+	timeoutOpts := rendering.TimeoutOpts{Timeout: renderTimeout}
+	errorOpts := rendering.ErrorOpts{ErrorConcurrentLimitReached: true, ErrorRenderUnavailable: true}
+
+	if report.Format == FormatPDF {
+		u := url.URL{Path: path.Join("d", dashboard.UID, dashboard.Slug), RawQuery: params.Encode()}
+		result, err := s.renderService.Render(ctx, rendering.RenderPDF, rendering.Opts{
+			AuthOpts:    authOpts,
+			ErrorOpts:   errorOpts,
+			TimeoutOpts: timeoutOpts,
+			Width:       s.cfg.RendererDefaultImageWidth,
+			// A height of -1 renders the full height of the dashboard.
+			Height:            -1,
+			DeviceScaleFactor: s.cfg.RendererDefaultImageScale,
+			Timezone:          report.Timezone,
+			ConcurrentLimit:   s.cfg.RendererConcurrentRequestLimit,
+			Path:              u.String(),
+		}, session)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to render pdf: %w", err)
+		}
+		file, err := readReportFile(result.FilePath, dashboard.Slug+".pdf", "application/pdf")
+		if err != nil {
+			return nil, nil, err
+		}
+		return dashboard, []reportFile{file}, nil
+	}
+
+	panels := reportPanels(dashboard.Data)
+	if len(panels) == 0 {
+		return nil, nil, ErrReportNoPanels.Errorf("dashboard %s has no panels", dashboard.UID)
+	}
+	files := make([]reportFile, 0, len(panels))
+	for _, panelID := range panels {
+		panelParams := url.Values{}
+		for k, v := range params {
+			panelParams[k] = v
+		}
+		panelParams.Set("panelId", strconv.FormatInt(panelID, 10))
+		u := url.URL{Path: path.Join("d-solo", dashboard.UID, dashboard.Slug), RawQuery: panelParams.Encode()}
+		name := fmt.Sprintf("%s-panel-%d.%s", dashboard.Slug, panelID, report.Format)
+
+		var filePath, contentType string
+		if report.Format == FormatCSV {
+			result, err := s.renderService.RenderCSV(ctx, rendering.CSVOpts{
+				AuthOpts:        authOpts,
+				TimeoutOpts:     timeoutOpts,
+				Timezone:        report.Timezone,
+				ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+				Path:            u.String(),
+			}, session)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to render csv of panel %d: %w", panelID, err)
+			}
+			filePath, contentType = result.FilePath, "text/csv"
+		} else {
+			result, err := s.renderService.Render(ctx, rendering.RenderPNG, rendering.Opts{
+				AuthOpts:          authOpts,
+				ErrorOpts:         errorOpts,
+				TimeoutOpts:       timeoutOpts,
+				Width:             s.cfg.RendererDefaultImageWidth,
+				Height:            s.cfg.RendererDefaultImageHeight,
+				DeviceScaleFactor: s.cfg.RendererDefaultImageScale,
+				Timezone:          report.Timezone,
+				ConcurrentLimit:   s.cfg.RendererConcurrentRequestLimit,
+				Path:              u.String(),
+			}, session)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to render panel %d: %w", panelID, err)
+			}
+			filePath, contentType = result.FilePath, "image/png"
+		}
+
+		file, err := readReportFile(filePath, name, contentType)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, file)
+	}
+	return dashboard, files, nil
+}
+
+// reportParams returns the query parameters of the time range and variables of the report.
+func reportParams(report *Report) url.Values {
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(report.OrgID, 10))
+	if report.From != "" && report.To != "" {
+		params.Set("from", report.From)
+		params.Set("to", report.To)
+	}
+	if report.Timezone != "" {
+		params.Set("timezone", report.Timezone)
+	}
+	for name, values := range report.Variables {
+		params["var-"+name] = values
+	}
+	return params
+}
+
+// reportPanels returns the IDs of the panels of the dashboard, including the panels of collapsed rows.
+func reportPanels(data *simplejson.Json) []int64 {
+	var ids []int64
+	var walk func(panels []any)
+	walk = func(panels []any) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			if panel.Get("type").MustString() == "row" {
+				walk(panel.Get("panels").MustArray())
+				continue
+			}
+			if id, err := panel.Get("id").Int64(); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+	walk(data.Get("panels").MustArray())
+	return ids
+}
+
+func readReportFile(filePath, name, contentType string) (reportFile, error) {
+	// #nosec G304 -- the file path is returned by the rendering service
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return reportFile{}, fmt.Errorf("failed to read rendered file: %w", err)
+	}
+	return reportFile{Name: name, ContentType: contentType, Content: content}, nil
+}
+
+// deliverReport sends the files to each recipient of the report, and returns the result of each delivery.
+func (s *ReportService) deliverReport(ctx context.Context, report *Report, dashboard *dashboards.Dashboard, files []reportFile) []Delivery {
+	deliveries := make([]Delivery, 0, len(report.Recipients.Emails)+len(report.Recipients.Webhooks))
+	dashboardURL := dashboards.GetFullDashboardURL(dashboard.UID, dashboard.Slug)
+
+	attachments := make([]*notifications.SendEmailAttachFile, 0, len(files))
+	for _, f := range files {
+		attachments = append(attachments, &notifications.SendEmailAttachFile{Name: f.Name, Content: f.Content})
+	}
+	timeRange := "the time range of the dashboard"
+	if report.From != "" {
+		timeRange = report.From + " to " + report.To
+	}
+	for _, email := range report.Recipients.Emails {
+		err := s.notificationService.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+			SendEmailCommand: notifications.SendEmailCommand{
+				To:       []string{email},
+				Template: "report",
+				Subject:  report.Name,
+				Data: map[string]any{
+					"ReportName":     report.Name,
+					"DashboardTitle": dashboard.Title,
+					"DashboardURL":   dashboardURL,
+					"TimeRange":      timeRange,
+				},
+				AttachedFiles: attachments,
+			},
+		})
+		deliveries = append(deliveries, newDelivery("email", email, err))
+	}
+
+	if len(report.Recipients.Webhooks) == 0 {
+		return deliveries
+	}
+	payload := webhookPayload{
+		UID:          report.UID,
+		Name:         report.Name,
+		DashboardUID: report.DashboardUID,
+		DashboardURL: dashboardURL,
+		From:         report.From,
+		To:           report.To,
+		Variables:    report.Variables,
+		Files:        make([]webhookFile, 0, len(files)),
+	}
+	for _, f := range files {
+		payload.Files = append(payload.Files, webhookFile{Name: f.Name, ContentType: f.ContentType, Content: base64.StdEncoding.EncodeToString(f.Content)})
+	}
+	body, err := json.Marshal(payload)
+	for _, webhook := range report.Recipients.Webhooks {
+		if err != nil {
+			deliveries = append(deliveries, newDelivery("webhook", webhook, err))
+			continue
+		}
+		sendErr := s.notificationService.SendWebhookSync(ctx, &notifications.SendWebhookSync{
+			Url:         webhook,
+			Body:        string(body),
+			HttpMethod:  "POST",
+			ContentType: "application/json",
+		})
+		deliveries = append(deliveries, newDelivery("webhook", webhook, sendErr))
+	}
+	return deliveries
+}
+
+func newDelivery(deliveryType, recipient string, err error) Delivery {
+	d := Delivery{Type: deliveryType, Recipient: recipient}
+	if err != nil {
+		d.Error = err.Error()
+	}
+	return d
+}
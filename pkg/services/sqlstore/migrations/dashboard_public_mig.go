@@ -96,4 +96,11 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add time_selection_max_range column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "time_selection_max_range",
+		Type:     DB_NVarchar,
+		Length:   32,
+		Nullable: true,
+	}))
 }
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addReportsMigrations(mg *Migrator) {
+	reportV1 := Table{
+		Name: "report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 64, Nullable: true},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "schedule", Type: DB_NVarchar, Length: 128, Nullable: false},
+			{Name: "format", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create report table v1", NewAddTableMigration(reportV1))
+	mg.AddMigration("add unique index report.org_id-uid", NewAddIndexMigration(reportV1, reportV1.Indices[0]))
+	mg.AddMigration("add index report.org_id-dashboard_uid", NewAddIndexMigration(reportV1, reportV1.Indices[1]))
+
+	reportRunV1 := Table{
+		Name: "report_run",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "report_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "triggered_by", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "state", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "deliveries", Type: DB_Text, Nullable: true},
+			{Name: "started", Type: DB_DateTime, Nullable: false},
+			{Name: "finished", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"report_id", "started"}},
+		},
+	}
+
+	mg.AddMigration("create report_run table v1", NewAddTableMigration(reportRunV1))
+	mg.AddMigration("add index report_run.report_id-started", NewAddIndexMigration(reportRunV1, reportRunV1.Indices[0]))
+}
@@ -193,6 +193,10 @@ func alertInstanceMigration(mg *migrator.Migrator) {
 	mg.AddMigration("add result_fingerprint column to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
 		Name: "result_fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: true,
 	}))
+
+	mg.AddMigration("add acknowledgement column to alert_instance", migrator.NewAddColumnMigration(alertInstance, &migrator.Column{
+		Name: "acknowledgement", Type: migrator.DB_Text, Nullable: true,
+	}))
 }
 
 func addAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64) {
@@ -153,6 +153,7 @@ type Cfg struct {
 	RendererDefaultImageWidth      int
 	RendererDefaultImageHeight     int
 	RendererDefaultImageScale      float64
+	RendererNativeEnabled          bool
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -283,6 +284,9 @@ type Cfg struct {
 	DataProxyRowLimit              int64
 	DataProxyUserAgent             string
 
+	// QueryLimits are the limits of query results
+	QueryLimits QueryLimitsSettings
+
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
 
@@ -336,6 +340,8 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	// SqliteAllowedDirectories are the directories SQLite data sources can open database files from.
+	SqliteAllowedDirectories []string
 
 	// Snapshots
 	SnapshotEnabled      bool
@@ -424,6 +430,10 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveHistoryMaxPoints and LiveHistoryMaxAge bound the history of managed
+	// stream channels. History is disabled when both are zero.
+	LiveHistoryMaxPoints int
+	LiveHistoryMaxAge    time.Duration
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -442,6 +452,10 @@ type Cfg struct {
 	// Query history
 	QueryHistoryEnabled bool
 
+	// Dashboard reports
+	ReportsEnabled      bool
+	ReportsHistoryLimit int
+
 	Storage StorageSettings
 
 	Search SearchSettings
@@ -490,6 +504,11 @@ type Cfg struct {
 
 	// Public dashboards
 	PublicDashboardsEnabled bool
+	// PublicDashboardsQueryRateLimit is the number of queries per second allowed for all viewers of a public dashboard, 0 means unlimited
+	PublicDashboardsQueryRateLimit float64
+	// PublicDashboardsQueryRateLimitPerIP is the number of queries per second allowed for each viewer IP of a public dashboard, 0 means unlimited
+	PublicDashboardsQueryRateLimitPerIP float64
+	PublicDashboardsQueryRateLimitBurst int
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
@@ -1076,6 +1095,10 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 		return err
 	}
 
+	if err := readQueryLimitsSettings(iniFile, cfg); err != nil {
+		return err
+	}
+
 	if err := readSecuritySettings(iniFile, cfg); err != nil {
 		return err
 	}
@@ -1164,6 +1187,10 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	queryHistory := iniFile.Section("query_history")
 	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(true)
 
+	reports := iniFile.Section("reports")
+	cfg.ReportsEnabled = reports.Key("enabled").MustBool(true)
+	cfg.ReportsHistoryLimit = reports.Key("history_limit").MustInt(100)
+
 	shortLinks := iniFile.Section("short_links")
 	cfg.ShortLinkExpiration = shortLinks.Key("expire_time").MustInt(7)
 
@@ -1734,6 +1761,7 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 	cfg.RendererDefaultImageWidth = renderSec.Key("default_image_width").MustInt(1000)
 	cfg.RendererDefaultImageHeight = renderSec.Key("default_image_height").MustInt(500)
 	cfg.RendererDefaultImageScale = renderSec.Key("default_image_scale").MustFloat64(1)
+	cfg.RendererNativeEnabled = renderSec.Key("native_renderer_enabled").MustBool(false)
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
 	cfg.PDFsDir = filepath.Join(cfg.DataPath, "pdf")
@@ -1949,6 +1977,7 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	cfg.SqliteAllowedDirectories = util.SplitString(sqlDatasources.Key("sqlite_allowed_directories").String())
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1978,6 +2007,14 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveHistoryMaxPoints = section.Key("history_max_points").MustInt(0)
+	if cfg.LiveHistoryMaxPoints < 0 {
+		return fmt.Errorf("unexpected value %d for [live] history_max_points", cfg.LiveHistoryMaxPoints)
+	}
+	cfg.LiveHistoryMaxAge = section.Key("history_max_age").MustDuration(0)
+	if cfg.LiveHistoryMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] history_max_age", cfg.LiveHistoryMaxAge)
+	}
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -1999,4 +2036,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+	cfg.PublicDashboardsQueryRateLimit = publicDashboards.Key("query_rate_limit").MustFloat64(0)
+	cfg.PublicDashboardsQueryRateLimitPerIP = publicDashboards.Key("query_rate_limit_per_ip").MustFloat64(0)
+	cfg.PublicDashboardsQueryRateLimitBurst = publicDashboards.Key("query_rate_limit_burst").MustInt(50)
 }
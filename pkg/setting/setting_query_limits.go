@@ -0,0 +1,75 @@
+package setting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+const queryLimitsOrgSectionPrefix = "query_limits.org."
+
+// QueryLimits are limits of the result of a single query. Zero means no limit.
+type QueryLimits struct {
+	// MaxSeries and MaxPoints are sent to plugins, which can stop reading results that exceed
+	// them, and are checked again after the result was built.
+	MaxSeries int64
+	MaxPoints int64
+	// MaxResponseBytes also stops reading HTTP responses of data sources to core plugins.
+	MaxResponseBytes int64
+}
+
+// QueryLimitsSettings are the limits of the [query_limits] section, and the limits of organizations
+// from the [query_limits.org.<org id>] sections.
+type QueryLimitsSettings struct {
+	QueryLimits
+	Orgs map[int64]QueryLimits
+}
+
+// ForOrg returns the limits of an organization.
+func (s QueryLimitsSettings) ForOrg(orgID int64) QueryLimits {
+	if limits, ok := s.Orgs[orgID]; ok {
+		return limits
+	}
+	return s.QueryLimits
+}
+
+func readQueryLimitsSettings(iniFile *ini.File, cfg *Cfg) error {
+	defaults, err := readQueryLimits(iniFile.Section("query_limits"), QueryLimits{})
+	if err != nil {
+		return err
+	}
+
+	cfg.QueryLimits = QueryLimitsSettings{QueryLimits: defaults, Orgs: map[int64]QueryLimits{}}
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), queryLimitsOrgSectionPrefix) {
+			continue
+		}
+
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(section.Name(), queryLimitsOrgSectionPrefix), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID in section [%s]", section.Name())
+		}
+		// organization limits which are not set are the ones of the [query_limits] section
+		limits, err := readQueryLimits(section, defaults)
+		if err != nil {
+			return err
+		}
+		cfg.QueryLimits.Orgs[orgID] = limits
+	}
+
+	return nil
+}
+
+func readQueryLimits(section *ini.Section, defaults QueryLimits) (QueryLimits, error) {
+	limits := QueryLimits{
+		MaxSeries:        section.Key("max_series").MustInt64(defaults.MaxSeries),
+		MaxPoints:        section.Key("max_points").MustInt64(defaults.MaxPoints),
+		MaxResponseBytes: section.Key("max_response_bytes").MustInt64(defaults.MaxResponseBytes),
+	}
+	if limits.MaxSeries < 0 || limits.MaxPoints < 0 || limits.MaxResponseBytes < 0 {
+		return QueryLimits{}, fmt.Errorf("query limits in section [%s] can't be negative", section.Name())
+	}
+	return limits, nil
+}
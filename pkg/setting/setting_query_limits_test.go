@@ -0,0 +1,42 @@
+package setting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestReadQueryLimitsSettings(t *testing.T) {
+	t.Run("no limits by default", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, readQueryLimitsSettings(ini.Empty(), cfg))
+		require.Equal(t, QueryLimits{}, cfg.QueryLimits.ForOrg(1))
+	})
+
+	t.Run("organization limits override the default limits", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[query_limits]
+max_series = 1000
+max_points = 100000
+
+[query_limits.org.2]
+max_series = 5000
+max_response_bytes = 1048576
+`))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, readQueryLimitsSettings(f, cfg))
+		require.Equal(t, QueryLimits{MaxSeries: 1000, MaxPoints: 100000}, cfg.QueryLimits.ForOrg(1))
+		require.Equal(t, QueryLimits{MaxSeries: 5000, MaxPoints: 100000, MaxResponseBytes: 1048576}, cfg.QueryLimits.ForOrg(2))
+	})
+
+	t.Run("invalid sections", func(t *testing.T) {
+		for _, content := range []string{"[query_limits.org.main]\nmax_series = 1", "[query_limits]\nmax_points = -1"} {
+			f, err := ini.Load([]byte(content))
+			require.NoError(t, err)
+			require.Error(t, readQueryLimitsSettings(f, NewCfg()))
+		}
+	})
+}
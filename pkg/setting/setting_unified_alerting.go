@@ -103,6 +103,9 @@ type UnifiedAlertingSettings struct {
 
 	// Retention period for Alertmanager notification log entries.
 	NotificationLogRetention time.Duration
+
+	// ContactPointHealthChecksEnabled allows integrations to send periodic health checks to their contact points.
+	ContactPointHealthChecksEnabled bool
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -387,6 +390,8 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		return err
 	}
 
+	uaCfg.ContactPointHealthChecksEnabled = ua.Key("contact_point_health_checks_enabled").MustBool(false)
+
 	cfg.UnifiedAlerting = uaCfg
 	return nil
 }
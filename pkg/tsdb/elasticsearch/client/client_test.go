@@ -3,6 +3,7 @@ package es
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -287,6 +288,83 @@ func TestClient_Index(t *testing.T) {
 	}
 }
 
+func TestClient_ExecuteColumnarQuery(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+	var responseStatus int
+	var responseBody string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		request = r
+		buf, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requestBody = buf
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(responseStatus)
+		_, err = rw.Write([]byte(responseBody))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:        ts.URL,
+		HTTPClient: ts.Client(),
+		Database:   "logs",
+		ConfiguredFields: ConfiguredFields{
+			TimeField: "@timestamp",
+		},
+	}
+	c, err := NewClient(context.Background(), &ds, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+
+	t.Run("ES|QL", func(t *testing.T) {
+		responseStatus = http.StatusOK
+		responseBody = `{"columns": [{"name": "count", "type": "long"}], "values": [[9007199254740993]]}`
+
+		res, err := c.ExecuteESQL(&ESQLRequest{
+			Query:  "FROM logs | STATS count = COUNT(*)",
+			Filter: &RangeFilter{Key: "@timestamp", Gte: 5, Lte: 10, Format: DateFormatEpochMS},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/_query", request.URL.Path)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"query": "FROM logs | STATS count = COUNT(*)",
+			"filter": {"range": {"@timestamp": {"gte": 5, "lte": 10, "format": "epoch_millis"}}}
+		}`, string(requestBody))
+
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.Equal(t, []ColumnarResponseColumn{{Name: "count", Type: "long"}}, res.Columns)
+		assert.Equal(t, [][]any{{json.Number("9007199254740993")}}, res.Values)
+	})
+
+	t.Run("PPL", func(t *testing.T) {
+		responseStatus = http.StatusOK
+		responseBody = `{"schema": [{"name": "host", "type": "string"}], "datarows": [["a"]], "total": 1, "size": 1}`
+
+		res, err := c.ExecutePPL(&PPLRequest{Query: "source=logs | fields host"})
+		require.NoError(t, err)
+
+		assert.Equal(t, "/_plugins/_ppl", request.URL.Path)
+		assert.JSONEq(t, `{"query": "source=logs | fields host"}`, string(requestBody))
+		assert.Equal(t, []ColumnarResponseColumn{{Name: "host", Type: "string"}}, res.Columns)
+		assert.Equal(t, [][]any{{"a"}}, res.Values)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		responseStatus = http.StatusBadRequest
+		responseBody = `{"error": {"reason": "Invalid Query", "details": "unknown field", "type": "SemanticCheckException"}, "status": 400}`
+
+		res, err := c.ExecutePPL(&PPLRequest{Query: "source=logs | fields hots"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+		assert.Equal(t, "Invalid Query", res.Error["reason"])
+	})
+}
+
 func createMultisearchForTest(t *testing.T, c Client, timeRange backend.TimeRange) (*MultiSearchRequest, error) {
 	t.Helper()
 
@@ -1,6 +1,7 @@
 package es
 
 import (
+	"bytes"
 	"encoding/json"
 	"time"
 
@@ -64,6 +65,65 @@ type MultiSearchResponse struct {
 	Responses []*SearchResponse `json:"responses"`
 }
 
+// ESQLRequest represents an ES|QL query request
+type ESQLRequest struct {
+	Query  string `json:"query"`
+	Filter Filter `json:"filter,omitempty"`
+}
+
+// PPLRequest represents a PPL query request
+type PPLRequest struct {
+	Query string `json:"query"`
+}
+
+// ColumnarResponseColumn represents a column of an ES|QL or PPL response
+type ColumnarResponseColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ColumnarResponse represents the response of an ES|QL or PPL query. ES|QL responses
+// contain columns and values, PPL responses contain a schema and data rows.
+type ColumnarResponse struct {
+	Status  int                      `json:"status,omitempty"`
+	Error   map[string]interface{}   `json:"error"`
+	Columns []ColumnarResponseColumn `json:"columns"`
+	Values  [][]interface{}          `json:"values"`
+}
+
+// UnmarshalJSON decodes ES|QL and PPL responses into the columns and values of the response.
+func (r *ColumnarResponse) UnmarshalJSON(b []byte) error {
+	var res struct {
+		Error    json.RawMessage          `json:"error"`
+		Columns  []ColumnarResponseColumn `json:"columns"`
+		Values   [][]interface{}          `json:"values"`
+		Schema   []ColumnarResponseColumn `json:"schema"`
+		Datarows [][]interface{}          `json:"datarows"`
+	}
+	// keep the precision of long values until they are converted
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&res); err != nil {
+		return err
+	}
+
+	r.Columns, r.Values = res.Columns, res.Values
+	if len(res.Schema) > 0 {
+		r.Columns, r.Values = res.Schema, res.Datarows
+	}
+
+	if len(res.Error) > 0 && string(res.Error) != "null" {
+		// errors are usually objects, but can also be plain strings
+		var reason string
+		if err := json.Unmarshal(res.Error, &reason); err == nil {
+			r.Error = map[string]interface{}{"reason": reason}
+		} else if err := json.Unmarshal(res.Error, &r.Error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Query represents a query
 type Query struct {
 	Bool *BoolQuery `json:"bool"`
@@ -29,6 +29,8 @@ type elasticsearchDataQuery struct {
 	ctx                  context.Context
 	tracer               tracing.Tracer
 	keepLabelsInResponse bool
+	// headers are the headers of the request, with the limits of series and points of the results.
+	headers map[string]string
 }
 
 var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, req *backend.QueryDataRequest, logger log.Logger, tracer tracing.Tracer) *elasticsearchDataQuery {
@@ -44,6 +46,7 @@ var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, req
 		// To maintain backward compatibility, it is necessary to keep labels in responses for alerting and expressions queries.
 		// Historically, these labels have been used in alerting rules and transformations.
 		keepLabelsInResponse: fromAlert || fromExpression,
+		headers:              req.Headers,
 	}
 }
 
@@ -58,6 +61,24 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
+	// ES|QL and PPL queries are not part of the multi search request, they are run separately
+	languageResponses := backend.Responses{}
+	dslQueries := make([]*Query, 0, len(queries))
+	for _, q := range queries {
+		if isQueryLanguage(q.QueryType) {
+			languageResponses[q.RefID] = e.executeQueryLanguageQuery(q)
+			continue
+		}
+		dslQueries = append(dslQueries, q)
+	}
+	for refID, res := range languageResponses {
+		response.Responses[refID] = res
+	}
+	if len(dslQueries) == 0 {
+		return response, nil
+	}
+	queries = dslQueries
+
 	ms := e.client.MultiSearch()
 
 	for _, q := range queries {
@@ -84,7 +105,14 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.headers, e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	for refID, res := range languageResponses {
+		result.Responses[refID] = res
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
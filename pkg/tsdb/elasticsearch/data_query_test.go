@@ -1806,6 +1806,10 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	columnarResponse    *es.ColumnarResponse
+	columnarError       error
+	esqlRequests        []*es.ESQLRequest
+	pplRequests         []*es.PPLRequest
 }
 
 func newFakeClient() *fakeClient {
@@ -1819,6 +1823,7 @@ func newFakeClient() *fakeClient {
 		configuredFields:    configuredFields,
 		multisearchRequests: make([]*es.MultiSearchRequest, 0),
 		multiSearchResponse: &es.MultiSearchResponse{},
+		columnarResponse:    &es.ColumnarResponse{},
 	}
 }
 
@@ -1831,6 +1836,16 @@ func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSear
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteESQL(r *es.ESQLRequest) (*es.ColumnarResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.columnarResponse, c.columnarError
+}
+
+func (c *fakeClient) ExecutePPL(r *es.PPLRequest) (*es.ColumnarResponse, error) {
+	c.pplRequests = append(c.pplRequests, r)
+	return c.columnarResponse, c.columnarError
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
@@ -19,6 +19,20 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 		// please do not create a new field with that name, to avoid potential problems with old, persisted queries.
 
 		rawQuery := model.Get("query").MustString()
+		queryType := model.Get("queryType").MustString()
+		if isQueryLanguage(queryType) {
+			// ES|QL and PPL queries have no aggregations
+			queries = append(queries, &Query{
+				RawQuery:      rawQuery,
+				QueryType:     queryType,
+				Interval:      q.Interval,
+				RefID:         q.RefID,
+				MaxDataPoints: q.MaxDataPoints,
+				TimeRange:     q.TimeRange,
+			})
+			continue
+		}
+
 		bucketAggs, err := parseBucketAggs(model)
 		if err != nil {
 			logger.Error("Failed to parse bucket aggs in query", "error", err, "model", string(q.JSON))
@@ -35,6 +49,7 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 
 		queries = append(queries, &Query{
 			RawQuery:      rawQuery,
+			QueryType:     queryType,
 			BucketAggs:    bucketAggs,
 			Metrics:       metrics,
 			Alias:         alias,
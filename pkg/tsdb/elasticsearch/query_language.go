@@ -0,0 +1,333 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// queryTypeESQL is the query type of ES|QL queries
+	queryTypeESQL = "esql"
+	// queryTypePPL is the query type of PPL queries of OpenSearch
+	queryTypePPL = "ppl"
+)
+
+// Layouts of date values in ES|QL and PPL responses
+var columnarTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func isQueryLanguage(queryType string) bool {
+	return queryType == queryTypeESQL || queryType == queryTypePPL
+}
+
+// executeQueryLanguageQuery runs an ES|QL or PPL query, limited to the time range of the query.
+func (e *elasticsearchDataQuery) executeQueryLanguageQuery(q *Query) backend.DataResponse {
+	if strings.TrimSpace(q.RawQuery) == "" {
+		return errorsource.Response(errorsource.PluginError(fmt.Errorf("%s query is required", strings.ToUpper(q.QueryType)), false))
+	}
+
+	start := time.Now()
+	timeField := e.client.GetConfiguredFields().TimeField
+	var res *es.ColumnarResponse
+	var err error
+	if q.QueryType == queryTypeESQL {
+		res, err = e.client.ExecuteESQL(&es.ESQLRequest{
+			Query: q.RawQuery,
+			Filter: &es.RangeFilter{
+				Key:    timeField,
+				Gte:    q.TimeRange.From.UnixMilli(),
+				Lte:    q.TimeRange.To.UnixMilli(),
+				Format: es.DateFormatEpochMS,
+			},
+		})
+	} else {
+		res, err = e.client.ExecutePPL(&es.PPLRequest{
+			Query: pplQueryWithTimeFilter(q.RawQuery, timeField, q.TimeRange),
+		})
+	}
+	if err != nil {
+		// We are returning error containing the source that was added trough errorsource.Middleware
+		return errorsource.Response(err)
+	}
+
+	if res.Error != nil || res.Status >= 400 {
+		reason := getErrorFromElasticResponse(&es.SearchResponse{Error: res.Error})
+		e.logger.Error("Processing error response from Elasticsearch", "error", reason, "queryType", q.QueryType, "statusCode", res.Status)
+		status := backend.Status(res.Status)
+		return errorsource.Response(errorsource.New(errors.New(reason), errorsource.FromStatus(status), status))
+	}
+
+	frames := parseColumnarResponse(res, q, timeField)
+	e.logger.Debug("Processed response from Elasticsearch", "queryType", q.QueryType, "rows", len(res.Values), "duration", time.Since(start), "stage", es.StageParseResponse)
+	return backend.DataResponse{Frames: frames}
+}
+
+// pplQueryWithTimeFilter adds a where command with the time range to the PPL query,
+// directly after the source command, so that later commands only see documents in
+// the time range.
+func pplQueryWithTimeFilter(query, timeField string, timeRange backend.TimeRange) string {
+	const layout = "2006-01-02 15:04:05.000"
+	filter := fmt.Sprintf("where `%s` >= '%s' and `%s` <= '%s'",
+		timeField, timeRange.From.UTC().Format(layout), timeField, timeRange.To.UTC().Format(layout))
+
+	query = strings.TrimSpace(query)
+	idx := pplPipeIndex(query)
+	if idx < 0 {
+		return query + " | " + filter
+	}
+	return strings.TrimSpace(query[:idx]) + " | " + filter + " | " + strings.TrimSpace(query[idx+1:])
+}
+
+// pplPipeIndex returns the index of the first pipe of the query outside of quotes, or -1.
+func pplPipeIndex(query string) int {
+	var quote rune
+	for i, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '|':
+			return i
+		}
+	}
+	return -1
+}
+
+type columnarKind int
+
+const (
+	columnarString columnarKind = iota
+	columnarNumber
+	columnarTime
+	columnarBool
+)
+
+func columnarKindOf(columnType string) columnarKind {
+	switch strings.ToLower(columnType) {
+	case "byte", "short", "integer", "long", "unsigned_long", "float", "half_float", "scaled_float", "double",
+		"counter_integer", "counter_long", "counter_double":
+		return columnarNumber
+	case "date", "date_nanos", "datetime", "timestamp":
+		return columnarTime
+	case "boolean":
+		return columnarBool
+	default:
+		return columnarString
+	}
+}
+
+// parseColumnarResponse converts the columns of an ES|QL or PPL response to data frames. Responses
+// with a time column and numeric columns are returned as time series, with the other columns as
+// labels. Any other response is returned as a table.
+func parseColumnarResponse(res *es.ColumnarResponse, q *Query, timeField string) data.Frames {
+	kinds := make([]columnarKind, len(res.Columns))
+	fields := make([]*data.Field, len(res.Columns))
+	for i, column := range res.Columns {
+		kinds[i] = columnarKindOf(column.Type)
+		fields[i] = newColumnarField(column.Name, kinds[i], len(res.Values))
+	}
+
+	for row, values := range res.Values {
+		for i, field := range fields {
+			var value any
+			if i < len(values) {
+				value = values[i]
+			}
+			field.Set(row, columnarValue(kinds[i], value))
+		}
+	}
+
+	if timeIdx := columnarTimeSeriesIndex(res.Columns, kinds, timeField); timeIdx >= 0 && len(res.Values) > 0 {
+		return columnarTimeSeriesFrames(fields, kinds, timeIdx, q)
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.RefID = q.RefID
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+		ExecutedQueryString:    q.RawQuery,
+	}
+	return data.Frames{frame}
+}
+
+func newColumnarField(name string, kind columnarKind, length int) *data.Field {
+	switch kind {
+	case columnarNumber:
+		return data.NewField(name, nil, make([]*float64, length))
+	case columnarTime:
+		return data.NewField(name, nil, make([]*time.Time, length))
+	case columnarBool:
+		return data.NewField(name, nil, make([]*bool, length))
+	default:
+		return data.NewField(name, nil, make([]*string, length))
+	}
+}
+
+// columnarValue converts a value of the response to a value of a field of the kind of the column.
+// Values which can't be converted are null.
+func columnarValue(kind columnarKind, value any) any {
+	if value == nil {
+		return nil
+	}
+
+	switch kind {
+	case columnarNumber:
+		var f float64
+		var err error
+		switch v := value.(type) {
+		case json.Number:
+			f, err = v.Float64()
+		case string:
+			f, err = strconv.ParseFloat(v, 64)
+		default:
+			return nil
+		}
+		if err != nil {
+			return nil
+		}
+		return &f
+	case columnarTime:
+		switch v := value.(type) {
+		case string:
+			for _, layout := range columnarTimeLayouts {
+				if t, err := time.Parse(layout, v); err == nil {
+					return &t
+				}
+			}
+		case json.Number:
+			if ms, err := v.Int64(); err == nil {
+				t := time.UnixMilli(ms).UTC()
+				return &t
+			}
+		}
+		return nil
+	case columnarBool:
+		if b, ok := value.(bool); ok {
+			return &b
+		}
+		return nil
+	default:
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case json.Number:
+			s = v.String()
+		default:
+			// multi-valued fields and objects
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil
+			}
+			s = string(b)
+		}
+		return &s
+	}
+}
+
+// columnarTimeSeriesIndex returns the index of the time column if the response is a time series,
+// or -1. The configured time field is preferred if there are several time columns.
+func columnarTimeSeriesIndex(columns []es.ColumnarResponseColumn, kinds []columnarKind, timeField string) int {
+	timeIdx := -1
+	hasNumber := false
+	for i, kind := range kinds {
+		switch kind {
+		case columnarTime:
+			if timeIdx < 0 || columns[i].Name == timeField {
+				timeIdx = i
+			}
+		case columnarNumber:
+			hasNumber = true
+		}
+	}
+	if !hasNumber {
+		return -1
+	}
+	return timeIdx
+}
+
+type columnarSeries struct {
+	labels data.Labels
+	name   string
+	times  []time.Time
+	values []*float64
+}
+
+// columnarTimeSeriesFrames returns a frame for each numeric column and set of values of the other
+// columns, which are used as labels. Time columns other than the time of the series are ignored.
+func columnarTimeSeriesFrames(fields []*data.Field, kinds []columnarKind, timeIdx int, q *Query) data.Frames {
+	var series []*columnarSeries
+	index := map[string]*columnarSeries{}
+
+	for row := 0; row < fields[timeIdx].Len(); row++ {
+		t, ok := fields[timeIdx].ConcreteAt(row)
+		if !ok {
+			continue
+		}
+
+		labels := data.Labels{}
+		for i, field := range fields {
+			if kinds[i] != columnarString && kinds[i] != columnarBool {
+				continue
+			}
+			if v, ok := field.ConcreteAt(row); ok {
+				labels[field.Name] = fmt.Sprint(v)
+			}
+		}
+
+		for i, field := range fields {
+			if kinds[i] != columnarNumber {
+				continue
+			}
+			key := field.Name + "\x00" + labels.String()
+			s, ok := index[key]
+			if !ok {
+				s = &columnarSeries{labels: labels, name: field.Name}
+				index[key] = s
+				series = append(series, s)
+			}
+			s.times = append(s.times, t.(time.Time))
+			s.values = append(s.values, field.At(row).(*float64))
+		}
+	}
+
+	frames := make(data.Frames, 0, len(series))
+	for _, s := range series {
+		sort.Stable(s)
+		frame := data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, s.times),
+			data.NewField(s.name, s.labels, s.values),
+		)
+		frame.RefID = q.RefID
+		frame.Meta = &data.FrameMeta{
+			Type:                data.FrameTypeTimeSeriesMulti,
+			ExecutedQueryString: q.RawQuery,
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func (s *columnarSeries) Len() int           { return len(s.times) }
+func (s *columnarSeries) Less(i, j int) bool { return s.times[i].Before(s.times[j]) }
+func (s *columnarSeries) Swap(i, j int) {
+	s.times[i], s.times[j] = s.times[j], s.times[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
@@ -0,0 +1,217 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func newColumnarResponse(t *testing.T, body string) *es.ColumnarResponse {
+	t.Helper()
+	res := &es.ColumnarResponse{}
+	require.NoError(t, json.Unmarshal([]byte(body), res))
+	return res
+}
+
+func TestExecuteQueryLanguageQuery(t *testing.T) {
+	from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)
+
+	t.Run("ES|QL query is filtered by the time range", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarResponse = newColumnarResponse(t, `{
+			"columns": [{"name": "host", "type": "keyword"}, {"name": "bytes", "type": "long"}],
+			"values": [["a", 10], ["b", null]]
+		}`)
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROM logs | KEEP host, bytes"}`, from, to)
+		require.NoError(t, err)
+		require.Empty(t, c.multisearchRequests)
+
+		require.Len(t, c.esqlRequests, 1)
+		require.Equal(t, "FROM logs | KEEP host, bytes", c.esqlRequests[0].Query)
+		filter, err := json.Marshal(c.esqlRequests[0].Filter)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"range": {"@timestamp": {"gte": 1709287200000, "lte": 1709290800000, "format": "epoch_millis"}}}`, string(filter))
+
+		dataRes := res.Responses["A"]
+		require.NoError(t, dataRes.Error)
+		require.Len(t, dataRes.Frames, 1)
+		frame := dataRes.Frames[0]
+		require.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, "FROM logs | KEEP host, bytes", frame.Meta.ExecutedQueryString)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "a", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, float64(10), *frame.Fields[1].At(0).(*float64))
+		require.Nil(t, frame.Fields[1].At(1))
+	})
+
+	t.Run("PPL query runs with a time filter", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarResponse = newColumnarResponse(t, `{
+			"schema": [{"name": "count()", "type": "integer"}],
+			"datarows": [[42]],
+			"total": 1,
+			"size": 1
+		}`)
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "ppl", "query": "source=logs | stats count()"}`, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, c.pplRequests, 1)
+		require.Equal(t, "source=logs | where `@timestamp` >= '2024-03-01 10:00:00.000' and `@timestamp` <= '2024-03-01 11:00:00.000' | stats count()", c.pplRequests[0].Query)
+
+		frame := res.Responses["A"].Frames[0]
+		require.Equal(t, "count()", frame.Fields[0].Name)
+		require.Equal(t, float64(42), *frame.Fields[0].At(0).(*float64))
+	})
+
+	t.Run("DSL and ES|QL queries in the same request", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponse = &es.MultiSearchResponse{Responses: []*es.SearchResponse{{}}}
+		c.columnarResponse = newColumnarResponse(t, `{"columns": [{"name": "host", "type": "keyword"}], "values": []}`)
+
+		req := backend.QueryDataRequest{Queries: []backend.DataQuery{
+			{RefID: "A", JSON: []byte(`{"queryType": "esql", "query": "FROM logs"}`), TimeRange: backend.TimeRange{From: from, To: to}},
+			{RefID: "B", JSON: []byte(`{"metrics": [{"type": "count", "id": "1"}], "bucketAggs": [{"type": "date_histogram", "field": "@timestamp", "id": "2"}]}`), TimeRange: backend.TimeRange{From: from, To: to}},
+		}}
+		res, err := newElasticsearchDataQuery(context.Background(), c, &req, log.New("test.logger"), tracing.InitializeTracerForTest()).execute()
+		require.NoError(t, err)
+
+		require.Len(t, c.esqlRequests, 1)
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		require.Contains(t, res.Responses, "A")
+		require.Contains(t, res.Responses, "B")
+	})
+
+	t.Run("error responses are returned", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarResponse = newColumnarResponse(t, `{
+			"error": {"root_cause": [{"type": "verification_exception", "reason": "Unknown column [hots]"}], "type": "verification_exception", "reason": "Found 1 problem"},
+			"status": 400
+		}`)
+		c.columnarResponse.Status = http.StatusBadRequest
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROM logs | KEEP hots"}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "Unknown column [hots]")
+		require.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+		require.Equal(t, backend.StatusBadRequest, res.Responses["A"].Status)
+	})
+
+	t.Run("client errors are returned", func(t *testing.T) {
+		c := newFakeClient()
+		c.columnarError = errors.New("connection refused")
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "ppl", "query": "source=logs"}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "connection refused")
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		c := newFakeClient()
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": " "}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "ESQL query is required")
+		require.Empty(t, c.esqlRequests)
+	})
+}
+
+func TestPPLQueryWithTimeFilter(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 3, 1, 11, 0, 0, 500_000_000, time.UTC),
+	}
+	filter := "where `timestamp` >= '2024-03-01 10:00:00.000' and `timestamp` <= '2024-03-01 11:00:00.500'"
+
+	require.Equal(t, "source=logs | "+filter, pplQueryWithTimeFilter("source=logs", "timestamp", timeRange))
+	require.Equal(t, "source=logs | "+filter+" | fields host", pplQueryWithTimeFilter(" source=logs|fields host ", "timestamp", timeRange))
+	require.Equal(t, "source=`logs|archive` | "+filter+" | where msg = 'a|b'", pplQueryWithTimeFilter("source=`logs|archive` | where msg = 'a|b'", "timestamp", timeRange))
+}
+
+func TestParseColumnarResponse(t *testing.T) {
+	query := &Query{RefID: "A", RawQuery: "FROM metrics | STATS avg(cpu) BY BUCKET(@timestamp, 1 minute), host"}
+
+	t.Run("time series with labels", func(t *testing.T) {
+		res := newColumnarResponse(t, `{
+			"columns": [
+				{"name": "avg(cpu)", "type": "double"},
+				{"name": "@timestamp", "type": "date"},
+				{"name": "host", "type": "keyword"}
+			],
+			"values": [
+				[0.5, "2024-03-01T10:01:00.000Z", "a"],
+				[0.25, "2024-03-01T10:00:00.000Z", "a"],
+				[1.5, "2024-03-01T10:00:00.000Z", "b"],
+				[2, null, "b"]
+			]
+		}`)
+
+		frames := parseColumnarResponse(res, query, "@timestamp")
+		require.Len(t, frames, 2)
+
+		a := frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, a.Meta.Type)
+		require.Equal(t, "A", a.RefID)
+		require.Equal(t, data.Labels{"host": "a"}, a.Fields[1].Labels)
+		require.Equal(t, "avg(cpu)", a.Fields[1].Name)
+		require.Equal(t, 2, a.Rows())
+		require.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), a.Fields[0].At(0).(time.Time))
+		require.Equal(t, 0.25, *a.Fields[1].At(0).(*float64))
+		require.Equal(t, 0.5, *a.Fields[1].At(1).(*float64))
+
+		b := frames[1]
+		require.Equal(t, data.Labels{"host": "b"}, b.Fields[1].Labels)
+		require.Equal(t, 1, b.Rows())
+	})
+
+	t.Run("PPL timestamps", func(t *testing.T) {
+		res := newColumnarResponse(t, `{
+			"schema": [{"name": "count()", "type": "integer"}, {"name": "span(timestamp,1h)", "type": "timestamp"}],
+			"datarows": [[3, "2024-03-01 10:00:00"], [4, "2024-03-01 11:00:00"]]
+		}`)
+
+		frames := parseColumnarResponse(res, query, "timestamp")
+		require.Len(t, frames, 1)
+		require.Equal(t, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC), frames[0].Fields[0].At(1).(time.Time))
+		require.Empty(t, frames[0].Fields[1].Labels)
+	})
+
+	t.Run("table without numeric columns", func(t *testing.T) {
+		res := newColumnarResponse(t, `{
+			"columns": [
+				{"name": "@timestamp", "type": "date"},
+				{"name": "message", "type": "text"},
+				{"name": "tags", "type": "keyword"},
+				{"name": "error", "type": "boolean"}
+			],
+			"values": [["2024-03-01T10:00:00.000Z", "started", ["a", "b"], false]]
+		}`)
+
+		frames := parseColumnarResponse(res, query, "@timestamp")
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+		require.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Equal(t, `["a","b"]`, *frame.Fields[2].At(0).(*string))
+		require.False(t, *frame.Fields[3].At(0).(*bool))
+	})
+
+	t.Run("long values", func(t *testing.T) {
+		res := newColumnarResponse(t, `{"columns": [{"name": "id", "type": "keyword"}, {"name": "n", "type": "long"}], "values": [[9007199254740993, 1]]}`)
+		frames := parseColumnarResponse(res, query, "@timestamp")
+		require.Equal(t, "9007199254740993", *frames[0].Fields[0].At(0).(*string))
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/grafana/grafana/pkg/tsdb/elasticsearch/instrumentation"
 )
@@ -47,7 +48,7 @@ const (
 
 var searchWordsRegex = regexp.MustCompile(regexp.QuoteMeta(es.HighlightPreTagsString) + `(.*?)` + regexp.QuoteMeta(es.HighlightPostTagsString))
 
-func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets []*Query, configuredFields es.ConfiguredFields, keepLabelsInResponse bool, logger log.Logger, tracer tracing.Tracer) (*backend.QueryDataResponse, error) {
+func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets []*Query, configuredFields es.ConfiguredFields, keepLabelsInResponse bool, headers map[string]string, logger log.Logger, tracer tracing.Tracer) (*backend.QueryDataResponse, error) {
 	result := backend.QueryDataResponse{
 		Responses: backend.Responses{},
 	}
@@ -104,8 +105,18 @@ func parseResponse(ctx context.Context, responses []*es.SearchResponse, targets
 		} else {
 			// Process as metric query result
 			props := make(map[string]string)
-			err := processBuckets(res.Aggregations, target, &queryRes, props, 0)
+			// Each query has its own limits of series and points
+			limiter := querylimits.NewLimiter(querylimits.LimitsFromHeaders(headers))
+			err := processBuckets(res.Aggregations, target, &queryRes, props, 0, limiter)
 			logger.Debug("Processed metric query response")
+			var limitErr *querylimits.ExceededError
+			if errors.As(err, &limitErr) {
+				logger.Warn("Stopped processing buckets at the query limits", "error", err, "stage", es.StageParseResponse)
+				result.Responses[target.RefID] = errorsource.Response(errorsource.DownstreamError(err, false))
+				instrumentation.UpdatePluginParsingResponseDurationSeconds(ctx, time.Since(start), "error")
+				resSpan.End()
+				continue
+			}
 			if err != nil {
 				mt, _ := json.Marshal(target)
 				span.RecordError(err)
@@ -397,7 +408,7 @@ func processDocsToDataFrameFields(docs []map[string]interface{}, propNames []str
 }
 
 func processBuckets(aggs map[string]interface{}, target *Query,
-	queryResult *backend.DataResponse, props map[string]string, depth int) error {
+	queryResult *backend.DataResponse, props map[string]string, depth int, limiter *querylimits.Limiter) error {
 	var err error
 	maxDepth := len(target.BucketAggs) - 1
 
@@ -414,7 +425,7 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 			continue
 		}
 		if aggDef.Type == nestedType {
-			err = processBuckets(esAgg.MustMap(), target, queryResult, props, depth+1)
+			err = processBuckets(esAgg.MustMap(), target, queryResult, props, depth+1, limiter)
 			if err != nil {
 				return err
 			}
@@ -423,9 +434,9 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 
 		if depth == maxDepth {
 			if aggDef.Type == dateHistType {
-				err = processMetrics(esAgg, target, queryResult, props)
+				err = processMetrics(esAgg, target, queryResult, props, limiter)
 			} else {
-				err = processAggregationDocs(esAgg, aggDef, target, queryResult, props)
+				err = processAggregationDocs(esAgg, aggDef, target, queryResult, props, limiter)
 			}
 			if err != nil {
 				return err
@@ -448,7 +459,7 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 				if key, err := bucket.Get("key_as_string").String(); err == nil {
 					newProps[aggDef.Field] = key
 				}
-				err = processBuckets(bucket.MustMap(), target, queryResult, newProps, depth+1)
+				err = processBuckets(bucket.MustMap(), target, queryResult, newProps, depth+1, limiter)
 				if err != nil {
 					return err
 				}
@@ -471,7 +482,7 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 
 				newProps["filter"] = bucketKey
 
-				err = processBuckets(bucket.MustMap(), target, queryResult, newProps, depth+1)
+				err = processBuckets(bucket.MustMap(), target, queryResult, newProps, depth+1, limiter)
 				if err != nil {
 					return err
 				}
@@ -686,7 +697,7 @@ func processDefaultMetric(metric *MetricAgg, buckets []*simplejson.Json, props m
 
 // nolint:gocyclo
 func processMetrics(esAgg *simplejson.Json, target *Query, query *backend.DataResponse,
-	props map[string]string) error {
+	props map[string]string, limiter *querylimits.Limiter) error {
 	frames := data.Frames{}
 	esAggBuckets := esAgg.Get("buckets").MustArray()
 
@@ -735,6 +746,17 @@ func processMetrics(esAgg *simplejson.Json, target *Query, query *backend.DataRe
 			frames = append(frames, defaultFrames...)
 		}
 	}
+	trim := getTrimEdges(target)
+	for _, frame := range frames {
+		// Each frame is a series with a value per bucket, less the buckets trimDatapoints removes
+		points, _ := frame.RowLen()
+		if points > trim*2 {
+			points -= trim * 2
+		}
+		if err := limiter.Add(1, int64(points)); err != nil {
+			return err
+		}
+	}
 	if query.Frames != nil {
 		oldFrames := query.Frames
 		frames = append(oldFrames, frames...)
@@ -744,12 +766,19 @@ func processMetrics(esAgg *simplejson.Json, target *Query, query *backend.DataRe
 }
 
 func processAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Query,
-	queryResult *backend.DataResponse, props map[string]string) error {
+	queryResult *backend.DataResponse, props map[string]string, limiter *querylimits.Limiter) error {
 	propKeys := createPropKeys(props)
 	frames := data.Frames{}
 	fields := createFields(queryResult.Frames, propKeys)
+	trim := getTrimEdges(target)
 
-	for _, v := range esAgg.Get("buckets").MustArray() {
+	if queryResult.Frames == nil {
+		// All buckets are rows of a single table
+		if err := limiter.Add(1, 0); err != nil {
+			return err
+		}
+	}
+	for i, v := range esAgg.Get("buckets").MustArray() {
 		bucket := simplejson.NewFromAny(v)
 		var values []interface{}
 
@@ -806,6 +835,13 @@ func processAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Q
 			}
 		}
 
+		// trimDatapoints removes the first and last rows of the table
+		if i >= trim*2 {
+			if err := limiter.Add(0, int64(len(fields))); err != nil {
+				return err
+			}
+		}
+
 		var dataFields []*data.Field
 		dataFields = append(dataFields, fields...)
 
@@ -833,21 +869,23 @@ func extractDataField(name string, v interface{}) *data.Field {
 	return field
 }
 
-func trimDatapoints(queryResult backend.DataResponse, target *Query) {
-	var histogram *BucketAgg
+// getTrimEdges returns the number of buckets trimmed from each edge of the date histogram of the query.
+func getTrimEdges(target *Query) int {
 	for _, bucketAgg := range target.BucketAggs {
 		if bucketAgg.Type == dateHistType {
-			histogram = bucketAgg
-			break
+			trimEdges, err := castToInt(bucketAgg.Settings.Get("trimEdges"))
+			if err != nil {
+				return 0
+			}
+			return trimEdges
 		}
 	}
+	return 0
+}
 
-	if histogram == nil {
-		return
-	}
-
-	trimEdges, err := castToInt(histogram.Settings.Get("trimEdges"))
-	if err != nil {
+func trimDatapoints(queryResult backend.DataResponse, target *Query) {
+	trimEdges := getTrimEdges(target)
+	if trimEdges <= 0 {
 		return
 	}
 
@@ -16,6 +16,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 )
 
@@ -3647,7 +3649,109 @@ func TestTrimEdges(t *testing.T) {
 	requireFrameLength(t, frames[0], 1)
 }
 
+func TestQueryLimits(t *testing.T) {
+	timeSeriesQuery := `{
+		"metrics": [{ "type": "avg", "id": "1", "field": "@value" }, { "type": "count", "id": "3" }],
+		"bucketAggs": [{ "id": "2", "type": "date_histogram", "field": "@timestamp", "settings": { "trimEdges": "1" } }]
+	}`
+	timeSeriesResponse := `{
+		"responses": [
+			{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{ "1": { "value": 1000 }, "key": 1, "doc_count": 369 },
+							{ "1": { "value": 2000 }, "key": 2, "doc_count": 200 },
+							{ "1": { "value": 2000 }, "key": 3, "doc_count": 200 }
+						]
+					}
+				}
+			}
+		]
+	}`
+	tableQuery := `{
+		"metrics": [{ "type": "avg", "id": "1", "field": "@value" }],
+		"bucketAggs": [{ "id": "2", "type": "terms", "field": "host" }]
+	}`
+	tableResponse := `{
+		"responses": [
+			{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{ "1": { "value": 1000 }, "key": "server-1", "doc_count": 369 },
+							{ "1": { "value": 2000 }, "key": "server-2", "doc_count": 200 }
+						]
+					}
+				}
+			}
+		]
+	}`
+
+	tests := []struct {
+		name          string
+		query         string
+		response      string
+		headers       map[string]string
+		expectedError string
+	}{
+		{
+			name:     "time series within the limits, which count the trimmed buckets",
+			query:    timeSeriesQuery,
+			response: timeSeriesResponse,
+			headers:  map[string]string{querylimits.HeaderMaxSeries: "2", querylimits.HeaderMaxPoints: "2"},
+		},
+		{
+			name:          "time series exceeding the limit of series",
+			query:         timeSeriesQuery,
+			response:      timeSeriesResponse,
+			headers:       map[string]string{querylimits.HeaderMaxSeries: "1"},
+			expectedError: "query result exceeds the limit of 1 series",
+		},
+		{
+			name:          "time series exceeding the limit of points",
+			query:         timeSeriesQuery,
+			response:      timeSeriesResponse,
+			headers:       map[string]string{querylimits.HeaderMaxPoints: "1"},
+			expectedError: "query result exceeds the limit of 1 points",
+		},
+		{
+			name:     "table within the limits",
+			query:    tableQuery,
+			response: tableResponse,
+			headers:  map[string]string{querylimits.HeaderMaxSeries: "1", querylimits.HeaderMaxPoints: "4"},
+		},
+		{
+			name:          "table exceeding the limit of points",
+			query:         tableQuery,
+			response:      tableResponse,
+			headers:       map[string]string{querylimits.HeaderMaxPoints: "3"},
+			expectedError: "query result exceeds the limit of 3 points",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := parseTestResponseWithHeaders(map[string]string{"A": test.query}, test.response, false, test.headers)
+			require.NoError(t, err)
+
+			res := result.Responses["A"]
+			if test.expectedError == "" {
+				require.NoError(t, res.Error)
+				require.Nil(t, querylimits.Check(setting.QueryLimits{MaxSeries: 2, MaxPoints: 4}, res))
+				return
+			}
+			require.EqualError(t, res.Error, test.expectedError)
+			require.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+			require.Nil(t, res.Frames)
+		})
+	}
+}
+
 func parseTestResponse(tsdbQueries map[string]string, responseBody string, keepLabelsInResponse bool) (*backend.QueryDataResponse, error) {
+	return parseTestResponseWithHeaders(tsdbQueries, responseBody, keepLabelsInResponse, nil)
+}
+
+func parseTestResponseWithHeaders(tsdbQueries map[string]string, responseBody string, keepLabelsInResponse bool, headers map[string]string) (*backend.QueryDataResponse, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
 	configuredFields := es.ConfiguredFields{
@@ -3682,7 +3786,7 @@ func parseTestResponse(tsdbQueries map[string]string, responseBody string, keepL
 		return nil, err
 	}
 
-	return parseResponse(context.Background(), response.Responses, queries, configuredFields, keepLabelsInResponse, log.New("test.logger"), tracing.InitializeTracerForTest())
+	return parseResponse(context.Background(), response.Responses, queries, configuredFields, keepLabelsInResponse, headers, log.New("test.logger"), tracing.InitializeTracerForTest())
 }
 
 func requireTimeValue(t *testing.T, expected int64, frame *data.Frame, index int) {
@@ -0,0 +1,111 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const healthCheckRefID = "__healthcheck__"
+
+var graphiteVersionRegex = regexp.MustCompile(`^\d+\.\d+(\.\d+)?`)
+
+// CheckHealth checks that series can be rendered and that the metric index can be browsed,
+// and reports the version of Graphite if it is available.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Failed to get data source info",
+		}, err
+	}
+
+	if err := s.checkRender(ctx, req.PluginContext); err != nil {
+		logger.Error("Graphite health check failed to render", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Failed to render series: %s", err),
+		}, nil
+	}
+
+	res, err := s.fetchResource(ctx, logger, dsInfo, "metrics/find", url.Values{"query": []string{"*"}})
+	if err == nil && res.Status != http.StatusOK {
+		err = fmt.Errorf("request failed, status: %d", res.Status)
+	}
+	if err != nil {
+		logger.Error("Graphite health check failed to find metrics", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Failed to find metrics: %s", err),
+		}, nil
+	}
+
+	message := "Data source is working"
+	details := map[string]any{}
+	var metrics []any
+	if err := json.Unmarshal(res.Body, &metrics); err == nil {
+		details["metrics"] = len(metrics)
+	}
+	// the version endpoint is not available in all versions of Graphite
+	if version := s.graphiteVersion(ctx, dsInfo); version != "" {
+		message = fmt.Sprintf("%s, Graphite version %s", message, version)
+		details["version"] = version
+	}
+
+	jsonDetails, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	return &backend.CheckHealthResult{
+		Status:      backend.HealthStatusOk,
+		Message:     message,
+		JSONDetails: jsonDetails,
+	}, nil
+}
+
+// checkRender renders a constant series, like the query editor does to test the data source.
+func (s *Service) checkRender(ctx context.Context, pluginCtx backend.PluginContext) error {
+	now := time.Now()
+	resp, err := s.QueryData(ctx, &backend.QueryDataRequest{
+		PluginContext: pluginCtx,
+		Queries: []backend.DataQuery{{
+			RefID:     healthCheckRefID,
+			JSON:      []byte(`{"target": "constantLine(100)"}`),
+			TimeRange: backend.TimeRange{From: now.Add(-time.Hour), To: now},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	res := resp.Responses[healthCheckRefID]
+	if res.Error != nil {
+		return res.Error
+	}
+	if len(res.Frames) == 0 {
+		return errors.New("no series returned")
+	}
+	return nil
+}
+
+func (s *Service) graphiteVersion(ctx context.Context, dsInfo *datasourceInfo) string {
+	res, err := s.fetchResource(ctx, logger.FromContext(ctx), dsInfo, "version", url.Values{})
+	if err != nil || res.Status != http.StatusOK {
+		return ""
+	}
+
+	version := strings.Trim(strings.TrimSpace(string(res.Body)), `"`)
+	return graphiteVersionRegex.FindString(version)
+}
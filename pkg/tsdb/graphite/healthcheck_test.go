@@ -0,0 +1,45 @@
+package graphite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		graphite := &fakeGraphite{}
+		s := setupFakeGraphite(t, graphite)
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working, Graphite version 1.1.10", res.Message)
+		require.JSONEq(t, `{"metrics": 2, "version": "1.1.10"}`, string(res.JSONDetails))
+
+		require.Len(t, graphite.requestsTo("/render"), 1)
+		require.Len(t, graphite.requestsTo("/metrics/find"), 1)
+	})
+
+	t.Run("without version endpoint", func(t *testing.T) {
+		s := setupFakeGraphite(t, &fakeGraphite{noVersion: true})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "Data source is working", res.Message)
+	})
+
+	t.Run("render fails", func(t *testing.T) {
+		graphite := &fakeGraphite{renderError: true}
+		s := setupFakeGraphite(t, graphite)
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "Failed to render series: request failed, status: 500 Internal Server Error", res.Message)
+		require.Empty(t, graphite.requestsTo("/metrics/find"))
+	})
+}
@@ -0,0 +1,259 @@
+package graphite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	// resourceCacheTTL is how long responses of metric and tag lookups are cached
+	resourceCacheTTL = time.Minute
+	// functionsCacheTTL is how long the function index is cached, it only changes with the Graphite version
+	functionsCacheTTL = time.Hour
+)
+
+// resourceEndpoint describes a Graphite API that can be called through CallResource.
+type resourceEndpoint struct {
+	// params are the query parameters passed on to Graphite
+	params []string
+	// required are the query parameters which must be set
+	required []string
+	cacheTTL time.Duration
+}
+
+var resourceEndpoints = map[string]resourceEndpoint{
+	"metrics/find": {
+		params:   []string{"query", "from", "until", "wildcards"},
+		required: []string{"query"},
+		cacheTTL: resourceCacheTTL,
+	},
+	"tags": {
+		params:   []string{"filter", "from", "until", "limit"},
+		cacheTTL: resourceCacheTTL,
+	},
+	"tags/autoComplete/tags": {
+		params:   []string{"expr", "tagPrefix", "limit", "from", "until"},
+		cacheTTL: resourceCacheTTL,
+	},
+	"tags/autoComplete/values": {
+		params:   []string{"expr", "tag", "valuePrefix", "limit", "from", "until"},
+		required: []string{"tag"},
+		cacheTTL: resourceCacheTTL,
+	},
+	"functions": {
+		cacheTTL: functionsCacheTTL,
+	},
+}
+
+// Graphite 1.1.7 returns Infinity as default value of some function parameters, which is not valid JSON.
+// See https://github.com/graphite-project/graphite-web/issues/2609
+var functionsInfinityDefault = regexp.MustCompile(`"default": ?Infinity`)
+
+// resourceResponse is the response of a Graphite API call
+type resourceResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	endpoint, ok := resourceEndpoints[strings.Trim(req.Path, "/")]
+	if !ok {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	params, err := resourceParams(req, endpoint)
+	if err != nil {
+		return sendResourceError(sender, http.StatusBadRequest, err)
+	}
+
+	res, err := s.callResource(ctx, logger, dsInfo, resourceCacheIdentity(req), strings.Trim(req.Path, "/"), params, endpoint.cacheTTL)
+	if err != nil {
+		return err
+	}
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  res.Status,
+		Headers: map[string][]string{"Content-Type": {res.ContentType}},
+		Body:    res.Body,
+	})
+}
+
+// resourceParams returns the parameters of the request which are passed on to Graphite, from
+// the URL or, for POST requests, from the form in the body.
+func resourceParams(req *backend.CallResourceRequest, endpoint resourceEndpoint) (url.Values, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	values := u.Query()
+
+	if req.Method == http.MethodPost && len(req.Body) > 0 {
+		form, err := url.ParseQuery(string(req.Body))
+		if err != nil {
+			return nil, fmt.Errorf("invalid form data: %w", err)
+		}
+		for key, v := range form {
+			values[key] = append(values[key], v...)
+		}
+	}
+
+	params := url.Values{}
+	for _, key := range endpoint.params {
+		if v, ok := values[key]; ok {
+			params[key] = v
+		}
+	}
+	for _, key := range endpoint.required {
+		if params.Get(key) == "" {
+			return nil, fmt.Errorf("missing required parameter %q", key)
+		}
+	}
+	return params, nil
+}
+
+func sendResourceError(sender backend.CallResourceResponseSender, status int, err error) error {
+	body, _ := json.Marshal(map[string]string{"message": err.Error()})
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
+
+// requestMetadataHeaders are forwarded headers which describe the request rather than the caller,
+// they are left out of the identity of cached responses. All other forwarded headers, such as OAuth
+// tokens, cookies and team headers, can change the response of Graphite.
+var requestMetadataHeaders = map[string]bool{
+	"X-Datasource-Uid":            true,
+	"X-Dashboard-Uid":             true,
+	"X-Panel-Id":                  true,
+	"X-Panel-Plugin-Id":           true,
+	"X-Query-Group-Id":            true,
+	"X-Grafana-From-Expr":         true,
+	"X-Grafana-Org-Id":            true,
+	"X-Grafana-Request-Id":        true,
+	"X-Grafana-Signed-Request-Id": true,
+	"X-Grafana-Internal-Request":  true,
+	"X-Real-Ip":                   true,
+}
+
+// resourceCacheIdentity hashes the user and the forwarded headers of the request, so that
+// responses cached for one user are never returned to another user.
+func resourceCacheIdentity(req *backend.CallResourceRequest) string {
+	h := sha256.New()
+	if user := req.PluginContext.User; user != nil {
+		_, _ = fmt.Fprintf(h, "%s\x00", user.Login)
+	}
+	headers := req.GetHTTPHeaders()
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		if !requestMetadataHeaders[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00", http.CanonicalHeaderKey(name), strings.Join(headers[name], "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// callResource calls the Graphite API at the path with the parameters. Successful responses are
+// cached in the cache of the data source instance, separately for each caller identity.
+func (s *Service) callResource(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, identity string, resourcePath string, params url.Values, cacheTTL time.Duration) (*resourceResponse, error) {
+	// the parameters are encoded sorted by key
+	cacheKey := identity + "\x00" + resourcePath + "?" + params.Encode()
+	if dsInfo.resourceCache != nil {
+		if cached, ok := dsInfo.resourceCache.Get(cacheKey); ok {
+			return cached.(*resourceResponse), nil
+		}
+	}
+
+	res, err := s.fetchResource(ctx, logger, dsInfo, resourcePath, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Status == http.StatusOK && dsInfo.resourceCache != nil {
+		dsInfo.resourceCache.Set(cacheKey, res, cacheTTL)
+	}
+	return res, nil
+}
+
+func (s *Service) fetchResource(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, resourcePath string, params url.Values) (*resourceResponse, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+	u.RawQuery = params.Encode()
+
+	ctx, span := s.tracer.Start(ctx, "datasource.graphite.CallResource", trace.WithAttributes(
+		attribute.String("path", resourcePath),
+		attribute.Int64("datasource_id", dsInfo.Id),
+	))
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Failed resource call to Graphite", "error", err, "path", resourcePath)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resourcePath == "functions" {
+		body = functionsInfinityDefault.ReplaceAll(body, []byte(`"default": 1e9999`))
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return &resourceResponse{Status: res.StatusCode, ContentType: contentType, Body: body}, nil
+}
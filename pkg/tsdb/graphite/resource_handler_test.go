@@ -0,0 +1,208 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// fakeGraphite answers the Graphite APIs used by resource calls and the health check,
+// and records the requests it received.
+type fakeGraphite struct {
+	mu          sync.Mutex
+	requests    []*http.Request
+	renderError bool
+	noVersion   bool
+}
+
+func (g *fakeGraphite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.requests = append(g.requests, r)
+	g.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/render":
+		if g.renderError {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"target": "constantLine(100) __healthcheck__", "datapoints": [[100, 1700000000], [100, 1700000060]]}]`))
+	case "/metrics/find":
+		_, _ = w.Write([]byte(`[{"text": "servers", "expandable": 1, "leaf": 0, "id": "servers"}, {"text": "apps", "expandable": 1, "leaf": 0, "id": "apps"}]`))
+	case "/tags/autoComplete/tags":
+		_, _ = w.Write([]byte(`["dc", "host"]`))
+	case "/functions":
+		_, _ = w.Write([]byte(`{"sumSeries": {"params": [{"name": "n", "default": Infinity}]}}`))
+	case "/version":
+		if g.noVersion {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("1.1.10\n"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (g *fakeGraphite) requestsTo(path string) []*http.Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var requests []*http.Request
+	for _, r := range g.requests {
+		if r.URL.Path == path {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+type testInstanceManager struct {
+	dsInfo datasourceInfo
+}
+
+func (m *testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m *testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func setupFakeGraphite(t *testing.T, graphite *fakeGraphite) *Service {
+	t.Helper()
+
+	server := httptest.NewServer(graphite)
+	t.Cleanup(server.Close)
+
+	return &Service{
+		im: &testInstanceManager{dsInfo: datasourceInfo{
+			HTTPClient:    server.Client(),
+			URL:           server.URL,
+			resourceCache: localcache.New(resourceCacheTTL, 5*time.Minute),
+		}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+}
+
+type fakeSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.response = res
+	return nil
+}
+
+func callTestResource(t *testing.T, s *Service, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+	t.Helper()
+	sender := &fakeSender{}
+	require.NoError(t, s.CallResource(context.Background(), req, sender))
+	require.NotNil(t, sender.response)
+	return sender.response
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("metrics find with form data is cached", func(t *testing.T) {
+		graphite := &fakeGraphite{}
+		s := setupFakeGraphite(t, graphite)
+
+		req := &backend.CallResourceRequest{
+			Method: http.MethodPost,
+			Path:   "metrics/find",
+			URL:    "metrics/find?from=-1h&until=now&jsonp=alert",
+			Body:   []byte("query=*"),
+		}
+		for i := 0; i < 2; i++ {
+			res := callTestResource(t, s, req)
+			require.Equal(t, http.StatusOK, res.Status)
+			require.Contains(t, string(res.Body), `"text": "servers"`)
+		}
+
+		requests := graphite.requestsTo("/metrics/find")
+		require.Len(t, requests, 1)
+		require.Equal(t, "from=-1h&query=%2A&until=now", requests[0].URL.RawQuery)
+	})
+
+	t.Run("cached responses are not shared between users and forwarded identities", func(t *testing.T) {
+		graphite := &fakeGraphite{}
+		s := setupFakeGraphite(t, graphite)
+
+		call := func(login string, headers map[string][]string) {
+			res := callTestResource(t, s, &backend.CallResourceRequest{
+				PluginContext: backend.PluginContext{User: &backend.User{Login: login}},
+				Method:        http.MethodGet,
+				Path:          "metrics/find",
+				URL:           "metrics/find?query=*",
+				Headers:       headers,
+			})
+			require.Equal(t, http.StatusOK, res.Status)
+		}
+
+		call("alice", map[string][]string{"Authorization": {"Bearer alice"}, "X-Panel-Id": {"1"}})
+		call("alice", map[string][]string{"Authorization": {"Bearer alice"}, "X-Panel-Id": {"2"}})
+		require.Len(t, graphite.requestsTo("/metrics/find"), 1, "request metadata is not part of the identity")
+
+		call("bob", map[string][]string{"Authorization": {"Bearer alice"}})
+		require.Len(t, graphite.requestsTo("/metrics/find"), 2)
+		call("alice", map[string][]string{"Authorization": {"Bearer other"}})
+		require.Len(t, graphite.requestsTo("/metrics/find"), 3)
+		call("alice", map[string][]string{"Authorization": {"Bearer alice"}, "X-Grafana-Team": {"ops"}})
+		require.Len(t, graphite.requestsTo("/metrics/find"), 4)
+	})
+
+	t.Run("tags autocomplete passes on all expressions", func(t *testing.T) {
+		graphite := &fakeGraphite{}
+		s := setupFakeGraphite(t, graphite)
+
+		res := callTestResource(t, s, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/tags",
+			URL:    "tags/autoComplete/tags?expr=dc%3Deu&expr=app%3Dweb&tagPrefix=h&limit=10",
+		})
+		require.Equal(t, http.StatusOK, res.Status)
+		require.JSONEq(t, `["dc", "host"]`, string(res.Body))
+
+		requests := graphite.requestsTo("/tags/autoComplete/tags")
+		require.Len(t, requests, 1)
+		require.Equal(t, []string{"dc=eu", "app=web"}, requests[0].URL.Query()["expr"])
+	})
+
+	t.Run("functions are returned as valid JSON", func(t *testing.T) {
+		s := setupFakeGraphite(t, &fakeGraphite{})
+
+		res := callTestResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "functions", URL: "functions"})
+		require.Equal(t, http.StatusOK, res.Status)
+		require.True(t, json.Valid(res.Body), string(res.Body))
+		require.False(t, strings.Contains(string(res.Body), "Infinity"))
+	})
+
+	t.Run("missing required parameters", func(t *testing.T) {
+		graphite := &fakeGraphite{}
+		s := setupFakeGraphite(t, graphite)
+
+		res := callTestResource(t, s, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/values", URL: "tags/autoComplete/values?expr=dc%3Deu"})
+		require.Equal(t, http.StatusBadRequest, res.Status)
+		require.JSONEq(t, `{"message": "missing required parameter \"tag\""}`, string(res.Body))
+		require.Empty(t, graphite.requestsTo("/tags/autoComplete/values"))
+	})
+
+	t.Run("unknown resources", func(t *testing.T) {
+		s := setupFakeGraphite(t, &fakeGraphite{})
+
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: "render", URL: "render?target=a"}, &fakeSender{})
+		require.EqualError(t, err, "invalid resource URL: render")
+	})
+}
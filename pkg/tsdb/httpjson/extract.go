@@ -0,0 +1,246 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/jmespath/go-jmespath"
+	"github.com/spyzhov/ajson"
+)
+
+// document is a JSON response which JSONPath and JMESPath expressions are evaluated against.
+type document struct {
+	root    *ajson.Node
+	decoded any
+}
+
+func newDocument(body []byte) (*document, error) {
+	root, err := ajson.Unmarshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return &document{root: root}, nil
+}
+
+// values returns the values matched by an expression. A single matched array is treated as the
+// list of values, so that both `$.items[*].value` and `$.values` select a column.
+func (d *document) values(language, expr string) ([]any, error) {
+	if language == languageJMESPath {
+		return d.jmespathValues(expr)
+	}
+
+	nodes, err := d.root.JSONPath(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+	}
+	if len(nodes) == 1 && nodes[0].IsArray() {
+		nodes = nodes[0].MustArray()
+	}
+
+	values := make([]any, 0, len(nodes))
+	for _, node := range nodes {
+		v, err := node.Unpack()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (d *document) jmespathValues(expr string) ([]any, error) {
+	if d.decoded == nil {
+		v, err := d.root.Unpack()
+		if err != nil {
+			return nil, err
+		}
+		d.decoded = v
+	}
+
+	result, err := jmespath.Search(expr, d.decoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JMESPath %q: %w", expr, err)
+	}
+	switch v := result.(type) {
+	case nil:
+		return []any{}, nil
+	case []any:
+		return v, nil
+	default:
+		return []any{v}, nil
+	}
+}
+
+// newField converts the extracted values of a field to the type of the field. Fields without a type
+// are numbers or booleans if all their values are, and strings otherwise.
+func newField(f fieldQuery, values []any) (*data.Field, error) {
+	typ := f.Type
+	if typ == fieldTypeAuto {
+		typ = detectType(values)
+	}
+
+	switch typ {
+	case fieldTypeNumber:
+		field := data.NewField(f.name(), nil, make([]*float64, len(values)))
+		for i, v := range values {
+			n, err := toNumber(v)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.name(), err)
+			}
+			field.Set(i, n)
+		}
+		return field, nil
+	case fieldTypeBoolean:
+		field := data.NewField(f.name(), nil, make([]*bool, len(values)))
+		for i, v := range values {
+			b, err := toBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.name(), err)
+			}
+			field.Set(i, b)
+		}
+		return field, nil
+	case fieldTypeTime:
+		// time fields are not nullable, so that frames with a time field are time series
+		field := data.NewField(f.name(), nil, make([]time.Time, len(values)))
+		for i, v := range values {
+			t, err := toTime(v, f.TimeFormat)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.name(), err)
+			}
+			if t == nil {
+				return nil, fmt.Errorf("field %q: time values can't be null", f.name())
+			}
+			field.Set(i, *t)
+		}
+		return field, nil
+	default:
+		field := data.NewField(f.name(), nil, make([]*string, len(values)))
+		for i, v := range values {
+			s, err := toString(v)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.name(), err)
+			}
+			field.Set(i, s)
+		}
+		return field, nil
+	}
+}
+
+func detectType(values []any) string {
+	typ := fieldTypeAuto
+	for _, v := range values {
+		var valueType string
+		switch v.(type) {
+		case nil:
+			continue
+		case float64:
+			valueType = fieldTypeNumber
+		case bool:
+			valueType = fieldTypeBoolean
+		default:
+			return fieldTypeString
+		}
+		if typ != fieldTypeAuto && typ != valueType {
+			return fieldTypeString
+		}
+		typ = valueType
+	}
+	if typ == fieldTypeAuto {
+		return fieldTypeString
+	}
+	return typ
+}
+
+func toNumber(v any) (*float64, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return &v, nil
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a number", v)
+		}
+		return &n, nil
+	default:
+		return nil, fmt.Errorf("value of type %T is not a number", v)
+	}
+}
+
+func toBool(v any) (*bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return &v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a boolean", v)
+		}
+		return &b, nil
+	default:
+		return nil, fmt.Errorf("value of type %T is not a boolean", v)
+	}
+}
+
+// toTime converts epoch timestamps in seconds or milliseconds, and strings in the given layout.
+func toTime(v any, layout string) (*time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		t := parseTimestamp(int64(v))
+		return &t, nil
+	case string:
+		if layout == "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				t := parseTimestamp(n)
+				return &t, nil
+			}
+			layout = time.RFC3339Nano
+		}
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a time: %w", v, err)
+		}
+		t = t.UTC()
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("value of type %T is not a time", v)
+	}
+}
+
+func parseTimestamp(timestamp int64) time.Time {
+	if timestamp > 9999999999 {
+		return time.UnixMilli(timestamp).UTC()
+	}
+	return time.Unix(timestamp, 0).UTC()
+}
+
+func toString(v any) (*string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &v, nil
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		return &s, nil
+	case bool:
+		s := strconv.FormatBool(v)
+		return &s, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s := string(b)
+		return &s, nil
+	}
+}
@@ -0,0 +1,114 @@
+package httpjson
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+var logger = log.New("tsdb.httpjson")
+
+type Service struct {
+	im instancemgmt.InstanceManager
+}
+
+func ProvideService(httpClientProvider httpclient.Provider) *Service {
+	return &Service{
+		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+	}
+}
+
+type datasourceInfo struct {
+	HTTPClient *http.Client
+	URL        string
+
+	// HealthCheckPath is requested by the health check, relative to the URL of the data source
+	HealthCheckPath string
+	// MaxResponseSize is the maximum number of bytes read from the responses of all pages of a query
+	MaxResponseSize int64
+}
+
+type jsonData struct {
+	HealthCheckPath string `json:"healthCheckPath"`
+	MaxResponseSize int64  `json:"maxResponseSize"`
+}
+
+func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		opts, err := settings.HTTPClientOptions(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := httpClientProvider.New(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var options jsonData
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &options); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
+		maxResponseSize := options.MaxResponseSize
+		if maxResponseSize <= 0 {
+			maxResponseSize = defaultMaxResponseSize
+		}
+
+		return &datasourceInfo{
+			HTTPClient:      client,
+			URL:             settings.URL,
+			HealthCheckPath: options.HealthCheckPath,
+			MaxResponseSize: maxResponseSize,
+		}, nil
+	}
+}
+
+func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	return i.(*datasourceInfo), nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	result := backend.NewQueryDataResponse()
+	for _, query := range req.Queries {
+		q, err := parseQuery(query)
+		if err != nil {
+			result.Responses[query.RefID] = backend.ErrDataResponseWithSource(backend.StatusBadRequest, backend.ErrorSourcePlugin, err.Error())
+			continue
+		}
+
+		frame, err := s.runQuery(ctx, logger, dsInfo, q)
+		if err != nil {
+			logger.Debug("Query failed", "refId", query.RefID, "error", err)
+			result.Responses[query.RefID] = errorsource.Response(err)
+			continue
+		}
+		frame.RefID = query.RefID
+		result.Responses[query.RefID] = backend.DataResponse{Frames: []*data.Frame{frame}}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,290 @@
+package httpjson
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI serves paginated items, and records the requests it received.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	items    int
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	a.mu.Lock()
+	a.requests = append(a.requests, r)
+	a.bodies = append(a.bodies, string(body))
+	a.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/api/items":
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			offset, _ = strconv.Atoi(cursor)
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil {
+			limit = a.items
+		}
+
+		items := []map[string]any{}
+		for i := offset; i < a.items && i < offset+limit; i++ {
+			items = append(items, map[string]any{
+				"ts":    1709287200000 + int64(i)*60000,
+				"value": float64(i) * 1.5,
+				"host":  "host-" + strconv.Itoa(i%2),
+			})
+		}
+		response := map[string]any{"data": map[string]any{"items": items}}
+		if offset+limit < a.items {
+			response["next"] = strconv.Itoa(offset + limit)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	case "/api/health":
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	case "/api/broken":
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type testInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (m *testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m *testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func setupFakeAPI(t *testing.T, api *fakeAPI, healthCheckPath string) *Service {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	return &Service{im: &testInstanceManager{dsInfo: &datasourceInfo{
+		HTTPClient:      server.Client(),
+		URL:             server.URL + "/api",
+		HealthCheckPath: healthCheckPath,
+		MaxResponseSize: defaultMaxResponseSize,
+	}}}
+}
+
+func runTestQuery(t *testing.T, s *Service, query string) backend.DataResponse {
+	t.Helper()
+
+	from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{{
+		RefID:     "A",
+		TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		JSON:      []byte(query),
+	}}})
+	require.NoError(t, err)
+	return res.Responses["A"]
+}
+
+func TestQueryData(t *testing.T) {
+	t.Run("extracts fields with JSONPath and JMESPath", func(t *testing.T) {
+		api := &fakeAPI{items: 3}
+		s := setupFakeAPI(t, api, "")
+
+		res := runTestQuery(t, s, `{
+			"path": "items",
+			"params": [{"key": "from", "value": "$__unixEpochMsFrom()"}, {"key": "to", "value": "$__timeTo()"}],
+			"fields": [
+				{"name": "time", "path": "$.data.items[*].ts", "type": "time"},
+				{"name": "value", "path": "data.items[].value", "language": "jmespath"},
+				{"path": "$.data.items[*].host"}
+			]
+		}`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, time.UnixMilli(1709287260000).UTC(), frame.Fields[0].At(1))
+		require.Equal(t, 3.0, *frame.Fields[1].At(2).(*float64))
+		require.Equal(t, "$.data.items[*].host", frame.Fields[2].Name)
+		require.Equal(t, "host-1", *frame.Fields[2].At(1).(*string))
+
+		require.Len(t, api.requests, 1)
+		require.Equal(t, "/api/items", api.requests[0].URL.Path)
+		require.Equal(t, "1709287200000", api.requests[0].URL.Query().Get("from"))
+		require.Equal(t, "2024-03-01T11:00:00Z", api.requests[0].URL.Query().Get("to"))
+	})
+
+	t.Run("posts the body with macros", func(t *testing.T) {
+		api := &fakeAPI{items: 1}
+		s := setupFakeAPI(t, api, "")
+
+		res := runTestQuery(t, s, `{
+			"method": "POST",
+			"path": "items",
+			"body": "{\"from\": $__unixEpochFrom(), \"to\": $__unixEpochTo()}",
+			"fields": [{"path": "$.data.items[*].value"}]
+		}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, http.MethodPost, api.requests[0].Method)
+		require.Equal(t, "application/json", api.requests[0].Header.Get("Content-Type"))
+		require.JSONEq(t, `{"from": 1709287200, "to": 1709290800}`, api.bodies[0])
+	})
+
+	t.Run("requests all pages with offset pagination", func(t *testing.T) {
+		api := &fakeAPI{items: 5}
+		s := setupFakeAPI(t, api, "")
+
+		res := runTestQuery(t, s, `{
+			"path": "items",
+			"fields": [{"path": "$.data.items[*].value"}],
+			"pagination": {"mode": "offset", "size": 2}
+		}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 5, res.Frames[0].Rows())
+
+		require.Len(t, api.requests, 3)
+		require.Equal(t, "limit=2&offset=4", api.requests[2].URL.RawQuery)
+	})
+
+	t.Run("requests all pages with cursor pagination", func(t *testing.T) {
+		api := &fakeAPI{items: 5}
+		s := setupFakeAPI(t, api, "")
+
+		res := runTestQuery(t, s, `{
+			"path": "items",
+			"fields": [{"path": "$.data.items[*].value"}],
+			"pagination": {"mode": "cursor", "sizeParam": "limit", "size": 2, "cursorPath": "next", "cursorLanguage": "jmespath"}
+		}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 5, res.Frames[0].Rows())
+		require.Len(t, api.requests, 3)
+		require.Equal(t, "2", api.requests[1].URL.Query().Get("cursor"))
+	})
+
+	t.Run("stops after the maximum number of pages", func(t *testing.T) {
+		api := &fakeAPI{items: 10}
+		s := setupFakeAPI(t, api, "")
+
+		res := runTestQuery(t, s, `{
+			"path": "items",
+			"fields": [{"path": "$.data.items[*].value"}],
+			"pagination": {"mode": "offset", "size": 2, "maxPages": 2}
+		}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 4, res.Frames[0].Rows())
+		require.Len(t, api.requests, 2)
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+
+	t.Run("stops when the responses exceed the maximum size", func(t *testing.T) {
+		api := &fakeAPI{items: 10}
+		s := setupFakeAPI(t, api, "")
+		s.im.(*testInstanceManager).dsInfo.MaxResponseSize = 300
+
+		res := runTestQuery(t, s, `{
+			"path": "items",
+			"fields": [{"path": "$.data.items[*].value"}],
+			"pagination": {"mode": "offset", "size": 2}
+		}`)
+		require.EqualError(t, res.Error, "response exceeds the maximum size of 300 bytes")
+		require.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+		require.Len(t, api.requests, 3)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[string]struct {
+			query  string
+			err    string
+			source backend.ErrorSource
+		}{
+			"failed request": {
+				query:  `{"path": "broken", "fields": [{"path": "$.a"}]}`,
+				err:    "request failed, status: 503 Service Unavailable",
+				source: backend.ErrorSourceDownstream,
+			},
+			"path outside of the data source URL": {
+				query:  `{"path": "../admin", "fields": [{"path": "$.a"}]}`,
+				err:    `path "../admin" must be relative to the URL of the data source`,
+				source: backend.ErrorSourcePlugin,
+			},
+			"absolute URL": {
+				query:  `{"path": "http://example.com/items", "fields": [{"path": "$.a"}]}`,
+				err:    `path "http://example.com/items" must be relative to the URL of the data source`,
+				source: backend.ErrorSourcePlugin,
+			},
+			"fields of different lengths": {
+				query:  `{"path": "items", "fields": [{"path": "$.data.items[*].value"}, {"name": "data", "path": "$.data"}]}`,
+				err:    `field "data" has 1 values, but field "$.data.items[*].value" has 3`,
+				source: backend.ErrorSourcePlugin,
+			},
+			"values of the wrong type": {
+				query:  `{"path": "items", "fields": [{"path": "$.data.items[*].host", "type": "number"}]}`,
+				err:    `field "$.data.items[*].host": value "host-0" is not a number`,
+				source: backend.ErrorSourcePlugin,
+			},
+			"authentication header": {
+				query:  `{"path": "items", "headers": [{"key": "authorization", "value": "Bearer token"}], "fields": [{"path": "$.a"}]}`,
+				err:    `header "authorization" can't be set by a query`,
+				source: backend.ErrorSourcePlugin,
+			},
+			"no fields": {
+				query:  `{"path": "items"}`,
+				err:    "query has no fields",
+				source: backend.ErrorSourcePlugin,
+			},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				s := setupFakeAPI(t, &fakeAPI{items: 3}, "")
+
+				res := runTestQuery(t, s, test.query)
+				require.EqualError(t, res.Error, test.err)
+				require.Equal(t, test.source, res.ErrorSource)
+			})
+		}
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("health check path", func(t *testing.T) {
+		api := &fakeAPI{}
+		s := setupFakeAPI(t, api, "health")
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+		require.Equal(t, "/api/health", api.requests[0].URL.Path)
+	})
+
+	t.Run("failed health check", func(t *testing.T) {
+		s := setupFakeAPI(t, &fakeAPI{}, "broken")
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "/api/broken returned status 503 Service Unavailable", res.Message)
+	})
+}
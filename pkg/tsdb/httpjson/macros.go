@@ -0,0 +1,27 @@
+package httpjson
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// interpolate replaces the time range macros in paths, parameters, headers and bodies of queries.
+// The macros are replaced in the backend so that queries of alert rules are interpolated too.
+func interpolate(s string, timeRange backend.TimeRange) string {
+	if !strings.Contains(s, "$__") {
+		return s
+	}
+
+	from, to := timeRange.From.UTC(), timeRange.To.UTC()
+	return strings.NewReplacer(
+		"$__timeFrom()", from.Format(time.RFC3339),
+		"$__timeTo()", to.Format(time.RFC3339),
+		"$__unixEpochFrom()", strconv.FormatInt(from.Unix(), 10),
+		"$__unixEpochTo()", strconv.FormatInt(to.Unix(), 10),
+		"$__unixEpochMsFrom()", strconv.FormatInt(from.UnixMilli(), 10),
+		"$__unixEpochMsTo()", strconv.FormatInt(to.UnixMilli(), 10),
+	).Replace(s)
+}
@@ -0,0 +1,180 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+const (
+	languageJSONPath = "jsonpath"
+	languageJMESPath = "jmespath"
+
+	fieldTypeAuto    = ""
+	fieldTypeString  = "string"
+	fieldTypeNumber  = "number"
+	fieldTypeBoolean = "boolean"
+	fieldTypeTime    = "time"
+
+	paginationNone   = ""
+	paginationOffset = "offset"
+	paginationPage   = "page"
+	paginationCursor = "cursor"
+
+	defaultMaxPages = 10
+	// maxPages limits the number of requests of a paginated query
+	maxPages = 100
+
+	// defaultMaxResponseSize limits the bytes read from the responses of all pages of a query,
+	// unless the data source configures another limit
+	defaultMaxResponseSize = 10 << 20
+)
+
+// restrictedHeaders are the headers that authenticate the requests of the data source. They are
+// set by the data source and Grafana, and can't be overridden by the headers of a query.
+var restrictedHeaders = map[string]bool{
+	backend.OAuthIdentityTokenHeaderName:   true,
+	backend.OAuthIdentityIDTokenHeaderName: true,
+	backend.CookiesHeaderName:              true,
+	"Proxy-Authorization":                  true,
+	"X-Grafana-Id":                         true,
+}
+
+// dataQuery is the model of a query which requests a path of the data source URL and extracts
+// the fields of the resulting frame from the JSON response.
+type dataQuery struct {
+	Method     string       `json:"method"`
+	Path       string       `json:"path"`
+	Params     []keyValue   `json:"params"`
+	Headers    []keyValue   `json:"headers"`
+	Body       string       `json:"body"`
+	Fields     []fieldQuery `json:"fields"`
+	Pagination pagination   `json:"pagination"`
+
+	TimeRange backend.TimeRange `json:"-"`
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// fieldQuery extracts the values of a field with a JSONPath or JMESPath expression.
+type fieldQuery struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	Type     string `json:"type"`
+	// TimeFormat is the Go layout of time values given as strings, RFC 3339 by default
+	TimeFormat string `json:"timeFormat"`
+}
+
+func (f fieldQuery) name() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Path
+}
+
+// pagination describes how further pages of a response are requested. With the offset and page
+// modes, requests stop at the first page with less than Size rows; with the cursor mode, they stop
+// when the response has no cursor.
+type pagination struct {
+	Mode      string `json:"mode"`
+	Param     string `json:"param"`
+	SizeParam string `json:"sizeParam"`
+	Size      int    `json:"size"`
+	// CursorPath extracts the cursor of the next page from a response
+	CursorPath     string `json:"cursorPath"`
+	CursorLanguage string `json:"cursorLanguage"`
+	MaxPages       int    `json:"maxPages"`
+}
+
+func parseQuery(query backend.DataQuery) (*dataQuery, error) {
+	q := &dataQuery{}
+	if err := json.Unmarshal(query.JSON, q); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+	q.TimeRange = query.TimeRange
+
+	q.Method = strings.ToUpper(q.Method)
+	switch q.Method {
+	case "":
+		q.Method = http.MethodGet
+	case http.MethodGet, http.MethodPost:
+	default:
+		return nil, fmt.Errorf("unsupported method %q", q.Method)
+	}
+
+	if len(q.Fields) == 0 {
+		return nil, fmt.Errorf("query has no fields")
+	}
+	for i, f := range q.Fields {
+		if f.Path == "" {
+			return nil, fmt.Errorf("field %d has no path", i+1)
+		}
+		if err := validateLanguage(f.Language); err != nil {
+			return nil, err
+		}
+		switch f.Type {
+		case fieldTypeAuto, fieldTypeString, fieldTypeNumber, fieldTypeBoolean, fieldTypeTime:
+		default:
+			return nil, fmt.Errorf("field %q has unsupported type %q", f.name(), f.Type)
+		}
+	}
+
+	return q, q.Pagination.validate()
+}
+
+func (p *pagination) validate() error {
+	switch p.Mode {
+	case paginationNone:
+		return nil
+	case paginationOffset:
+		if p.Param == "" {
+			p.Param = "offset"
+		}
+		if p.SizeParam == "" {
+			p.SizeParam = "limit"
+		}
+	case paginationPage:
+		if p.Param == "" {
+			p.Param = "page"
+		}
+	case paginationCursor:
+		if p.Param == "" {
+			p.Param = "cursor"
+		}
+		if p.CursorPath == "" {
+			return fmt.Errorf("cursor pagination requires the path of the cursor")
+		}
+		if err := validateLanguage(p.CursorLanguage); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported pagination mode %q", p.Mode)
+	}
+
+	if p.Mode == paginationOffset && p.Size <= 0 {
+		return fmt.Errorf("offset pagination requires a page size")
+	}
+	if p.MaxPages <= 0 {
+		p.MaxPages = defaultMaxPages
+	}
+	if p.MaxPages > maxPages {
+		p.MaxPages = maxPages
+	}
+	return nil
+}
+
+func validateLanguage(language string) error {
+	switch language {
+	case "", languageJSONPath, languageJMESPath:
+		return nil
+	default:
+		return fmt.Errorf("unsupported query language %q", language)
+	}
+}
@@ -0,0 +1,228 @@
+package httpjson
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// runQuery requests the pages of a query and returns the fields extracted from all of them as a frame.
+func (s *Service) runQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, q *dataQuery) (*data.Frame, error) {
+	columns := make([][]any, len(q.Fields))
+	p := q.Pagination
+
+	var executedQuery, cursor string
+	var notices []data.Notice
+	remaining := dsInfo.MaxResponseSize
+	for page := 0; ; page++ {
+		req, err := newRequest(ctx, dsInfo, q, p.params(page, cursor))
+		if err != nil {
+			return nil, errorsource.New(err, backend.ErrorSourcePlugin, backend.StatusBadRequest)
+		}
+		if page == 0 {
+			executedQuery = fmt.Sprintf("%s %s", req.Method, req.URL.String())
+		}
+
+		doc, size, err := s.fetch(logger, dsInfo, req, remaining)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= size
+
+		rows := 0
+		for i, f := range q.Fields {
+			values, err := doc.values(f.Language, f.Path)
+			if err != nil {
+				return nil, errorsource.New(err, backend.ErrorSourcePlugin, backend.StatusBadRequest)
+			}
+			if i > 0 && len(values) != rows {
+				err := fmt.Errorf("field %q has %d values, but field %q has %d", f.name(), len(values), q.Fields[0].name(), rows)
+				return nil, errorsource.New(err, backend.ErrorSourcePlugin, backend.StatusBadRequest)
+			}
+			rows = len(values)
+			columns[i] = append(columns[i], values...)
+		}
+
+		next, ok, err := p.next(doc, rows)
+		if err != nil {
+			return nil, errorsource.New(err, backend.ErrorSourcePlugin, backend.StatusBadRequest)
+		}
+		if !ok {
+			break
+		}
+		if page+1 >= p.MaxPages {
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Stopped after %d pages, the response has more pages", p.MaxPages),
+			})
+			break
+		}
+		cursor = next
+	}
+
+	fields := make([]*data.Field, 0, len(q.Fields))
+	for i, f := range q.Fields {
+		field, err := newField(f, columns[i])
+		if err != nil {
+			return nil, errorsource.New(err, backend.ErrorSourcePlugin, backend.StatusBadRequest)
+		}
+		fields = append(fields, field)
+	}
+
+	frame := data.NewFrame("", fields...)
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString: executedQuery,
+		Notices:             notices,
+	}
+	switch frame.TimeSeriesSchema().Type {
+	case data.TimeSeriesTypeWide:
+		frame.Meta.Type = data.FrameTypeTimeSeriesWide
+	case data.TimeSeriesTypeLong:
+		frame.Meta.Type = data.FrameTypeTimeSeriesLong
+	}
+	return frame, nil
+}
+
+// newRequest creates the request of a query, with the path of the query relative to the URL of the data source.
+func newRequest(ctx context.Context, dsInfo *datasourceInfo, q *dataQuery, pageParams url.Values) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(interpolate(q.Path, q.TimeRange))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	if ref.Scheme != "" || ref.Host != "" {
+		return nil, fmt.Errorf("path %q must be relative to the URL of the data source", q.Path)
+	}
+
+	basePath := path.Join("/", u.Path)
+	u.Path = path.Join(basePath, ref.Path)
+	if u.Path != basePath && !strings.HasPrefix(u.Path, strings.TrimSuffix(basePath, "/")+"/") {
+		return nil, fmt.Errorf("path %q must be relative to the URL of the data source", q.Path)
+	}
+	if strings.HasSuffix(ref.Path, "/") && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	query := ref.Query()
+	for _, p := range q.Params {
+		query.Add(interpolate(p.Key, q.TimeRange), interpolate(p.Value, q.TimeRange))
+	}
+	for key, values := range pageParams {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if q.Method == http.MethodPost && q.Body != "" {
+		body = strings.NewReader(interpolate(q.Body, q.TimeRange))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, q.Method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for _, h := range q.Headers {
+		if restrictedHeaders[http.CanonicalHeaderKey(h.Key)] {
+			return nil, fmt.Errorf("header %q can't be set by a query", h.Key)
+		}
+		req.Header.Set(h.Key, interpolate(h.Value, q.TimeRange))
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// fetch requests a page and parses its response, which must not be larger than limit bytes.
+// It returns the size of the response.
+func (s *Service) fetch(logger log.Logger, dsInfo *datasourceInfo, req *http.Request, limit int64) (*document, int64, error) {
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, errorsource.DownstreamError(err, false)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, limit+1))
+	if err != nil {
+		return nil, 0, errorsource.DownstreamError(err, false)
+	}
+	if int64(len(body)) > limit {
+		err := fmt.Errorf("response exceeds the maximum size of %d bytes", dsInfo.MaxResponseSize)
+		return nil, 0, errorsource.DownstreamError(err, false)
+	}
+
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		status := backend.Status(res.StatusCode)
+		return nil, 0, errorsource.New(fmt.Errorf("request failed, status: %s", res.Status), errorsource.FromStatus(status), status)
+	}
+
+	doc, err := newDocument(body)
+	if err != nil {
+		return nil, 0, errorsource.DownstreamError(err, false)
+	}
+	return doc, int64(len(body)), nil
+}
+
+// params returns the query parameters which request a page.
+func (p pagination) params(page int, cursor string) url.Values {
+	values := url.Values{}
+	switch p.Mode {
+	case paginationNone:
+		return values
+	case paginationOffset:
+		values.Set(p.Param, strconv.Itoa(page*p.Size))
+	case paginationPage:
+		values.Set(p.Param, strconv.Itoa(page+1))
+	case paginationCursor:
+		if page > 0 {
+			values.Set(p.Param, cursor)
+		}
+	}
+	if p.SizeParam != "" && p.Size > 0 {
+		values.Set(p.SizeParam, strconv.Itoa(p.Size))
+	}
+	return values
+}
+
+// next returns the cursor of the next page, and whether there is a next page.
+func (p pagination) next(doc *document, rows int) (string, bool, error) {
+	if rows == 0 {
+		return "", false, nil
+	}
+
+	switch p.Mode {
+	case paginationOffset, paginationPage:
+		return "", p.Size <= 0 || rows >= p.Size, nil
+	case paginationCursor:
+		values, err := doc.values(p.CursorLanguage, p.CursorPath)
+		if err != nil || len(values) == 0 {
+			return "", false, err
+		}
+		cursor, err := toString(values[0])
+		if err != nil || cursor == nil || *cursor == "" {
+			return "", false, err
+		}
+		return *cursor, true, nil
+	default:
+		return "", false, nil
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/converters"
 	"github.com/influxdata/influxdb-client-go/v2/api/query"
+
+	"github.com/grafana/grafana/pkg/services/querylimits"
 )
 
 // based on https://docs.influxdata.com/influxdb/v2.0/reference/syntax/annotated-csv/#data-types
@@ -61,6 +63,9 @@ type frameBuilder struct {
 	maxSeries           int // max number of series
 	totalSeries         int
 	hasUsualStartStop   bool // has _start and _stop timestamp-labels
+	limiter             *querylimits.Limiter
+	seriesPerTable      int64 // series of the frame of a table counted by the limiter
+	pointsPerRecord     int64 // points of a record counted by the limiter
 }
 
 // some csv-columns contain metadata about the data, like what is part of a "table",
@@ -243,6 +248,21 @@ func (fb *frameBuilder) Init(metadata *query.FluxTableMetadata) error {
 		}
 	}
 
+	// the limiter counts the numeric columns, which are series of frames with a time column,
+	// and a frame without a time column is a single series
+	fb.seriesPerTable, fb.pointsPerRecord = 1, 0
+	hasTimeCol := false
+	for _, col := range fb.columns {
+		if col.converter.OutputFieldType.Time() {
+			hasTimeCol = true
+		} else if col.converter.OutputFieldType.Numeric() {
+			fb.pointsPerRecord++
+		}
+	}
+	if hasTimeCol || len(fb.columns) == 0 {
+		fb.seriesPerTable = fb.pointsPerRecord
+	}
+
 	return nil
 }
 
@@ -318,6 +338,9 @@ func (fb *frameBuilder) Append(record *query.FluxRecord) error {
 		if fb.totalSeries > fb.maxSeries {
 			return fmt.Errorf("results are truncated, max series reached (%d)", fb.maxSeries)
 		}
+		if err := fb.limiter.Add(fb.seriesPerTable, 0); err != nil {
+			return err
+		}
 
 		// labels have the same value for every row in the same "table",
 		// so we collect them here
@@ -399,6 +422,9 @@ func (fb *frameBuilder) Append(record *query.FluxRecord) error {
 
 		fb.active.Fields[idx].Append(val)
 	}
+	if err := fb.limiter.Add(0, fb.pointsPerRecord); err != nil {
+		return err
+	}
 
 	pointsCount := fb.active.Fields[0].Len()
 	if pointsCount > fb.maxPoints {
@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/influxdb-client-go/v2/api"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 )
 
 const maxPointsEnforceFactor float64 = 10
@@ -32,7 +33,7 @@ func executeQuery(ctx context.Context, logger log.Logger, query queryModel, runn
 		// we only enforce a larger number than maxDataPoints
 		maxPointsEnforced := int(float64(query.MaxDataPoints) * maxPointsEnforceFactor)
 
-		dr = readDataFrames(logger, tables, maxPointsEnforced, maxSeries)
+		dr = readDataFrames(logger, tables, maxPointsEnforced, maxSeries, querylimits.NewLimiter(query.LimitSeries, query.LimitPoints))
 
 		if dr.Error != nil {
 			// we check if a too-many-data-points error happened, and if it is so,
@@ -64,13 +65,14 @@ func executeQuery(ctx context.Context, logger log.Logger, query queryModel, runn
 	return dr
 }
 
-func readDataFrames(logger log.Logger, result *api.QueryTableResult, maxPoints int, maxSeries int) (dr backend.DataResponse) {
+func readDataFrames(logger log.Logger, result *api.QueryTableResult, maxPoints int, maxSeries int, limiter *querylimits.Limiter) (dr backend.DataResponse) {
 	logger.Debug("Reading data frames from query result", "maxPoints", maxPoints, "maxSeries", maxSeries)
 	dr = backend.DataResponse{}
 
 	builder := &frameBuilder{
 		maxPoints: maxPoints,
 		maxSeries: maxSeries,
+		limiter:   limiter,
 	}
 
 	for result.Next() {
@@ -20,6 +20,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -316,3 +318,40 @@ func TestWithoutTimeColumn(t *testing.T) {
 	require.Equal(t, "cpu", dr.Frames[0].Fields[0].Name)
 	require.Equal(t, "host", dr.Frames[0].Fields[1].Name)
 }
+
+func TestReadDataFramesWithQueryLimits(t *testing.T) {
+	read := func(t *testing.T, name string, limiter *querylimits.Limiter) backend.DataResponse {
+		f, err := os.Open(filepath.Join("testdata", name+".csv"))
+		require.NoError(t, err)
+		return readDataFrames(glog, api.NewQueryTableResult(f), 1000, 1000, limiter)
+	}
+
+	for _, name := range []string{"aggregate", "boolean_data", "grouping", "multiple", "multitime", "multivalue", "non_standard_time_column", "simple", "single", "table", "time_first", "without-time-column"} {
+		t.Run(name, func(t *testing.T) {
+			dr := read(t, name, nil)
+			require.NoError(t, dr.Error)
+
+			// The builder must only stop at limits which the result exceeds
+			for limit := int64(1); limit <= 64; limit++ {
+				if limited := read(t, name, querylimits.NewLimiter(limit, 0)); limited.Error != nil {
+					require.NotNil(t, querylimits.Check(setting.QueryLimits{MaxSeries: limit}, dr), "stopped at %d series", limit)
+				}
+				if limited := read(t, name, querylimits.NewLimiter(0, limit)); limited.Error != nil {
+					require.NotNil(t, querylimits.Check(setting.QueryLimits{MaxPoints: limit}, dr), "stopped at %d points", limit)
+				}
+			}
+		})
+	}
+
+	t.Run("stops reading at the limits", func(t *testing.T) {
+		dr := read(t, "multiple", nil)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 3)
+
+		dr = read(t, "multiple", querylimits.NewLimiter(2, 0))
+		require.EqualError(t, dr.Error, "query result exceeds the limit of 2 series")
+
+		dr = read(t, "multiple", querylimits.NewLimiter(0, 1))
+		require.EqualError(t, dr.Error, "query result exceeds the limit of 1 points")
+	})
+}
@@ -26,6 +26,9 @@ type queryModel struct {
 	TimeRange     backend.TimeRange `json:"-"`
 	MaxDataPoints int64             `json:"-"`
 	Interval      time.Duration     `json:"-"`
+	// LimitSeries and LimitPoints are the query limits of series and points of the result, zero means no limit.
+	LimitSeries int64 `json:"-"`
+	LimitPoints int64 `json:"-"`
 }
 
 func getQueryModel(query backend.DataQuery, timeRange backend.TimeRange,
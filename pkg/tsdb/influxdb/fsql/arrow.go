@@ -15,12 +15,13 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/services/querylimits"
 )
 
-// TODO: Make this configurable. This is an arbitrary value right
-// now. Grafana used to have a 1M row rowLimit established in open-source. I'll
-// let users hit that for now until we decide how to proceed.
-const rowLimit = 1_000_000
+// defaultRowLimit is the row limit of data sources which don't have one in their settings or
+// in the configuration of Grafana.
+const defaultRowLimit = 1_000_000
 
 type recordReader interface {
 	Next() bool
@@ -32,10 +33,15 @@ type recordReader interface {
 // newQueryDataResponse builds a [backend.DataResponse] from a stream of
 // [arrow.Record]s.
 //
-// The backend.DataResponse contains a single [data.Frame].
-func newQueryDataResponse(reader recordReader, query sqlutil.Query, headers metadata.MD) backend.DataResponse {
+// The backend.DataResponse contains a single [data.Frame] with at most rowLimit rows.
+// Reading stops with an error once the limiter counts more points than its limit.
+func newQueryDataResponse(reader recordReader, query sqlutil.Query, headers metadata.MD, rowLimit int64, limiter *querylimits.Limiter) backend.DataResponse {
 	var resp backend.DataResponse
-	frame, err := frameForRecords(reader)
+	if query.Format == sqlutil.FormatOptionTimeSeries && newFrame(reader.Schema()).TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+		// LongToWide merges rows with the same time and labels, so their points are only checked on the result
+		limiter = nil
+	}
+	frame, err := frameForRecords(reader, rowLimit, limiter)
 	if err != nil {
 		resp.Error = err
 	}
@@ -79,31 +85,51 @@ func newQueryDataResponse(reader recordReader, query sqlutil.Query, headers meta
 }
 
 // frameForRecords creates a [data.Frame] from a stream of [arrow.Record]s.
-func frameForRecords(reader recordReader) (*data.Frame, error) {
+// Records are converted as they are received, and reading stops once the frame
+// has rowLimit rows, so that large results are never held in memory as a whole.
+// The limiter counts a point for each value of a numeric column.
+func frameForRecords(reader recordReader, rowLimit int64, limiter *querylimits.Limiter) (*data.Frame, error) {
 	var (
-		frame = newFrame(reader.Schema())
-		rows  int64
+		frame         = newFrame(reader.Schema())
+		rows          int64
+		numericFields int64
 	)
+	for _, field := range frame.Fields {
+		if field.Type().Numeric() {
+			numericFields++
+		}
+	}
 	for reader.Next() {
 		record := reader.Record()
+		truncated := rows+record.NumRows() > rowLimit
+		if truncated {
+			record = record.NewSlice(0, rowLimit-rows)
+		}
+		if err := limiter.Add(0, numericFields*record.NumRows()); err != nil {
+			if truncated {
+				record.Release()
+			}
+			return frame, err
+		}
+
 		for i, col := range record.Columns() {
 			if err := copyData(frame.Fields[i], col); err != nil {
 				return frame, err
 			}
 		}
-
 		rows += record.NumRows()
-		if rows > rowLimit {
+
+		if truncated {
+			record.Release()
 			frame.AppendNotices(data.Notice{
 				Severity: data.NoticeSeverityWarning,
 				Text:     fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", rowLimit),
 			})
 			return frame, nil
 		}
-
-		if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
-			return frame, err
-		}
+	}
+	if err := reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return frame, err
 	}
 	return frame, nil
 }
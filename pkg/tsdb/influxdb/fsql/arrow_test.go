@@ -15,6 +15,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/services/querylimits"
 )
 
 func TestNewQueryDataResponse(t *testing.T) {
@@ -79,7 +81,7 @@ func TestNewQueryDataResponse(t *testing.T) {
 	assert.NoError(t, err)
 
 	query := sqlutil.Query{Format: sqlutil.FormatOptionTable}
-	resp := newQueryDataResponse(errReader{RecordReader: reader}, query, metadata.MD{})
+	resp := newQueryDataResponse(errReader{RecordReader: reader}, query, metadata.MD{}, defaultRowLimit, nil)
 	assert.NoError(t, resp.Error)
 	assert.Len(t, resp.Frames, 1)
 	assert.Len(t, resp.Frames[0].Fields, 13)
@@ -200,11 +202,47 @@ func TestNewQueryDataResponse_Error(t *testing.T) {
 		err:          fmt.Errorf("explosion!"),
 	}
 	query := sqlutil.Query{Format: sqlutil.FormatOptionTable}
-	resp := newQueryDataResponse(wrappedReader, query, metadata.MD{})
+	resp := newQueryDataResponse(wrappedReader, query, metadata.MD{}, defaultRowLimit, nil)
 	assert.Error(t, resp.Error)
 	assert.Equal(t, fmt.Errorf("explosion!"), resp.Error)
 }
 
+func TestNewQueryDataResponse_QueryLimits(t *testing.T) {
+	alloc := memory.DefaultAllocator
+	newReader := func(t *testing.T) array.RecordReader {
+		schema := arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "name", Type: &arrow.StringType{}},
+				{Name: "i64", Type: arrow.PrimitiveTypes.Int64},
+				{Name: "f64", Type: arrow.PrimitiveTypes.Float64},
+			},
+			nil,
+		)
+		strs, _, err := array.FromJSON(alloc, &arrow.StringType{}, strings.NewReader(`["foo", "bar", "baz"]`))
+		assert.NoError(t, err)
+		i64s, _, err := array.FromJSON(alloc, arrow.PrimitiveTypes.Int64, strings.NewReader(`[1, 2, 3]`))
+		assert.NoError(t, err)
+		f64s, _, err := array.FromJSON(alloc, arrow.PrimitiveTypes.Float64, strings.NewReader(`[1.1, 2.2, 3.3]`))
+		assert.NoError(t, err)
+
+		record := array.NewRecord(schema, []arrow.Array{strs, i64s, f64s}, -1)
+		reader, err := array.NewRecordReader(schema, []arrow.Record{record})
+		assert.NoError(t, err)
+		return reader
+	}
+	query := sqlutil.Query{Format: sqlutil.FormatOptionTable}
+
+	t.Run("within the limit of points", func(t *testing.T) {
+		resp := newQueryDataResponse(errReader{RecordReader: newReader(t)}, query, metadata.MD{}, defaultRowLimit, querylimits.NewLimiter(0, 6))
+		assert.NoError(t, resp.Error)
+	})
+
+	t.Run("exceeding the limit of points", func(t *testing.T) {
+		resp := newQueryDataResponse(errReader{RecordReader: newReader(t)}, query, metadata.MD{}, defaultRowLimit, querylimits.NewLimiter(0, 5))
+		assert.EqualError(t, resp.Error, "query result exceeds the limit of 5 points")
+	})
+}
+
 func TestNewQueryDataResponse_WideTable(t *testing.T) {
 	alloc := memory.DefaultAllocator
 	schema := arrow.NewSchema(
@@ -240,7 +278,7 @@ func TestNewQueryDataResponse_WideTable(t *testing.T) {
 	reader, err := array.NewRecordReader(schema, records)
 	assert.NoError(t, err)
 
-	resp := newQueryDataResponse(errReader{RecordReader: reader}, sqlutil.Query{}, metadata.MD{})
+	resp := newQueryDataResponse(errReader{RecordReader: reader}, sqlutil.Query{}, metadata.MD{}, defaultRowLimit, nil)
 	assert.NoError(t, resp.Error)
 	assert.Len(t, resp.Frames, 1)
 	assert.Equal(t, 3, resp.Frames[0].Rows())
@@ -484,7 +522,7 @@ func TestCustomMetadata(t *testing.T) {
 	query := sqlutil.Query{
 		Format: sqlutil.FormatOptionTable,
 	}
-	resp := newQueryDataResponse(errReader{RecordReader: reader}, query, md)
+	resp := newQueryDataResponse(errReader{RecordReader: reader}, query, md, defaultRowLimit, nil)
 	assert.NoError(t, resp.Error)
 
 	assert.Equal(t, map[string]any{
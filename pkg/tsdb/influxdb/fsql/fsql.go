@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/apache/arrow/go/v15/arrow/flight"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
@@ -41,40 +43,87 @@ func Query(ctx context.Context, dsInfo *models.DatasourceInfo, req backend.Query
 		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
 	}
 
+	rowLimit := dsInfo.RowLimit
+	if rowLimit <= 0 {
+		rowLimit = defaultRowLimit
+	}
+
 	for _, q := range req.Queries {
 		qm, err := getQueryModel(q)
 		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, "bad request")
+			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("bad request: %s", err))
 			continue
 		}
 
+		qm.LimitSeries, qm.LimitPoints = querylimits.LimitsFromHeaders(req.Headers)
+
 		logger.Info(fmt.Sprintf("InfluxDB executing SQL: %s", qm.RawSQL))
-		info, err := r.client.Execute(ctx, qm.RawSQL)
-		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
-			return tRes, nil
-		}
-		if len(info.Endpoint) != 1 {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("unsupported endpoint count in response: %d", len(info.Endpoint)))
-			return tRes, nil
-		}
+		tRes.Responses[q.RefID] = r.runQuery(ctx, logger, qm, rowLimit)
+	}
 
-		reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
-		if err != nil {
-			tRes.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
-			return tRes, nil
-		}
-		defer reader.Release()
+	return tRes, nil
+}
 
-		headers, err := reader.Header()
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to extract headers: %s", err))
+// runQuery executes a query, with a prepared statement if it has parameters, and converts the
+// records of the result to a frame as they are received.
+func (r *runner) runQuery(ctx context.Context, logger log.Logger, qm *queryModel, rowLimit int64) backend.DataResponse {
+	info, closeStmt, err := r.execute(ctx, qm)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
+	}
+	defer func() {
+		if err := closeStmt(); err != nil {
+			logger.Warn("Failed to close prepared statement", "err", err)
 		}
+	}()
+	if len(info.Endpoint) != 1 {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("unsupported endpoint count in response: %d", len(info.Endpoint)))
+	}
+
+	reader, err := r.client.DoGetWithHeaderExtraction(ctx, info.Endpoint[0].Ticket)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("flightsql: %s", err))
+	}
+	defer reader.Release()
 
-		tRes.Responses[q.RefID] = newQueryDataResponse(reader, *qm.Query, headers)
+	headers, err := reader.Header()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to extract headers: %s", err))
 	}
 
-	return tRes, nil
+	return newQueryDataResponse(reader, *qm.Query, headers, rowLimit, querylimits.NewLimiter(qm.LimitSeries, qm.LimitPoints))
+}
+
+// execute runs a query without parameters as a statement. Queries with parameters run as
+// prepared statements, so that the parameters are bound by the server instead of being
+// interpolated into the query. The returned function closes the prepared statement once
+// its result has been read.
+func (r *runner) execute(ctx context.Context, qm *queryModel) (*flight.FlightInfo, func() error, error) {
+	noop := func() error { return nil }
+	if len(qm.Parameters) == 0 {
+		info, err := r.client.Execute(ctx, qm.RawSQL)
+		return info, noop, err
+	}
+
+	params, err := newParameterRecord(r.client.Alloc, qm.Parameters)
+	if err != nil {
+		return nil, noop, err
+	}
+	defer params.Release()
+
+	stmt, err := r.client.Prepare(ctx, qm.RawSQL)
+	if err != nil {
+		return nil, noop, err
+	}
+	closeStmt := func() error { return stmt.Close(ctx) }
+
+	stmt.SetParameters(params)
+	info, err := stmt.Execute(ctx)
+	if err != nil {
+		_ = closeStmt()
+		return nil, noop, err
+	}
+	return info, closeStmt, nil
 }
 
 type runner struct {
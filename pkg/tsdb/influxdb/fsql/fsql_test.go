@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/apache/arrow/go/v15/arrow/flight"
@@ -96,6 +98,102 @@ func (suite *FSQLTestSuite) TestIntegration_QueryData() {
 	})
 }
 
+func (suite *FSQLTestSuite) TestIntegration_QueryDataParameters() {
+	suite.Run("should bind variables as parameters", func() {
+		q, err := json.Marshal(queryRequest{
+			RefID:     "A",
+			RawQuery:  "select * from intTable where keyName = $name or keyName in ($names)",
+			Format:    "table",
+			Variables: map[string][]string{"name": {"one' or 1=1 --"}, "names": {"zero", "negative one"}},
+		})
+		require.NoError(suite.T(), err)
+
+		resp, err := Query(context.Background(), testDatasourceInfo(0), backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: q}},
+		})
+		require.NoError(suite.T(), err)
+
+		respA := resp.Responses["A"]
+		require.NoError(suite.T(), respA.Error)
+		require.Equal(suite.T(), 2, respA.Frames[0].Rows())
+		require.Equal(suite.T(), "select * from intTable where keyName = $1 or keyName in ($2, $3)", respA.Frames[0].Meta.ExecutedQueryString)
+	})
+}
+
+func (suite *FSQLTestSuite) TestIntegration_QueryDataRowLimit() {
+	suite.Run("should limit the rows of the result", func() {
+		resp, err := Query(context.Background(), testDatasourceInfo(2), backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{RefID: "A", JSON: mustQueryJSON(suite.T(), "A", "select * from intTable")}},
+		})
+		require.NoError(suite.T(), err)
+
+		respA := resp.Responses["A"]
+		require.NoError(suite.T(), respA.Error)
+		require.Equal(suite.T(), 2, respA.Frames[0].Rows())
+		require.Len(suite.T(), respA.Frames[0].Meta.Notices, 1)
+	})
+}
+
+func (suite *FSQLTestSuite) TestIntegration_CallResource() {
+	callResource := func(path string) *backend.CallResourceResponse {
+		sender := &fakeSender{}
+		err := CallResource(context.Background(), testDatasourceInfo(0), &backend.CallResourceRequest{
+			Path: strings.Split(path, "?")[0],
+			URL:  path,
+		}, sender)
+		require.NoError(suite.T(), err)
+		return sender.response
+	}
+
+	suite.Run("should list tables", func() {
+		res := callResource("tables")
+		require.Equal(suite.T(), http.StatusOK, res.Status)
+
+		var tables []table
+		require.NoError(suite.T(), json.Unmarshal(res.Body, &tables))
+		names := make([]string, 0, len(tables))
+		for _, t := range tables {
+			names = append(names, t.Name)
+		}
+		require.Contains(suite.T(), names, "intTable")
+		require.Contains(suite.T(), names, "foreignTable")
+	})
+
+	suite.Run("should list columns", func() {
+		res := callResource("columns?table=intTable")
+		require.Equal(suite.T(), http.StatusOK, res.Status)
+
+		var columns []column
+		require.NoError(suite.T(), json.Unmarshal(res.Body, &columns))
+		require.Len(suite.T(), columns, 4)
+		require.Equal(suite.T(), "keyName", columns[1].Name)
+		require.Equal(suite.T(), "utf8", columns[1].Type)
+	})
+
+	suite.Run("should return not found for unknown tables and resources", func() {
+		require.Equal(suite.T(), http.StatusNotFound, callResource("columns?table=unknown").Status)
+		require.Equal(suite.T(), http.StatusNotFound, callResource("unknown").Status)
+	})
+}
+
+type fakeSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.response = res
+	return nil
+}
+
+func testDatasourceInfo(rowLimit int64) *models.DatasourceInfo {
+	return &models.DatasourceInfo{
+		URL:          "http://localhost:12345",
+		DbName:       "influxdb",
+		InsecureGrpc: true,
+		RowLimit:     rowLimit,
+	}
+}
+
 func mustQueryJSON(t *testing.T, refID, sql string) []byte {
 	t.Helper()
 
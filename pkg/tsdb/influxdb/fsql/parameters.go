@@ -0,0 +1,374 @@
+package fsql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// bindParameters replaces the references to template variables by placeholders of a prepared statement
+// where they are values, and returns the values bound to them. Identifiers and literals can't be bound,
+// so references are replaced depending on where they are:
+//   - in expressions, like host = $host, by placeholders, which are bound to the values as strings. The values
+//     of multi-value variables are bound to a list of placeholders, to be used in IN ($hosts). The values in
+//     LIMIT and OFFSET can't be bound, and are written as integers instead.
+//   - in the SELECT, GROUP BY, ORDER BY and PARTITION BY clauses, and in table names, like FROM iox.$measurement,
+//     by identifiers. Values which are not plain identifiers are quoted, multiple values are separated by commas.
+//   - in string literals, like '$prefix%' or interval '$range', and in quoted identifiers, like "$field", by their
+//     values, escaped for the literal or identifier. Multiple values in literals are joined into a regular
+//     expression, like (a|b).
+//
+// The $__timeFrom and $__timeTo macros are replaced by placeholders bound to the time range as timestamps.
+// Other macros, like $__interval, are left to [sqlutil.Interpolate], and references in comments and to unknown
+// variables are kept as they are.
+func bindParameters(sql string, variables map[string][]string, timeRange backend.TimeRange) (string, []any, error) {
+	var (
+		b      strings.Builder
+		params []any
+		// prev is the previous token, which tells if a reference is a table name
+		prev string
+		// clause is the kind of the clause at the current depth of parentheses, and clauses the
+		// kinds of the clauses of the outer parentheses
+		clause  = valueClause
+		clauses []clauseKind
+	)
+	placeholder := func(value any) string {
+		params = append(params, value)
+		return fmt.Sprintf("$%d", len(params))
+	}
+	placeholders := func(values []string) string {
+		list := make([]string, len(values))
+		for i, v := range values {
+			list[i] = placeholder(v)
+		}
+		return strings.Join(list, ", ")
+	}
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			end := quotedEnd(sql, i, '\'')
+			b.WriteString("'" + interpolateLiteral(sql[i+1:end-1], variables) + "'")
+			i, prev = end, "'"
+		case c == '"':
+			end := quotedEnd(sql, i, '"')
+			identifier, err := interpolateIdentifier(sql[i+1:end-1], variables)
+			if err != nil {
+				return "", nil, err
+			}
+			b.WriteString(`"` + identifier + `"`)
+			i, prev = end, `"`
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end == -1 {
+				end = len(sql) - i
+			}
+			b.WriteString(sql[i : i+end])
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end == -1 {
+				end = len(sql) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(sql[i : i+end])
+			i += end
+		case c == '$':
+			name, n := variableRef(sql[i:])
+			values, ok := variables[name]
+			switch {
+			case n == 0:
+				b.WriteByte(c)
+				i++
+				prev = "$"
+				continue
+			case name == "__timeFrom" || name == "__timeTo":
+				// The macros have no arguments, but can be written as calls, like $__timeFrom()
+				n += emptyArgs(sql[i+n:])
+				if name == "__timeFrom" {
+					b.WriteString(placeholder(timeRange.From.UTC()))
+				} else {
+					b.WriteString(placeholder(timeRange.To.UTC()))
+				}
+			case !ok || strings.HasPrefix(name, "__"):
+				b.WriteString(sql[i : i+n])
+			case prev == "." || prev == "FROM" || prev == "JOIN":
+				if len(values) != 1 {
+					return "", nil, fmt.Errorf("variable %q is used as a table name and must have a single value", name)
+				}
+				b.WriteString(identifier(values[0]))
+			case clause == identifierClause && !isComparison(prev):
+				list := make([]string, len(values))
+				for i, v := range values {
+					list[i] = identifier(v)
+				}
+				b.WriteString(strings.Join(list, ", "))
+			case clause == limitClause:
+				if len(values) != 1 || !isInteger(values[0]) {
+					return "", nil, fmt.Errorf("variable %q is used in LIMIT or OFFSET and must have a single integer value", name)
+				}
+				b.WriteString(values[0])
+			default:
+				b.WriteString(placeholders(values))
+			}
+			i += n
+			prev = "$"
+		case isIdentifierChar(c):
+			end := i
+			for end < len(sql) && isIdentifierChar(sql[end]) {
+				end++
+			}
+			b.WriteString(sql[i:end])
+			word := strings.ToUpper(sql[i:end])
+			switch word {
+			case "SELECT":
+				clause = identifierClause
+			case "FROM", "JOIN", "WHERE", "HAVING", "ON", "VALUES":
+				clause = valueClause
+			case "LIMIT", "OFFSET":
+				clause = limitClause
+			case "BY":
+				if prev == "GROUP" || prev == "ORDER" || prev == "PARTITION" {
+					clause = identifierClause
+				}
+			}
+			prev = word
+			i = end
+		default:
+			switch c {
+			case '(':
+				clauses = append(clauses, clause)
+			case ')':
+				if len(clauses) > 0 {
+					clause = clauses[len(clauses)-1]
+					clauses = clauses[:len(clauses)-1]
+				}
+			}
+			b.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+				prev = string(c)
+			}
+			i++
+		}
+	}
+	return b.String(), params, nil
+}
+
+// isComparison returns true for the previous tokens of a value compared in an expression, like in
+// CASE WHEN host = $host, which is bound in clauses where references are otherwise identifiers.
+func isComparison(prev string) bool {
+	switch prev {
+	case "=", "<", ">", "LIKE", "ILIKE":
+		return true
+	}
+	return false
+}
+
+// clauseKind tells how references to variables in a clause of a query are replaced.
+type clauseKind int
+
+const (
+	valueClause clauseKind = iota
+	identifierClause
+	limitClause
+)
+
+// identifier returns the value as an identifier, which is quoted unless it is a plain identifier,
+// so that it keeps referring to the same column as when it was interpolated as text.
+func identifier(value string) string {
+	if isName(value) {
+		return value
+	}
+	return `"` + escapeIdentifier(value) + `"`
+}
+
+// interpolateLiteral replaces the references to variables in a string literal by their values. The values
+// of multi-value variables are joined into a regular expression.
+func interpolateLiteral(literal string, variables map[string][]string) string {
+	var b strings.Builder
+	for i := 0; i < len(literal); {
+		name, n := variableRef(literal[i:])
+		values, ok := variables[name]
+		if n == 0 || !ok || strings.HasPrefix(name, "__") {
+			b.WriteByte(literal[i])
+			i++
+			continue
+		}
+		value := ""
+		switch len(values) {
+		case 0:
+		case 1:
+			value = values[0]
+		default:
+			escaped := make([]string, len(values))
+			for i, v := range values {
+				escaped[i] = regexp.QuoteMeta(v)
+			}
+			value = "(" + strings.Join(escaped, "|") + ")"
+		}
+		b.WriteString(strings.ReplaceAll(value, "'", "''"))
+		i += n
+	}
+	return b.String()
+}
+
+// emptyArgs returns the length of the empty argument list at the start of s, like (), or zero if there is none.
+func emptyArgs(s string) int {
+	if !strings.HasPrefix(s, "(") {
+		return 0
+	}
+	end := strings.IndexByte(s, ')')
+	if end == -1 || strings.TrimSpace(s[1:end]) != "" {
+		return 0
+	}
+	return end + 1
+}
+
+// variableRef returns the name of the variable referenced at the start of s as $name or ${name},
+// and the length of the reference, which is zero if s doesn't start with a reference.
+func variableRef(s string) (string, int) {
+	if len(s) < 2 || s[0] != '$' {
+		return "", 0
+	}
+	if s[1] == '{' {
+		end := strings.IndexByte(s, '}')
+		if end < 3 || !isName(s[2:end]) {
+			return "", 0
+		}
+		return s[2:end], end + 1
+	}
+	end := 1
+	for end < len(s) && isIdentifierChar(s[end]) {
+		end++
+	}
+	if !isName(s[1:end]) {
+		return "", 0
+	}
+	return s[1:end], end
+}
+
+// interpolateIdentifier replaces the references to variables in a quoted identifier by their values.
+func interpolateIdentifier(identifier string, variables map[string][]string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(identifier); {
+		name, n := variableRef(identifier[i:])
+		values, ok := variables[name]
+		if n == 0 || !ok {
+			b.WriteByte(identifier[i])
+			i++
+			continue
+		}
+		if len(values) != 1 {
+			return "", fmt.Errorf("variable %q is used in an identifier and must have a single value", name)
+		}
+		b.WriteString(escapeIdentifier(values[0]))
+		i += n
+	}
+	return b.String(), nil
+}
+
+// quotedEnd returns the index after the quote which closes the literal or identifier at start,
+// where doubled quotes are escaped quotes.
+func quotedEnd(s string, start int, quote byte) int {
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(s)
+}
+
+func escapeIdentifier(s string) string {
+	return strings.ReplaceAll(s, `"`, `""`)
+}
+
+// isName returns true for names of variables, which don't start with a digit so that
+// placeholders like $1 are not references.
+func isName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentifierChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var integerPattern = regexp.MustCompile(`^[0-9]+$`)
+
+func isInteger(s string) bool {
+	return integerPattern.MatchString(s)
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// newParameterRecord returns a record with a single row of the parameters of a prepared statement.
+// Times are bound as timestamps in UTC, and nil as null.
+func newParameterRecord(alloc memory.Allocator, params []any) (arrow.Record, error) {
+	fields := make([]arrow.Field, len(params))
+	columns := make([]arrow.Array, len(params))
+	defer func() {
+		for _, c := range columns {
+			if c != nil {
+				c.Release()
+			}
+		}
+	}()
+
+	for i, p := range params {
+		var builder array.Builder
+		switch v := p.(type) {
+		case string:
+			b := array.NewStringBuilder(alloc)
+			b.Append(v)
+			builder = b
+		case bool:
+			b := array.NewBooleanBuilder(alloc)
+			b.Append(v)
+			builder = b
+		case int:
+			b := array.NewInt64Builder(alloc)
+			b.Append(int64(v))
+			builder = b
+		case int64:
+			b := array.NewInt64Builder(alloc)
+			b.Append(v)
+			builder = b
+		case float64:
+			b := array.NewFloat64Builder(alloc)
+			b.Append(v)
+			builder = b
+		case time.Time:
+			b := array.NewTimestampBuilder(alloc, &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"})
+			b.Append(arrow.Timestamp(v.UnixNano()))
+			builder = b
+		case nil:
+			b := array.NewNullBuilder(alloc)
+			b.AppendNull()
+			builder = b
+		default:
+			return nil, fmt.Errorf("unsupported parameter type %T", p)
+		}
+		columns[i] = builder.NewArray()
+		builder.Release()
+		fields[i] = arrow.Field{Name: fmt.Sprintf("$%d", i+1), Type: columns[i].DataType(), Nullable: p == nil}
+	}
+	return array.NewRecord(arrow.NewSchema(fields, nil), columns, 1), nil
+}
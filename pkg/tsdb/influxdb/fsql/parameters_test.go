@@ -0,0 +1,151 @@
+package fsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindParameters(t *testing.T) {
+	variables := map[string][]string{
+		"host":        {"a'; drop table cpu; --"},
+		"hosts":       {"a", "b"},
+		"measurement": {"cpu"},
+		"field":       {`usage"idle`},
+		"column":      {"usage_idle"},
+		"tags":        {"host", "region"},
+		"prefix":      {"serv"},
+		"range":       {"1 hour"},
+		"threshold":   {"0.5"},
+		"limit":       {"10"},
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	timeRange := backend.TimeRange{From: from.In(time.FixedZone("CET", 3600)), To: to}
+
+	cs := []struct {
+		name   string
+		in     string
+		out    string
+		params []any
+		err    string
+	}{
+		{
+			name: "no parameters",
+			in:   `select * from x`,
+			out:  `select * from x`,
+		},
+		{
+			name:   "time range macros",
+			in:     `select * from x where time >= $__timeFrom and time < $__timeTo() and time < ${__timeTo}`,
+			out:    `select * from x where time >= $1 and time < $2 and time < $3`,
+			params: []any{from, to, to},
+		},
+		{
+			name:   "variables",
+			in:     `select * from x where host = $host or host = ${host}`,
+			out:    `select * from x where host = $1 or host = $2`,
+			params: []any{"a'; drop table cpu; --", "a'; drop table cpu; --"},
+		},
+		{
+			name:   "multi-value variable",
+			in:     `select * from x where host in ($hosts) and time >= $__timeFrom`,
+			out:    `select * from x where host in ($1, $2) and time >= $3`,
+			params: []any{"a", "b", from},
+		},
+		{
+			name:   "numbers",
+			in:     `select * from x where usage_idle > $threshold and host = $limit order by time limit $limit offset $limit`,
+			out:    `select * from x where usage_idle > $1 and host = $2 order by time limit 10 offset 10`,
+			params: []any{"0.5", "10"},
+		},
+		{
+			name: "columns",
+			in:   `select $column, "$field", mean($column) from x group by $tags order by $column`,
+			out:  `select usage_idle, "usage""idle", mean(usage_idle) from x group by host, region order by usage_idle`,
+		},
+		{
+			name: "quoted columns",
+			in:   `select $field from x`,
+			out:  `select "usage""idle" from x`,
+		},
+		{
+			name:   "values in subqueries and comparisons of the select list",
+			in:     `select case when host = $host then $column end from (select $column from x where host in ($hosts))`,
+			out:    `select case when host = $1 then usage_idle end from (select usage_idle from x where host in ($2, $3))`,
+			params: []any{"a'; drop table cpu; --", "a", "b"},
+		},
+		{
+			name: "tables",
+			in:   `select * from iox.$measurement join $measurement on true`,
+			out:  `select * from iox.cpu join cpu on true`,
+		},
+		{
+			name: "literals",
+			in:   `select * from x where host like '$prefix%' and host != '${host}' and time > now() - interval '$range'`,
+			out:  `select * from x where host like 'serv%' and host != 'a''; drop table cpu; --' and time > now() - interval '1 hour'`,
+		},
+		{
+			name: "multi-value variable in a literal",
+			in:   `select * from x where host ~ '^$hosts$'`,
+			out:  `select * from x where host ~ '^(a|b)$'`,
+		},
+		{
+			name: "comments, macros and unknown variables",
+			in:   `select $__interval, $unknown, '$unknown', '$__timeFrom' -- $host $__timeFrom` + "\n" + `/* $host */`,
+			out:  `select $__interval, $unknown, '$unknown', '$__timeFrom' -- $host $__timeFrom` + "\n" + `/* $host */`,
+		},
+		{
+			name: "multi-value variable as a table name",
+			in:   `select * from $hosts`,
+			err:  `variable "hosts" is used as a table name and must have a single value`,
+		},
+		{
+			name: "multi-value variable in a quoted identifier",
+			in:   `select "$hosts" from x`,
+			err:  `variable "hosts" is used in an identifier and must have a single value`,
+		},
+		{
+			name: "limit which is not a number",
+			in:   `select * from x limit $host`,
+			err:  `variable "host" is used in LIMIT or OFFSET and must have a single integer value`,
+		},
+	}
+	for _, c := range cs {
+		t.Run(c.name, func(t *testing.T) {
+			sql, params, err := bindParameters(c.in, variables, timeRange)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.out, sql)
+			require.Equal(t, c.params, params)
+		})
+	}
+}
+
+func TestNewParameterRecord(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	record, err := newParameterRecord(memory.DefaultAllocator, []any{"a", int64(1), 0.5, true, ts, nil})
+	require.NoError(t, err)
+	defer record.Release()
+
+	require.Equal(t, int64(1), record.NumRows())
+	require.Equal(t, "a", record.Column(0).(*array.String).Value(0))
+	require.Equal(t, int64(1), record.Column(1).(*array.Int64).Value(0))
+	require.Equal(t, 0.5, record.Column(2).(*array.Float64).Value(0))
+	require.True(t, record.Column(3).(*array.Boolean).Value(0))
+	require.Equal(t, &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}, record.Column(4).DataType())
+	require.Equal(t, arrow.Timestamp(ts.UnixNano()), record.Column(4).(*array.Timestamp).Value(0))
+	require.Equal(t, 1, record.Column(5).NullN())
+	require.Equal(t, "$6", record.Schema().Field(5).Name)
+
+	_, err = newParameterRecord(memory.DefaultAllocator, []any{struct{}{}})
+	require.EqualError(t, err, "unsupported parameter type struct {}")
+}
@@ -11,6 +11,13 @@ import (
 
 type queryModel struct {
 	*sqlutil.Query
+
+	// Parameters are the values bound to the placeholders of the query.
+	Parameters []any
+
+	// LimitSeries and LimitPoints are the query limits of series and points of the result, zero means no limit.
+	LimitSeries int64
+	LimitPoints int64
 }
 
 // queryRequest is an inbound query request as part of a batch of queries sent
@@ -21,6 +28,10 @@ type queryRequest struct {
 	IntervalMilliseconds int    `json:"intervalMs"`
 	MaxDataPoints        int64  `json:"maxDataPoints"`
 	Format               string `json:"format"`
+
+	// Variables are the values of the template variables referenced in the query, which are bound
+	// as parameters or interpolated depending on where they are referenced, see [bindParameters].
+	Variables map[string][]string `json:"variables"`
 }
 
 func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
@@ -48,12 +59,16 @@ func getQueryModel(dataQuery backend.DataQuery) (*queryModel, error) {
 		Format:        format,
 	}
 
-	// Process macros and execute the query.
-	sql, err := sqlutil.Interpolate(query, macros)
+	// Bind the variables and the time range, then process the other macros.
+	sql, params, err := bindParameters(query.RawSQL, q.Variables, query.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+	sql, err = sqlutil.Interpolate(query.WithSQL(sql), macros)
 	if err != nil {
 		return nil, fmt.Errorf("macro interpolation: %w", err)
 	}
 	query.RawSQL = sql
 
-	return &queryModel{query}, nil
+	return &queryModel{Query: query, Parameters: params}, nil
 }
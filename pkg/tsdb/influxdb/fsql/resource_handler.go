@@ -0,0 +1,196 @@
+package fsql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/flight"
+	"github.com/apache/arrow/go/v15/arrow/flight/flightsql"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
+)
+
+type table struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+type column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// CallResource serves the tables resource, which lists the tables of a schema, and the columns
+// resource, which lists the columns of a table. Both take the schema parameter, and the columns
+// resource also takes the table parameter.
+func CallResource(ctx context.Context, dsInfo *models.DatasourceInfo, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := glog.FromContext(ctx)
+	resourcePath := strings.Trim(req.Path, "/")
+	if resourcePath != "tables" && resourcePath != "columns" {
+		return sendJSON(sender, http.StatusNotFound, map[string]string{"message": fmt.Sprintf("invalid resource URL: %s", req.Path)})
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+	schema, tableName := u.Query().Get("schema"), u.Query().Get("table")
+	if resourcePath == "columns" && tableName == "" {
+		return sendJSON(sender, http.StatusBadRequest, map[string]string{"message": "table is required"})
+	}
+
+	r, err := runnerFromDataSource(dsInfo)
+	if err != nil {
+		return err
+	}
+	defer func(client *client) {
+		if err := client.Close(); err != nil {
+			logger.Warn("Failed to close fsql client", "err", err)
+		}
+	}(r.client)
+
+	if r.client.md.Len() != 0 {
+		ctx = metadata.NewOutgoingContext(ctx, r.client.md)
+	}
+
+	if resourcePath == "tables" {
+		tables, err := r.tables(ctx, schema, "", false)
+		if err != nil {
+			logger.Error("Failed to list tables", "error", err)
+			return sendJSON(sender, http.StatusBadGateway, map[string]string{"message": fmt.Sprintf("flightsql: %s", err)})
+		}
+		result := make([]table, 0, len(tables))
+		for _, t := range tables {
+			result = append(result, t.table)
+		}
+		return sendJSON(sender, http.StatusOK, result)
+	}
+
+	tables, err := r.tables(ctx, schema, tableName, true)
+	if err != nil {
+		logger.Error("Failed to list columns", "error", err)
+		return sendJSON(sender, http.StatusBadGateway, map[string]string{"message": fmt.Sprintf("flightsql: %s", err)})
+	}
+	for _, t := range tables {
+		// The filter is a LIKE pattern, so other tables can match it
+		if t.Name != tableName {
+			continue
+		}
+		columns := make([]column, 0, len(t.schema.Fields()))
+		for _, f := range t.schema.Fields() {
+			columns = append(columns, column{Name: f.Name, Type: f.Type.String(), Nullable: f.Nullable})
+		}
+		return sendJSON(sender, http.StatusOK, columns)
+	}
+	return sendJSON(sender, http.StatusNotFound, map[string]string{"message": fmt.Sprintf("table %q not found", tableName)})
+}
+
+type tableWithSchema struct {
+	table
+	schema *arrow.Schema
+}
+
+// tables returns the tables of the schema, or of all schemas if it's empty, whose names match
+// the tableName pattern.
+func (r *runner) tables(ctx context.Context, schema, tableName string, includeSchema bool) ([]tableWithSchema, error) {
+	opts := &flightsql.GetTablesOpts{IncludeSchema: includeSchema}
+	if schema != "" {
+		opts.DbSchemaFilterPattern = &schema
+	}
+	if tableName != "" {
+		opts.TableNameFilterPattern = &tableName
+	}
+
+	info, err := r.client.GetTables(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []tableWithSchema
+	for _, endpoint := range info.Endpoint {
+		reader, err := r.client.DoGet(ctx, endpoint.Ticket)
+		if err != nil {
+			return nil, err
+		}
+		tables, err = readTables(reader, r.client.Alloc, tables, includeSchema)
+		reader.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// readTables appends the tables of a GetTables result to tables.
+func readTables(reader *flight.Reader, alloc memory.Allocator, tables []tableWithSchema, includeSchema bool) ([]tableWithSchema, error) {
+	for reader.Next() {
+		record := reader.Record()
+		schemas, err := recordColumn[*array.String](record, "db_schema_name")
+		if err != nil {
+			return nil, err
+		}
+		names, err := recordColumn[*array.String](record, "table_name")
+		if err != nil {
+			return nil, err
+		}
+		types, err := recordColumn[*array.String](record, "table_type")
+		if err != nil {
+			return nil, err
+		}
+		var tableSchemas *array.Binary
+		if includeSchema {
+			if tableSchemas, err = recordColumn[*array.Binary](record, "table_schema"); err != nil {
+				return nil, err
+			}
+		}
+
+		for i := 0; i < int(record.NumRows()); i++ {
+			t := tableWithSchema{table: table{Schema: schemas.Value(i), Name: names.Value(i), Type: types.Value(i)}}
+			if includeSchema {
+				t.schema, err = flight.DeserializeSchema(tableSchemas.Value(i), alloc)
+				if err != nil {
+					return nil, fmt.Errorf("table schema of %q: %w", t.Name, err)
+				}
+			}
+			tables = append(tables, t)
+		}
+	}
+	return tables, reader.Err()
+}
+
+// recordColumn returns the column of a record with the name and the array type T.
+func recordColumn[T arrow.Array](record arrow.Record, name string) (T, error) {
+	var col T
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return col, fmt.Errorf("missing column %q", name)
+	}
+	col, ok := record.Column(indices[0]).(T)
+	if !ok {
+		return col, fmt.Errorf("unexpected type %s of column %q", record.Column(indices[0]).DataType(), name)
+	}
+	return col, nil
+}
+
+func sendJSON(sender backend.CallResourceResponseSender, status int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -72,6 +73,14 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			database = settings.Database
 		}
 
+		// The row limit of the data source takes precedence over the one of the [dataproxy] configuration
+		rowLimit := jsonData.RowLimit
+		if rowLimit <= 0 {
+			if sqlCfg, err := backend.GrafanaConfigFromContext(ctx).SQL(); err == nil {
+				rowLimit = sqlCfg.RowLimit
+			}
+		}
+
 		model := &models.DatasourceInfo{
 			HTTPClient:    client,
 			URL:           settings.URL,
@@ -83,6 +92,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			Organization:  jsonData.Organization,
 			MaxSeries:     maxSeries,
 			InsecureGrpc:  jsonData.InsecureGrpc,
+			RowLimit:      rowLimit,
 			Token:         settings.DecryptedSecureJSONData["token"],
 			Timeout:       opts.Timeouts.Timeout,
 		}
@@ -115,6 +125,20 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 }
 
+// CallResource serves the resources of the FlightSQL mode, which list the tables and columns of the
+// database for the query builder.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+
+	if dsInfo.Version != influxVersionSQL {
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusNotFound})
+	}
+	return fsql.CallResource(ctx, dsInfo, req, sender)
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*models.DatasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
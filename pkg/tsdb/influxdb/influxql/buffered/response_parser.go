@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/util"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
@@ -44,6 +45,10 @@ func parse(buf io.Reader, statusCode int, query *models.Query) *backend.DataResp
 		return &backend.DataResponse{Frames: transformRowsForTable(result.Series, *query)}
 	}
 
+	if err := checkTimeSeriesLimits(result.Series, querylimits.NewLimiter(query.MaxSeries, query.MaxPoints)); err != nil {
+		return &backend.DataResponse{Error: err}
+	}
+
 	return &backend.DataResponse{Frames: transformRowsForTimeSeries(result.Series, *query)}
 }
 
@@ -268,6 +273,41 @@ func transformRowsForTimeSeries(rows []models.Row, query models.Query) data.Fram
 	return frames
 }
 
+// checkTimeSeriesLimits counts the series and points of the numeric columns of rows with a time column,
+// before they are transformed to frames.
+func checkTimeSeriesLimits(rows []models.Row, limiter *querylimits.Limiter) error {
+	if limiter == nil {
+		return nil
+	}
+	for _, row := range rows {
+		var hasTimeCol bool
+		for _, column := range row.Columns {
+			if strings.ToLower(column) == "time" {
+				hasTimeCol = true
+			}
+		}
+		if !hasTimeCol {
+			continue
+		}
+
+		var points int64
+		for _, valuePair := range row.Values {
+			if _, err := util.ParseTimestamp(valuePair[0]); err == nil {
+				points++
+			}
+		}
+		for colIndex, column := range row.Columns {
+			if column == "time" || util.Typeof(row.Values, colIndex) != "json.Number" {
+				continue
+			}
+			if err := limiter.Add(1, points); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func newFrameWithTimeField(row models.Row, column string, colIndex int, query models.Query, frameName []byte) *data.Frame {
 	var timeArray []time.Time
 	var floatArray []*float64
@@ -16,6 +16,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/util"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
@@ -96,6 +98,45 @@ func runScenario(tf string, resultFormat string) func(t *testing.T) {
 	}
 }
 
+func TestResponseParseWithLimits(t *testing.T) {
+	for _, tf := range testFiles {
+		t.Run(tf, func(t *testing.T) {
+			rsp := ResponseParse(readJsonFile(tf), 200, generateQuery("time_series", ""))
+			require.NoError(t, rsp.Error)
+
+			// The parser must only stop at limits which the result exceeds
+			for limit := int64(1); limit <= 64; limit++ {
+				query := generateQuery("time_series", "")
+				query.MaxSeries = limit
+				if limited := ResponseParse(readJsonFile(tf), 200, query); limited.Error != nil {
+					require.NotNil(t, querylimits.Check(setting.QueryLimits{MaxSeries: limit}, *rsp), "stopped at %d series", limit)
+				}
+				query = generateQuery("time_series", "")
+				query.MaxPoints = limit
+				if limited := ResponseParse(readJsonFile(tf), 200, query); limited.Error != nil {
+					require.NotNil(t, querylimits.Check(setting.QueryLimits{MaxPoints: limit}, *rsp), "stopped at %d points", limit)
+				}
+			}
+		})
+	}
+
+	t.Run("stops at the limits", func(t *testing.T) {
+		query := generateQuery("time_series", "")
+		query.MaxSeries = 54
+		rsp := ResponseParse(readJsonFile("multiple_series_with_tags_and_multiple_columns"), 200, query)
+		require.EqualError(t, rsp.Error, "query result exceeds the limit of 54 series")
+		require.Nil(t, rsp.Frames)
+
+		query.MaxSeries, query.MaxPoints = 55, 54
+		rsp = ResponseParse(readJsonFile("multiple_series_with_tags_and_multiple_columns"), 200, query)
+		require.EqualError(t, rsp.Error, "query result exceeds the limit of 54 points")
+
+		query.MaxPoints = 55
+		rsp = ResponseParse(readJsonFile("multiple_series_with_tags_and_multiple_columns"), 200, query)
+		require.NoError(t, rsp.Error)
+	})
+}
+
 func TestInfluxdbResponseParser(t *testing.T) {
 	t.Run("Influxdb response parser should handle invalid JSON", func(t *testing.T) {
 		result := ResponseParse(
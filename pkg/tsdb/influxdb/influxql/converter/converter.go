@@ -10,6 +10,7 @@ import (
 	sdkjsoniter "github.com/grafana/grafana-plugin-sdk-go/data/utils/jsoniter"
 	jsoniter "github.com/json-iterator/go"
 
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/util"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
@@ -20,6 +21,7 @@ func rspErr(e error) *backend.DataResponse {
 
 func ReadInfluxQLStyleResult(jIter *jsoniter.Iterator, query *models.Query) *backend.DataResponse {
 	iter := sdkjsoniter.NewIterator(jIter)
+	limiter := querylimits.NewLimiter(query.MaxSeries, query.MaxPoints)
 	var rsp *backend.DataResponse
 
 l1Fields:
@@ -29,7 +31,7 @@ l1Fields:
 		}
 		switch l1Field {
 		case "results":
-			rsp = readResults(iter, query)
+			rsp = readResults(iter, query, limiter)
 			if rsp.Error != nil {
 				return rsp
 			}
@@ -51,7 +53,7 @@ l1Fields:
 	return rsp
 }
 
-func readResults(iter *sdkjsoniter.Iterator, query *models.Query) *backend.DataResponse {
+func readResults(iter *sdkjsoniter.Iterator, query *models.Query, limiter *querylimits.Limiter) *backend.DataResponse {
 	rsp := &backend.DataResponse{Frames: make(data.Frames, 0)}
 l1Fields:
 	for more, err := iter.ReadArray(); more; more, err = iter.ReadArray() {
@@ -64,7 +66,10 @@ l1Fields:
 			}
 			switch l1Field {
 			case "series":
-				rsp = readSeries(iter, query)
+				rsp = readSeries(iter, query, limiter)
+				if rsp.Error != nil {
+					return rsp
+				}
 			case "":
 				break l1Fields
 			default:
@@ -79,7 +84,9 @@ l1Fields:
 	return rsp
 }
 
-func readSeries(iter *sdkjsoniter.Iterator, query *models.Query) *backend.DataResponse {
+// readSeries reads the series of a result. The limiter counts a series for each numeric column of
+// time series, and a point for each number of time series and tables.
+func readSeries(iter *sdkjsoniter.Iterator, query *models.Query, limiter *querylimits.Limiter) *backend.DataResponse {
 	var (
 		measurement   string
 		tags          map[string]string
@@ -120,10 +127,26 @@ func readSeries(iter *sdkjsoniter.Iterator, query *models.Query) *backend.DataRe
 					hasTimeColumn = true
 				}
 			case "values":
-				valueFields, err = readValues(iter, hasTimeColumn)
+				isTable := util.GetVisType(query.ResultFormat) == util.TableVisType
+				pointsLimiter := limiter
+				if !hasTimeColumn && !isTable {
+					// Only one of the columns becomes a frame
+					pointsLimiter = nil
+				}
+				valueFields, err = readValues(iter, hasTimeColumn, pointsLimiter)
 				if err != nil {
 					return rspErr(err)
 				}
+				if hasTimeColumn && !isTable {
+					for _, v := range valueFields[1:] {
+						if v.Type() != data.FieldTypeNullableFloat64 {
+							continue
+						}
+						if err := limiter.Add(1, 0); err != nil {
+							return rspErr(err)
+						}
+					}
+				}
 				if util.GetVisType(query.ResultFormat) != util.TableVisType {
 					for i, v := range valueFields {
 						if v.Type() == data.FieldTypeNullableJSON {
@@ -209,7 +232,7 @@ func readColumns(iter *sdkjsoniter.Iterator) (columns []string, err error) {
 	return columns, nil
 }
 
-func readValues(iter *sdkjsoniter.Iterator, hasTimeColumn bool) (valueFields data.Fields, err error) {
+func readValues(iter *sdkjsoniter.Iterator, hasTimeColumn bool, limiter *querylimits.Limiter) (valueFields data.Fields, err error) {
 	if hasTimeColumn {
 		valueFields = append(valueFields, data.NewField("Time", nil, make([]time.Time, 0)))
 	}
@@ -261,6 +284,11 @@ func readValues(iter *sdkjsoniter.Iterator, hasTimeColumn bool) (valueFields dat
 				valueFields = maybeCreateValueField(valueFields, data.FieldTypeNullableFloat64, colIdx)
 				maybeFixValueFieldType(valueFields, data.FieldTypeNullableFloat64, colIdx)
 				tryToAppendValue(valueFields, &n, colIdx)
+				if valueFields[colIdx].Type() == data.FieldTypeNullableFloat64 {
+					if err := limiter.Add(0, 1); err != nil {
+						return nil, err
+					}
+				}
 			case jsoniter.BoolValue:
 				b, err := iter.ReadAny()
 				if err != nil {
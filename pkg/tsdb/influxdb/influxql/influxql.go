@@ -17,6 +17,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/buffered"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/influxql/querydata"
@@ -58,6 +59,7 @@ func Query(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceIn
 
 			query.RefID = reqQuery.RefID
 			query.RawQuery = rawQuery
+			query.MaxSeries, query.MaxPoints = querylimits.LimitsFromHeaders(req.Headers)
 
 			if setting.Env == setting.Dev {
 				logger.Debug("Influxdb query", "raw query", rawQuery)
@@ -97,6 +99,7 @@ func Query(ctx context.Context, tracer trace.Tracer, dsInfo *models.DatasourceIn
 
 			query.RefID = reqQuery.RefID
 			query.RawQuery = rawQuery
+			query.MaxSeries, query.MaxPoints = querylimits.LimitsFromHeaders(req.Headers)
 
 			if setting.Env == setting.Dev {
 				logger.Debug("Influxdb query", "raw query", rawQuery)
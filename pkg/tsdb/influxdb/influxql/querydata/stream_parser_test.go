@@ -11,6 +11,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/experimental"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/influxdb/models"
 )
 
@@ -78,3 +80,49 @@ func runScenario(tf string, resultFormat string) func(t *testing.T) {
 		experimental.CheckGoldenJSONResponse(t, testPath, fname, rsp, shouldUpdate)
 	}
 }
+
+func TestResponseParseWithLimits(t *testing.T) {
+	parse := func(t *testing.T, tf string, query *models.Query) *backend.DataResponse {
+		f, err := os.Open(path.Join(testPath, filepath.Clean(tf+".json")))
+		require.NoError(t, err)
+		return ResponseParse(f, 200, query)
+	}
+
+	for _, resultFormat := range []string{"time_series", "table"} {
+		for _, tf := range testFiles {
+			if strings.Contains(tf, "error") {
+				continue
+			}
+			t.Run(resultFormat+"/"+tf, func(t *testing.T) {
+				rsp := parse(t, tf, &models.Query{RawQuery: "Test raw query", UseRawQuery: true, ResultFormat: resultFormat})
+				require.NoError(t, rsp.Error)
+
+				// The parser must only stop at limits which the result exceeds
+				for limit := int64(1); limit <= 64; limit++ {
+					limited := parse(t, tf, &models.Query{RawQuery: "Test raw query", UseRawQuery: true, ResultFormat: resultFormat, MaxSeries: limit})
+					if limited.Error != nil {
+						require.NotNil(t, querylimits.Check(setting.QueryLimits{MaxSeries: limit}, *rsp), "stopped at %d series", limit)
+					}
+					limited = parse(t, tf, &models.Query{RawQuery: "Test raw query", UseRawQuery: true, ResultFormat: resultFormat, MaxPoints: limit})
+					if limited.Error != nil {
+						require.NotNil(t, querylimits.Check(setting.QueryLimits{MaxPoints: limit}, *rsp), "stopped at %d points", limit)
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("stops reading at the limits", func(t *testing.T) {
+		query := &models.Query{RawQuery: "Test raw query", UseRawQuery: true, ResultFormat: "time_series", MaxSeries: 54}
+		rsp := parse(t, "multiple_series_with_tags_and_multiple_columns", query)
+		require.EqualError(t, rsp.Error, "query result exceeds the limit of 54 series")
+
+		query = &models.Query{RawQuery: "Test raw query", UseRawQuery: true, ResultFormat: "table", MaxPoints: 54}
+		rsp = parse(t, "multiple_series_with_tags_and_multiple_columns", query)
+		require.EqualError(t, rsp.Error, "query result exceeds the limit of 54 points")
+
+		query = &models.Query{RawQuery: "Test raw query", UseRawQuery: true, ResultFormat: "time_series", MaxSeries: 55, MaxPoints: 55}
+		rsp = parse(t, "multiple_series_with_tags_and_multiple_columns", query)
+		require.NoError(t, rsp.Error)
+	})
+}
@@ -22,4 +22,6 @@ type DatasourceInfo struct {
 
 	// FlightSQL grpc connection
 	InsecureGrpc bool `json:"insecureGrpc"`
+	// RowLimit is the maximum number of rows of the results of FlightSQL queries
+	RowLimit int64 `json:"rowLimit"`
 }
@@ -18,6 +18,9 @@ type Query struct {
 	OrderByTime  string
 	RefID        string
 	ResultFormat string
+	// MaxSeries and MaxPoints are the limits of series and points of the result, zero means no limit.
+	MaxSeries int64
+	MaxPoints int64
 }
 
 type Tag struct {
@@ -33,6 +33,7 @@ type LokiAPI struct {
 	log                       log.Logger
 	tracer                    tracing.Tracer
 	requestStructuredMetadata bool
+	querySplit                querySplitOptions
 }
 
 type RawLokiResponse struct {
@@ -223,7 +224,11 @@ func (api *LokiAPI) DataQuery(ctx context.Context, query lokiQuery, responseOpts
 	defer span.End()
 
 	iter := jsoniter.Parse(jsoniter.ConfigDefault, resp.Body, 1024)
-	res := converter.ReadPrometheusStyleResult(iter, converter.Options{Dataplane: responseOpts.metricDataplane})
+	res := converter.ReadPrometheusStyleResult(iter, converter.Options{
+		Dataplane: responseOpts.metricDataplane,
+		MaxSeries: responseOpts.maxSeries,
+		MaxPoints: responseOpts.maxPoints,
+	})
 
 	if res.Error != nil {
 		span.RecordError(res.Error)
@@ -280,3 +280,33 @@ func TestApiReturnValues(t *testing.T) {
 		require.ErrorContains(t, err, "foo")
 	})
 }
+
+func TestApiQueryLimits(t *testing.T) {
+	response := []byte(`
+	{
+		"status": "success",
+		"data": {
+			"resultType" : "streams",
+			"result": [
+				{"stream": {"job": "a"}, "values": [["1645030244810757120", "line 1"], ["1645030246277587968", "line 2"]]},
+				{"stream": {"job": "b"}, "values": [["1645030247800000000", "line 3"]]}
+			]
+		}
+	}
+	`)
+
+	t.Run("stops reading responses which exceed the limit of points", func(t *testing.T) {
+		api := makeMockedAPI(200, "application/json", response, nil, false)
+
+		_, err := api.DataQuery(context.Background(), lokiQuery{QueryType: QueryTypeRange}, ResponseOpts{maxPoints: 2})
+		require.EqualError(t, err, "query result exceeds the limit of 2 points")
+	})
+
+	t.Run("reads responses within the limits", func(t *testing.T) {
+		api := makeMockedAPI(200, "application/json", response, nil, false)
+
+		res, err := api.DataQuery(context.Background(), lokiQuery{QueryType: QueryTypeRange}, ResponseOpts{maxSeries: 1, maxPoints: 3})
+		require.NoError(t, err)
+		require.Equal(t, 3, res.Frames[0].Rows())
+	})
+}
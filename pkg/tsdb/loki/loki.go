@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
 )
 
@@ -63,6 +64,7 @@ var (
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	QuerySplit querySplitOptions
 
 	// open streams
 	streams   map[string]data.FrameJSONCache
@@ -78,6 +80,9 @@ type QueryJSONModel struct {
 type ResponseOpts struct {
 	metricDataplane bool
 	logsDataplane   bool
+	// maxSeries and maxPoints are the query limits of the request, zero means no limit
+	maxSeries int64
+	maxPoints int64
 }
 
 func parseQueryModel(raw json.RawMessage) (*QueryJSONModel, error) {
@@ -98,9 +103,15 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, err
 		}
 
+		querySplit, err := parseQuerySplitOptions(settings.JSONData)
+		if err != nil {
+			return nil, err
+		}
+
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
+			QuerySplit: querySplit,
 			streams:    make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
@@ -163,6 +174,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		metricDataplane: s.features.IsEnabled(ctx, featuremgmt.FlagLokiMetricDataplane),
 		logsDataplane:   s.features.IsEnabled(ctx, featuremgmt.FlagLokiLogsDataplane),
 	}
+	responseOpts.maxSeries, responseOpts.maxPoints = querylimits.LimitsFromHeaders(req.Headers)
 
 	return queryData(ctx, req, dsInfo, responseOpts, s.tracer, logger, s.features.IsEnabled(ctx, featuremgmt.FlagLokiRunQueriesInParallel), s.features.IsEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata))
 }
@@ -171,6 +183,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	result := backend.NewQueryDataResponse()
 
 	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, plog, tracer, requestStructuredMetadata)
+	api.querySplit = dsInfo.QuerySplit
 
 	start := time.Now()
 	queries, err := parseQuery(req)
@@ -243,7 +256,7 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 // we extracted this part of the functionality to make it easy to unit-test it
 func runQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
-	res, err := api.DataQuery(ctx, *query, responseOpts)
+	res, err := api.SplitDataQuery(ctx, *query, responseOpts)
 	if err != nil {
 		plog.Error("Error querying loki", "error", err)
 		return res, err
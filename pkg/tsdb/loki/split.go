@@ -0,0 +1,397 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const defaultQuerySplitConcurrency = 4
+
+// querySplitOptions configures splitting range queries into smaller queries by time.
+// Splitting is disabled when the duration is zero.
+type querySplitOptions struct {
+	Duration    time.Duration
+	Concurrency int
+}
+
+func parseQuerySplitOptions(jsonData json.RawMessage) (querySplitOptions, error) {
+	opts := querySplitOptions{Concurrency: defaultQuerySplitConcurrency}
+	if len(jsonData) == 0 {
+		return opts, nil
+	}
+
+	settings := struct {
+		QuerySplitDuration    string `json:"querySplitDuration"`
+		QuerySplitConcurrency *int   `json:"querySplitConcurrency"`
+	}{}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return opts, fmt.Errorf("error reading settings: %w", err)
+	}
+
+	if settings.QuerySplitDuration != "" {
+		duration, err := gtime.ParseDuration(settings.QuerySplitDuration)
+		if err != nil {
+			return opts, fmt.Errorf("invalid querySplitDuration: %w", err)
+		}
+		opts.Duration = duration
+	}
+	if settings.QuerySplitConcurrency != nil {
+		if *settings.QuerySplitConcurrency < 1 {
+			return opts, fmt.Errorf("invalid querySplitConcurrency: %d", *settings.QuerySplitConcurrency)
+		}
+		opts.Concurrency = *settings.QuerySplitConcurrency
+	}
+	return opts, nil
+}
+
+// splitQuery splits a range query into queries of at most the split duration, in the
+// order they should run: newest first for backward queries, so that we can stop when
+// enough log lines have been received. Split durations are rounded down to a multiple
+// of the step, so that metric queries are evaluated at the same timestamps. Nil is
+// returned if the query doesn't need to be split.
+func splitQuery(query lokiQuery, duration time.Duration) []lokiQuery {
+	if query.QueryType != QueryTypeRange || duration <= 0 {
+		return nil
+	}
+
+	size := duration
+	if query.Step > 0 {
+		size = duration - duration%query.Step
+		if size < query.Step {
+			size = query.Step
+		}
+	}
+	if query.End.Sub(query.Start) <= size {
+		return nil
+	}
+
+	var queries []lokiQuery
+	for start := query.Start; start.Before(query.End); start = start.Add(size) {
+		end := start.Add(size)
+		if end.After(query.End) {
+			end = query.End
+		}
+		q := query
+		q.Start = start
+		q.End = end
+		queries = append(queries, q)
+	}
+
+	if query.Direction == DirectionBackward {
+		for i, j := 0, len(queries)-1; i < j; i, j = i+1, j-1 {
+			queries[i], queries[j] = queries[j], queries[i]
+		}
+	}
+	return queries
+}
+
+// SplitDataQuery runs the query like DataQuery, but split into smaller queries by time if
+// query splitting is configured. The frames of the split queries are merged into the frames
+// that a single query would have returned.
+func (api *LokiAPI) SplitDataQuery(ctx context.Context, query lokiQuery, responseOpts ResponseOpts) (*backend.DataResponse, error) {
+	queries := splitQuery(query, api.querySplit.Duration)
+	if len(queries) < 2 {
+		return api.DataQuery(ctx, query, responseOpts)
+	}
+
+	api.log.Debug("Splitting query to loki", "query", query.Expr, "queries", len(queries), "splitDuration", api.querySplit.Duration)
+
+	concurrency := api.querySplit.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultQuerySplitConcurrency
+	}
+
+	responses := make([]*backend.DataResponse, 0, len(queries))
+	for batchStart := 0; batchStart < len(queries); batchStart += concurrency {
+		batch := queries[batchStart:min(batchStart+concurrency, len(queries))]
+		batchResponses, err := api.runSplitQueries(ctx, batch, responseOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range batchResponses {
+			if res.Error != nil {
+				return res, nil
+			}
+		}
+		responses = append(responses, batchResponses...)
+
+		// later queries only return older log lines, which would be dropped anyway
+		if query.Direction == DirectionBackward && query.MaxLines > 0 && countLogLines(responses) >= query.MaxLines {
+			break
+		}
+	}
+
+	// merge in time order
+	if query.Direction == DirectionBackward {
+		for i, j := 0, len(responses)-1; i < j; i, j = i+1, j-1 {
+			responses[i], responses[j] = responses[j], responses[i]
+		}
+	}
+	return &backend.DataResponse{Frames: mergeSplitFrames(responses, query)}, nil
+}
+
+func (api *LokiAPI) runSplitQueries(ctx context.Context, queries []lokiQuery, responseOpts ResponseOpts) ([]*backend.DataResponse, error) {
+	responses := make([]*backend.DataResponse, len(queries))
+	errs := make([]error, len(queries))
+
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q lokiQuery) {
+			defer wg.Done()
+			responses[i], errs[i] = api.DataQuery(ctx, q, responseOpts)
+			if responses[i] == nil && errs[i] == nil {
+				responses[i] = &backend.DataResponse{}
+			}
+		}(i, q)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+func isMetricFrame(frame *data.Frame) bool {
+	return len(frame.Fields) == 2 && frame.Fields[1].Type() == data.FieldTypeFloat64
+}
+
+// logFields are the fields of a log frame that identify and order its lines. They are looked up
+// by name, since frames parsed from Loki responses, legacy and dataplane log frames have
+// different fields, and Loki adds the label types field for structured metadata.
+type logFields struct {
+	labels *data.Field
+	time   *data.Field
+	line   *data.Field
+	// tsNs is the nanosecond timestamp of the line, which is only the prefix of the id in
+	// dataplane frames.
+	tsNs *data.Field
+	id   *data.Field
+}
+
+func getLogFields(frame *data.Frame) (logFields, bool) {
+	var fields logFields
+	for _, field := range frame.Fields {
+		switch {
+		case (field.Name == "__labels" || field.Name == "labels") && field.Type() == data.FieldTypeJSON:
+			fields.labels = field
+		case (field.Name == "Time" || field.Name == "timestamp") && field.Type() == data.FieldTypeTime:
+			fields.time = field
+		case (field.Name == "Line" || field.Name == "body") && field.Type() == data.FieldTypeString:
+			fields.line = field
+		case (field.Name == "TS" || field.Name == "tsNs") && field.Type() == data.FieldTypeString:
+			fields.tsNs = field
+		case field.Name == "id" && field.Type() == data.FieldTypeString:
+			fields.id = field
+		}
+	}
+	isLogs := frame.Meta != nil && frame.Meta.Type == data.FrameTypeLogLines
+	if !isLogs {
+		isLogs = fields.labels != nil && fields.tsNs != nil
+	}
+	return fields, isLogs && fields.time != nil && fields.line != nil
+}
+
+// timestamp returns the nanosecond timestamp of the line.
+func (f logFields) timestamp(row int) int64 {
+	var tsNs string
+	if f.tsNs != nil {
+		tsNs, _ = f.tsNs.At(row).(string)
+	} else if f.id != nil {
+		id, _ := f.id.At(row).(string)
+		tsNs, _, _ = strings.Cut(id, "_")
+	}
+	if ts, err := strconv.ParseInt(tsNs, 10, 64); err == nil {
+		return ts
+	}
+	t, _ := f.time.At(row).(time.Time)
+	return t.UnixNano()
+}
+
+func isLogsFrame(frame *data.Frame) bool {
+	_, ok := getLogFields(frame)
+	return ok
+}
+
+func countLogLines(responses []*backend.DataResponse) int {
+	lines := 0
+	for _, res := range responses {
+		for _, frame := range res.Frames {
+			if isLogsFrame(frame) {
+				lines += frame.Rows()
+			}
+		}
+	}
+	return lines
+}
+
+// mergeSplitFrames merges the frames of split queries, which are in time order. Samples of
+// metric series are concatenated, skipping samples at the shared boundary of two queries.
+// Log lines are merged into a single frame, deduplicated across queries, sorted in the
+// query direction and limited to the max lines of the query.
+func mergeSplitFrames(responses []*backend.DataResponse, query lokiQuery) data.Frames {
+	var merged, logFrames, other data.Frames
+	frames := map[string]*data.Frame{}
+	logLines := map[string]bool{}
+
+	for _, res := range responses {
+		seenInResponse := map[string]bool{}
+		for _, frame := range res.Frames {
+			if !isMetricFrame(frame) && !isLogsFrame(frame) {
+				other = append(other, frame)
+				continue
+			}
+
+			key := frameKey(frame)
+			target, ok := frames[key]
+			if !ok {
+				target = emptyFrameCopy(frame)
+				frames[key] = target
+				merged = append(merged, target)
+				if isLogsFrame(frame) {
+					logFrames = append(logFrames, target)
+				}
+			}
+
+			if isMetricFrame(frame) {
+				appendMetricSamples(target, frame)
+				continue
+			}
+			fields, _ := getLogFields(frame)
+			for i := 0; i < frame.Rows(); i++ {
+				lineKey := logLineKey(fields, i)
+				if logLines[lineKey] && !seenInResponse[lineKey] {
+					continue
+				}
+				seenInResponse[lineKey] = true
+				target.AppendRow(frame.RowCopy(i)...)
+			}
+		}
+		for key := range seenInResponse {
+			logLines[key] = true
+		}
+	}
+
+	for _, logs := range logFrames {
+		sortLogLines(logs, query.Direction)
+		if query.MaxLines > 0 && logs.Rows() > query.MaxLines {
+			for i, field := range logs.Fields {
+				logs.Fields[i] = emptyFieldCopy(field)
+				for row := 0; row < query.MaxLines; row++ {
+					logs.Fields[i].Append(field.CopyAt(row))
+				}
+			}
+		}
+	}
+
+	// frames without data, like the frame of an empty result, are only kept once
+	for _, frame := range other {
+		if len(frame.Fields) > 0 || len(merged) == 0 {
+			merged = append(merged, frame)
+		}
+	}
+	return merged
+}
+
+// frameKey identifies the frames of the same metric series, or log frames with the same
+// fields, by the name of the frame and the names, types and labels of its fields.
+func frameKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("\x00")
+		sb.WriteString(field.Name)
+		sb.WriteString("\x00")
+		sb.WriteString(field.Type().String())
+		sb.WriteString("\x00")
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
+
+func appendMetricSamples(target *data.Frame, frame *data.Frame) {
+	var last time.Time
+	if rows := target.Rows(); rows > 0 {
+		last = target.Fields[0].At(rows - 1).(time.Time)
+	}
+	for i := 0; i < frame.Rows(); i++ {
+		if t := frame.Fields[0].At(i).(time.Time); !last.IsZero() && !t.After(last) {
+			continue
+		}
+		target.AppendRow(frame.RowCopy(i)...)
+	}
+}
+
+// logLineKey identifies a log line by its nanosecond timestamp, labels and line.
+func logLineKey(fields logFields, row int) string {
+	var labels json.RawMessage
+	if fields.labels != nil {
+		labels, _ = fields.labels.At(row).(json.RawMessage)
+	}
+	line, _ := fields.line.At(row).(string)
+	return strconv.FormatInt(fields.timestamp(row), 10) + "\x00" + string(labels) + "\x00" + line
+}
+
+func sortLogLines(frame *data.Frame, direction Direction) {
+	fields, _ := getLogFields(frame)
+	rows := frame.Rows()
+	timestamps := make([]int64, rows)
+	for i := 0; i < rows; i++ {
+		timestamps[i] = fields.timestamp(i)
+	}
+
+	order := make([]int, rows)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if direction == DirectionForward {
+			return timestamps[order[a]] < timestamps[order[b]]
+		}
+		return timestamps[order[a]] > timestamps[order[b]]
+	})
+
+	for i, field := range frame.Fields {
+		sorted := emptyFieldCopy(field)
+		for _, row := range order {
+			sorted.Append(field.CopyAt(row))
+		}
+		frame.Fields[i] = sorted
+	}
+}
+
+// emptyFrameCopy returns a frame with the same name, metadata and fields as the frame, but
+// without rows.
+func emptyFrameCopy(frame *data.Frame) *data.Frame {
+	fields := make([]*data.Field, len(frame.Fields))
+	for i, field := range frame.Fields {
+		fields[i] = emptyFieldCopy(field)
+	}
+	copied := data.NewFrame(frame.Name, fields...)
+	copied.RefID = frame.RefID
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		copied.Meta = &meta
+	}
+	return copied
+}
+
+func emptyFieldCopy(field *data.Field) *data.Field {
+	copied := data.NewFieldFromFieldType(field.Type(), 0)
+	copied.Name = field.Name
+	copied.Labels = field.Labels.Copy()
+	copied.Config = field.Config
+	return copied
+}
@@ -0,0 +1,327 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// fakeLoki answers range queries with a metric sample per step, or a log line per minute.
+// An additional log line is returned at the end of every requested range, which Loki
+// would not return, to test deduplication of lines returned by consecutive queries.
+// Log lines have structured metadata when categorized labels are requested.
+type fakeLoki struct {
+	mu       sync.Mutex
+	logs     bool
+	requests [][2]time.Time
+	status   int
+}
+
+func (l *fakeLoki) RoundTrip(req *http.Request) (*http.Response, error) {
+	qs := req.URL.Query()
+	startNs, _ := strconv.ParseInt(qs.Get("start"), 10, 64)
+	endNs, _ := strconv.ParseInt(qs.Get("end"), 10, 64)
+	start, end := time.Unix(0, startNs).UTC(), time.Unix(0, endNs).UTC()
+	step, _ := time.ParseDuration(qs.Get("step"))
+
+	l.mu.Lock()
+	l.requests = append(l.requests, [2]time.Time{start, end})
+	l.mu.Unlock()
+
+	if l.status != 0 {
+		return &http.Response{StatusCode: l.status, Body: io.NopCloser(bytes.NewReader([]byte(`{"message":"the query time range exceeds the limit"}`)))}, nil
+	}
+
+	var body map[string]any
+	if l.logs {
+		categorize := req.Header.Get("X-Loki-Response-Encoding-Flags") == "categorize-labels"
+		var values [][]any
+		for t := start; !t.After(end); t = t.Add(time.Minute) {
+			value := []any{strconv.FormatInt(t.UnixNano(), 10), "line " + t.Format(time.RFC3339)}
+			if categorize {
+				value = append(value, map[string]any{"structuredMetadata": map[string]string{"trace_id": "abc"}})
+			}
+			values = append(values, value)
+		}
+		body = map[string]any{"resultType": "streams", "result": []any{
+			map[string]any{"stream": map[string]string{"app": "a"}, "values": values},
+		}}
+		if categorize {
+			body["encodingFlags"] = []string{"categorize-labels"}
+		}
+	} else {
+		var values [][]any
+		for t := start; !t.After(end); t = t.Add(step) {
+			values = append(values, []any{float64(t.Unix()), "1"})
+		}
+		body = map[string]any{"resultType": "matrix", "result": []any{
+			map[string]any{"metric": map[string]string{"app": "a"}, "values": values},
+		}}
+	}
+
+	b, err := json.Marshal(map[string]any{"status": "success", "data": body})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(b))}, nil
+}
+
+func (l *fakeLoki) requestsMade() [][2]time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.requests
+}
+
+func makeSplitAPI(loki *fakeLoki, opts querySplitOptions) *LokiAPI {
+	api := newLokiAPI(&http.Client{Transport: loki}, "http://localhost:3100", backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest(), false)
+	api.querySplit = opts
+	return api
+}
+
+func TestSplitDataQuery(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("metric query is split and samples are concatenated", func(t *testing.T) {
+		loki := &fakeLoki{}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: 24 * time.Hour, Concurrency: 2})
+		query := lokiQuery{Expr: `count_over_time({app="a"}[1h])`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Hour, Start: start, End: start.Add(7 * 24 * time.Hour), RefID: "A"}
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, loki.requestsMade(), 7)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 7*24+1, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			require.Equal(t, start.Add(time.Duration(i)*time.Hour), frame.Fields[0].At(i).(time.Time).UTC())
+		}
+		require.Equal(t, data.Labels{"app": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, "Expr: count_over_time({app=\"a\"}[1h])\nStep: 1h0m0s", frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("log lines are deduplicated, sorted and limited", func(t *testing.T) {
+		loki := &fakeLoki{logs: true}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: time.Hour, Concurrency: 2})
+		query := lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, MaxLines: 90, Start: start, End: start.Add(10 * time.Hour), RefID: "A"}
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		// the newest queries already return enough lines
+		requests := loki.requestsMade()
+		require.Len(t, requests, 2)
+		for _, r := range requests {
+			require.True(t, !r[0].Before(start.Add(8*time.Hour)))
+		}
+
+		frame := res.Frames[0]
+		require.Equal(t, 90, frame.Rows())
+		ids := map[string]bool{}
+		for i := 0; i < frame.Rows(); i++ {
+			require.Equal(t, start.Add(10*time.Hour-time.Duration(i)*time.Minute), frame.Fields[1].At(i).(time.Time).UTC())
+			id := frame.Fields[4].At(i).(string)
+			require.False(t, ids[id], "duplicated line %s", id)
+			ids[id] = true
+		}
+	})
+
+	t.Run("log lines with label types are merged", func(t *testing.T) {
+		loki := &fakeLoki{logs: true}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: time.Hour, Concurrency: 2})
+		api.requestStructuredMetadata = true
+		query := lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, MaxLines: 90, Start: start, End: start.Add(10 * time.Hour), RefID: "A"}
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, loki.requestsMade(), 2)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Len(t, frame.Fields, 6)
+		labelTypes, _ := frame.FieldByName("labelTypes")
+		require.NotNil(t, labelTypes)
+		require.Equal(t, 90, frame.Rows())
+		requireLogLines(t, frame, "Time", start.Add(10*time.Hour), -time.Minute)
+	})
+
+	t.Run("backward dataplane log queries are sorted newest first", func(t *testing.T) {
+		loki := &fakeLoki{logs: true}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: time.Hour, Concurrency: 2})
+		api.requestStructuredMetadata = true
+		query := lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, MaxLines: 90, Start: start, End: start.Add(10 * time.Hour), RefID: "A"}
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{logsDataplane: true}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, loki.requestsMade(), 2)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, data.FrameTypeLogLines, frame.Meta.Type)
+		require.Equal(t, 90, frame.Rows())
+		requireLogLines(t, frame, "timestamp", start.Add(10*time.Hour), -time.Minute)
+	})
+
+	t.Run("forward log queries are sorted oldest first", func(t *testing.T) {
+		loki := &fakeLoki{logs: true}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: time.Hour, Concurrency: 4})
+		query := lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionForward, Step: time.Minute, MaxLines: 1000, Start: start, End: start.Add(3 * time.Hour), RefID: "A"}
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{logsDataplane: true}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		require.Len(t, loki.requestsMade(), 3)
+
+		frame := res.Frames[0]
+		require.Equal(t, 3*60+1, frame.Rows())
+		require.Equal(t, start, frame.Fields[1].At(0).(time.Time).UTC())
+		require.Equal(t, start.Add(3*time.Hour), frame.Fields[1].At(frame.Rows()-1).(time.Time).UTC())
+	})
+
+	t.Run("errors of split queries are returned", func(t *testing.T) {
+		loki := &fakeLoki{status: http.StatusBadRequest}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: time.Hour, Concurrency: 2})
+		query := lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, Start: start, End: start.Add(3 * time.Hour), RefID: "A"}
+
+		res, err := runQuery(context.Background(), api, &query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+		require.EqualError(t, res.Error, "the query time range exceeds the limit")
+		require.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+
+	t.Run("instant and short queries are not split", func(t *testing.T) {
+		loki := &fakeLoki{}
+		api := makeSplitAPI(loki, querySplitOptions{Duration: time.Hour, Concurrency: 2})
+
+		short := lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, Start: start, End: start.Add(time.Hour)}
+		_, err := api.SplitDataQuery(context.Background(), short, ResponseOpts{})
+		require.NoError(t, err)
+		require.Len(t, loki.requestsMade(), 1)
+
+		require.Nil(t, splitQuery(lokiQuery{QueryType: QueryTypeInstant, Start: start, End: start.Add(10 * time.Hour)}, time.Hour))
+	})
+}
+
+func TestMergeSplitFrames(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	labels := json.RawMessage(`{"app":"a"}`)
+	labelTypes := json.RawMessage(`{"app":"I"}`)
+
+	// logResponse returns a response with a log line at each of the minutes after start.
+	logResponse := func(dataplane bool, minutes ...int) *backend.DataResponse {
+		frame := data.NewFrame("",
+			data.NewField("labels", nil, []json.RawMessage{}),
+			data.NewField("Time", nil, []time.Time{}),
+			data.NewField("Line", nil, []string{}),
+			data.NewField("tsNs", nil, []string{}),
+			data.NewField("labelTypes", nil, []json.RawMessage{}),
+			data.NewField("id", nil, []string{}),
+		)
+		frame.Meta = &data.FrameMeta{}
+		if dataplane {
+			frame = data.NewFrame("",
+				data.NewField("labels", nil, []json.RawMessage{}),
+				data.NewField("timestamp", nil, []time.Time{}),
+				data.NewField("body", nil, []string{}),
+				data.NewField("id", nil, []string{}),
+				data.NewField("labelTypes", nil, []json.RawMessage{}),
+			)
+			frame.Meta = &data.FrameMeta{Type: data.FrameTypeLogLines}
+		}
+		for _, m := range minutes {
+			ts := start.Add(time.Duration(m) * time.Minute)
+			tsNs := strconv.FormatInt(ts.UnixNano(), 10)
+			line := fmt.Sprintf("line %d", m)
+			if dataplane {
+				frame.AppendRow(labels, ts, line, tsNs+"_"+line, labelTypes)
+			} else {
+				frame.AppendRow(labels, ts, line, tsNs, labelTypes, tsNs+"_"+line)
+			}
+		}
+		return &backend.DataResponse{Frames: data.Frames{frame}}
+	}
+
+	for _, dataplane := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dataplane %t", dataplane), func(t *testing.T) {
+			responses := []*backend.DataResponse{logResponse(dataplane, 0, 1, 2), logResponse(dataplane, 2, 3, 4)}
+			require.Equal(t, 6, countLogLines(responses))
+
+			frames := mergeSplitFrames(responses, lokiQuery{Direction: DirectionBackward, MaxLines: 4})
+			require.Len(t, frames, 1)
+			require.Equal(t, 4, frames[0].Rows())
+			timeField := "Time"
+			if dataplane {
+				timeField = "timestamp"
+			}
+			requireLogLines(t, frames[0], timeField, start.Add(4*time.Minute), -time.Minute)
+		})
+	}
+}
+
+func TestSplitQuery(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("split duration is a multiple of the step", func(t *testing.T) {
+		queries := splitQuery(lokiQuery{QueryType: QueryTypeRange, Direction: DirectionForward, Step: 7 * time.Minute, Start: start, End: start.Add(2 * time.Hour)}, time.Hour)
+		require.Len(t, queries, 3)
+		for i, q := range queries {
+			require.Equal(t, start.Add(time.Duration(i)*56*time.Minute), q.Start)
+		}
+		require.Equal(t, start.Add(2*time.Hour), queries[2].End)
+	})
+
+	t.Run("backward queries run newest first", func(t *testing.T) {
+		queries := splitQuery(lokiQuery{QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, Start: start, End: start.Add(150 * time.Minute)}, time.Hour)
+		require.Len(t, queries, 3)
+		require.Equal(t, start.Add(2*time.Hour), queries[0].Start)
+		require.Equal(t, start.Add(150*time.Minute), queries[0].End)
+		require.Equal(t, start, queries[2].Start)
+	})
+}
+
+func TestParseQuerySplitOptions(t *testing.T) {
+	opts, err := parseQuerySplitOptions(nil)
+	require.NoError(t, err)
+	require.Equal(t, querySplitOptions{Concurrency: defaultQuerySplitConcurrency}, opts)
+
+	opts, err = parseQuerySplitOptions(json.RawMessage(`{"querySplitDuration": "1d", "querySplitConcurrency": 2, "maxLines": "1000"}`))
+	require.NoError(t, err)
+	require.Equal(t, querySplitOptions{Duration: 24 * time.Hour, Concurrency: 2}, opts)
+
+	for _, jsonData := range []string{`{"querySplitDuration": "a day"}`, `{"querySplitConcurrency": 0}`} {
+		_, err := parseQuerySplitOptions(json.RawMessage(jsonData))
+		require.Error(t, err, fmt.Sprint(jsonData))
+	}
+}
+
+// requireLogLines checks that the log lines of the frame are unique, and a step apart starting at first.
+func requireLogLines(t *testing.T, frame *data.Frame, timeField string, first time.Time, step time.Duration) {
+	t.Helper()
+	times, _ := frame.FieldByName(timeField)
+	require.NotNil(t, times)
+	ids, _ := frame.FieldByName("id")
+	require.NotNil(t, ids)
+	seen := map[string]bool{}
+	for i := 0; i < frame.Rows(); i++ {
+		require.Equal(t, first.Add(time.Duration(i)*step), times.At(i).(time.Time).UTC())
+		id := ids.At(i).(string)
+		require.False(t, seen[id], "duplicated line %s", id)
+		seen[id] = true
+	}
+}
@@ -0,0 +1,59 @@
+package opentsdb
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// annotationEvent is an annotation of a metric, or a global annotation
+type annotationEvent struct {
+	Time    int64  `json:"time"`
+	TimeEnd int64  `json:"timeEnd,omitempty"`
+	Text    string `json:"text"`
+	TSUID   string `json:"tsuid,omitempty"`
+}
+
+// annotationEvents returns the annotations of the result, or the global annotations.
+func annotationEvents(r OpenTsdbResponse, isGlobal bool) []annotationEvent {
+	annotations := r.Annotations
+	if isGlobal {
+		annotations = r.GlobalAnnotations
+	}
+
+	events := make([]annotationEvent, 0, len(annotations))
+	for _, a := range annotations {
+		event := annotationEvent{
+			Time:  int64(a.StartTime) * 1000,
+			Text:  a.Description,
+			TSUID: a.TSUID,
+		}
+		if a.EndTime > 0 {
+			event.TimeEnd = int64(a.EndTime) * 1000
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func annotationsFrame(events []annotationEvent) *data.Frame {
+	times := make([]time.Time, 0, len(events))
+	timeEnds := make([]*time.Time, 0, len(events))
+	texts := make([]string, 0, len(events))
+	for _, e := range events {
+		times = append(times, time.UnixMilli(e.Time).UTC())
+		var timeEnd *time.Time
+		if e.TimeEnd > 0 {
+			t := time.UnixMilli(e.TimeEnd).UTC()
+			timeEnd = &t
+		}
+		timeEnds = append(timeEnds, timeEnd)
+		texts = append(texts, e.Text)
+	}
+
+	return data.NewFrame("annotations",
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("text", nil, texts),
+	)
+}
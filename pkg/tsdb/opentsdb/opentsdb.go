@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
@@ -38,6 +39,10 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// TSDBVersion is 1 for OpenTSDB <=2.1, 2 for 2.2 and 3 for 2.3
+	TSDBVersion int
+	// TSDBResolution is 1 for second and 2 for millisecond resolution
+	TSDBResolution int
 }
 
 type DsAccess string
@@ -54,9 +59,21 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := struct {
+			TSDBVersion    int `json:"tsdbVersion"`
+			TSDBResolution int `json:"tsdbResolution"`
+		}{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jsonData.TSDBVersion,
+			TSDBResolution: jsonData.TSDBResolution,
 		}
 
 		return model, nil
@@ -64,20 +81,103 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+
+	result := backend.NewQueryDataResponse()
+	for _, batch := range s.batchQueries(dsInfo, req.Queries) {
+		for refID, res := range s.runBatch(ctx, logger, dsInfo, batch) {
+			result.Responses[refID] = res
+		}
+	}
+	return result, nil
+}
+
+// tsdbQuery is a query of the request with the OpenTSDB sub query it runs
+type tsdbQuery struct {
+	RefID     string
+	TimeRange backend.TimeRange
+	Metric    map[string]any
+	// Annotation is set for annotation queries, which return the annotations of the metric
+	Annotation bool
+	IsGlobal   bool
+}
 
-	myRefID := q.RefID
+// batchQueries groups the queries of the request into batches which can run in a single request. Results
+// can only be assigned to the queries of a batch if OpenTSDB returns the query of each result, which is
+// supported since OpenTSDB 2.3, so with older versions every query runs in its own request. Queries
+// without metric are skipped.
+func (s *Service) batchQueries(dsInfo *datasourceInfo, queries []backend.DataQuery) [][]tsdbQuery {
+	var batches [][]tsdbQuery
+	byTimeRange := map[[2]int64]int{}
+
+	for _, query := range queries {
+		q, ok := s.parseQuery(query)
+		if !ok {
+			continue
+		}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+		if dsInfo.TSDBVersion < 3 {
+			batches = append(batches, []tsdbQuery{q})
+			continue
+		}
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+		key := [2]int64{q.TimeRange.From.UnixMilli(), q.TimeRange.To.UnixMilli()}
+		if i, ok := byTimeRange[key]; ok {
+			batches[i] = append(batches[i], q)
+			continue
+		}
+		byTimeRange[key] = len(batches)
+		batches = append(batches, []tsdbQuery{q})
+	}
+	return batches
+}
+
+func (s *Service) parseQuery(query backend.DataQuery) (tsdbQuery, bool) {
+	q := tsdbQuery{RefID: query.RefID, TimeRange: query.TimeRange}
+
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return q, false
+	}
+
+	if model.Get("fromAnnotations").MustBool() {
+		target := model.Get("target").MustString()
+		q.Annotation = true
+		q.IsGlobal = model.Get("isGlobal").MustBool()
+		q.Metric = map[string]any{"aggregator": "sum", "metric": target}
+		return q, target != ""
+	}
+
+	q.Metric = s.buildMetric(query)
+	return q, q.Metric != nil && q.Metric["metric"] != ""
+}
+
+// runBatch runs the queries of the batch in a single request and returns the responses by ref ID.
+func (s *Service) runBatch(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, batch []tsdbQuery) backend.Responses {
+	responses := backend.Responses{}
+	errorResponses := func(err error) backend.Responses {
+		for _, q := range batch {
+			responses[q.RefID] = errorsource.Response(err)
+		}
+		return responses
+	}
+
+	tsdbQuery := OpenTsdbQuery{
+		Start:        batch[0].TimeRange.From.UnixMilli(),
+		End:          batch[0].TimeRange.To.UnixMilli(),
+		MsResolution: dsInfo.TSDBResolution == 2,
+		ShowQuery:    len(batch) > 1,
+	}
+	for _, q := range batch {
+		tsdbQuery.Queries = append(tsdbQuery.Queries, q.Metric)
+		if q.Annotation {
+			tsdbQuery.GlobalAnnotations = true
+		}
 	}
 
 	// TODO: Don't use global variable
@@ -85,34 +185,63 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
-	}
-
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return errorResponses(err)
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return errorResponses(errorsource.DownstreamError(err, false))
 	}
 
-	defer func() {
-		err := res.Body.Close()
+	// a single query gets all results
+	if len(batch) == 1 && !batch[0].Annotation {
+		result, err := s.parseResponse(logger, res, batch[0].RefID)
 		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
+			return errorResponses(err)
 		}
-	}()
+		return result.Responses
+	}
 
-	result, err := s.parseResponse(logger, res, myRefID)
+	results, err := s.decodeResponse(logger, res)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return errorResponses(err)
 	}
 
-	return result, nil
+	frames := make([]data.Frames, len(batch))
+	for _, r := range results {
+		index := 0
+		if len(batch) > 1 {
+			if r.Query == nil || r.Query.Index < 0 || r.Query.Index >= len(batch) {
+				logger.Warn("Unable to find the query of an OpenTSDB result", "metric", r.Metric)
+				continue
+			}
+			index = r.Query.Index
+		}
+
+		q := batch[index]
+		if q.Annotation {
+			// all results of an annotation query have the same annotations
+			if len(frames[index]) == 0 {
+				frames[index] = data.Frames{annotationsFrame(annotationEvents(r, q.IsGlobal))}
+			}
+			continue
+		}
+		frame, err := responseFrame(logger, r)
+		if err != nil {
+			return errorResponses(err)
+		}
+		frames[index] = append(frames[index], frame)
+	}
+
+	for i, q := range batch {
+		if q.Annotation && len(frames[i]) == 0 {
+			frames[i] = data.Frames{annotationsFrame(nil)}
+		}
+		responses[q.RefID] = backend.DataResponse{Frames: frames[i]}
+	}
+	return responses
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -141,6 +270,26 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID string) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
+	responseData, err := s.decodeResponse(logger, res)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := data.Frames{}
+	for _, val := range responseData {
+		frame, err := responseFrame(logger, val)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	result := resp.Responses[myRefID]
+	result.Frames = frames
+	resp.Responses[myRefID] = result
+	return resp, nil
+}
+
+func (s *Service) decodeResponse(logger log.Logger, res *http.Response) ([]OpenTsdbResponse, error) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -153,7 +302,13 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		err := fmt.Errorf("request failed, status: %s", res.Status)
+		var errorResponse OpenTsdbErrorResponse
+		if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error.Message != "" {
+			err = fmt.Errorf("%w: %s", err, errorResponse.Error.Message)
+		}
+		status := backend.Status(res.StatusCode)
+		return nil, errorsource.New(err, errorsource.FromStatus(status), status)
 	}
 
 	var responseData []OpenTsdbResponse
@@ -162,31 +317,34 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response, myRefID s
 		logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
 		return nil, err
 	}
+	return responseData, nil
+}
 
-	frames := data.Frames{}
-	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
-
-		for timeString, value := range val.DataPoints {
-			timestamp, err := strconv.ParseInt(timeString, 10, 64)
-			if err != nil {
-				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
-				return nil, err
-			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+func responseFrame(logger log.Logger, val OpenTsdbResponse) (*data.Frame, error) {
+	timeVector := make([]time.Time, 0, len(val.DataPoints))
+	values := make([]float64, 0, len(val.DataPoints))
+
+	for timeString, value := range val.DataPoints {
+		timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		if err != nil {
+			logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
+			return nil, err
 		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+		timeVector = append(timeVector, parseTimestamp(timestamp))
+		values = append(values, value)
 	}
-	result := resp.Responses[myRefID]
-	result.Frames = frames
-	resp.Responses[myRefID] = result
-	return resp, nil
+	return data.NewFrame(val.Metric,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", val.Tags, values)), nil
+}
+
+// parseTimestamp parses timestamps in seconds, or in milliseconds if the data source uses
+// millisecond resolution. Timestamps in seconds have at most 10 digits.
+func parseTimestamp(timestamp int64) time.Time {
+	if timestamp > 9999999999 {
+		return time.UnixMilli(timestamp).UTC()
+	}
+	return time.Unix(timestamp, 0).UTC()
 }
 
 func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
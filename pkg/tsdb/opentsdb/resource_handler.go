@@ -0,0 +1,159 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// lookupParams are the query parameters of the OpenTSDB APIs which can be called through CallResource
+var lookupParams = map[string][]string{
+	"api/suggest":       {"type", "q", "max"},
+	"api/search/lookup": {"m", "limit", "useMeta"},
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	resourcePath := strings.Trim(req.Path, "/")
+
+	params, ok := lookupParams[resourcePath]
+	if !ok && resourcePath != "annotations" {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return err
+	}
+	values := u.Query()
+
+	if resourcePath == "annotations" {
+		return s.callAnnotations(ctx, logger, dsInfo, values, sender)
+	}
+
+	query := url.Values{}
+	for _, key := range params {
+		if v, ok := values[key]; ok {
+			query[key] = v
+		}
+	}
+
+	res, err := s.get(ctx, dsInfo, resourcePath, query)
+	if err != nil {
+		logger.Error("Failed resource call to OpenTSDB", "error", err, "path", resourcePath)
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  res.StatusCode,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
+
+// callAnnotations returns the annotations of the target metric, or the global annotations, in the
+// time range of the from and to parameters in epoch milliseconds.
+func (s *Service) callAnnotations(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, values url.Values, sender backend.CallResourceResponseSender) error {
+	q := tsdbQuery{Annotation: true, IsGlobal: values.Get("isGlobal") == "true"}
+	target := values.Get("target")
+	from, fromErr := strconv.ParseInt(values.Get("from"), 10, 64)
+	to, toErr := strconv.ParseInt(values.Get("to"), 10, 64)
+	if target == "" || fromErr != nil || toErr != nil {
+		return sendJSON(sender, http.StatusBadRequest, map[string]string{"message": "target, from and to are required"})
+	}
+	q.Metric = map[string]any{"aggregator": "sum", "metric": target}
+	q.TimeRange = backend.TimeRange{From: time.UnixMilli(from), To: time.UnixMilli(to)}
+
+	events, err := s.queryAnnotations(ctx, logger, dsInfo, q)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var sourceErr errorsource.Error
+		if errors.As(err, &sourceErr) && sourceErr.Source() == backend.ErrorSourceDownstream {
+			status = http.StatusBadGateway
+		}
+		return sendJSON(sender, status, map[string]string{"message": err.Error()})
+	}
+	return sendJSON(sender, http.StatusOK, events)
+}
+
+func (s *Service) queryAnnotations(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, q tsdbQuery) ([]annotationEvent, error) {
+	request, err := s.createRequest(ctx, logger, dsInfo, OpenTsdbQuery{
+		Start:             q.TimeRange.From.UnixMilli(),
+		End:               q.TimeRange.To.UnixMilli(),
+		Queries:           []map[string]any{q.Metric},
+		GlobalAnnotations: q.IsGlobal,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return nil, errorsource.DownstreamError(err, false)
+	}
+
+	results, err := s.decodeResponse(logger, res)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return []annotationEvent{}, nil
+	}
+	return annotationEvents(results[0], q.IsGlobal), nil
+}
+
+func (s *Service) get(ctx context.Context, dsInfo *datasourceInfo, apiPath string, query url.Values) (*http.Response, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, apiPath)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return dsInfo.HTTPClient.Do(req)
+}
+
+func sendJSON(sender backend.CallResourceResponseSender, status int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  status,
+		Headers: map[string][]string{"Content-Type": {"application/json"}},
+		Body:    body,
+	})
+}
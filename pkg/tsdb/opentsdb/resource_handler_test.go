@@ -0,0 +1,249 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOpenTSDB answers queries with one series per sub query, and records the query requests.
+type fakeOpenTSDB struct {
+	mu      sync.Mutex
+	queries []OpenTsdbQuery
+	lookups []*http.Request
+}
+
+func (f *fakeOpenTSDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/query":
+		var q OpenTsdbQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.queries = append(f.queries, q)
+		f.mu.Unlock()
+
+		results := []map[string]any{}
+		for i, sub := range q.Queries {
+			if sub["metric"] == "unknown" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "No such name for 'metrics': 'unknown'"}}`))
+				return
+			}
+			ts := strconv.FormatInt(q.Start/1000, 10)
+			result := map[string]any{
+				"metric":      sub["metric"],
+				"tags":        map[string]string{"host": "a"},
+				"dps":         map[string]float64{ts: float64(i)},
+				"annotations": []map[string]any{{"description": "deploy", "startTime": 1709287200}},
+			}
+			if q.GlobalAnnotations {
+				result["globalAnnotations"] = []map[string]any{{"description": "maintenance", "startTime": 1709287300, "endTime": 1709287400}}
+			}
+			if q.ShowQuery {
+				result["query"] = map[string]any{"index": i, "metric": sub["metric"]}
+			}
+			results = append(results, result)
+		}
+		// results are not in the order of the queries
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	case "/api/suggest", "/api/search/lookup":
+		f.mu.Lock()
+		f.lookups = append(f.lookups, r)
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`["cpu.user", "cpu.system"]`))
+	case "/api/version":
+		_, _ = w.Write([]byte(`{"version": "2.4.1", "short_revision": "abc"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type testInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (m *testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m *testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func setupFakeOpenTSDB(t *testing.T, tsdb *fakeOpenTSDB, version int) *Service {
+	t.Helper()
+
+	server := httptest.NewServer(tsdb)
+	t.Cleanup(server.Close)
+
+	return &Service{im: &testInstanceManager{dsInfo: &datasourceInfo{
+		HTTPClient:  server.Client(),
+		URL:         server.URL,
+		TSDBVersion: version,
+	}}}
+}
+
+type fakeSender struct {
+	response *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.response = res
+	return nil
+}
+
+func TestQueryDataBatching(t *testing.T) {
+	from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(time.Hour)}
+	otherTimeRange := backend.TimeRange{From: from.Add(-time.Hour), To: from}
+
+	queries := []backend.DataQuery{
+		{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "avg", "disableDownsampling": true}`)},
+		{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "mem", "aggregator": "avg", "disableDownsampling": true}`)},
+		{RefID: "C", TimeRange: otherTimeRange, JSON: []byte(`{"metric": "disk", "aggregator": "avg", "disableDownsampling": true}`)},
+		{RefID: "D", TimeRange: timeRange, JSON: []byte(`{"aggregator": "avg"}`)},
+	}
+
+	t.Run("queries with the same time range run in one request", func(t *testing.T) {
+		tsdb := &fakeOpenTSDB{}
+		s := setupFakeOpenTSDB(t, tsdb, 3)
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries})
+		require.NoError(t, err)
+
+		require.Len(t, tsdb.queries, 2)
+		require.Len(t, tsdb.queries[0].Queries, 2)
+		require.True(t, tsdb.queries[0].ShowQuery)
+		require.Len(t, tsdb.queries[1].Queries, 1)
+		require.False(t, tsdb.queries[1].ShowQuery)
+
+		for refID, metric := range map[string]string{"A": "cpu", "B": "mem", "C": "disk"} {
+			require.NoError(t, res.Responses[refID].Error)
+			require.Len(t, res.Responses[refID].Frames, 1, refID)
+			require.Equal(t, metric, res.Responses[refID].Frames[0].Name)
+		}
+		require.NotContains(t, res.Responses, "D")
+	})
+
+	t.Run("older versions run a request per query", func(t *testing.T) {
+		tsdb := &fakeOpenTSDB{}
+		s := setupFakeOpenTSDB(t, tsdb, 2)
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries})
+		require.NoError(t, err)
+		require.Len(t, tsdb.queries, 3)
+		require.Equal(t, "mem", res.Responses["B"].Frames[0].Name)
+	})
+
+	t.Run("errors are returned for the queries of the request", func(t *testing.T) {
+		tsdb := &fakeOpenTSDB{}
+		s := setupFakeOpenTSDB(t, tsdb, 3)
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			queries[0],
+			{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "unknown", "aggregator": "avg"}`)},
+			queries[2],
+		}})
+		require.NoError(t, err)
+		for _, refID := range []string{"A", "B"} {
+			require.EqualError(t, res.Responses[refID].Error, "request failed, status: 400 Bad Request: No such name for 'metrics': 'unknown'")
+			require.Equal(t, backend.ErrorSourceDownstream, res.Responses[refID].ErrorSource)
+		}
+		require.NoError(t, res.Responses["C"].Error)
+	})
+
+	t.Run("annotation queries", func(t *testing.T) {
+		tsdb := &fakeOpenTSDB{}
+		s := setupFakeOpenTSDB(t, tsdb, 3)
+
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{
+			queries[0],
+			{RefID: "Anno", TimeRange: timeRange, JSON: []byte(`{"fromAnnotations": true, "target": "deploys", "isGlobal": true}`)},
+		}})
+		require.NoError(t, err)
+		require.Len(t, tsdb.queries, 1)
+		require.True(t, tsdb.queries[0].GlobalAnnotations)
+
+		frames := res.Responses["Anno"].Frames
+		require.Len(t, frames, 1)
+		require.Equal(t, 1, frames[0].Rows())
+		require.Equal(t, time.Unix(1709287300, 0).UTC(), frames[0].Fields[0].At(0))
+		require.Equal(t, time.Unix(1709287400, 0).UTC(), *frames[0].Fields[1].At(0).(*time.Time))
+		require.Equal(t, "maintenance", frames[0].Fields[2].At(0))
+		require.Equal(t, "cpu", res.Responses["A"].Frames[0].Name)
+	})
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("suggest", func(t *testing.T) {
+		tsdb := &fakeOpenTSDB{}
+		s := setupFakeOpenTSDB(t, tsdb, 3)
+
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "api/suggest",
+			URL:    "api/suggest?type=metrics&q=cpu&max=100&other=1",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.response.Status)
+		require.JSONEq(t, `["cpu.user", "cpu.system"]`, string(sender.response.Body))
+
+		require.Len(t, tsdb.lookups, 1)
+		require.Equal(t, "max=100&q=cpu&type=metrics", tsdb.lookups[0].URL.RawQuery)
+	})
+
+	t.Run("annotations", func(t *testing.T) {
+		s := setupFakeOpenTSDB(t, &fakeOpenTSDB{}, 3)
+
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "annotations",
+			URL:    "annotations?target=deploys&from=1709287200000&to=1709290800000",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.response.Status)
+		require.JSONEq(t, `[{"time": 1709287200000, "text": "deploy"}]`, string(sender.response.Body))
+	})
+
+	t.Run("annotations without target", func(t *testing.T) {
+		s := setupFakeOpenTSDB(t, &fakeOpenTSDB{}, 3)
+
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: "annotations", URL: "annotations?from=1&to=2"}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, sender.response.Status)
+	})
+
+	t.Run("unknown resources", func(t *testing.T) {
+		s := setupFakeOpenTSDB(t, &fakeOpenTSDB{}, 3)
+
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: "api/put", URL: "api/put"}, &fakeSender{})
+		require.EqualError(t, err, "invalid resource URL: api/put")
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	s := setupFakeOpenTSDB(t, &fakeOpenTSDB{}, 3)
+
+	res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+	require.NoError(t, err)
+	require.Equal(t, backend.HealthStatusOk, res.Status)
+	require.Equal(t, "Data source is working, OpenTSDB version 2.4.1", res.Message)
+}
@@ -0,0 +1,227 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqlite/sqleng"
+)
+
+var errNoAllowedDirectories = errors.New("SQLite data sources are not allowed to open files, configure sqlite_allowed_directories in the [sql_datasources] section")
+
+// driverName is the driver of the connections of SQLite data sources. Unlike the default sqlite3
+// driver, it does not allow queries to attach other database files, which could be outside of the
+// allowed directories.
+const driverName = "sqlite3_datasource"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{ConnectHook: restrictConnection})
+}
+
+// restrictConnection denies attaching databases to the connection, both by limiting the number of
+// attached databases to zero and with an authorizer. The driver does not enable loading extensions.
+func restrictConnection(conn *sqlite3.SQLiteConn) error {
+	conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+	conn.RegisterAuthorizer(func(action int, _, _, _ string) int {
+		if action == sqlite3.SQLITE_ATTACH {
+			return sqlite3.SQLITE_DENY
+		}
+		return sqlite3.SQLITE_OK
+	})
+	return nil
+}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlite")
+	return &Service{
+		im:     datasource.NewInstanceManager(newInstanceSettings(cfg, logger)),
+		logger: logger,
+	}
+}
+
+func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+// CheckHealth checks the database file can be read.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+
+	err = dsHandler.Ping()
+	if err != nil {
+		s.logger.Error("Check health failed", "error", err)
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: dsHandler.TransformQueryError(s.logger, err).Error()}, nil
+	}
+
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+}
+
+func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    cfg.SqlDatasourceMaxOpenConnsDefault,
+			MaxIdleConns:    cfg.SqlDatasourceMaxIdleConnsDefault,
+			ConnMaxLifetime: cfg.SqlDatasourceMaxConnLifetimeDefault,
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		database := jsonData.Database
+		if database == "" {
+			database = settings.Database
+		}
+
+		path, err := resolveDatabasePath(database, cfg.SqliteAllowedDirectories)
+		if err != nil {
+			return nil, err
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			User:                    settings.User,
+			Database:                path,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
+			RowLimit:          cfg.DataProxyRowLimit,
+		}
+
+		db, err := sql.Open(driverName, connectionString(path))
+		if err != nil {
+			return nil, err
+		}
+
+		db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
+		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
+		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+
+		queryResultTransformer := sqliteQueryResultTransformer{
+			userError: cfg.UserFacingDefaultError,
+		}
+
+		handler, err := sqleng.NewQueryDataHandler(cfg.UserFacingDefaultError, db, config, &queryResultTransformer, newSqliteMacroEngine(),
+			logger)
+		if err != nil {
+			logger.Error("Failed opening SQLite database", "err", err)
+			return nil, err
+		}
+
+		logger.Debug("Successfully opened SQLite database", "database", path)
+		return handler, nil
+	}
+}
+
+// resolveDatabasePath returns the absolute path of the database file, which must be in
+// one of the allowed directories. Relative paths are relative to the allowed directories.
+// Symbolic links are resolved, so that links can't point outside of allowed directories.
+func resolveDatabasePath(database string, allowedDirectories []string) (string, error) {
+	if len(allowedDirectories) == 0 {
+		return "", errNoAllowedDirectories
+	}
+	if database == "" {
+		return "", errors.New("database file is not set")
+	}
+
+	for _, dir := range allowedDirectories {
+		dir, err := resolvePath(dir)
+		if err != nil {
+			continue
+		}
+
+		path := database
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path, err = resolvePath(path)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return path, nil
+	}
+
+	return "", fmt.Errorf("database file %q not found in allowed directories", database)
+}
+
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// connectionString returns a URI opening the database file read-only, the query only
+// pragma additionally prevents any changes through the connection.
+func connectionString(path string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String() + "?mode=ro&_query_only=true"
+}
+
+type sqliteQueryResultTransformer struct {
+	userError string
+}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(logger log.Logger, err error) error {
+	var driverErr sqlite3.Error
+	if errors.As(err, &driverErr) && driverErr.Code == sqlite3.ErrCantOpen {
+		logger.Error("Query error", "error", err)
+		return fmt.Errorf("failed to open database - %s", t.userError)
+	}
+
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{}
+}
@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func createTestDatabase(t *testing.T, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "metrics.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.Exec(`CREATE TABLE metrics (time DATETIME, host TEXT, value REAL)`)
+	require.NoError(t, err)
+	for _, row := range []struct {
+		time  string
+		host  string
+		value float64
+	}{
+		{"2018-04-12 18:00:00", "a", 1},
+		{"2018-04-12 18:00:30", "a", 3},
+		{"2018-04-12 18:01:00", "a", 5},
+		{"2018-04-12 18:03:00", "a", 7},
+		{"2018-04-12 19:00:00", "a", 100},
+	} {
+		_, err = db.Exec(`INSERT INTO metrics VALUES (?, ?, ?)`, row.time, row.host, row.value)
+		require.NoError(t, err)
+	}
+	return path
+}
+
+func newTestService(dir string) *Service {
+	cfg := setting.NewCfg()
+	cfg.SqliteAllowedDirectories = []string{dir}
+	cfg.DataProxyRowLimit = 1000
+	return ProvideService(cfg)
+}
+
+func testPluginContext(database string) backend.PluginContext {
+	return backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			JSONData: []byte(`{"database": "` + database + `"}`),
+		},
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	createTestDatabase(t, dir)
+	s := newTestService(dir)
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	timeRange := backend.TimeRange{From: from, To: from.Add(5 * time.Minute)}
+
+	query := func(t *testing.T, rawSQL, format string) backend.DataResponse {
+		t.Helper()
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: testPluginContext("metrics.db"),
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      []byte(`{"rawSql": "` + rawSQL + `", "format": "` + format + `"}`),
+				TimeRange: timeRange,
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("time series grouped by $__timeGroup", func(t *testing.T) {
+		res := query(t, "SELECT $__timeGroupAlias(time, '1m'), avg(value) AS value FROM metrics WHERE $__timeFilter(time) GROUP BY 1 ORDER BY 1", "time_series")
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.True(t, from.Equal(*frame.Fields[0].At(0).(*time.Time)))
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 7.0, *frame.Fields[1].At(2).(*float64))
+	})
+
+	t.Run("time series with fill", func(t *testing.T) {
+		res := query(t, "SELECT $__timeGroupAlias(time, '1m', 0), avg(value) AS value FROM metrics WHERE $__timeFilter(time) GROUP BY 1 ORDER BY 1", "time_series")
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 6, frame.Rows())
+		require.Equal(t, 0.0, *frame.Fields[1].At(2).(*float64))
+	})
+
+	t.Run("table with native time column", func(t *testing.T) {
+		res := query(t, "SELECT $__time(time), host, value FROM metrics ORDER BY time", "table")
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 5, frame.Rows())
+		require.True(t, from.Equal(*frame.Fields[0].At(0).(*time.Time)))
+		require.Equal(t, "a", *frame.Fields[1].At(0).(*string))
+	})
+
+	t.Run("database is read-only", func(t *testing.T) {
+		res := query(t, "DELETE FROM metrics", "table")
+		require.Error(t, res.Error)
+	})
+
+	t.Run("other databases cannot be attached", func(t *testing.T) {
+		outside := createTestDatabase(t, t.TempDir())
+		res := query(t, "ATTACH DATABASE '"+outside+"' AS other", "table")
+		require.ErrorContains(t, res.Error, "not authorized")
+
+		res = query(t, "SELECT * FROM other.metrics", "table")
+		require.Error(t, res.Error)
+	})
+
+	t.Run("extensions cannot be loaded", func(t *testing.T) {
+		res := query(t, "SELECT load_extension('mod_spatialite')", "table")
+		require.Error(t, res.Error)
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: testPluginContext("metrics.db")})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("health check of a file which is not a database", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "not-a-database.db"), []byte("not a database, but long enough to have a header"), 0600))
+		pluginContext := testPluginContext("not-a-database.db")
+		pluginContext.DataSourceInstanceSettings.ID = 1
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+	})
+}
+
+func TestResolveDatabasePath(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestDatabase(t, dir)
+	outside := createTestDatabase(t, t.TempDir())
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link.db")))
+
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	resolvedPath := filepath.Join(resolvedDir, filepath.Base(path))
+
+	t.Run("relative path", func(t *testing.T) {
+		resolved, err := resolveDatabasePath("metrics.db", []string{dir})
+		require.NoError(t, err)
+		require.Equal(t, resolvedPath, resolved)
+	})
+
+	t.Run("absolute path", func(t *testing.T) {
+		resolved, err := resolveDatabasePath(path, []string{t.TempDir(), dir})
+		require.NoError(t, err)
+		require.Equal(t, resolvedPath, resolved)
+	})
+
+	t.Run("no allowed directories", func(t *testing.T) {
+		_, err := resolveDatabasePath(path, nil)
+		require.ErrorIs(t, err, errNoAllowedDirectories)
+	})
+
+	for name, database := range map[string]string{
+		"absolute path outside":    outside,
+		"relative path outside":    filepath.Join("..", filepath.Base(filepath.Dir(outside)), "metrics.db"),
+		"symbolic link outside":    "link.db",
+		"allowed directory itself": ".",
+		"missing file":             "missing.db",
+		"empty":                    "",
+		"in memory database":       ":memory:",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := resolveDatabasePath(database, []string{dir})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestConnectionString(t *testing.T) {
+	require.Equal(t, "file:///data/my%20metrics%3F.db?mode=ro&_query_only=true", connectionString("/data/my metrics?.db"))
+}
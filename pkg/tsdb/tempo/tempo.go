@@ -49,6 +49,8 @@ type Datasource struct {
 	HTTPClient      *http.Client
 	StreamingClient tempopb.StreamingQuerierClient
 	URL             string
+	UID             string
+	Name            string
 }
 
 func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -76,6 +78,8 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			HTTPClient:      client,
 			StreamingClient: streamingClient,
 			URL:             settings.URL,
+			UID:             settings.UID,
+			Name:            settings.Name,
 		}
 		return model, nil
 	}
@@ -109,8 +113,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 }
 
 func (s *Service) query(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
-	if query.QueryType == string(dataquery.TempoQueryTypeTraceId) {
+	switch query.QueryType {
+	case string(dataquery.TempoQueryTypeTraceId):
 		return s.getTrace(ctx, pCtx, query)
+	case string(dataquery.TempoQueryTypeTraceql), string(dataquery.TempoQueryTypeTraceqlSearch):
+		return s.runTraceQL(ctx, pCtx, query)
 	}
 	return nil, fmt.Errorf("unsupported query type: '%s' for query with refID '%s'", query.QueryType, query.RefID)
 }
@@ -0,0 +1,213 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+// traceQLMetricsFnRegex matches TraceQL queries with a metrics function, the same way the frontend does.
+var traceQLMetricsFnRegex = regexp.MustCompile(`\|\s*(rate|count_over_time|avg_over_time|max_over_time|min_over_time|quantile_over_time)\s*\(`)
+
+var traceIDRegex = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+// intrinsics are TraceQL fields without scope.
+var intrinsics = map[string]bool{
+	"duration":        true,
+	"kind":            true,
+	"name":            true,
+	"rootName":        true,
+	"rootServiceName": true,
+	"status":          true,
+	"statusMessage":   true,
+	"traceDuration":   true,
+}
+
+func isTraceQLMetricsQuery(query string) bool {
+	return traceQLMetricsFnRegex.MatchString(strings.TrimSpace(query))
+}
+
+// runTraceQL runs TraceQL and TraceQL search queries. Like in the frontend, TraceQL
+// queries can be trace IDs, TraceQL searches or TraceQL metrics queries.
+func (s *Service) runTraceQL(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	model := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(query.JSON, model); err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	var traceQL string
+	if model.Query != nil {
+		traceQL = strings.TrimSpace(*model.Query)
+	}
+	if query.QueryType == string(dataquery.TempoQueryTypeTraceqlSearch) {
+		traceQL = queryFromFilters(model.Filters)
+	} else if traceIDRegex.MatchString(traceQL) {
+		return s.getTrace(ctx, pCtx, query)
+	}
+	if traceQL == "" {
+		return &backend.DataResponse{Error: fmt.Errorf("TraceQL query is required")}, nil
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	if isTraceQLMetricsQuery(traceQL) {
+		return s.runTraceQLMetrics(ctx, dsInfo, query, traceQL), nil
+	}
+	return s.runTraceQLSearch(ctx, dsInfo, query, model, traceQL), nil
+}
+
+// traceQLRequest sends a GET request to the Tempo API. Errors of the request are returned
+// as a data response, so that they are shown for the query.
+func (s *Service) traceQLRequest(ctx context.Context, dsInfo *Datasource, path string, params url.Values) ([]byte, *backend.DataResponse) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, &backend.DataResponse{Error: fmt.Errorf("invalid Tempo URL: %w", err), ErrorSource: backend.ErrorSourceDownstream}
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, &backend.DataResponse{Error: err}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "path", path, "function", logEntrypoint())
+		res := &backend.DataResponse{Error: fmt.Errorf("failed to query Tempo: %w", err)}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			res.ErrorSource = backend.ErrorSourceDownstream
+		}
+		return nil, res
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &backend.DataResponse{Error: fmt.Errorf("failed to read Tempo response: %w", err), ErrorSource: backend.ErrorSourceDownstream}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		ctxLogger.Error("Tempo returned an error", "status", resp.StatusCode, "path", path, "function", logEntrypoint())
+		res := backend.ErrDataResponseWithSource(backend.Status(resp.StatusCode), backend.ErrorSourceFromHTTPStatus(resp.StatusCode),
+			fmt.Sprintf("failed to run TraceQL query: %s", tempoErrorMessage(resp.Status, body)))
+		return nil, &res
+	}
+
+	return body, nil
+}
+
+// tempoErrorMessage returns the message of an error response of Tempo, which is
+// plain text usually, but the message of JSON errors is used when present.
+func tempoErrorMessage(status string, body []byte) string {
+	var jsonError struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &jsonError); err == nil {
+		if jsonError.Message != "" {
+			return jsonError.Message
+		}
+		if jsonError.Error != "" {
+			return jsonError.Error
+		}
+	}
+	if message := strings.TrimSpace(string(body)); message != "" {
+		return message
+	}
+	return status
+}
+
+// queryFromFilters generates the TraceQL query of TraceQL search filters, the same
+// way the query editor does.
+func queryFromFilters(filters []dataquery.TraceqlFilter) string {
+	var conditions []string
+	for _, f := range filters {
+		if f.Tag == nil || *f.Tag == "" || f.Operator == nil || *f.Operator == "" || f.Value == nil {
+			continue
+		}
+		values := filterValues(*f.Value)
+		if len(values) == 0 {
+			continue
+		}
+		conditions = append(conditions, filterScope(f)+filterTag(f, filters)+*f.Operator+filterValue(f, values))
+	}
+	return "{" + strings.Join(conditions, " && ") + "}"
+}
+
+func filterValues(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	}
+	return nil
+}
+
+func filterScope(f dataquery.TraceqlFilter) string {
+	if intrinsics[*f.Tag] {
+		return ""
+	}
+	if f.Scope != nil && (*f.Scope == dataquery.TraceqlSearchScopeResource || *f.Scope == dataquery.TraceqlSearchScopeSpan) {
+		return string(*f.Scope) + "."
+	}
+	return "."
+}
+
+func filterTag(f dataquery.TraceqlFilter, filters []dataquery.TraceqlFilter) string {
+	if *f.Tag != "duration" {
+		return *f.Tag
+	}
+	for _, durationType := range filters {
+		if durationType.Id != "duration-type" || durationType.Value == nil {
+			continue
+		}
+		if values := filterValues(*durationType.Value); len(values) == 1 && values[0] == "trace" {
+			return "traceDuration"
+		}
+		return "duration"
+	}
+	return *f.Tag
+}
+
+func filterValue(f dataquery.TraceqlFilter, values []string) string {
+	if len(values) > 1 {
+		return `"` + strings.Join(values, "|") + `"`
+	}
+	if f.ValueType != nil && *f.ValueType == "string" {
+		return `"` + values[0] + `"`
+	}
+	return values[0]
+}
@@ -0,0 +1,190 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceQLMetricsResponse is the JSON response of the Tempo query range API.
+type traceQLMetricsResponse struct {
+	Series []traceQLMetricsSeries `json:"series"`
+}
+
+type traceQLMetricsSeries struct {
+	Labels  []traceQLMetricsLabel  `json:"labels"`
+	Samples []traceQLMetricsSample `json:"samples"`
+}
+
+type traceQLMetricsLabel struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string      `json:"stringValue"`
+		IntValue    *json.Number `json:"intValue"`
+		DoubleValue *float64     `json:"doubleValue"`
+		BoolValue   *bool        `json:"boolValue"`
+	} `json:"value"`
+}
+
+type traceQLMetricsSample struct {
+	TimestampMs json.Number   `json:"timestampMs"`
+	Value       metricsSample `json:"value"`
+}
+
+// metricsSample is a sample value, which is a string for values not representable in JSON.
+type metricsSample float64
+
+func (v *metricsSample) UnmarshalJSON(b []byte) error {
+	var value float64
+	if err := json.Unmarshal(b, &value); err == nil {
+		*v = metricsSample(value)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "NaN":
+		value = math.NaN()
+	case "Infinity", "+Inf":
+		value = math.Inf(1)
+	case "-Infinity", "-Inf":
+		value = math.Inf(-1)
+	default:
+		parsed, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		value = parsed
+	}
+	*v = metricsSample(value)
+	return nil
+}
+
+func (l traceQLMetricsLabel) String() string {
+	switch {
+	case l.Value.StringValue != nil:
+		return *l.Value.StringValue
+	case l.Value.IntValue != nil:
+		return l.Value.IntValue.String()
+	case l.Value.DoubleValue != nil:
+		return strconv.FormatFloat(*l.Value.DoubleValue, 'f', -1, 64)
+	case l.Value.BoolValue != nil:
+		return strconv.FormatBool(*l.Value.BoolValue)
+	}
+	return ""
+}
+
+func (s *Service) runTraceQLMetrics(ctx context.Context, dsInfo *Datasource, query backend.DataQuery, traceQL string) *backend.DataResponse {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Running TraceQL metrics query", "function", logEntrypoint())
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runTraceQLMetrics", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	params := url.Values{}
+	params.Set("query", traceQL)
+	params.Set("start", strconv.FormatInt(query.TimeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(query.TimeRange.To.Unix(), 10))
+	params.Set("step", metricsStep(query.Interval))
+
+	body, errResponse := s.traceQLRequest(ctx, dsInfo, "/api/metrics/query_range", params)
+	if errResponse != nil {
+		span.RecordError(errResponse.Error)
+		span.SetStatus(codes.Error, errResponse.Error.Error())
+		return errResponse
+	}
+
+	metricsResponse := traceQLMetricsResponse{}
+	if err := json.Unmarshal(body, &metricsResponse); err != nil {
+		ctxLogger.Error("Failed to unmarshal TraceQL metrics response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{Error: fmt.Errorf("failed to unmarshal TraceQL metrics response: %w", err), ErrorSource: backend.ErrorSourceDownstream}
+	}
+
+	frames, err := traceQLMetricsFrames(traceQL, metricsResponse)
+	if err != nil {
+		ctxLogger.Error("Failed to transform TraceQL metrics response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{Error: err, ErrorSource: backend.ErrorSourceDownstream}
+	}
+	for _, frame := range frames {
+		frame.RefID = query.RefID
+	}
+
+	ctxLogger.Debug("Successfully ran TraceQL metrics query", "function", logEntrypoint())
+	return &backend.DataResponse{Frames: frames}
+}
+
+// metricsStep returns the step of the metrics query, which is the query interval but
+// at least a second.
+func metricsStep(interval time.Duration) string {
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return fmt.Sprintf("%ds", int64(interval/time.Second))
+}
+
+// traceQLMetricsFrames returns a time series frame per series. Series are named the same
+// way as in the frontend: by the label value if there is only one label, or by all labels.
+// A single series without labels is named by the query.
+func traceQLMetricsFrames(traceQL string, response traceQLMetricsResponse) (data.Frames, error) {
+	frames := make(data.Frames, 0, len(response.Series))
+	for _, series := range response.Series {
+		labels := data.Labels{}
+		labelStrings := make([]string, 0, len(series.Labels))
+		for _, label := range series.Labels {
+			labels[label.Key] = label.String()
+			labelStrings = append(labelStrings, label.Key+"="+label.String())
+		}
+
+		name := ""
+		switch {
+		case len(series.Labels) == 1:
+			name = series.Labels[0].String()
+		case len(series.Labels) > 1:
+			name = "{" + strings.Join(labelStrings, ", ") + "}"
+		case len(response.Series) == 1:
+			name = traceQL
+		}
+
+		times := make([]time.Time, len(series.Samples))
+		values := make([]float64, len(series.Samples))
+		for i, sample := range series.Samples {
+			ms, err := sample.TimestampMs.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid sample timestamp %q: %w", sample.TimestampMs, err)
+			}
+			times[i] = time.UnixMilli(ms)
+			values[i] = float64(sample.Value)
+		}
+
+		valueField := data.NewField(data.TimeSeriesValueFieldName, labels, values)
+		valueField.Config = &data.FieldConfig{DisplayNameFromDS: name}
+		frame := data.NewFrame(name, data.NewField(data.TimeSeriesTimeFieldName, nil, times), valueField)
+		frame.Meta = &data.FrameMeta{
+			Type:                   data.FrameTypeTimeSeriesMulti,
+			PreferredVisualization: data.VisTypeGraph,
+			ExecutedQueryString:    traceQL,
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
@@ -0,0 +1,231 @@
+package tempo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultSearchLimit = 20
+	defaultSearchSpss  = 3
+)
+
+func (s *Service) runTraceQLSearch(ctx context.Context, dsInfo *Datasource, query backend.DataQuery, model *dataquery.TempoQuery, traceQL string) *backend.DataResponse {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Running TraceQL search", "function", logEntrypoint())
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runTraceQLSearch", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	limit, spss := int64(defaultSearchLimit), int64(defaultSearchSpss)
+	if model.Limit != nil && *model.Limit > 0 {
+		limit = *model.Limit
+	}
+	if model.Spss != nil && *model.Spss > 0 {
+		spss = *model.Spss
+	}
+
+	params := url.Values{}
+	params.Set("q", traceQL)
+	params.Set("limit", strconv.FormatInt(limit, 10))
+	params.Set("spss", strconv.FormatInt(spss, 10))
+	if !query.TimeRange.From.IsZero() && !query.TimeRange.To.IsZero() {
+		params.Set("start", strconv.FormatInt(query.TimeRange.From.Unix(), 10))
+		params.Set("end", strconv.FormatInt(query.TimeRange.To.Unix(), 10))
+	}
+
+	body, errResponse := s.traceQLRequest(ctx, dsInfo, "/api/search", params)
+	if errResponse != nil {
+		span.RecordError(errResponse.Error)
+		span.SetStatus(codes.Error, errResponse.Error.Error())
+		return errResponse
+	}
+
+	tableType := dataquery.SearchTableTypeTraces
+	if model.TableType != nil {
+		tableType = *model.TableType
+	}
+
+	var frame *data.Frame
+	if tableType == dataquery.SearchTableTypeRaw {
+		frame = data.NewFrame("Raw response", data.NewField("response", nil, []string{string(body)}))
+	} else {
+		searchResponse := &tempopb.SearchResponse{}
+		unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
+		if err := unmarshaler.Unmarshal(bytes.NewReader(body), searchResponse); err != nil {
+			ctxLogger.Error("Failed to unmarshal TraceQL search response", "error", err, "function", logEntrypoint())
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return &backend.DataResponse{Error: fmt.Errorf("failed to unmarshal TraceQL search response: %w", err), ErrorSource: backend.ErrorSourceDownstream}
+		}
+
+		if tableType == dataquery.SearchTableTypeSpans {
+			frame = traceQLSpansFrame(searchResponse.Traces, dsInfo)
+		} else {
+			frame = traceQLTracesFrame(searchResponse.Traces, dsInfo)
+		}
+	}
+
+	frame.RefID = query.RefID
+	frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeTable, ExecutedQueryString: traceQL}
+	ctxLogger.Debug("Successfully ran TraceQL search", "function", logEntrypoint())
+	return &backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// traceLink returns the link running a trace query with the trace ID of the field value.
+func traceLink(dsInfo *Datasource, traceID string) data.DataLink {
+	return data.DataLink{
+		Title: "Trace: ${__value.raw}",
+		Internal: &data.InternalDataLink{
+			DatasourceUID:  dsInfo.UID,
+			DatasourceName: dsInfo.Name,
+			Query: map[string]any{
+				"query":     traceID,
+				"queryType": string(dataquery.TempoQueryTypeTraceql),
+			},
+		},
+	}
+}
+
+// traceQLTracesFrame returns a table with a row per trace, the newest traces first.
+func traceQLTracesFrame(traces []*tempopb.TraceSearchMetadata, dsInfo *Datasource) *data.Frame {
+	traces = append([]*tempopb.TraceSearchMetadata(nil), traces...)
+	sort.SliceStable(traces, func(i, j int) bool {
+		return traces[i].StartTimeUnixNano > traces[j].StartTimeUnixNano
+	})
+
+	traceIDs := data.NewField("traceID", nil, make([]string, len(traces)))
+	traceIDs.Config = &data.FieldConfig{
+		DisplayNameFromDS: "Trace ID",
+		Links:             []data.DataLink{traceLink(dsInfo, "${__value.raw}")},
+	}
+	startTimes := data.NewField("startTime", nil, make([]time.Time, len(traces)))
+	startTimes.Config = &data.FieldConfig{DisplayNameFromDS: "Start time"}
+	services := data.NewField("traceService", nil, make([]string, len(traces)))
+	services.Config = &data.FieldConfig{DisplayNameFromDS: "Service"}
+	names := data.NewField("traceName", nil, make([]string, len(traces)))
+	names.Config = &data.FieldConfig{DisplayNameFromDS: "Name"}
+	durations := data.NewField("traceDuration", nil, make([]float64, len(traces)))
+	durations.Config = &data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}
+
+	for i, t := range traces {
+		traceIDs.Set(i, t.TraceID)
+		startTimes.Set(i, time.Unix(0, int64(t.StartTimeUnixNano)))
+		services.Set(i, t.RootServiceName)
+		names.Set(i, t.RootTraceName)
+		durations.Set(i, float64(t.DurationMs))
+	}
+
+	return data.NewFrame("Traces", traceIDs, startTimes, services, names, durations)
+}
+
+// traceQLSpansFrame returns a table with a row per matched span, with a column per span
+// and span set attribute.
+func traceQLSpansFrame(traces []*tempopb.TraceSearchMetadata, dsInfo *Datasource) *data.Frame {
+	type spanRow struct {
+		trace      *tempopb.TraceSearchMetadata
+		span       *tempopb.Span
+		attributes map[string]string
+	}
+
+	var rows []spanRow
+	attributeKeys := map[string]bool{}
+	for _, t := range traces {
+		spanSets := t.SpanSets
+		if len(spanSets) == 0 && t.SpanSet != nil {
+			spanSets = []*tempopb.SpanSet{t.SpanSet}
+		}
+		for _, spanSet := range spanSets {
+			for _, span := range spanSet.Spans {
+				row := spanRow{trace: t, span: span, attributes: map[string]string{}}
+				for _, attributes := range [][]*v1.KeyValue{spanSet.Attributes, span.Attributes} {
+					for _, kv := range attributes {
+						row.attributes[kv.Key] = anyValueString(kv.Value)
+						attributeKeys[kv.Key] = true
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(attributeKeys))
+	for key := range attributeKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	traceIDs := data.NewField("traceIdHidden", nil, make([]string, len(rows)))
+	traceIDs.Config = &data.FieldConfig{Custom: map[string]any{"hidden": true}}
+	services := data.NewField("traceService", nil, make([]string, len(rows)))
+	services.Config = &data.FieldConfig{DisplayNameFromDS: "Trace Service"}
+	traceNames := data.NewField("traceName", nil, make([]string, len(rows)))
+	traceNames.Config = &data.FieldConfig{DisplayNameFromDS: "Trace Name"}
+	spanIDs := data.NewField("spanID", nil, make([]string, len(rows)))
+	spanIDs.Config = &data.FieldConfig{
+		DisplayNameFromDS: "Span ID",
+		Links:             []data.DataLink{traceLink(dsInfo, "${__data.fields.traceIdHidden}")},
+	}
+	startTimes := data.NewField("time", nil, make([]time.Time, len(rows)))
+	startTimes.Config = &data.FieldConfig{DisplayNameFromDS: "Start time"}
+	names := data.NewField("name", nil, make([]string, len(rows)))
+	names.Config = &data.FieldConfig{DisplayNameFromDS: "Name"}
+	attributeFields := make([]*data.Field, len(keys))
+	for i, key := range keys {
+		attributeFields[i] = data.NewField(key, nil, make([]*string, len(rows)))
+	}
+	durations := data.NewField("duration", nil, make([]float64, len(rows)))
+	durations.Config = &data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ns"}
+
+	for i, row := range rows {
+		traceIDs.Set(i, row.trace.TraceID)
+		services.Set(i, row.trace.RootServiceName)
+		traceNames.Set(i, row.trace.RootTraceName)
+		spanIDs.Set(i, row.span.SpanID)
+		startTimes.Set(i, time.Unix(0, int64(row.span.StartTimeUnixNano)))
+		names.Set(i, row.span.Name)
+		for j, key := range keys {
+			if value, ok := row.attributes[key]; ok {
+				attributeFields[j].Set(i, &value)
+			}
+		}
+		durations.Set(i, float64(row.span.DurationNanos))
+	}
+
+	fields := []*data.Field{traceIDs, services, traceNames, spanIDs, startTimes, names}
+	fields = append(fields, attributeFields...)
+	fields = append(fields, durations)
+	return data.NewFrame("Spans", fields...)
+}
+
+func anyValueString(value *v1.AnyValue) string {
+	switch value.GetValue().(type) {
+	case *v1.AnyValue_StringValue:
+		return value.GetStringValue()
+	case *v1.AnyValue_IntValue:
+		return strconv.FormatInt(value.GetIntValue(), 10)
+	case *v1.AnyValue_DoubleValue:
+		return strconv.FormatFloat(value.GetDoubleValue(), 'f', -1, 64)
+	case *v1.AnyValue_BoolValue:
+		return strconv.FormatBool(value.GetBoolValue())
+	}
+	return ""
+}
@@ -0,0 +1,298 @@
+package tempo
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+// tempoStub serves recorded Tempo responses and records the received requests.
+type tempoStub struct {
+	status   int
+	body     []byte
+	requests []*http.Request
+}
+
+func (ts *tempoStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.requests = append(ts.requests, r)
+	w.WriteHeader(ts.status)
+	_, _ = w.Write(ts.body)
+}
+
+func newTraceQLTestService(t *testing.T, status int, body []byte) (*Service, *tempoStub) {
+	t.Helper()
+
+	stub := &tempoStub{status: status, body: body}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	dsInfo := &Datasource{HTTPClient: server.Client(), URL: server.URL, UID: "tempo-uid", Name: "Tempo"}
+	return &Service{
+		logger: backend.NewLoggerWith("logger", "tsdb.tempo.test"),
+		im: datasource.NewInstanceManager(func(_ context.Context, _ backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return dsInfo, nil
+		}),
+	}, stub
+}
+
+func runTraceQLTestQuery(t *testing.T, s *Service, queryType string, model string) backend.DataResponse {
+	t.Helper()
+
+	from := time.Unix(1700000000, 0)
+	resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{}},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			QueryType: queryType,
+			JSON:      []byte(model),
+			Interval:  30 * time.Second,
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		}},
+	})
+	require.NoError(t, err)
+	return resp.Responses["A"]
+}
+
+func readTestData(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile("testData/" + name)
+	require.NoError(t, err)
+	return body
+}
+
+func TestTraceQLSearch(t *testing.T) {
+	body := readTestData(t, "traceql_search_response.json")
+
+	t.Run("traces table", func(t *testing.T) {
+		s, stub := newTraceQLTestService(t, http.StatusOK, body)
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{ resource.service.name = \"frontend\" }", "limit": 50}`)
+		require.NoError(t, res.Error)
+
+		require.Len(t, stub.requests, 1)
+		assert.Equal(t, "/api/search", stub.requests[0].URL.Path)
+		assert.Equal(t, url.Values{
+			"q":     {`{ resource.service.name = "frontend" }`},
+			"limit": {"50"},
+			"spss":  {"3"},
+			"start": {"1700000000"},
+			"end":   {"1700003600"},
+		}, stub.requests[0].URL.Query())
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		assert.Equal(t, "Traces", frame.Name)
+		assert.Equal(t, "A", frame.RefID)
+		assert.Equal(t, data.VisType(data.VisTypeTable), frame.Meta.PreferredVisualization)
+		require.Equal(t, 2, frame.Rows())
+
+		// newest traces first
+		assert.Equal(t, "7a8b9c0d", frame.Fields[0].At(0))
+		assert.Equal(t, "1a2b3c4d5e6f", frame.Fields[0].At(1))
+		assert.Equal(t, time.Unix(1700000060, 0), frame.Fields[1].At(0))
+		assert.Equal(t, "checkout", frame.Fields[2].At(0))
+		assert.Equal(t, "POST /api/orders", frame.Fields[3].At(0))
+		assert.Equal(t, 350.0, frame.Fields[4].At(0))
+
+		link := frame.Fields[0].Config.Links[0]
+		assert.Equal(t, "tempo-uid", link.Internal.DatasourceUID)
+		assert.Equal(t, map[string]any{"query": "${__value.raw}", "queryType": "traceql"}, link.Internal.Query)
+	})
+
+	t.Run("spans table", func(t *testing.T) {
+		s, _ := newTraceQLTestService(t, http.StatusOK, body)
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{}", "tableType": "spans"}`)
+		require.NoError(t, res.Error)
+
+		frame := res.Frames[0]
+		assert.Equal(t, "Spans", frame.Name)
+		require.Equal(t, 2, frame.Rows())
+
+		names := make([]string, len(frame.Fields))
+		for i, f := range frame.Fields {
+			names[i] = f.Name
+		}
+		assert.Equal(t, []string{"traceIdHidden", "traceService", "traceName", "spanID", "time", "name",
+			"db.system", "http.status_code", "retry", "duration"}, names)
+
+		assert.Equal(t, "1a2b3c4d5e6f", frame.Fields[0].At(0))
+		assert.Equal(t, "aa11", frame.Fields[3].At(0))
+		assert.Equal(t, "postgresql", *frame.Fields[6].At(0).(*string))
+		assert.Equal(t, "200", *frame.Fields[7].At(0).(*string))
+		assert.Nil(t, frame.Fields[8].At(0))
+		assert.Equal(t, "true", *frame.Fields[8].At(1).(*string))
+		assert.Equal(t, 45000000.0, frame.Fields[9].At(0))
+	})
+
+	t.Run("raw table", func(t *testing.T) {
+		s, _ := newTraceQLTestService(t, http.StatusOK, body)
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{}", "tableType": "raw"}`)
+		require.NoError(t, res.Error)
+		assert.JSONEq(t, string(body), res.Frames[0].Fields[0].At(0).(string))
+	})
+
+	t.Run("search with filters", func(t *testing.T) {
+		s, stub := newTraceQLTestService(t, http.StatusOK, body)
+		res := runTraceQLTestQuery(t, s, "traceqlSearch", `{"filters": [
+			{"id": "service-name", "tag": "service.name", "operator": "=", "scope": "resource", "value": ["frontend"], "valueType": "string"},
+			{"id": "min-duration", "tag": "duration", "operator": ">", "value": "100ms", "valueType": "duration"}
+		]}`)
+		require.NoError(t, res.Error)
+		assert.Equal(t, `{resource.service.name="frontend" && duration>100ms}`, stub.requests[0].URL.Query().Get("q"))
+	})
+
+	t.Run("trace ID query gets the trace", func(t *testing.T) {
+		s, stub := newTraceQLTestService(t, http.StatusNotFound, nil)
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "1a2b3c4d5e6f"}`)
+		require.Error(t, res.Error)
+		assert.Equal(t, "/api/traces/1a2b3c4d5e6f", stub.requests[0].URL.Path)
+	})
+}
+
+func TestTraceQLMetrics(t *testing.T) {
+	t.Run("time series", func(t *testing.T) {
+		s, stub := newTraceQLTestService(t, http.StatusOK, readTestData(t, "traceql_metrics_response.json"))
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{} | rate() by (resource.service.name)"}`)
+		require.NoError(t, res.Error)
+
+		require.Len(t, stub.requests, 1)
+		assert.Equal(t, "/api/metrics/query_range", stub.requests[0].URL.Path)
+		assert.Equal(t, url.Values{
+			"query": {"{} | rate() by (resource.service.name)"},
+			"start": {"1700000000"},
+			"end":   {"1700003600"},
+			"step":  {"30s"},
+		}, stub.requests[0].URL.Query())
+
+		require.Len(t, res.Frames, 2)
+		frame := res.Frames[0]
+		assert.Equal(t, "frontend", frame.Name)
+		assert.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		assert.Equal(t, time.UnixMilli(1700000060000), frame.Fields[0].At(1))
+		assert.Equal(t, 2.0, frame.Fields[1].At(1))
+		assert.Equal(t, data.Labels{"resource.service.name": "frontend"}, frame.Fields[1].Labels)
+		assert.Equal(t, "frontend", frame.Fields[1].Config.DisplayNameFromDS)
+
+		assert.True(t, math.IsNaN(res.Frames[1].Fields[1].At(0).(float64)))
+	})
+
+	t.Run("series names", func(t *testing.T) {
+		stringValue := func(s string) traceQLMetricsLabel {
+			l := traceQLMetricsLabel{Key: "k" + s}
+			l.Value.StringValue = &s
+			return l
+		}
+
+		frames, err := traceQLMetricsFrames("{} | rate()", traceQLMetricsResponse{Series: []traceQLMetricsSeries{{}}})
+		require.NoError(t, err)
+		assert.Equal(t, "{} | rate()", frames[0].Name)
+
+		frames, err = traceQLMetricsFrames("{} | rate()", traceQLMetricsResponse{Series: []traceQLMetricsSeries{
+			{Labels: []traceQLMetricsLabel{stringValue("a"), stringValue("b")}},
+			{},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, "{ka=a, kb=b}", frames[0].Name)
+		assert.Equal(t, "", frames[1].Name)
+	})
+
+	t.Run("metrics step", func(t *testing.T) {
+		assert.Equal(t, "1s", metricsStep(0))
+		assert.Equal(t, "1s", metricsStep(100*time.Millisecond))
+		assert.Equal(t, "60s", metricsStep(time.Minute))
+	})
+}
+
+func TestTraceQLErrors(t *testing.T) {
+	t.Run("bad request is a downstream error", func(t *testing.T) {
+		s, _ := newTraceQLTestService(t, http.StatusBadRequest, []byte("invalid TraceQL query: parse error at line 1, col 3: syntax error"))
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{ ."}`)
+		require.Error(t, res.Error)
+		assert.Equal(t, "failed to run TraceQL query: invalid TraceQL query: parse error at line 1, col 3: syntax error", res.Error.Error())
+		assert.Equal(t, backend.StatusBadRequest, res.Status)
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+
+	t.Run("server error is a downstream error", func(t *testing.T) {
+		s, _ := newTraceQLTestService(t, http.StatusInternalServerError, nil)
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{} | rate()"}`)
+		require.Error(t, res.Error)
+		assert.Equal(t, "failed to run TraceQL query: 500 Internal Server Error", res.Error.Error())
+		assert.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		s, _ := newTraceQLTestService(t, http.StatusOK, []byte("not json"))
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": "{}"}`)
+		require.Error(t, res.Error)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		s, stub := newTraceQLTestService(t, http.StatusOK, nil)
+		res := runTraceQLTestQuery(t, s, "traceql", `{"query": " "}`)
+		require.Error(t, res.Error)
+		assert.Empty(t, stub.requests)
+	})
+}
+
+func TestQueryFromFilters(t *testing.T) {
+	str := func(s string) *string { return &s }
+	value := func(v any) *any { return &v }
+	scope := func(s dataquery.TraceqlSearchScope) *dataquery.TraceqlSearchScope { return &s }
+
+	tests := map[string]struct {
+		filters  []dataquery.TraceqlFilter
+		expected string
+	}{
+		"no filters": {expected: "{}"},
+		"incomplete filters are ignored": {
+			filters: []dataquery.TraceqlFilter{
+				{Id: "a", Tag: str("name"), Operator: str("=")},
+				{Id: "b", Operator: str("="), Value: value("x")},
+				{Id: "c", Tag: str("name"), Operator: str("="), Value: value("")},
+			},
+			expected: "{}",
+		},
+		"scopes": {
+			filters: []dataquery.TraceqlFilter{
+				{Id: "a", Tag: str("service.name"), Operator: str("="), Scope: scope(dataquery.TraceqlSearchScopeResource), Value: value("api"), ValueType: str("string")},
+				{Id: "b", Tag: str("http.status_code"), Operator: str(">="), Scope: scope(dataquery.TraceqlSearchScopeSpan), Value: value("500"), ValueType: str("int")},
+				{Id: "c", Tag: str("team"), Operator: str("="), Scope: scope(dataquery.TraceqlSearchScopeUnscoped), Value: value("x"), ValueType: str("string")},
+				{Id: "d", Tag: str("status"), Operator: str("="), Scope: scope(dataquery.TraceqlSearchScopeIntrinsic), Value: value("error"), ValueType: str("keyword")},
+			},
+			expected: `{resource.service.name="api" && span.http.status_code>=500 && .team="x" && status=error}`,
+		},
+		"multiple values": {
+			filters: []dataquery.TraceqlFilter{
+				{Id: "a", Tag: str("name"), Operator: str("=~"), Value: value([]any{"GET", "POST"}), ValueType: str("string")},
+			},
+			expected: `{name=~"GET|POST"}`,
+		},
+		"trace duration": {
+			filters: []dataquery.TraceqlFilter{
+				{Id: "duration-type", Value: value("trace")},
+				{Id: "min-duration", Tag: str("duration"), Operator: str(">"), Value: value("1s"), ValueType: str("duration")},
+			},
+			expected: `{traceDuration>1s}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, queryFromFilters(tt.filters))
+		})
+	}
+}
@@ -39,7 +39,14 @@ export function useSaveDashboard(isCopy = false) {
 
         // important that these happen before location redirect below
         appEvents.publish(new DashboardSavedEvent());
-        notifyApp.success('Dashboard saved');
+        if (resultData.provisioningError) {
+          notifyApp.warning(
+            'Dashboard saved, but not written back to its provisioning source',
+            resultData.provisioningError
+          );
+        } else {
+          notifyApp.success('Dashboard saved');
+        }
 
         //Update local storage dashboard to handle things like last used datasource
         updateDashboardUidLastUsedDatasource(resultData.uid);
@@ -50,7 +50,14 @@ export const useDashboardSave = (isCopy = false) => {
 
         // important that these happen before location redirect below
         appEvents.publish(new DashboardSavedEvent());
-        notifyApp.success('Dashboard saved');
+        if (result.provisioningError) {
+          notifyApp.warning(
+            'Dashboard saved, but not written back to its provisioning source',
+            result.provisioningError
+          );
+        } else {
+          notifyApp.success('Dashboard saved');
+        }
 
         //Update local storage dashboard to handle things like last used datasource
         updateDashboardUidLastUsedDatasource(result.uid);
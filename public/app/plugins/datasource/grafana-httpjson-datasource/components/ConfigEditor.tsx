@@ -0,0 +1,63 @@
+import React, { ChangeEvent } from 'react';
+
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { config } from '@grafana/runtime';
+import { DataSourceHttpSettings, InlineField, InlineFieldRow, Input } from '@grafana/ui';
+
+import { HTTPJSONOptions } from '../types';
+
+export const ConfigEditor = (props: DataSourcePluginOptionsEditorProps<HTTPJSONOptions>) => {
+  const { options, onOptionsChange } = props;
+
+  const onMaxResponseSizeChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const value = parseInt(event.currentTarget.value, 10);
+    onOptionsChange({
+      ...options,
+      jsonData: { ...options.jsonData, maxResponseSize: value > 0 ? value : undefined },
+    });
+  };
+
+  return (
+    <>
+      <DataSourceHttpSettings
+        defaultUrl="http://localhost:8080"
+        dataSourceConfig={options}
+        onChange={onOptionsChange}
+        secureSocksDSProxyEnabled={config.secureSocksDSProxyEnabled}
+      />
+
+      <h3 className="page-heading">Health check</h3>
+      <InlineFieldRow>
+        <InlineField
+          label="Path"
+          labelWidth={20}
+          tooltip="Path requested by Save & test, relative to the URL. The URL itself is requested if it is empty."
+        >
+          <Input
+            width={40}
+            value={options.jsonData.healthCheckPath || ''}
+            placeholder="health"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'healthCheckPath')}
+          />
+        </InlineField>
+      </InlineFieldRow>
+
+      <h3 className="page-heading">Limits</h3>
+      <InlineFieldRow>
+        <InlineField
+          label="Max response size"
+          labelWidth={20}
+          tooltip="Maximum number of bytes read from the responses of all pages of a query. Defaults to 10485760, or 10 MiB."
+        >
+          <Input
+            type="number"
+            width={40}
+            value={options.jsonData.maxResponseSize ?? ''}
+            placeholder="10485760"
+            onChange={onMaxResponseSizeChange}
+          />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
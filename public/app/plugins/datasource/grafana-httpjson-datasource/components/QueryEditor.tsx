@@ -0,0 +1,298 @@
+import React from 'react';
+
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import {
+  Button,
+  IconButton,
+  InlineField,
+  InlineFieldRow,
+  Input,
+  RadioButtonGroup,
+  Select,
+  TextArea,
+} from '@grafana/ui';
+
+import { HTTPJSONDataSource } from '../datasource';
+import {
+  FieldQuery,
+  FieldType,
+  HTTPJSONOptions,
+  HTTPJSONQuery,
+  KeyValue,
+  Pagination,
+  PaginationMode,
+  QueryLanguage,
+} from '../types';
+
+type Props = QueryEditorProps<HTTPJSONDataSource, HTTPJSONQuery, HTTPJSONOptions>;
+
+const methodOptions: Array<SelectableValue<'GET' | 'POST'>> = [
+  { label: 'GET', value: 'GET' },
+  { label: 'POST', value: 'POST' },
+];
+
+const languageOptions: Array<SelectableValue<QueryLanguage>> = [
+  { label: 'JSONPath', value: 'jsonpath' },
+  { label: 'JMESPath', value: 'jmespath' },
+];
+
+const typeOptions: Array<SelectableValue<FieldType>> = [
+  { label: 'Auto', value: '' },
+  { label: 'String', value: 'string' },
+  { label: 'Number', value: 'number' },
+  { label: 'Boolean', value: 'boolean' },
+  { label: 'Time', value: 'time' },
+];
+
+const paginationOptions: Array<SelectableValue<PaginationMode>> = [
+  { label: 'None', value: '' },
+  { label: 'Offset', value: 'offset' },
+  { label: 'Page', value: 'page' },
+  { label: 'Cursor', value: 'cursor' },
+];
+
+const LABEL_WIDTH = 14;
+
+export const QueryEditor = ({ query, onChange, onRunQuery }: Props) => {
+  const method = query.method ?? 'GET';
+  const fields = query.fields?.length ? query.fields : [{ path: '' }];
+  const pagination: Pagination = query.pagination ?? { mode: '' };
+
+  const onFieldChange = (index: number, field: Partial<FieldQuery>) => {
+    onChange({ ...query, fields: fields.map((f, i) => (i === index ? { ...f, ...field } : f)) });
+  };
+
+  const onPaginationChange = (value: Partial<Pagination>) => {
+    onChange({ ...query, pagination: { ...pagination, ...value } });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Method" labelWidth={LABEL_WIDTH}>
+          <RadioButtonGroup
+            options={methodOptions}
+            value={method}
+            onChange={(value) => onChange({ ...query, method: value })}
+          />
+        </InlineField>
+        <InlineField label="Path" tooltip="Path relative to the URL of the data source" grow>
+          <Input
+            value={query.path ?? ''}
+            placeholder="api/v1/items"
+            onChange={(e) => onChange({ ...query, path: e.currentTarget.value })}
+            onBlur={onRunQuery}
+          />
+        </InlineField>
+      </InlineFieldRow>
+
+      <KeyValueEditor
+        label="Params"
+        values={query.params ?? []}
+        onChange={(params) => onChange({ ...query, params })}
+        onBlur={onRunQuery}
+      />
+      <KeyValueEditor
+        label="Headers"
+        values={query.headers ?? []}
+        onChange={(headers) => onChange({ ...query, headers })}
+        onBlur={onRunQuery}
+      />
+
+      {method === 'POST' && (
+        <InlineFieldRow>
+          <InlineField
+            label="Body"
+            labelWidth={LABEL_WIDTH}
+            tooltip="Use $__unixEpochFrom(), $__unixEpochTo(), $__timeFrom() and $__timeTo() for the time range"
+            grow
+          >
+            <TextArea
+              rows={4}
+              value={query.body ?? ''}
+              onChange={(e) => onChange({ ...query, body: e.currentTarget.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+        </InlineFieldRow>
+      )}
+
+      {fields.map((field, index) => (
+        <InlineFieldRow key={index}>
+          <InlineField label={index === 0 ? 'Fields' : ''} labelWidth={LABEL_WIDTH}>
+            <Select
+              width={14}
+              options={languageOptions}
+              value={field.language ?? 'jsonpath'}
+              onChange={(v) => onFieldChange(index, { language: v.value })}
+            />
+          </InlineField>
+          <InlineField grow>
+            <Input
+              value={field.path}
+              placeholder={field.language === 'jmespath' ? 'items[].value' : '$.items[*].value'}
+              onChange={(e) => onFieldChange(index, { path: e.currentTarget.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+          <InlineField label="Name">
+            <Input
+              width={16}
+              value={field.name ?? ''}
+              onChange={(e) => onFieldChange(index, { name: e.currentTarget.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+          <InlineField label="Type">
+            <Select
+              width={12}
+              options={typeOptions}
+              value={field.type ?? ''}
+              onChange={(v) => {
+                onFieldChange(index, { type: v.value });
+                onRunQuery();
+              }}
+            />
+          </InlineField>
+          {field.type === 'time' && (
+            <InlineField label="Format" tooltip="Go layout of time strings, RFC 3339 if empty">
+              <Input
+                width={20}
+                value={field.timeFormat ?? ''}
+                placeholder="2006-01-02 15:04:05"
+                onChange={(e) => onFieldChange(index, { timeFormat: e.currentTarget.value })}
+                onBlur={onRunQuery}
+              />
+            </InlineField>
+          )}
+          <IconButton
+            name="trash-alt"
+            tooltip="Remove field"
+            onClick={() => onChange({ ...query, fields: fields.filter((_, i) => i !== index) })}
+          />
+        </InlineFieldRow>
+      ))}
+      <Button
+        variant="secondary"
+        size="sm"
+        icon="plus"
+        onClick={() => onChange({ ...query, fields: [...fields, { path: '' }] })}
+      >
+        Add field
+      </Button>
+
+      <InlineFieldRow>
+        <InlineField label="Pagination" labelWidth={LABEL_WIDTH}>
+          <RadioButtonGroup
+            options={paginationOptions}
+            value={pagination.mode}
+            onChange={(mode) => onPaginationChange({ mode })}
+          />
+        </InlineField>
+        {pagination.mode && (
+          <>
+            <InlineField label="Param">
+              <Input
+                width={12}
+                value={pagination.param ?? ''}
+                placeholder={pagination.mode}
+                onChange={(e) => onPaginationChange({ param: e.currentTarget.value })}
+              />
+            </InlineField>
+            <InlineField label="Size param">
+              <Input
+                width={12}
+                value={pagination.sizeParam ?? ''}
+                placeholder={pagination.mode === 'offset' ? 'limit' : ''}
+                onChange={(e) => onPaginationChange({ sizeParam: e.currentTarget.value })}
+              />
+            </InlineField>
+            <InlineField label="Size">
+              <Input
+                type="number"
+                width={8}
+                value={pagination.size ?? ''}
+                onChange={(e) => onPaginationChange({ size: e.currentTarget.valueAsNumber || undefined })}
+              />
+            </InlineField>
+            <InlineField label="Max pages" tooltip="At most 100 pages are requested">
+              <Input
+                type="number"
+                width={8}
+                value={pagination.maxPages ?? ''}
+                placeholder="10"
+                onChange={(e) => onPaginationChange({ maxPages: e.currentTarget.valueAsNumber || undefined })}
+              />
+            </InlineField>
+          </>
+        )}
+      </InlineFieldRow>
+      {pagination.mode === 'cursor' && (
+        <InlineFieldRow>
+          <InlineField label="Cursor" labelWidth={LABEL_WIDTH}>
+            <Select
+              width={14}
+              options={languageOptions}
+              value={pagination.cursorLanguage ?? 'jsonpath'}
+              onChange={(v) => onPaginationChange({ cursorLanguage: v.value })}
+            />
+          </InlineField>
+          <InlineField grow>
+            <Input
+              value={pagination.cursorPath ?? ''}
+              placeholder="$.next"
+              onChange={(e) => onPaginationChange({ cursorPath: e.currentTarget.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+        </InlineFieldRow>
+      )}
+    </>
+  );
+};
+
+interface KeyValueEditorProps {
+  label: string;
+  values: KeyValue[];
+  onChange: (values: KeyValue[]) => void;
+  onBlur: () => void;
+}
+
+const KeyValueEditor = ({ label, values, onChange, onBlur }: KeyValueEditorProps) => {
+  const onValueChange = (index: number, value: Partial<KeyValue>) => {
+    onChange(values.map((v, i) => (i === index ? { ...v, ...value } : v)));
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label={label} labelWidth={LABEL_WIDTH}>
+        <Button
+          variant="secondary"
+          size="sm"
+          icon="plus"
+          aria-label={`Add ${label.toLowerCase()}`}
+          onClick={() => onChange([...values, { key: '', value: '' }])}
+        />
+      </InlineField>
+      {values.map((value, index) => (
+        <InlineFieldRow key={index}>
+          <Input
+            width={16}
+            value={value.key}
+            placeholder="key"
+            onChange={(e) => onValueChange(index, { key: e.currentTarget.value })}
+            onBlur={onBlur}
+          />
+          <Input
+            width={24}
+            value={value.value}
+            placeholder="value"
+            onChange={(e) => onValueChange(index, { value: e.currentTarget.value })}
+            onBlur={onBlur}
+          />
+          <IconButton name="times" tooltip="Remove" onClick={() => onChange(values.filter((_, i) => i !== index))} />
+        </InlineFieldRow>
+      ))}
+    </InlineFieldRow>
+  );
+};
@@ -0,0 +1,33 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend, getTemplateSrv, TemplateSrv } from '@grafana/runtime';
+
+import { HTTPJSONOptions, HTTPJSONQuery, KeyValue } from './types';
+
+export class HTTPJSONDataSource extends DataSourceWithBackend<HTTPJSONQuery, HTTPJSONOptions> {
+  constructor(
+    instanceSettings: DataSourceInstanceSettings<HTTPJSONOptions>,
+    private readonly templateSrv: TemplateSrv = getTemplateSrv()
+  ) {
+    super(instanceSettings);
+  }
+
+  filterQuery(query: HTTPJSONQuery): boolean {
+    return !query.hide && Boolean(query.fields?.some((field) => field.path));
+  }
+
+  // Time range macros such as $__unixEpochFrom() are replaced in the backend, so that alert rules can use them too.
+  applyTemplateVariables(query: HTTPJSONQuery, scopedVars: ScopedVars): HTTPJSONQuery {
+    const replace = (value?: string) => this.templateSrv.replace(value ?? '', scopedVars);
+    const replaceAll = (values?: KeyValue[]) =>
+      values?.map(({ key, value }) => ({ key: replace(key), value: replace(value) }));
+
+    return {
+      ...query,
+      path: replace(query.path),
+      params: replaceAll(query.params),
+      headers: replaceAll(query.headers),
+      body: replace(query.body),
+      fields: query.fields?.map((field) => ({ ...field, path: replace(field.path) })),
+    };
+  }
+}
@@ -0,0 +1,10 @@
+import { DataSourcePlugin } from '@grafana/data';
+
+import { ConfigEditor } from './components/ConfigEditor';
+import { QueryEditor } from './components/QueryEditor';
+import { HTTPJSONDataSource } from './datasource';
+import { HTTPJSONOptions, HTTPJSONQuery } from './types';
+
+export const plugin = new DataSourcePlugin<HTTPJSONDataSource, HTTPJSONQuery, HTTPJSONOptions>(HTTPJSONDataSource)
+  .setQueryEditor(QueryEditor)
+  .setConfigEditor(ConfigEditor);
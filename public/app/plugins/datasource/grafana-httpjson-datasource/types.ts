@@ -0,0 +1,45 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type QueryLanguage = 'jsonpath' | 'jmespath';
+
+export type FieldType = '' | 'string' | 'number' | 'boolean' | 'time';
+
+export type PaginationMode = '' | 'offset' | 'page' | 'cursor';
+
+export interface KeyValue {
+  key: string;
+  value: string;
+}
+
+export interface FieldQuery {
+  name?: string;
+  path: string;
+  language?: QueryLanguage;
+  type?: FieldType;
+  timeFormat?: string;
+}
+
+export interface Pagination {
+  mode: PaginationMode;
+  param?: string;
+  sizeParam?: string;
+  size?: number;
+  cursorPath?: string;
+  cursorLanguage?: QueryLanguage;
+  maxPages?: number;
+}
+
+export interface HTTPJSONQuery extends DataQuery {
+  method?: 'GET' | 'POST';
+  path?: string;
+  params?: KeyValue[];
+  headers?: KeyValue[];
+  body?: string;
+  fields?: FieldQuery[];
+  pagination?: Pagination;
+}
+
+export interface HTTPJSONOptions extends DataSourceJsonData {
+  healthCheckPath?: string;
+  maxResponseSize?: number;
+}
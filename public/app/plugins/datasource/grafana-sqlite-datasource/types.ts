@@ -0,0 +1,3 @@
+import { SQLOptions } from '@grafana/sql';
+
+export type SQLiteOptions = SQLOptions;
@@ -76,6 +76,34 @@ export const InfluxSqlConfig = (props: Props) => {
           }}
         />
       </Field>
+      <Field
+        horizontal
+        label={
+          <InlineLabel
+            width={WIDTH_SHORT}
+            tooltip="Maximum number of rows of query results. Defaults to the row_limit of the Grafana configuration."
+          >
+            Row limit
+          </InlineLabel>
+        }
+        className={styles.horizontalField}
+      >
+        <Input
+          id={`${htmlPrefix}-row-limit`}
+          className="width-20"
+          type="number"
+          value={jsonData.rowLimit ?? ''}
+          onChange={(event) => {
+            onOptionsChange({
+              ...options,
+              jsonData: {
+                ...jsonData,
+                rowLimit: event.currentTarget.valueAsNumber || undefined,
+              },
+            });
+          }}
+        />
+      </Field>
     </div>
   );
 };
@@ -1,4 +1,4 @@
-import { cloneDeep, extend, has, isString, map as _map, omit, pick, reduce } from 'lodash';
+import { castArray, cloneDeep, extend, has, isString, map as _map, omit, pick, reduce } from 'lodash';
 import { lastValueFrom, merge, Observable, of, throwError } from 'rxjs';
 import { catchError, map } from 'rxjs/operators';
 
@@ -31,6 +31,7 @@ import {
   FetchResponse,
   frameToMetricFindValue,
   getBackendSrv,
+  VariableInterpolation,
 } from '@grafana/runtime';
 import { QueryFormat, SQLQuery } from '@grafana/sql';
 import config from 'app/core/config';
@@ -289,12 +290,16 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
         (value: string | string[] = [], variable: QueryVariableModel) =>
           this.interpolateQueryExpr(value, variable, query.query)
       ), // The raw sql query text
-      rawSql: this.templateSrv.replace(
-        query.rawSql ?? '',
-        scopedVars,
-        (value: string | string[] = [], variable: QueryVariableModel) =>
-          this.interpolateQueryExpr(value, variable, query.rawSql)
-      ), // The raw sql query text
+      ...(this.version === InfluxVersion.SQL
+        ? this.applySQLVariables(query.rawSql ?? '', scopedVars)
+        : {
+            rawSql: this.templateSrv.replace(
+              query.rawSql ?? '',
+              scopedVars,
+              (value: string | string[] = [], variable: QueryVariableModel) =>
+                this.interpolateQueryExpr(value, variable, query.rawSql)
+            ), // The raw sql query text
+          }),
       alias: this.templateSrv.replace(query.alias ?? '', scopedVars),
       limit: this.templateSrv.replace(query.limit?.toString() ?? '', scopedVars),
       measurement: this.templateSrv.replace(
@@ -309,6 +314,42 @@ export default class InfluxDatasource extends DataSourceWithBackend<InfluxQuery,
     };
   }
 
+  /**
+   * Returns the SQL query with the values of its template variables, which the backend binds as parameters
+   * or interpolates depending on where they are referenced. Variables with a format or a field path, like
+   * ${hosts:csv}, built-in variables, like $__interval, and the deprecated [[var]] syntax, are still
+   * interpolated as text. References to macros, like $__timeFrom, are not variables and are left to the backend.
+   */
+  applySQLVariables(rawSql: string, scopedVars: ScopedVars): Pick<InfluxQuery & SQLQuery, 'rawSql' | 'variables'> {
+    const interpolatedSql = rawSql.replace(/\$\{\w+[:.][^}]*\}|\$\{?__\w+\}?|\[\[[^\]]+\]\]/g, (match) =>
+      this.templateSrv.replace(match, scopedVars, (value: string | string[] = [], variable: QueryVariableModel) =>
+        this.interpolateQueryExpr(value, variable, rawSql)
+      )
+    );
+
+    const interpolations: VariableInterpolation[] = [];
+    this.templateSrv.replace(
+      interpolatedSql,
+      scopedVars,
+      (value: string | string[] = []) => JSON.stringify(castArray(value)),
+      interpolations
+    );
+
+    const variables: Record<string, string[]> = {};
+    for (const { variableName, value, found } of interpolations) {
+      // References to macros, like $__timeFrom, are interpolated in the backend
+      if (!found || variableName.startsWith('__')) {
+        continue;
+      }
+      try {
+        variables[variableName] = castArray(JSON.parse(value)).map(String);
+      } catch {
+        variables[variableName] = [value];
+      }
+    }
+    return { rawSql: interpolatedSql, variables };
+  }
+
   interpolateQueryExpr(value: string | string[] = [], variable: QueryVariableModel, query?: string) {
     // If there is no query just return the value directly
     if (!query) {
@@ -13,7 +13,7 @@ config.featureToggles.influxdbBackendMigration = true;
 mockBackendService(mockInfluxSQLFetchResponse);
 
 describe('InfluxDB SQL Support', () => {
-  const replaceMock = jest.fn();
+  const replaceMock = jest.fn((target?: string) => target ?? '');
   const templateSrv = mockTemplateSrv(jest.fn(), replaceMock);
 
   let sqlQuery: SQLQuery;
@@ -29,11 +29,24 @@ describe('InfluxDB SQL Support', () => {
   describe('interpolate variables', () => {
     const ds = new InfluxDatasource(getMockDSInstanceSettings({ version: InfluxVersion.SQL }), templateSrv);
 
+    beforeEach(() => {
+      replaceMock.mockClear();
+    });
+
     it('should call replace template variables for rawSql', async () => {
       await lastValueFrom(ds.query(mockInfluxQueryRequest([sqlQuery])));
-      expect(replaceMock.mock.calls[1][0]).toBe(
+      expect(replaceMock.mock.calls.map((call) => call[0])).toContain(
         `SELECT "$interpolationVar2", time FROM iox.$interpolationVar WHERE time >= $__timeFrom AND time <= $__timeTo`
       );
     });
+
+    it('should interpolate built-in variables as text', () => {
+      replaceMock.mockImplementation((target?: string) => (target === '$__interval' ? '10s' : (target ?? '')));
+      const { rawSql } = ds.applySQLVariables(
+        "SELECT date_bin(interval '$__interval', time) FROM cpu WHERE time >= $__timeFrom",
+        {}
+      );
+      expect(rawSql).toBe("SELECT date_bin(interval '10s', time) FROM cpu WHERE time >= $__timeFrom");
+    });
   });
 });
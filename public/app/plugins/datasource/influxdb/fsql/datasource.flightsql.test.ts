@@ -44,7 +44,26 @@ describe('flightsql datasource', () => {
   const ds = new FlightSQLDatasource(instanceSettings, templateSrv);
 
   it('should add template variables to the responses', async () => {
+    jest.spyOn(ds, 'getResource').mockResolvedValue([{ name: 'time', type: 'timestamp[ns]', nullable: false }]);
     const fields = await ds.fetchFields({ dataset: 'test', table: 'table' });
     expect(fields[0].name).toBe('$templateVar');
   });
+
+  it('should fetch the tables of the dataset from the backend', async () => {
+    const getResource = jest
+      .spyOn(ds, 'getResource')
+      .mockResolvedValue([{ schema: 'iox', name: 'cpu', type: 'BASE TABLE' }]);
+    const tables = await ds.fetchTables('iox');
+    expect(getResource).toHaveBeenCalledWith('tables', { schema: 'iox' });
+    expect(tables).toEqual(['$templateVar', 'cpu']);
+  });
+
+  it('should fetch the columns of schema qualified tables from the backend', async () => {
+    const getResource = jest
+      .spyOn(ds, 'getResource')
+      .mockResolvedValue([{ name: 'usage_idle', type: 'float64', nullable: true }]);
+    const fields = await ds.fetchFields({ dataset: 'test', table: 'iox.cpu' });
+    expect(getResource).toHaveBeenCalledWith('columns', { schema: 'iox', table: 'cpu' });
+    expect(fields[1]).toMatchObject({ name: 'usage_idle', raqbFieldType: 'number' });
+  });
 });
@@ -4,10 +4,9 @@ import { getTemplateSrv, TemplateSrv } from '@grafana/runtime';
 import { DB, formatSQL, SqlDatasource, SQLQuery } from '@grafana/sql';
 
 import { mapFieldsToTypes } from './fields';
-import { buildColumnQuery, buildTableQuery } from './flightsqlMetaQuery';
 import { getSqlCompletionProvider } from './sqlCompletionProvider';
-import { quoteIdentifierIfNecessary, quoteLiteral, toRawSql } from './sqlUtil';
-import { FlightSQLOptions } from './types';
+import { quoteIdentifierIfNecessary, quoteLiteral, toRawSql, unquoteIdentifier } from './sqlUtil';
+import { FlightSQLColumn, FlightSQLOptions, FlightSQLTable } from './types';
 
 export class FlightSQLDatasource extends SqlDatasource {
   sqlLanguageDefinition: LanguageDefinition | undefined;
@@ -44,9 +43,8 @@ export class FlightSQLDatasource extends SqlDatasource {
   }
 
   async fetchTables(dataset?: string): Promise<string[]> {
-    const query = buildTableQuery(dataset);
-    const tables = await this.runSql<string[]>(query, { refId: 'tables' });
-    const tableNames = tables.map((t) => quoteIdentifierIfNecessary(t[0]));
+    const tables = await this.getResource<FlightSQLTable[]>('tables', dataset ? { schema: dataset } : {});
+    const tableNames = tables.map((t) => quoteIdentifierIfNecessary(t.name));
     tableNames.unshift(...this.getTemplateVariables());
     return tableNames;
   }
@@ -56,14 +54,20 @@ export class FlightSQLDatasource extends SqlDatasource {
       return [];
     }
     const interpolatedTable = this.templateSrv.replace(query.table);
-    const queryString = buildColumnQuery(interpolatedTable, query.dataset);
-    const frame = await this.runSql<string[]>(queryString, { refId: 'fields' });
-    const fields = frame.map((f) => ({
-      name: f[0],
-      text: f[0],
-      value: quoteIdentifierIfNecessary(f[0]),
-      type: f[1],
-      label: f[0],
+    // check for schema qualified table
+    const [schema, table] = interpolatedTable.includes('.')
+      ? interpolatedTable.split('.')
+      : [query.dataset, interpolatedTable];
+    const columns = await this.getResource<FlightSQLColumn[]>('columns', {
+      schema: unquoteIdentifier(schema),
+      table: unquoteIdentifier(table),
+    });
+    const fields = columns.map((c) => ({
+      name: c.name,
+      text: c.name,
+      value: quoteIdentifierIfNecessary(c.name),
+      type: c.type,
+      label: c.name,
     }));
     fields.unshift(
       ...this.getTemplateVariables().map((v) => ({
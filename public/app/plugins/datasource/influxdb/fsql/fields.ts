@@ -20,6 +20,7 @@ export function mapFieldsToTypes(columns: SQLSelectableValue[]) {
       case 'INT':
       case 'INTEGER':
       case 'INT64':
+      case 'UINT64':
       case 'NUMERIC':
       case 'BIGNUMERIC': {
         type = 'number';
@@ -30,6 +31,8 @@ export function mapFieldsToTypes(columns: SQLSelectableValue[]) {
         break;
       }
       case 'TIMESTAMP(NANOSECOND, NONE)':
+      case 'TIMESTAMP[NS]':
+      case 'TIMESTAMP[NS, TZ=UTC]':
       case 'DATETIME': {
         type = 'datetime';
         break;
@@ -60,6 +63,8 @@ export function mapColumnTypeToIcon(type: string) {
     case 'TIME':
     case 'DATETIME':
     case 'TIMESTAMP':
+    case 'TIMESTAMP[NS]':
+    case 'TIMESTAMP[NS, TZ=UTC]':
       return 'clock-nine';
     case 'BOOLEAN':
       return 'toggle-off';
@@ -72,12 +77,14 @@ export function mapColumnTypeToIcon(type: string) {
     case 'TINYINT':
     case 'BYTEINT':
     case 'INT64':
+    case 'UINT64':
     case 'NUMERIC':
     case 'DECIMAL':
       return 'calculator-alt';
     case 'CHAR':
     case 'VARCHAR':
     case 'STRING':
+    case 'UTF8':
     case 'BYTES':
     case 'TEXT':
     case 'TINYTEXT':
@@ -5,3 +5,17 @@ export interface FlightSQLOptions extends SQLOptions {
 }
 
 export interface FlightSQLQuery extends SQLQuery {}
+
+// A table of the tables resource of the backend
+export interface FlightSQLTable {
+  schema: string;
+  name: string;
+  type: string;
+}
+
+// A column of the columns resource of the backend
+export interface FlightSQLColumn {
+  name: string;
+  type: string;
+  nullable: boolean;
+}
@@ -24,6 +24,7 @@ export interface InfluxOptions extends DataSourceJsonData {
   // With SQL
   metadata?: Array<Record<string, string>>;
   insecureGrpc?: boolean;
+  rowLimit?: number;
 }
 
 /**
@@ -91,6 +92,8 @@ export interface InfluxQuery extends DataQuery {
 
   textEditor?: boolean;
   adhocFilters?: AdHocVariableFilter[];
+  // values of the template variables of SQL queries, which are bound as parameters in the backend
+  variables?: Record<string, string[]>;
 }
 
 export type MetadataQueryType = 'TAG_KEYS' | 'TAG_VALUES' | 'MEASUREMENTS' | 'FIELDS' | 'RETENTION_POLICIES';
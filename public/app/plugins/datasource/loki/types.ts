@@ -44,6 +44,8 @@ export interface LokiOptions extends DataSourceJsonData {
   alertmanager?: string;
   keepCookies?: string[];
   predefinedOperations?: string;
+  querySplitDuration?: string;
+  querySplitConcurrency?: number;
 }
 
 export interface LokiStreamResult {
@@ -32,6 +32,8 @@ export interface SaveDashboardResponseDTO {
   uid: string;
   url: string;
   version: number;
+  /** Set when a provisioned dashboard was saved, but could not be written back to its provisioning source */
+  provisioningError?: string;
 }
 
 export interface DashboardMeta {